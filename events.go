@@ -0,0 +1,86 @@
+// Copyright 2021 github.com/gagliardetto
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package solana
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+)
+
+var ErrEventDecoderNotFound = errors.New("event decoder not found")
+
+// AnchorEventDiscriminatorLength is the length of the discriminator
+// that prefixes the data of Anchor events.
+const AnchorEventDiscriminatorLength = 8
+
+// AnchorEventInstructionTag prefixes the data of the self-invocations
+// with which emit_cpi! emits Anchor events; the event follows.
+var AnchorEventInstructionTag = []byte{0xe4, 0x45, 0xa5, 0x2e, 0x51, 0xcb, 0x9a, 0x1d}
+
+// AnchorEventDiscriminator returns the discriminator of the Anchor event
+// with the provided name (the name of the event struct).
+func AnchorEventDiscriminator(name string) [AnchorEventDiscriminatorLength]byte {
+	var discriminator [AnchorEventDiscriminatorLength]byte
+	sum := sha256.Sum256([]byte("event:" + name))
+	copy(discriminator[:], sum[:])
+	return discriminator
+}
+
+// EventDecoder decodes the events emitted by a program.
+// The data starts with the discriminator of the event.
+type EventDecoder func(data []byte) (interface{}, error)
+
+var eventDecoderRegistry = newDecoderRegistry[EventDecoder]()
+
+// RegisterEventDecoder registers the decoder of the events emitted by the program,
+// used for both the "Program data:" logs and the emit_cpi! instructions.
+// Like RegisterInstructionDecoder, it panics if another decoder
+// is already registered for the program.
+func RegisterEventDecoder(programID PublicKey, decoder EventDecoder) {
+	prev, has := eventDecoderRegistry.Get(programID)
+	if has {
+		if isSameFunction(prev, decoder) {
+			return
+		}
+		panic(fmt.Sprintf("unable to re-register event decoder for program %s", programID))
+	}
+	eventDecoderRegistry.RegisterIfNew(programID, decoder)
+}
+
+// HasEventDecoder returns whether an event decoder is registered for the program.
+func HasEventDecoder(programID PublicKey) bool {
+	return eventDecoderRegistry.Has(programID)
+}
+
+// DecodeEvent decodes an event emitted by the program,
+// with the decoder registered for the program.
+func DecodeEvent(programID PublicKey, data []byte) (interface{}, error) {
+	decoder, found := eventDecoderRegistry.Get(programID)
+	if !found {
+		return nil, ErrEventDecoderNotFound
+	}
+	if len(data) < AnchorEventDiscriminatorLength {
+		return nil, fmt.Errorf("event data too short: %d bytes", len(data))
+	}
+	return decoder(data)
+}
+
+// IsAnchorEventInstruction returns whether the instruction data is
+// an event emitted with emit_cpi!.
+func IsAnchorEventInstruction(data []byte) bool {
+	return bytes.HasPrefix(data, AnchorEventInstructionTag)
+}
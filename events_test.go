@@ -0,0 +1,67 @@
+// Copyright 2021 github.com/gagliardetto
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package solana
+
+import (
+	"crypto/sha256"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAnchorEventInstructionTag(t *testing.T) {
+	// EVENT_IX_TAG_LE: the little-endian bytes of the first 8 bytes of sha256("anchor:event").
+	sum := sha256.Sum256([]byte("anchor:event"))
+	for i := range AnchorEventInstructionTag {
+		assert.Equal(t, sum[7-i], AnchorEventInstructionTag[i])
+	}
+	assert.True(t, IsAnchorEventInstruction(append(AnchorEventInstructionTag, 1, 2)))
+	assert.False(t, IsAnchorEventInstruction([]byte{1, 2}))
+
+	discriminator := AnchorEventDiscriminator("MyEvent")
+	sum = sha256.Sum256([]byte("event:MyEvent"))
+	assert.Equal(t, sum[:8], discriminator[:])
+}
+
+func TestRegisterEventDecoder(t *testing.T) {
+	programID := NewWallet().PublicKey()
+	decoder := func(data []byte) (interface{}, error) {
+		return len(data), nil
+	}
+	decoderAnother := func(data []byte) (interface{}, error) {
+		return nil, nil
+	}
+
+	_, err := DecodeEvent(programID, make([]byte, 8))
+	assert.ErrorIs(t, err, ErrEventDecoderNotFound)
+
+	assert.NotPanics(t, func() {
+		RegisterEventDecoder(programID, decoder)
+	})
+	assert.NotPanics(t, func() {
+		RegisterEventDecoder(programID, decoder)
+	})
+	assert.Panics(t, func() {
+		RegisterEventDecoder(programID, decoderAnother)
+	})
+	assert.True(t, HasEventDecoder(programID))
+
+	got, err := DecodeEvent(programID, make([]byte, 10))
+	require.NoError(t, err)
+	assert.Equal(t, 10, got)
+	_, err = DecodeEvent(programID, make([]byte, 7))
+	assert.Error(t, err)
+}
@@ -2,12 +2,15 @@ package addresslookuptable
 
 import (
 	"bytes"
+	"context"
 	"encoding/base64"
 	"math"
 	"testing"
 
 	bin "github.com/gagliardetto/binary"
 	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+	"github.com/gagliardetto/solana-go/rpc/rpcreplay"
 	"github.com/stretchr/testify/require"
 )
 
@@ -535,3 +538,23 @@ func TestDecodeTable(t *testing.T) {
 		}
 	}
 }
+
+func TestGetAddressLookupTable_Replay(t *testing.T) {
+	rep, err := rpcreplay.NewReplayerFromFile("testdata/get-address-lookup-table.replay.json")
+	require.NoError(t, err)
+	client := rpc.NewWithCustomRPCClient(rep)
+
+	table, err := GetAddressLookupTable(context.Background(), client, solana.MPK("2immgwYNHBbyVQKVGCEkgWpi53bLwWNRMB5G2nbgYV17"))
+	require.NoError(t, err)
+	require.NoError(t, rep.Err())
+
+	require.Equal(t, uint64(math.MaxUint64), table.DeactivationSlot)
+	require.Equal(t, uint64(154742572), table.LastExtendedSlot)
+	require.Equal(t, solana.MPK("9FRhPDoDk9JrpCqc4r51qTWgdBTxM892TdjexeErQUNs"), *table.Authority)
+	require.Equal(t, solana.MPK("9W959DqEETiGZocYWCQPaJ6sBmUzgfxXfqGeTEdp3aQP"), table.Addresses[0])
+
+	// Any other table is not in the fixture.
+	_, err = GetAddressLookupTable(context.Background(), client, solana.MPK("9W959DqEETiGZocYWCQPaJ6sBmUzgfxXfqGeTEdp3aQP"))
+	var unmatched *rpcreplay.UnmatchedRequestError
+	require.ErrorAs(t, err, &unmatched)
+}
@@ -23,6 +23,8 @@ import (
 	"os"
 	"testing"
 
+	bin "github.com/gagliardetto/binary"
+	"github.com/gagliardetto/solana-go/rpc/rpcreplay"
 	"github.com/gagliardetto/solana-go/rpc/ws"
 
 	"github.com/gagliardetto/solana-go/rpc"
@@ -69,3 +71,35 @@ func TestStreamOpenOrders(t *testing.T) {
 	err = StreamOpenOrders(context.Background(), client)
 	require.NoError(t, err)
 }
+
+func TestFetchMarket_Replay(t *testing.T) {
+	rep, err := rpcreplay.NewReplayerFromFile("testdata/fetch-market.replay.json")
+	require.NoError(t, err)
+	client := rpc.NewWithCustomRPCClient(rep)
+
+	market, err := FetchMarket(context.Background(), client, solana.MustPublicKeyFromBase58("ByRys5tuUWDgL73G8JBAEfkdFf8JWBzPBDHsBVQ5vbQA"))
+	require.NoError(t, err)
+	require.NoError(t, rep.Err())
+
+	require.True(t, market.MarketV2.AccountFlags.Is(AccountFlagMarket))
+	require.Equal(t, solana.MustPublicKeyFromBase58("SRMuApVNdxXokk5GT7XD5cUUgXMBCoAz2LHeuAoKWRt"), market.MarketV2.BaseMint)
+	require.Equal(t, solana.MustPublicKeyFromBase58("EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v"), market.MarketV2.QuoteMint)
+	require.Equal(t, bin.Uint64(100000), market.MarketV2.BaseLotSize)
+	require.Equal(t, uint8(6), market.BaseMint.Decimals)
+	require.Equal(t, uint8(6), market.QuoteMint.Decimals)
+	require.NotNil(t, market.QuoteMint.FreezeAuthority)
+}
+
+func TestFetchOpenOrders_Replay(t *testing.T) {
+	rep, err := rpcreplay.NewReplayerFromFile("testdata/fetch-open-orders.replay.json")
+	require.NoError(t, err)
+	client := rpc.NewWithCustomRPCClient(rep)
+
+	openOrders, err := FetchOpenOrders(context.Background(), client, solana.MustPublicKeyFromBase58("jFoHUkNDC767PyK11cZM4zyNcpjLqFnSjaqEYp5GVBr"))
+	require.NoError(t, err)
+	require.NoError(t, rep.Err())
+
+	var expected OpenOrders
+	require.NoError(t, expected.Decode(readHexFile(t, "testdata/serum-open-orders-new.hex")))
+	require.Equal(t, expected, openOrders.OpenOrders)
+}
@@ -27,21 +27,26 @@ import (
 )
 
 type DepositSol struct {
+	Lamports *uint64
 	// [0] = [WRITE] stakePool
 	// [1] = [] withdrawAuthority
 	// [2] = [WRITE] reserveStake
-	// [3] = [SIGNER] fundingAccount
+	// [3] = [WRITE, SIGNER] fundingAccount
 	// [4] = [WRITE] destinationAccount
 	// [5] = [WRITE] managerFeeAccount
 	// [6] = [WRITE] referralFeeAccount
 	// [7] = [WRITE] poolMint
 	// [8] = [] systemProgram
 	// [9] = [] tokenProgram
+	// [10] = [SIGNER] solDepositAuthority (optional)
 	Accounts ag_solanago.AccountMetaSlice `bin:"-" borsh_skip:"true"`
 	Signers  ag_solanago.AccountMetaSlice `bin:"-" borsh_skip:"true"`
 }
 
 func NewDepositSolInstruction(
+	// Parameters:
+	lamports uint64,
+	// Accounts:
 	stakePool ag_solanago.PublicKey,
 	withdrawAuthority ag_solanago.PublicKey,
 	reserveStake ag_solanago.PublicKey,
@@ -54,6 +59,7 @@ func NewDepositSolInstruction(
 	tokenProgram ag_solanago.PublicKey,
 ) *DepositSol {
 	return NewDepositSolBuilder().
+		SetLamports(lamports).
 		SetStakePool(stakePool).
 		SetWithdrawAuthority(withdrawAuthority).
 		SetReserveStake(reserveStake).
@@ -73,6 +79,20 @@ func NewDepositSolBuilder() *DepositSol {
 	}
 }
 
+func (inst *DepositSol) GetAccounts() []*ag_solanago.AccountMeta {
+	return inst.Accounts
+}
+
+func (inst *DepositSol) SetAccounts(accounts []*ag_solanago.AccountMeta) error {
+	inst.Accounts = accounts
+	return nil
+}
+
+func (inst *DepositSol) SetLamports(lamports uint64) *DepositSol {
+	inst.Lamports = &lamports
+	return inst
+}
+
 func (inst *DepositSol) SetStakePool(stakePool ag_solanago.PublicKey) *DepositSol {
 	inst.Accounts[0] = ag_solanago.Meta(stakePool).WRITE()
 	return inst
@@ -89,7 +109,7 @@ func (inst *DepositSol) SetReserveStake(reserveStake ag_solanago.PublicKey) *Dep
 }
 
 func (inst *DepositSol) SetFundingAccount(fundingAccount ag_solanago.PublicKey) *DepositSol {
-	inst.Accounts[3] = ag_solanago.Meta(fundingAccount).SIGNER()
+	inst.Accounts[3] = ag_solanago.Meta(fundingAccount).WRITE().SIGNER()
 	inst.Signers = append(inst.Signers, ag_solanago.Meta(fundingAccount).SIGNER())
 	return inst
 }
@@ -124,6 +144,14 @@ func (inst *DepositSol) SetTokenProgram(tokenProgram ag_solanago.PublicKey) *Dep
 	return inst
 }
 
+// SetSolDepositAuthority appends the SOL deposit authority, required by pools
+// with a `SolDepositAuthority` set.
+func (inst *DepositSol) SetSolDepositAuthority(solDepositAuthority ag_solanago.PublicKey) *DepositSol {
+	inst.Accounts = append(inst.Accounts, ag_solanago.Meta(solDepositAuthority).SIGNER())
+	inst.Signers = append(inst.Signers, ag_solanago.Meta(solDepositAuthority).SIGNER())
+	return inst
+}
+
 func (inst *DepositSol) GetStakePool() ag_solanago.PublicKey {
 	return inst.Accounts[0].PublicKey
 }
@@ -185,6 +213,11 @@ func (inst *DepositSol) EncodeToTree(parent ag_treeout.Branches) {
 		ParentFunc(func(programBranch ag_treeout.Branches) {
 			programBranch.Child(ag_format.Instruction("DepositSol")).
 				ParentFunc(func(instructionBranch ag_treeout.Branches) {
+					instructionBranch.Child("Params").ParentFunc(func(paramsBranch ag_treeout.Branches) {
+						if inst.Lamports != nil {
+							paramsBranch.Child(ag_format.Param("Lamports", *inst.Lamports))
+						}
+					})
 					instructionBranch.Child("Accounts").ParentFunc(func(accountsBranch ag_treeout.Branches) {
 						for i, account := range inst.Accounts {
 							accountsBranch.Child(ag_format.Meta(fmt.Sprintf("[%v]", i), account))
@@ -199,24 +232,22 @@ func (inst *DepositSol) EncodeToTree(parent ag_treeout.Branches) {
 }
 
 func (inst *DepositSol) MarshalWithEncoder(encoder *ag_binary.Encoder) error {
-	for _, account := range inst.Accounts {
-		if err := encoder.Encode(account); err != nil {
-			return err
-		}
-	}
-	return nil
+	return encoder.WriteUint64(*inst.Lamports, ag_binary.LE)
 }
 
 func (inst *DepositSol) UnmarshalWithDecoder(decoder *ag_binary.Decoder) error {
-	for i := range inst.Accounts {
-		if err := decoder.Decode(inst.Accounts[i]); err != nil {
-			return err
-		}
+	lamports, err := decoder.ReadUint64(ag_binary.LE)
+	if err != nil {
+		return err
 	}
+	inst.Lamports = &lamports
 	return nil
 }
 
 func (inst *DepositSol) Validate() error {
+	if inst.Lamports == nil {
+		return errors.New("lamports is not set")
+	}
 	for i, account := range inst.Accounts {
 		if account == nil {
 			return fmt.Errorf("accounts[%v] is not set", i)
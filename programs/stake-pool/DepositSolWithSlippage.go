@@ -32,13 +32,14 @@ type DepositSolWithSlippage struct {
 	// [0] = [WRITE] stakePool
 	// [1] = [] withdrawAuthority
 	// [2] = [WRITE] reserveStake
-	// [3] = [SIGNER] depositFrom
+	// [3] = [WRITE, SIGNER] depositFrom
 	// [4] = [WRITE] mintTo
 	// [5] = [WRITE] managerFeeAccount
 	// [6] = [WRITE] referralFeeDest
 	// [7] = [WRITE] poolMint
 	// [8] = [] systemProgram
 	// [9] = [] tokenProgram
+	// [10] = [SIGNER] solDepositAuthority (optional)
 	Accounts ag_solanago.AccountMetaSlice `bin:"-" borsh_skip:"true"`
 	Signers  ag_solanago.AccountMetaSlice `bin:"-" borsh_skip:"true"`
 }
@@ -74,6 +75,37 @@ func DepositSolWithSlippageInstruction(
 		SetTokenProgram(tokenProgram)
 }
 
+func NewDepositSolWithSlippageInstruction(
+	// Parameters:
+	lamportsIn uint64,
+	minTokensOut uint64,
+	// Accounts:
+	stakePool ag_solanago.PublicKey,
+	withdrawAuthority ag_solanago.PublicKey,
+	reserveStake ag_solanago.PublicKey,
+	depositFrom ag_solanago.PublicKey,
+	mintTo ag_solanago.PublicKey,
+	managerFeeAccount ag_solanago.PublicKey,
+	referralFeeDest ag_solanago.PublicKey,
+	poolMint ag_solanago.PublicKey,
+	systemProgram ag_solanago.PublicKey,
+	tokenProgram ag_solanago.PublicKey,
+) *DepositSolWithSlippage {
+	return NewDepositSolWithSlippageBuilder().
+		SetLamportsIn(lamportsIn).
+		SetMinTokensOut(minTokensOut).
+		SetStakePool(stakePool).
+		SetWithdrawAuthority(withdrawAuthority).
+		SetReserveStake(reserveStake).
+		SetDepositFrom(depositFrom).
+		SetMintTo(mintTo).
+		SetManagerFeeAccount(managerFeeAccount).
+		SetReferralFeeDest(referralFeeDest).
+		SetPoolMint(poolMint).
+		SetSystemProgram(systemProgram).
+		SetTokenProgram(tokenProgram)
+}
+
 func NewDepositSolWithSlippageBuilder() *DepositSolWithSlippage {
 	return &DepositSolWithSlippage{
 		Accounts: make(ag_solanago.AccountMetaSlice, 10),
@@ -81,6 +113,15 @@ func NewDepositSolWithSlippageBuilder() *DepositSolWithSlippage {
 	}
 }
 
+func (inst *DepositSolWithSlippage) GetAccounts() []*ag_solanago.AccountMeta {
+	return inst.Accounts
+}
+
+func (inst *DepositSolWithSlippage) SetAccounts(accounts []*ag_solanago.AccountMeta) error {
+	inst.Accounts = accounts
+	return nil
+}
+
 func (inst *DepositSolWithSlippage) SetLamportsIn(in uint64) *DepositSolWithSlippage {
 	inst.LamportsIn = &in
 	return inst
@@ -107,7 +148,7 @@ func (inst *DepositSolWithSlippage) SetReserveStake(stake ag_solanago.PublicKey)
 }
 
 func (inst *DepositSolWithSlippage) SetDepositFrom(from ag_solanago.PublicKey) *DepositSolWithSlippage {
-	inst.Accounts[3] = ag_solanago.Meta(from).SIGNER()
+	inst.Accounts[3] = ag_solanago.Meta(from).WRITE().SIGNER()
 	inst.Signers[0] = ag_solanago.Meta(from).SIGNER()
 	return inst
 }
@@ -142,6 +183,14 @@ func (inst *DepositSolWithSlippage) SetTokenProgram(program ag_solanago.PublicKe
 	return inst
 }
 
+// SetSolDepositAuthority appends the SOL deposit authority, required by pools
+// with a `SolDepositAuthority` set.
+func (inst *DepositSolWithSlippage) SetSolDepositAuthority(authority ag_solanago.PublicKey) *DepositSolWithSlippage {
+	inst.Accounts = append(inst.Accounts, ag_solanago.Meta(authority).SIGNER())
+	inst.Signers = append(inst.Signers, ag_solanago.Meta(authority).SIGNER())
+	return inst
+}
+
 func (inst *DepositSolWithSlippage) GetLamportsIn() *uint64 {
 	return inst.LamportsIn
 }
@@ -233,40 +282,23 @@ func (inst *DepositSolWithSlippage) EncodeToTree(parent ag_treeout.Branches) {
 }
 
 func (inst *DepositSolWithSlippage) MarshalWithEncoder(encoder *ag_binary.Encoder) error {
-	if inst.LamportsIn != nil {
-		if err := encoder.Encode(inst.LamportsIn); err != nil {
-			return err
-		}
-	}
-	if inst.MinTokensOut != nil {
-		if err := encoder.Encode(inst.MinTokensOut); err != nil {
-			return err
-		}
-	}
-	for _, account := range inst.Accounts {
-		if err := encoder.Encode(account); err != nil {
-			return err
-		}
+	if err := encoder.WriteUint64(*inst.LamportsIn, ag_binary.LE); err != nil {
+		return err
 	}
-	return nil
+	return encoder.WriteUint64(*inst.MinTokensOut, ag_binary.LE)
 }
 
 func (inst *DepositSolWithSlippage) UnmarshalWithDecoder(decoder *ag_binary.Decoder) error {
-	if inst.LamportsIn != nil {
-		if err := decoder.Decode(inst.LamportsIn); err != nil {
-			return err
-		}
+	lamportsIn, err := decoder.ReadUint64(ag_binary.LE)
+	if err != nil {
+		return err
 	}
-	if inst.MinTokensOut != nil {
-		if err := decoder.Decode(inst.MinTokensOut); err != nil {
-			return err
-		}
-	}
-	for i := range inst.Accounts {
-		if err := decoder.Decode(inst.Accounts[i]); err != nil {
-			return err
-		}
+	inst.LamportsIn = &lamportsIn
+	minTokensOut, err := decoder.ReadUint64(ag_binary.LE)
+	if err != nil {
+		return err
 	}
+	inst.MinTokensOut = &minTokensOut
 	return nil
 }
 
@@ -16,7 +16,6 @@
 package stakepool
 
 import (
-	"errors"
 	"fmt"
 
 	ag_binary "github.com/gagliardetto/binary"
@@ -29,7 +28,7 @@ import (
 type DepositStake struct {
 	// [0] = [WRITE] stakePool
 	// [1] = [WRITE] validatorList
-	// [2] = [SIGNER] stakeDepositAuthority
+	// [2] = [] stakeDepositAuthority (SIGNER if not the default deposit authority)
 	// [3] = [] withdrawAuthority
 	// [4] = [WRITE] stakeDepositing
 	// [5] = [WRITE] validatorStakeAccount
@@ -89,6 +88,15 @@ func NewDepositStakeBuilder() *DepositStake {
 	}
 }
 
+func (inst *DepositStake) GetAccounts() []*ag_solanago.AccountMeta {
+	return inst.Accounts
+}
+
+func (inst *DepositStake) SetAccounts(accounts []*ag_solanago.AccountMeta) error {
+	inst.Accounts = accounts
+	return nil
+}
+
 func (inst *DepositStake) SetStakePool(stakePool ag_solanago.PublicKey) *DepositStake {
 	inst.Accounts[0] = ag_solanago.Meta(stakePool).WRITE()
 	return inst
@@ -101,7 +109,16 @@ func (inst *DepositStake) SetValidatorList(validatorList ag_solanago.PublicKey)
 
 func (inst *DepositStake) SetStakeDepositAuthority(stakeDepositAuthority ag_solanago.PublicKey) *DepositStake {
 	inst.Accounts[2] = ag_solanago.Meta(stakeDepositAuthority).SIGNER()
-	inst.Signers[0] = ag_solanago.Meta(stakeDepositAuthority).SIGNER()
+	inst.Signers = append(inst.Signers, ag_solanago.Meta(stakeDepositAuthority).SIGNER())
+	return inst
+}
+
+// SetDefaultStakeDepositAuthority sets the deposit authority derived from the
+// stake pool address, which does not sign. Use SetStakeDepositAuthority for pools
+// with a custom stake deposit authority.
+func (inst *DepositStake) SetDefaultStakeDepositAuthority(stakeDepositAuthority ag_solanago.PublicKey) *DepositStake {
+	inst.Accounts[2] = ag_solanago.Meta(stakeDepositAuthority)
+	inst.Signers = inst.Signers[:0]
 	return inst
 }
 
@@ -260,20 +277,10 @@ func (inst *DepositStake) EncodeToTree(parent ag_treeout.Branches) {
 }
 
 func (inst *DepositStake) MarshalWithEncoder(encoder *ag_binary.Encoder) error {
-	for _, account := range inst.Accounts {
-		if err := encoder.Encode(account); err != nil {
-			return err
-		}
-	}
 	return nil
 }
 
 func (inst *DepositStake) UnmarshalWithDecoder(decoder *ag_binary.Decoder) error {
-	for i := range inst.Accounts {
-		if err := decoder.Decode(inst.Accounts[i]); err != nil {
-			return err
-		}
-	}
 	return nil
 }
 
@@ -283,8 +290,5 @@ func (inst *DepositStake) Validate() error {
 			return fmt.Errorf("accounts[%v] is not set", i)
 		}
 	}
-	if len(inst.Signers) == 0 || !inst.Signers[0].IsSigner {
-		return errors.New("accounts.StakeDepositAuthority should be a signer")
-	}
 	return nil
 }
@@ -30,7 +30,7 @@ type DepositStakeWithSlippage struct {
 	MinTokensOut *uint64
 	// [0] = [WRITE] stakePool
 	// [1] = [WRITE] validatorList
-	// [2] = [SIGNER] stakeDepositAuthority
+	// [2] = [] stakeDepositAuthority (SIGNER if not the default deposit authority)
 	// [3] = [] withdrawAuthority
 	// [4] = [WRITE] stakeDepositing
 	// [5] = [WRITE] validatorStakeAccount
@@ -89,10 +89,19 @@ func NewDepositStakeWithSlippageInstruction(
 func NewDepositStakeWithSlippageBuilder() *DepositStakeWithSlippage {
 	return &DepositStakeWithSlippage{
 		Accounts: make(ag_solanago.AccountMetaSlice, 15),
-		Signers:  make(ag_solanago.AccountMetaSlice, 1),
+		Signers:  make(ag_solanago.AccountMetaSlice, 0),
 	}
 }
 
+func (inst *DepositStakeWithSlippage) GetAccounts() []*ag_solanago.AccountMeta {
+	return inst.Accounts
+}
+
+func (inst *DepositStakeWithSlippage) SetAccounts(accounts []*ag_solanago.AccountMeta) error {
+	inst.Accounts = accounts
+	return nil
+}
+
 func (inst *DepositStakeWithSlippage) SetMinTokensOut(out uint64) *DepositStakeWithSlippage {
 	inst.MinTokensOut = &out
 	return inst
@@ -110,7 +119,16 @@ func (inst *DepositStakeWithSlippage) SetValidatorList(list ag_solanago.PublicKe
 
 func (inst *DepositStakeWithSlippage) SetStakeDepositAuthority(authority ag_solanago.PublicKey) *DepositStakeWithSlippage {
 	inst.Accounts[2] = ag_solanago.Meta(authority).SIGNER()
-	inst.Signers[0] = ag_solanago.Meta(authority).SIGNER()
+	inst.Signers = append(inst.Signers, ag_solanago.Meta(authority).SIGNER())
+	return inst
+}
+
+// SetDefaultStakeDepositAuthority sets the deposit authority derived from the
+// stake pool address, which does not sign. Use SetStakeDepositAuthority for pools
+// with a custom stake deposit authority.
+func (inst *DepositStakeWithSlippage) SetDefaultStakeDepositAuthority(authority ag_solanago.PublicKey) *DepositStakeWithSlippage {
+	inst.Accounts[2] = ag_solanago.Meta(authority)
+	inst.Signers = inst.Signers[:0]
 	return inst
 }
 
@@ -278,30 +296,15 @@ func (inst *DepositStakeWithSlippage) EncodeToTree(parent ag_treeout.Branches) {
 }
 
 func (inst *DepositStakeWithSlippage) MarshalWithEncoder(encoder *ag_binary.Encoder) error {
-	if inst.MinTokensOut != nil {
-		if err := encoder.Encode(inst.MinTokensOut); err != nil {
-			return err
-		}
-	}
-	for _, account := range inst.Accounts {
-		if err := encoder.Encode(account); err != nil {
-			return err
-		}
-	}
-	return nil
+	return encoder.WriteUint64(*inst.MinTokensOut, ag_binary.LE)
 }
 
 func (inst *DepositStakeWithSlippage) UnmarshalWithDecoder(decoder *ag_binary.Decoder) error {
-	if inst.MinTokensOut != nil {
-		if err := decoder.Decode(inst.MinTokensOut); err != nil {
-			return err
-		}
-	}
-	for i := range inst.Accounts {
-		if err := decoder.Decode(inst.Accounts[i]); err != nil {
-			return err
-		}
+	minTokensOut, err := decoder.ReadUint64(ag_binary.LE)
+	if err != nil {
+		return err
 	}
+	inst.MinTokensOut = &minTokensOut
 	return nil
 }
 
@@ -314,8 +317,5 @@ func (inst *DepositStakeWithSlippage) Validate() error {
 			return fmt.Errorf("accounts[%v] is not set", i)
 		}
 	}
-	if len(inst.Signers) == 0 || !inst.Signers[0].IsSigner {
-		return errors.New("accounts.StakeDepositAuthority should be a signer")
-	}
 	return nil
 }
@@ -16,7 +16,6 @@
 package stakepool
 
 import (
-	"encoding/binary"
 	"errors"
 	"fmt"
 
@@ -298,16 +297,7 @@ func (inst *IncreaseAdditionalValidatorStake) Validate() error {
 	return nil
 }
 
+// Deprecated: use FindEphemeralStakeProgramAddress.
 func (inst *IncreaseAdditionalValidatorStake) FindEphemeralAccount(programID, stakePoolAddress ag_solanago.PublicKey, seed uint64) (ag_solanago.PublicKey, uint8, error) {
-	seedBytes := make([]byte, 8)
-	binary.LittleEndian.PutUint64(seedBytes, seed)
-
-	seeds := [][]byte{
-		[]byte("ephemeral"),
-		stakePoolAddress.Bytes(),
-		seedBytes,
-	}
-
-	// Find Program Address (PDA)
-	return ag_solanago.FindProgramAddress(seeds, programID)
+	return FindEphemeralStakeProgramAddress(programID, stakePoolAddress, seed)
 }
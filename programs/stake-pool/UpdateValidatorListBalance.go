@@ -16,7 +16,6 @@
 package stakepool
 
 import (
-	"encoding/binary"
 	"errors"
 	"fmt"
 
@@ -230,27 +229,12 @@ func (inst *UpdateValidatorListBalance) Validate() error {
 	return nil
 }
 
+// Deprecated: use FindTransientStakeProgramAddress.
 func (inst *UpdateValidatorListBalance) FindTransientStakeAccount(programID, voteAccountAddress, stakePoolAddress ag_solanago.PublicKey, validatorTransitSuffix uint64) (ag_solanago.PublicKey, uint8, error) {
-	seedBytes := make([]byte, 8)
-	binary.LittleEndian.PutUint64(seedBytes, validatorTransitSuffix)
-
-	seeds := [][]byte{
-		[]byte("transient"),
-		voteAccountAddress.Bytes(),
-		stakePoolAddress.Bytes(),
-		seedBytes,
-	}
-
-	// Find Program Address (PDA)
-	return ag_solanago.FindProgramAddress(seeds, programID)
+	return FindTransientStakeProgramAddress(programID, voteAccountAddress, stakePoolAddress, validatorTransitSuffix)
 }
 
+// Deprecated: use FindStakeProgramAddress.
 func (inst *UpdateValidatorListBalance) FindStakeProgramAddress(programID ag_solanago.PublicKey, voteAccountAddress ag_solanago.PublicKey, stakePoolAddress ag_solanago.PublicKey) (ag_solanago.PublicKey, uint8, error) {
-	seeds := [][]byte{
-		voteAccountAddress.Bytes(), // Validator Vote Account
-		stakePoolAddress.Bytes(),   // Stake Pool Address
-	}
-
-	// Find Program Address (PDA)
-	return ag_solanago.FindProgramAddress(seeds, programID)
+	return FindStakeProgramAddress(programID, voteAccountAddress, stakePoolAddress, 0)
 }
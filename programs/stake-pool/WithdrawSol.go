@@ -30,7 +30,7 @@ type WithdrawSol struct {
 	Arg *uint64
 	// [0] = [WRITE] stakePool
 	// [1] = [] withdrawAuthority
-	// [2] = [] transferAuthority
+	// [2] = [SIGNER] transferAuthority
 	// [3] = [WRITE] burnPoolTokens
 	// [4] = [WRITE] reserveStakeAccount
 	// [5] = [WRITE] withdrawAccount
@@ -40,7 +40,7 @@ type WithdrawSol struct {
 	// [9] = [] sysvarStakeHistory
 	// [10] = [] stakeProgram
 	// [11] = [] tokenProgram
-	// [12] = [SIGNER] solWithdrawAuthority
+	// [12] = [SIGNER] solWithdrawAuthority (optional)
 	Accounts ag_solanago.AccountMetaSlice `bin:"-" borsh_skip:"true"`
 	Signers  ag_solanago.AccountMetaSlice `bin:"-" borsh_skip:"true"`
 }
@@ -59,7 +59,6 @@ func NewWithdrawSolInstruction(
 	sysvarStakeHistory ag_solanago.PublicKey,
 	stakeProgram ag_solanago.PublicKey,
 	tokenProgram ag_solanago.PublicKey,
-	solWithdrawAuthority ag_solanago.PublicKey,
 ) *WithdrawSol {
 	return NewWithdrawSolBuilder().
 		SetArg(arg).
@@ -74,17 +73,25 @@ func NewWithdrawSolInstruction(
 		SetSysvarClock(sysvarClock).
 		SetSysvarStakeHistory(sysvarStakeHistory).
 		SetStakeProgram(stakeProgram).
-		SetTokenProgram(tokenProgram).
-		SetSolWithdrawAuthority(solWithdrawAuthority)
+		SetTokenProgram(tokenProgram)
 }
 
 func NewWithdrawSolBuilder() *WithdrawSol {
 	return &WithdrawSol{
-		Accounts: make(ag_solanago.AccountMetaSlice, 13),
-		Signers:  make(ag_solanago.AccountMetaSlice, 0),
+		Accounts: make(ag_solanago.AccountMetaSlice, 12),
+		Signers:  make(ag_solanago.AccountMetaSlice, 1),
 	}
 }
 
+func (inst *WithdrawSol) GetAccounts() []*ag_solanago.AccountMeta {
+	return inst.Accounts
+}
+
+func (inst *WithdrawSol) SetAccounts(accounts []*ag_solanago.AccountMeta) error {
+	inst.Accounts = accounts
+	return nil
+}
+
 func (inst *WithdrawSol) SetArg(arg uint64) *WithdrawSol {
 	inst.Arg = &arg
 	return inst
@@ -102,6 +109,7 @@ func (inst *WithdrawSol) SetWithdrawAuthority(withdrawAuthority ag_solanago.Publ
 
 func (inst *WithdrawSol) SetTransferAuthority(transferAuthority ag_solanago.PublicKey) *WithdrawSol {
 	inst.Accounts[2] = ag_solanago.Meta(transferAuthority).SIGNER()
+	inst.Signers[0] = ag_solanago.Meta(transferAuthority).SIGNER()
 	return inst
 }
 
@@ -151,8 +159,8 @@ func (inst *WithdrawSol) SetTokenProgram(tokenProgram ag_solanago.PublicKey) *Wi
 }
 
 func (inst *WithdrawSol) SetSolWithdrawAuthority(solWithdrawAuthority ag_solanago.PublicKey) *WithdrawSol {
-	inst.Accounts[12] = ag_solanago.Meta(solWithdrawAuthority).SIGNER()
-	inst.Signers[0] = ag_solanago.Meta(solWithdrawAuthority).SIGNER()
+	inst.Accounts = append(inst.Accounts, ag_solanago.Meta(solWithdrawAuthority).SIGNER())
+	inst.Signers = append(inst.Signers, ag_solanago.Meta(solWithdrawAuthority).SIGNER())
 	return inst
 }
 
@@ -252,30 +260,15 @@ func (inst *WithdrawSol) EncodeToTree(parent ag_treeout.Branches) {
 }
 
 func (inst *WithdrawSol) MarshalWithEncoder(encoder *ag_binary.Encoder) error {
-	if inst.Arg != nil {
-		if err := encoder.Encode(inst.Arg); err != nil {
-			return err
-		}
-	}
-	for _, account := range inst.Accounts {
-		if err := encoder.Encode(account); err != nil {
-			return err
-		}
-	}
-	return nil
+	return encoder.WriteUint64(*inst.Arg, ag_binary.LE)
 }
 
 func (inst *WithdrawSol) UnmarshalWithDecoder(decoder *ag_binary.Decoder) error {
-	if inst.Arg != nil {
-		if err := decoder.Decode(inst.Arg); err != nil {
-			return err
-		}
-	}
-	for i := range inst.Accounts {
-		if err := decoder.Decode(inst.Accounts[i]); err != nil {
-			return err
-		}
+	arg, err := decoder.ReadUint64(ag_binary.LE)
+	if err != nil {
+		return err
 	}
+	inst.Arg = &arg
 	return nil
 }
 
@@ -289,7 +282,7 @@ func (inst *WithdrawSol) Validate() error {
 		}
 	}
 	if len(inst.Signers) == 0 || !inst.Signers[0].IsSigner {
-		return errors.New("accounts.solWithdrawAuthority should be a signer")
+		return errors.New("accounts.transferAuthority should be a signer")
 	}
 	return nil
 }
@@ -41,6 +41,7 @@ type WithdrawSolWithSlippage struct {
 	// [9] = [] stakeHistory
 	// [10] = [] stakeProgram
 	// [11] = [] tokenProgram
+	// [12] = [SIGNER] solWithdrawAuthority (optional)
 	Accounts ag_solanago.AccountMetaSlice `bin:"-" borsh_skip:"true"`
 	Signers  ag_solanago.AccountMetaSlice `bin:"-" borsh_skip:"true"`
 }
@@ -88,6 +89,15 @@ func NewWithdrawSolWithSlippageInstructionBuilder() *WithdrawSolWithSlippage {
 	}
 }
 
+func (inst *WithdrawSolWithSlippage) GetAccounts() []*ag_solanago.AccountMeta {
+	return inst.Accounts
+}
+
+func (inst *WithdrawSolWithSlippage) SetAccounts(accounts []*ag_solanago.AccountMeta) error {
+	inst.Accounts = accounts
+	return nil
+}
+
 func (inst *WithdrawSolWithSlippage) SetTokensIn(in uint64) *WithdrawSolWithSlippage {
 	inst.TokensIn = &in
 	return inst
@@ -159,6 +169,14 @@ func (inst *WithdrawSolWithSlippage) SetTokenProgram(tokenProgram ag_solanago.Pu
 	return inst
 }
 
+// SetSolWithdrawAuthority appends the SOL withdraw authority, required by pools
+// with a `SolWithdrawAuthority` set.
+func (inst *WithdrawSolWithSlippage) SetSolWithdrawAuthority(solWithdrawAuthority ag_solanago.PublicKey) *WithdrawSolWithSlippage {
+	inst.Accounts = append(inst.Accounts, ag_solanago.Meta(solWithdrawAuthority).SIGNER())
+	inst.Signers = append(inst.Signers, ag_solanago.Meta(solWithdrawAuthority).SIGNER())
+	return inst
+}
+
 func (inst *WithdrawSolWithSlippage) GetTokensIn() *uint64 {
 	return inst.TokensIn
 }
@@ -258,40 +276,23 @@ func (inst *WithdrawSolWithSlippage) EncodeToTree(parent ag_treeout.Branches) {
 }
 
 func (inst *WithdrawSolWithSlippage) MarshalWithEncoder(encoder *ag_binary.Encoder) error {
-	if inst.TokensIn != nil {
-		if err := encoder.Encode(inst.TokensIn); err != nil {
-			return err
-		}
+	if err := encoder.WriteUint64(*inst.TokensIn, ag_binary.LE); err != nil {
+		return err
 	}
-	if inst.MinLamportsOut != nil {
-		if err := encoder.Encode(inst.MinLamportsOut); err != nil {
-			return err
-		}
-	}
-	for _, account := range inst.Accounts {
-		if err := encoder.Encode(account); err != nil {
-			return err
-		}
-	}
-	return nil
+	return encoder.WriteUint64(*inst.MinLamportsOut, ag_binary.LE)
 }
 
 func (inst *WithdrawSolWithSlippage) UnmarshalWithDecoder(decoder *ag_binary.Decoder) error {
-	if inst.TokensIn != nil {
-		if err := decoder.Decode(inst.TokensIn); err != nil {
-			return err
-		}
-	}
-	if inst.MinLamportsOut != nil {
-		if err := decoder.Decode(inst.MinLamportsOut); err != nil {
-			return err
-		}
+	tokensIn, err := decoder.ReadUint64(ag_binary.LE)
+	if err != nil {
+		return err
 	}
-	for i := range inst.Accounts {
-		if err := decoder.Decode(inst.Accounts[i]); err != nil {
-			return err
-		}
+	inst.TokensIn = &tokensIn
+	minLamportsOut, err := decoder.ReadUint64(ag_binary.LE)
+	if err != nil {
+		return err
 	}
+	inst.MinLamportsOut = &minLamportsOut
 	return nil
 }
 
@@ -83,10 +83,19 @@ func NewWithdrawStakeInstruction(
 func NewWithdrawStakeBuilder() *WithdrawStake {
 	return &WithdrawStake{
 		Accounts: make(ag_solanago.AccountMetaSlice, 13),
-		Signers:  make(ag_solanago.AccountMetaSlice, 0),
+		Signers:  make(ag_solanago.AccountMetaSlice, 1),
 	}
 }
 
+func (inst *WithdrawStake) GetAccounts() []*ag_solanago.AccountMeta {
+	return inst.Accounts
+}
+
+func (inst *WithdrawStake) SetAccounts(accounts []*ag_solanago.AccountMeta) error {
+	inst.Accounts = accounts
+	return nil
+}
+
 func (inst *WithdrawStake) SetAmount(amount uint64) *WithdrawStake {
 	inst.Amount = &amount
 	return inst
@@ -254,30 +263,15 @@ func (inst *WithdrawStake) EncodeToTree(parent ag_treeout.Branches) {
 }
 
 func (inst *WithdrawStake) MarshalWithEncoder(encoder *ag_binary.Encoder) error {
-	if inst.Amount != nil {
-		if err := encoder.Encode(inst.Amount); err != nil {
-			return err
-		}
-	}
-	for _, account := range inst.Accounts {
-		if err := encoder.Encode(account); err != nil {
-			return err
-		}
-	}
-	return nil
+	return encoder.WriteUint64(*inst.Amount, ag_binary.LE)
 }
 
 func (inst *WithdrawStake) UnmarshalWithDecoder(decoder *ag_binary.Decoder) error {
-	if inst.Amount != nil {
-		if err := decoder.Decode(inst.Amount); err != nil {
-			return err
-		}
-	}
-	for i := range inst.Accounts {
-		if err := decoder.Decode(inst.Accounts[i]); err != nil {
-			return err
-		}
+	amount, err := decoder.ReadUint64(ag_binary.LE)
+	if err != nil {
+		return err
 	}
+	inst.Amount = &amount
 	return nil
 }
 
@@ -90,6 +90,15 @@ func NewWithdrawStakeWithSlippageInstructionBuilder() *WithdrawStakeWithSlippage
 	}
 }
 
+func (inst *WithdrawStakeWithSlippage) GetAccounts() []*ag_solanago.AccountMeta {
+	return inst.Accounts
+}
+
+func (inst *WithdrawStakeWithSlippage) SetAccounts(accounts []*ag_solanago.AccountMeta) error {
+	inst.Accounts = accounts
+	return nil
+}
+
 func (inst *WithdrawStakeWithSlippage) SetPoolTokensIn(in uint64) *WithdrawStakeWithSlippage {
 	inst.PoolTokensIn = &in
 	return inst
@@ -269,40 +278,23 @@ func (inst *WithdrawStakeWithSlippage) EncodeToTree(parent ag_treeout.Branches)
 }
 
 func (inst *WithdrawStakeWithSlippage) MarshalWithEncoder(encoder *ag_binary.Encoder) error {
-	if inst.PoolTokensIn != nil {
-		if err := encoder.Encode(inst.PoolTokensIn); err != nil {
-			return err
-		}
-	}
-	if inst.MinLamportsOut != nil {
-		if err := encoder.Encode(inst.MinLamportsOut); err != nil {
-			return err
-		}
+	if err := encoder.WriteUint64(*inst.PoolTokensIn, ag_binary.LE); err != nil {
+		return err
 	}
-	for _, account := range inst.Accounts {
-		if err := encoder.Encode(account); err != nil {
-			return err
-		}
-	}
-	return nil
+	return encoder.WriteUint64(*inst.MinLamportsOut, ag_binary.LE)
 }
 
 func (inst *WithdrawStakeWithSlippage) UnmarshalWithDecoder(decoder *ag_binary.Decoder) error {
-	if inst.PoolTokensIn != nil {
-		if err := decoder.Decode(inst.PoolTokensIn); err != nil {
-			return err
-		}
+	poolTokensIn, err := decoder.ReadUint64(ag_binary.LE)
+	if err != nil {
+		return err
 	}
-	if inst.MinLamportsOut != nil {
-		if err := decoder.Decode(inst.MinLamportsOut); err != nil {
-			return err
-		}
-	}
-	for i := range inst.Accounts {
-		if err := decoder.Decode(inst.Accounts[i]); err != nil {
-			return err
-		}
+	inst.PoolTokensIn = &poolTokensIn
+	minLamportsOut, err := decoder.ReadUint64(ag_binary.LE)
+	if err != nil {
+		return err
 	}
+	inst.MinLamportsOut = &minLamportsOut
 	return nil
 }
 
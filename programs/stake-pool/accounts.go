@@ -20,6 +20,13 @@ import (
 	ag_solanago "github.com/gagliardetto/solana-go"
 )
 
+// Discriminators of the stake pool program accounts, stored in their first byte.
+const (
+	AccountTypeUninitialized uint8 = iota
+	AccountTypeStakePool
+	AccountTypeValidatorList
+)
+
 type AccountType interface {
 	isAccountType()
 }
@@ -55,6 +62,28 @@ func (f *FutureEpoch[T]) Value() *T {
 	}
 }
 
+// Next returns the value taking effect at the next epoch update, if any:
+// a value set for two epochs later only takes effect at the update after.
+func (f *FutureEpoch[T]) Next() *T {
+	if f.Enum == 1 {
+		return &f.One
+	}
+	return nil
+}
+
+// UpdateEpoch moves the value one epoch closer, like the program does at
+// every epoch update: a value for the next epoch is cleared, and a value
+// for two epochs later becomes the value for the next epoch.
+func (f *FutureEpoch[T]) UpdateEpoch() {
+	var zero T
+	switch f.Enum {
+	case 1:
+		f.Enum, f.One = 0, zero
+	case 2:
+		f.Enum, f.One, f.Two = 1, f.Two, zero
+	}
+}
+
 type StakePool struct {
 	// Account type, must be `StakePool` currently
 	AccountType uint8
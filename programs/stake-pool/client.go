@@ -0,0 +1,175 @@
+// Copyright 2021 github.com/gagliardetto
+// Copyright 2025 github.com/liquid-collective
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stakepool
+
+import (
+	"context"
+	"fmt"
+
+	ag_solanago "github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/programs/stake"
+	"github.com/gagliardetto/solana-go/rpc"
+)
+
+// Client loads stake pools and builds their deposit, withdrawal and update transactions.
+// The transactions are returned unsigned, with the latest blockhash.
+type Client struct {
+	rpcClient  *rpc.Client
+	commitment rpc.CommitmentType
+}
+
+// NewClient returns a client reading the cluster with rpcClient at the given commitment.
+func NewClient(rpcClient *rpc.Client, commitment rpc.CommitmentType) *Client {
+	return &Client{
+		rpcClient:  rpcClient,
+		commitment: commitment,
+	}
+}
+
+// LoadPool fetches the stake pool at address, its validator list and reserve,
+// and the cluster values needed to build its instructions.
+// The program of the pool is the owner of its account.
+func (c *Client) LoadPool(ctx context.Context, address ag_solanago.PublicKey) (*Pool, error) {
+	out, err := c.rpcClient.GetAccountInfoWithOpts(ctx, address, &rpc.GetAccountInfoOpts{
+		Encoding:   ag_solanago.EncodingBase64,
+		Commitment: c.commitment,
+	})
+	if err != nil {
+		return nil, err
+	}
+	stakePool, err := DecodeStakePool(out.Value.Data.GetBinary())
+	if err != nil {
+		return nil, fmt.Errorf("account %s: %w", address, err)
+	}
+
+	accounts, err := c.rpcClient.GetMultipleAccountsWithOpts(ctx, []ag_solanago.PublicKey{stakePool.ValidatorList, stakePool.ReserveStake}, &rpc.GetMultipleAccountsOpts{
+		Encoding:   ag_solanago.EncodingBase64,
+		Commitment: c.commitment,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(accounts.Value) != 2 || accounts.Value[0] == nil {
+		return nil, fmt.Errorf("validator list %s not found", stakePool.ValidatorList)
+	}
+	if accounts.Value[1] == nil {
+		return nil, fmt.Errorf("reserve stake %s not found", stakePool.ReserveStake)
+	}
+	validatorList, err := DecodeValidatorList(accounts.Value[0].Data.GetBinary())
+	if err != nil {
+		return nil, fmt.Errorf("account %s: %w", stakePool.ValidatorList, err)
+	}
+
+	rentExemption, err := c.rpcClient.GetMinimumBalanceForRentExemption(ctx, stake.StakeStateV2Size, c.commitment)
+	if err != nil {
+		return nil, err
+	}
+	minimumDelegation, err := c.rpcClient.GetStakeMinimumDelegation(ctx, c.commitment)
+	if err != nil {
+		return nil, err
+	}
+
+	pool, err := NewPool(out.Value.Owner, address, stakePool, validatorList)
+	if err != nil {
+		return nil, err
+	}
+	pool.ReserveLamports = accounts.Value[1].Lamports
+	pool.StakeRentExemption = rentExemption
+	pool.StakeMinimumDelegation = minimumDelegation.Value
+	return pool, nil
+}
+
+func (c *Client) newTransaction(ctx context.Context, instructions []ag_solanago.Instruction, payer ag_solanago.PublicKey) (*ag_solanago.Transaction, error) {
+	latest, err := c.rpcClient.GetLatestBlockhash(ctx, c.commitment)
+	if err != nil {
+		return nil, err
+	}
+	return ag_solanago.NewTransaction(instructions, latest.Value.Blockhash, ag_solanago.TransactionPayer(payer))
+}
+
+// DepositSol returns the transaction depositing SOL into pool, paid by params.From.
+func (c *Client) DepositSol(ctx context.Context, pool *Pool, params DepositSolParams) (*ag_solanago.Transaction, error) {
+	instructions, err := pool.DepositSolInstructions(params)
+	if err != nil {
+		return nil, err
+	}
+	return c.newTransaction(ctx, instructions, params.From)
+}
+
+// WithdrawSol returns the transaction withdrawing SOL from pool, paid by params.Owner.
+func (c *Client) WithdrawSol(ctx context.Context, pool *Pool, params WithdrawSolParams) (*ag_solanago.Transaction, error) {
+	instructions, err := pool.WithdrawSolInstructions(params)
+	if err != nil {
+		return nil, err
+	}
+	return c.newTransaction(ctx, instructions, params.Owner)
+}
+
+// DepositStake returns the transaction depositing stakeAccount into pool,
+// paid by the withdrawer of the stake account.
+// The stake account is fetched to find its validator and authorities.
+func (c *Client) DepositStake(ctx context.Context, pool *Pool, stakeAccount ag_solanago.PublicKey, params DepositStakeParams) (*ag_solanago.Transaction, error) {
+	account, err := stake.FetchStakeAccount(ctx, c.rpcClient, stakeAccount, c.commitment)
+	if err != nil {
+		return nil, err
+	}
+	instructions, err := pool.DepositStakeInstructions(account, params)
+	if err != nil {
+		return nil, err
+	}
+	return c.newTransaction(ctx, instructions, *account.State.Meta.Authorized.Withdrawer)
+}
+
+// WithdrawStake returns the transaction withdrawing stake from pool, paid by params.Owner,
+// and the keys of the new stake accounts, which must sign it too.
+func (c *Client) WithdrawStake(ctx context.Context, pool *Pool, params WithdrawStakeParams) (*ag_solanago.Transaction, []ag_solanago.PrivateKey, error) {
+	instructions, newStakeAccounts, err := pool.WithdrawStakeInstructions(params)
+	if err != nil {
+		return nil, nil, err
+	}
+	tx, err := c.newTransaction(ctx, instructions, params.Owner)
+	if err != nil {
+		return nil, nil, err
+	}
+	return tx, newStakeAccounts, nil
+}
+
+// Update returns the transactions updating pool for the current epoch, paid by payer:
+// one transaction per UpdateValidatorListBalance instruction, which can be sent in parallel,
+// then the last transaction, updating the pool balance, to send once they are confirmed.
+func (c *Client) Update(ctx context.Context, pool *Pool, payer ag_solanago.PublicKey, noMerge bool) ([]*ag_solanago.Transaction, error) {
+	validatorListInstructions, poolInstructions, err := pool.UpdateInstructions(noMerge)
+	if err != nil {
+		return nil, err
+	}
+	latest, err := c.rpcClient.GetLatestBlockhash(ctx, c.commitment)
+	if err != nil {
+		return nil, err
+	}
+	var txs []*ag_solanago.Transaction
+	for _, instruction := range validatorListInstructions {
+		tx, err := ag_solanago.NewTransaction([]ag_solanago.Instruction{instruction}, latest.Value.Blockhash, ag_solanago.TransactionPayer(payer))
+		if err != nil {
+			return nil, err
+		}
+		txs = append(txs, tx)
+	}
+	tx, err := ag_solanago.NewTransaction(poolInstructions, latest.Value.Blockhash, ag_solanago.TransactionPayer(payer))
+	if err != nil {
+		return nil, err
+	}
+	return append(txs, tx), nil
+}
@@ -0,0 +1,84 @@
+// Copyright 2021 github.com/gagliardetto
+// Copyright 2025 github.com/liquid-collective
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stakepool
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	bin "github.com/gagliardetto/binary"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	ag_solanago "github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+	"github.com/gagliardetto/solana-go/rpc/rpctest"
+)
+
+func borshEncode(t *testing.T, v interface{}) []byte {
+	buf := new(bytes.Buffer)
+	require.NoError(t, bin.NewBorshEncoder(buf).Encode(v))
+	return buf.Bytes()
+}
+
+func TestClient_LoadPool(t *testing.T) {
+	ctx := context.Background()
+	want := newTestPool(t)
+	want.StakePool.PreferredWithdrawValidatorVoteAddress = &voteA
+	want.StakePool.NextEpochFee = FutureEpoch[Fee]{Enum: 2, Two: Fee{Denominator: 100, Numerator: 5}}
+
+	srv := rpctest.NewServer()
+	defer srv.Close()
+	srv.SetStakeMinimumDelegation(1_000_000_000)
+	srv.SetAccount(want.Address, rpctest.Account{
+		Lamports: 1,
+		Owner:    want.ProgramID,
+		Data:     borshEncode(t, want.StakePool),
+	})
+	srv.SetAccount(want.StakePool.ValidatorList, rpctest.Account{
+		Lamports: 1,
+		Owner:    want.ProgramID,
+		Data:     borshEncode(t, want.ValidatorList),
+	})
+	srv.SetAccount(want.StakePool.ReserveStake, rpctest.Account{
+		Lamports: want.ReserveLamports,
+		Owner:    ag_solanago.StakeProgramID,
+	})
+
+	client := NewClient(rpc.New(srv.URL), rpc.CommitmentConfirmed)
+	pool, err := client.LoadPool(ctx, want.Address)
+	require.NoError(t, err)
+	want.StakeMinimumDelegation = 1_000_000_000
+	assert.Equal(t, want, pool)
+
+	owner := ag_solanago.NewWallet().PublicKey()
+	tx, err := client.DepositSol(ctx, pool, DepositSolParams{From: owner, Lamports: 1_000_000_000})
+	require.NoError(t, err)
+	assert.Equal(t, owner, tx.Message.AccountKeys[0])
+	require.Len(t, tx.Message.Instructions, 2)
+
+	txs, err := client.Update(ctx, pool, owner, true)
+	require.NoError(t, err)
+	require.Len(t, txs, 2)
+	for _, tx := range txs {
+		assert.Equal(t, owner, tx.Message.AccountKeys[0])
+	}
+
+	srv.SetAccount(want.Address, rpctest.Account{Lamports: 1, Owner: want.ProgramID, Data: []byte{AccountTypeValidatorList}})
+	_, err = client.LoadPool(ctx, want.Address)
+	assert.Error(t, err)
+}
@@ -0,0 +1,117 @@
+// Copyright 2021 github.com/gagliardetto
+// Copyright 2025 github.com/liquid-collective
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stakepool
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	ag_solanago "github.com/gagliardetto/solana-go"
+)
+
+func u64Data(tag uint8, values ...uint64) []byte {
+	data := []byte{tag}
+	for _, value := range values {
+		data = binary.LittleEndian.AppendUint64(data, value)
+	}
+	return data
+}
+
+func newTestAccounts(n int) []ag_solanago.PublicKey {
+	keys := make([]ag_solanago.PublicKey, n)
+	for i := range keys {
+		keys[i] = ag_solanago.NewWallet().PublicKey()
+	}
+	return keys
+}
+
+func TestDepositWithdrawInstructions_RoundTrip(t *testing.T) {
+	k := newTestAccounts(15)
+	for _, tt := range []struct {
+		name        string
+		instruction *Instruction
+		args        []uint64
+		accounts    int
+	}{
+		{
+			name:        "DepositSol",
+			instruction: NewDepositSolInstruction(100, k[0], k[1], k[2], k[3], k[4], k[5], k[6], k[7], k[8], k[9]).SetSolDepositAuthority(k[10]).Build(),
+			args:        []uint64{100},
+			accounts:    11,
+		},
+		{
+			name:        "DepositSolWithSlippage",
+			instruction: NewDepositSolWithSlippageInstruction(100, 90, k[0], k[1], k[2], k[3], k[4], k[5], k[6], k[7], k[8], k[9]).Build(),
+			args:        []uint64{100, 90},
+			accounts:    10,
+		},
+		{
+			name:        "WithdrawSol",
+			instruction: NewWithdrawSolInstruction(100, k[0], k[1], k[2], k[3], k[4], k[5], k[6], k[7], k[8], k[9], k[10], k[11]).Build(),
+			args:        []uint64{100},
+			accounts:    12,
+		},
+		{
+			name:        "WithdrawSolWithSlippage",
+			instruction: NewWithdrawSolWithSlippageInstruction(100, 90, k[0], k[1], k[2], k[3], k[4], k[5], k[6], k[7], k[8], k[9], k[10], k[11]).SetSolWithdrawAuthority(k[12]).Build(),
+			args:        []uint64{100, 90},
+			accounts:    13,
+		},
+		{
+			name:        "DepositStake",
+			instruction: NewDepositStakeInstruction(k[0], k[1], k[2], k[3], k[4], k[5], k[6], k[7], k[8], k[9], k[10], k[11], k[12], k[13], k[14]).Build(),
+			accounts:    15,
+		},
+		{
+			name:        "DepositStakeWithSlippage",
+			instruction: NewDepositStakeWithSlippageInstruction(90, k[0], k[1], k[2], k[3], k[4], k[5], k[6], k[7], k[8], k[9], k[10], k[11], k[12], k[13], k[14]).Build(),
+			args:        []uint64{90},
+			accounts:    15,
+		},
+		{
+			name:        "WithdrawStake",
+			instruction: NewWithdrawStakeInstruction(100, k[0], k[1], k[2], k[3], k[4], k[5], k[6], k[7], k[8], k[9], k[10], k[11], k[12]).Build(),
+			args:        []uint64{100},
+			accounts:    13,
+		},
+		{
+			name:        "WithdrawStakeWithSlippage",
+			instruction: NewWithdrawStakeWithSlippageInstruction(100, 90, k[0], k[1], k[2], k[3], k[4], k[5], k[6], k[7], k[8], k[9], k[10], k[11], k[12]).Build(),
+			args:        []uint64{100, 90},
+			accounts:    13,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := tt.instruction.Data()
+			require.NoError(t, err)
+			// The instruction tag, followed by the u64 arguments only.
+			assert.Equal(t, u64Data(tt.instruction.TypeID.Uint8(), tt.args...), data)
+
+			accounts := tt.instruction.Accounts()
+			require.Len(t, accounts, tt.accounts)
+			decoded, err := DecodeInstruction(accounts, data)
+			require.NoError(t, err)
+			assert.Equal(t, tt.instruction.TypeID, decoded.TypeID)
+			assert.Equal(t, accounts, decoded.Accounts())
+			redata, err := decoded.Data()
+			require.NoError(t, err)
+			assert.Equal(t, data, redata)
+		})
+	}
+}
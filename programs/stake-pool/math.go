@@ -0,0 +1,156 @@
+// Copyright 2021 github.com/gagliardetto
+// Copyright 2025 github.com/liquid-collective
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stakepool
+
+import (
+	"errors"
+	"math/bits"
+)
+
+// ErrCalculationFailure is returned when a pool calculation overflows,
+// like the `CalculationFailure` error of the program.
+var ErrCalculationFailure = errors.New("stake pool calculation failure")
+
+// mulDiv returns a*b/c, rounded down, computed on 128 bits.
+func mulDiv(a, b, c uint64) (uint64, error) {
+	if c == 0 {
+		return 0, ErrCalculationFailure
+	}
+	hi, lo := bits.Mul64(a, b)
+	if hi >= c {
+		return 0, ErrCalculationFailure
+	}
+	q, _ := bits.Div64(hi, lo, c)
+	return q, nil
+}
+
+// mulDivCeil returns a*b/c, rounded up, computed on 128 bits.
+func mulDivCeil(a, b, c uint64) (uint64, error) {
+	if c == 0 {
+		return 0, ErrCalculationFailure
+	}
+	hi, lo := bits.Mul64(a, b)
+	if hi >= c {
+		return 0, ErrCalculationFailure
+	}
+	q, r := bits.Div64(hi, lo, c)
+	if r != 0 {
+		if q == ^uint64(0) {
+			return 0, ErrCalculationFailure
+		}
+		q++
+	}
+	return q, nil
+}
+
+// Apply returns the fee on amount, rounded up. A fee with a zero denominator is zero.
+func (f Fee) Apply(amount uint64) (uint64, error) {
+	if f.Denominator == 0 {
+		return 0, nil
+	}
+	return mulDivCeil(amount, f.Numerator, f.Denominator)
+}
+
+// CalcPoolTokensForDeposit returns the pool tokens minted for a deposit of lamports,
+// before fees.
+func (p *StakePool) CalcPoolTokensForDeposit(lamports uint64) (uint64, error) {
+	if p.TotalLamports == 0 || p.PoolTokenSupply == 0 {
+		return lamports, nil
+	}
+	return mulDiv(lamports, p.PoolTokenSupply, p.TotalLamports)
+}
+
+// CalcLamportsWithdrawAmount returns the lamports withdrawn for burning poolTokens,
+// after fees.
+func (p *StakePool) CalcLamportsWithdrawAmount(poolTokens uint64) (uint64, error) {
+	if p.PoolTokenSupply == 0 {
+		return 0, nil
+	}
+	hi, lo := bits.Mul64(poolTokens, p.TotalLamports)
+	if hi == 0 && lo < p.PoolTokenSupply {
+		return 0, nil
+	}
+	return mulDiv(poolTokens, p.TotalLamports, p.PoolTokenSupply)
+}
+
+// LamportsPerPoolToken returns the lamports of one pool token, rounded up.
+func (p *StakePool) LamportsPerPoolToken() (uint64, error) {
+	return mulDivCeil(p.TotalLamports, 1, p.PoolTokenSupply)
+}
+
+// CalcPoolTokensStakeWithdrawalFee returns the pool tokens taken as fee on a stake withdrawal.
+func (p *StakePool) CalcPoolTokensStakeWithdrawalFee(poolTokens uint64) (uint64, error) {
+	return p.StakeWithdrawalFee.Apply(poolTokens)
+}
+
+// CalcPoolTokensSolWithdrawalFee returns the pool tokens taken as fee on a SOL withdrawal.
+func (p *StakePool) CalcPoolTokensSolWithdrawalFee(poolTokens uint64) (uint64, error) {
+	return p.SolWithdrawalFee.Apply(poolTokens)
+}
+
+// CalcPoolTokensStakeDepositFee returns the pool tokens taken as fee on the tokens
+// minted for deposited stake.
+func (p *StakePool) CalcPoolTokensStakeDepositFee(poolTokensMinted uint64) (uint64, error) {
+	return p.StakeDepositFee.Apply(poolTokensMinted)
+}
+
+// CalcPoolTokensSolDepositFee returns the pool tokens taken as fee on the tokens
+// minted for deposited SOL.
+func (p *StakePool) CalcPoolTokensSolDepositFee(poolTokensMinted uint64) (uint64, error) {
+	return p.SolDepositFee.Apply(poolTokensMinted)
+}
+
+// CalcPoolTokensStakeReferralFee returns the part of a stake deposit fee paid to the referrer.
+func (p *StakePool) CalcPoolTokensStakeReferralFee(stakeDepositFee uint64) (uint64, error) {
+	return mulDiv(stakeDepositFee, uint64(p.StakeReferralFee), 100)
+}
+
+// CalcPoolTokensSolReferralFee returns the part of a SOL deposit fee paid to the referrer.
+func (p *StakePool) CalcPoolTokensSolReferralFee(solDepositFee uint64) (uint64, error) {
+	return mulDiv(solDepositFee, uint64(p.SolReferralFee), 100)
+}
+
+// CalcEpochFeeAmount returns the pool tokens minted to the manager for the epoch fee
+// on rewardLamports, so that they are worth the fee once the rewards are added.
+func (p *StakePool) CalcEpochFeeAmount(rewardLamports uint64) (uint64, error) {
+	if rewardLamports == 0 {
+		return 0, nil
+	}
+	totalLamports, carry := bits.Add64(p.TotalLamports, rewardLamports, 0)
+	if carry != 0 {
+		return 0, ErrCalculationFailure
+	}
+	feeLamports, err := p.EpochFee.Apply(rewardLamports)
+	if err != nil {
+		return 0, err
+	}
+	if totalLamports == feeLamports || p.PoolTokenSupply == 0 {
+		return rewardLamports, nil
+	}
+	if feeLamports > totalLamports {
+		return 0, ErrCalculationFailure
+	}
+	return mulDiv(p.PoolTokenSupply, feeLamports, totalLamports-feeLamports)
+}
+
+// ExchangeRate returns the lamports of one pool token, for display.
+// It is 1 for an empty pool.
+func (p *StakePool) ExchangeRate() float64 {
+	if p.TotalLamports == 0 || p.PoolTokenSupply == 0 {
+		return 1
+	}
+	return float64(p.TotalLamports) / float64(p.PoolTokenSupply)
+}
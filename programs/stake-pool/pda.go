@@ -0,0 +1,88 @@
+// Copyright 2021 github.com/gagliardetto
+// Copyright 2025 github.com/liquid-collective
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stakepool
+
+import (
+	"encoding/binary"
+
+	ag_solanago "github.com/gagliardetto/solana-go"
+)
+
+// Seeds of the program derived addresses of the stake pool program.
+var (
+	AuthorityWithdrawSeed = []byte("withdraw")
+	AuthorityDepositSeed  = []byte("deposit")
+	TransientStakeSeed    = []byte("transient")
+	EphemeralStakeSeed    = []byte("ephemeral")
+)
+
+// FindWithdrawAuthorityProgramAddress finds the address of the withdraw authority
+// of the stake pool, which owns the pool's stake accounts and mints pool tokens.
+func FindWithdrawAuthorityProgramAddress(programID, stakePoolAddress ag_solanago.PublicKey) (ag_solanago.PublicKey, uint8, error) {
+	return ag_solanago.FindProgramAddress([][]byte{
+		stakePoolAddress.Bytes(),
+		AuthorityWithdrawSeed,
+	}, programID)
+}
+
+// FindDepositAuthorityProgramAddress finds the address of the default stake deposit
+// authority of the stake pool, used when the pool has no custom deposit authority.
+func FindDepositAuthorityProgramAddress(programID, stakePoolAddress ag_solanago.PublicKey) (ag_solanago.PublicKey, uint8, error) {
+	return ag_solanago.FindProgramAddress([][]byte{
+		stakePoolAddress.Bytes(),
+		AuthorityDepositSeed,
+	}, programID)
+}
+
+// FindStakeProgramAddress finds the address of the stake account of a validator
+// of the stake pool. The seed is the validator's ValidatorSeedSuffix, 0 if none.
+func FindStakeProgramAddress(programID, voteAccountAddress, stakePoolAddress ag_solanago.PublicKey, seed uint32) (ag_solanago.PublicKey, uint8, error) {
+	seeds := [][]byte{
+		voteAccountAddress.Bytes(),
+		stakePoolAddress.Bytes(),
+	}
+	if seed != 0 {
+		seedBytes := make([]byte, 4)
+		binary.LittleEndian.PutUint32(seedBytes, seed)
+		seeds = append(seeds, seedBytes)
+	}
+	return ag_solanago.FindProgramAddress(seeds, programID)
+}
+
+// FindTransientStakeProgramAddress finds the address of the transient stake account
+// of a validator of the stake pool. The seed is the validator's TransientSeedSuffix.
+func FindTransientStakeProgramAddress(programID, voteAccountAddress, stakePoolAddress ag_solanago.PublicKey, seed uint64) (ag_solanago.PublicKey, uint8, error) {
+	seedBytes := make([]byte, 8)
+	binary.LittleEndian.PutUint64(seedBytes, seed)
+	return ag_solanago.FindProgramAddress([][]byte{
+		TransientStakeSeed,
+		voteAccountAddress.Bytes(),
+		stakePoolAddress.Bytes(),
+		seedBytes,
+	}, programID)
+}
+
+// FindEphemeralStakeProgramAddress finds the address of the ephemeral stake account
+// used by IncreaseAdditionalValidatorStake, DecreaseAdditionalValidatorStake and Redelegate.
+func FindEphemeralStakeProgramAddress(programID, stakePoolAddress ag_solanago.PublicKey, seed uint64) (ag_solanago.PublicKey, uint8, error) {
+	seedBytes := make([]byte, 8)
+	binary.LittleEndian.PutUint64(seedBytes, seed)
+	return ag_solanago.FindProgramAddress([][]byte{
+		EphemeralStakeSeed,
+		stakePoolAddress.Bytes(),
+		seedBytes,
+	}, programID)
+}
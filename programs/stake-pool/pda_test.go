@@ -0,0 +1,141 @@
+// Copyright 2021 github.com/gagliardetto
+// Copyright 2025 github.com/liquid-collective
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stakepool
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	ag_solanago "github.com/gagliardetto/solana-go"
+)
+
+var (
+	jitoStakePool  = ag_solanago.MustPublicKeyFromBase58("Jito4APyf642JPZPx3hGc6WWJ8zPKtRbRs4P815Awbb")
+	blazeStakePool = ag_solanago.MustPublicKeyFromBase58("stk9ApL5HeVAwPLr3TLhDXdZS8ptVu7zp6ov8HFDuMi")
+	testVote       = ag_solanago.MustPublicKeyFromBase58("CertusDeBmqN8ZawdkxK5kFGMwBXdudvWHYwtNgNhvLu")
+)
+
+func TestFindProgramAddresses(t *testing.T) {
+	for _, tt := range []struct {
+		name    string
+		find    func() (ag_solanago.PublicKey, uint8, error)
+		address string
+		bump    uint8
+	}{
+		// Withdraw authorities of mainnet pools.
+		{
+			name: "jito withdraw authority",
+			find: func() (ag_solanago.PublicKey, uint8, error) {
+				return FindWithdrawAuthorityProgramAddress(ProgramID, jitoStakePool)
+			},
+			address: "6iQKfEyhr3bZMotVkW6beNZz5CPAkiwvgV2CTje9pVSS",
+			bump:    253,
+		},
+		{
+			name: "blazestake withdraw authority",
+			find: func() (ag_solanago.PublicKey, uint8, error) {
+				return FindWithdrawAuthorityProgramAddress(ProgramID, blazeStakePool)
+			},
+			address: "6WecYymEARvjG5ZyqkrVQ6YkhPfujNzWpSPwNKXHCbV2",
+			bump:    253,
+		},
+		{
+			name: "deposit authority",
+			find: func() (ag_solanago.PublicKey, uint8, error) {
+				return FindDepositAuthorityProgramAddress(ProgramID, jitoStakePool)
+			},
+			address: "74opVa3v51hUmTrsZn8YusZw4fXB16vGQY4WYHt9UegR",
+			bump:    255,
+		},
+		{
+			name: "validator stake",
+			find: func() (ag_solanago.PublicKey, uint8, error) {
+				return FindStakeProgramAddress(ProgramID, testVote, jitoStakePool, 0)
+			},
+			address: "LHij1ZgJF2rk4irga1YsWzkyKPkseKXGkXPztnK5Pac",
+			bump:    255,
+		},
+		{
+			name: "validator stake with seed",
+			find: func() (ag_solanago.PublicKey, uint8, error) {
+				return FindStakeProgramAddress(ProgramID, testVote, jitoStakePool, 7)
+			},
+			address: "5r44bdPb64PugShgR58sN2znBTPeub6Zh9ZB3ZeQh4vy",
+			bump:    255,
+		},
+		{
+			name: "transient stake",
+			find: func() (ag_solanago.PublicKey, uint8, error) {
+				return FindTransientStakeProgramAddress(ProgramID, testVote, jitoStakePool, 0)
+			},
+			address: "7bzdyqAeYNjwj5pgZUst7iKhRpf9KFXmJaa4G7DPY1oG",
+			bump:    255,
+		},
+		{
+			name: "transient stake with seed",
+			find: func() (ag_solanago.PublicKey, uint8, error) {
+				return FindTransientStakeProgramAddress(ProgramID, testVote, jitoStakePool, 3)
+			},
+			address: "JCb3BnM8N3P32vZeFCxgUbW51goyfFxX2AJ7ANr6PGN8",
+			bump:    253,
+		},
+		{
+			name: "ephemeral stake",
+			find: func() (ag_solanago.PublicKey, uint8, error) {
+				return FindEphemeralStakeProgramAddress(ProgramID, jitoStakePool, 0)
+			},
+			address: "CPzuFDUuXYManczPNJCpyb8KyVQspimsJUFsx76B2Kv",
+			bump:    254,
+		},
+		{
+			name: "withdraw authority of another deployment",
+			find: func() (ag_solanago.PublicKey, uint8, error) {
+				return FindWithdrawAuthorityProgramAddress(ag_solanago.MustPublicKeyFromBase58("SP1s4uFeTAX9jsXXmwyDs1gxYYf7cdDZ8qHUHVxE1yr"), jitoStakePool)
+			},
+			address: "77FS3NAVfiTMHvgLmduwpam1UCFPajsqvksqVmfJGGRW",
+			bump:    252,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			address, bump, err := tt.find()
+			require.NoError(t, err)
+			assert.Equal(t, ag_solanago.MustPublicKeyFromBase58(tt.address), address)
+			assert.Equal(t, tt.bump, bump)
+		})
+	}
+}
+
+func TestFindProgramAddresses_Deprecated(t *testing.T) {
+	expected, _, err := FindTransientStakeProgramAddress(ProgramID, testVote, jitoStakePool, 3)
+	require.NoError(t, err)
+	got, _, err := new(UpdateValidatorListBalance).FindTransientStakeAccount(ProgramID, testVote, jitoStakePool, 3)
+	require.NoError(t, err)
+	assert.Equal(t, expected, got)
+
+	expected, _, err = FindStakeProgramAddress(ProgramID, testVote, jitoStakePool, 0)
+	require.NoError(t, err)
+	got, _, err = new(UpdateValidatorListBalance).FindStakeProgramAddress(ProgramID, testVote, jitoStakePool)
+	require.NoError(t, err)
+	assert.Equal(t, expected, got)
+
+	expected, _, err = FindEphemeralStakeProgramAddress(ProgramID, jitoStakePool, 1)
+	require.NoError(t, err)
+	got, _, err = new(IncreaseAdditionalValidatorStake).FindEphemeralAccount(ProgramID, jitoStakePool, 1)
+	require.NoError(t, err)
+	assert.Equal(t, expected, got)
+}
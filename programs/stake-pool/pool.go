@@ -0,0 +1,801 @@
+// Copyright 2021 github.com/gagliardetto
+// Copyright 2025 github.com/liquid-collective
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stakepool
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	ag_solanago "github.com/gagliardetto/solana-go"
+	associatedtokenaccount "github.com/gagliardetto/solana-go/programs/associated-token-account"
+	"github.com/gagliardetto/solana-go/programs/stake"
+	"github.com/gagliardetto/solana-go/programs/system"
+)
+
+const (
+	// MinimumActiveStake is the minimum active stake of the validator stake accounts,
+	// on top of their rent exemption, if above the stake minimum delegation.
+	MinimumActiveStake = 1_000_000
+
+	// MaxValidatorsToUpdate is the number of validators updated by an
+	// UpdateValidatorListBalance instruction.
+	MaxValidatorsToUpdate = 5
+
+	// maxSlippageBps is the slippage of 100%, in basis points.
+	maxSlippageBps = 10_000
+)
+
+// ErrWithdrawalTooSmall is returned when a withdrawal would give no lamports,
+// like the `WithdrawalTooSmall` error of the program.
+var ErrWithdrawalTooSmall = errors.New("stake pool withdrawal too small")
+
+// ErrDepositTooSmall is returned when a deposit would mint no pool tokens,
+// like the `DepositTooSmall` error of the program.
+var ErrDepositTooSmall = errors.New("stake pool deposit too small")
+
+// Pool is a stake pool with its validator list, and the cluster values needed
+// to build its instructions offline.
+type Pool struct {
+	// The stake pool program owning the pool.
+	ProgramID ag_solanago.PublicKey
+
+	// The address of the stake pool account.
+	Address ag_solanago.PublicKey
+
+	StakePool     *StakePool
+	ValidatorList *ValidatorList
+
+	// The withdraw authority of the pool, derived from its address.
+	WithdrawAuthority ag_solanago.PublicKey
+
+	// The lamports of the reserve stake account.
+	ReserveLamports uint64
+
+	// The rent exemption of a stake account.
+	StakeRentExemption uint64
+
+	// The minimum delegation of the stake program.
+	StakeMinimumDelegation uint64
+}
+
+// NewPool returns the pool at address, owned by programID, from its decoded accounts.
+// The cluster values of the pool are left to the caller.
+func NewPool(programID, address ag_solanago.PublicKey, stakePool *StakePool, validatorList *ValidatorList) (*Pool, error) {
+	withdrawAuthority, _, err := FindWithdrawAuthorityProgramAddress(programID, address)
+	if err != nil {
+		return nil, err
+	}
+	return &Pool{
+		ProgramID:         programID,
+		Address:           address,
+		StakePool:         stakePool,
+		ValidatorList:     validatorList,
+		WithdrawAuthority: withdrawAuthority,
+	}, nil
+}
+
+// Validator returns the validator list entry of voteAccount, or nil if the
+// validator is not part of the pool.
+func (p *Pool) Validator(voteAccount ag_solanago.PublicKey) *ValidatorStakeInfo {
+	for i := range p.ValidatorList.Validators {
+		if p.ValidatorList.Validators[i].VoteAccountAddress.Equals(voteAccount) {
+			return &p.ValidatorList.Validators[i]
+		}
+	}
+	return nil
+}
+
+// ValidatorStakeAddress returns the address of the stake account of a validator of the pool.
+func (p *Pool) ValidatorStakeAddress(validator *ValidatorStakeInfo) (ag_solanago.PublicKey, error) {
+	address, _, err := FindStakeProgramAddress(p.ProgramID, validator.VoteAccountAddress, p.Address, validator.ValidatorSeedSuffix)
+	return address, err
+}
+
+// TransientStakeAddress returns the address of the transient stake account of a validator of the pool.
+func (p *Pool) TransientStakeAddress(validator *ValidatorStakeInfo) (ag_solanago.PublicKey, error) {
+	address, _, err := FindTransientStakeProgramAddress(p.ProgramID, validator.VoteAccountAddress, p.Address, validator.TransientSeedSuffix)
+	return address, err
+}
+
+// NeedsUpdate reports whether the pool must be updated before deposits and
+// withdrawals are accepted in epoch.
+func (p *Pool) NeedsUpdate(epoch uint64) bool {
+	return p.StakePool.LastUpdateEpoch < epoch
+}
+
+// minimumStakeLamports returns the lamports a validator stake account must keep.
+func (p *Pool) minimumStakeLamports() uint64 {
+	minimumDelegation := p.StakeMinimumDelegation
+	if minimumDelegation < MinimumActiveStake {
+		minimumDelegation = MinimumActiveStake
+	}
+	return p.StakeRentExemption + minimumDelegation
+}
+
+func (p *Pool) associatedTokenAddress(wallet ag_solanago.PublicKey) (ag_solanago.PublicKey, error) {
+	address, _, err := ag_solanago.FindProgramAddress([][]byte{
+		wallet[:],
+		p.StakePool.TokenProgramID[:],
+		p.StakePool.PoolMint[:],
+	}, ag_solanago.SPLAssociatedTokenAccountProgramID)
+	return address, err
+}
+
+// destinationTokenAccount returns the token account receiving pool tokens, creating
+// the associated token account of owner if no account is given.
+func (p *Pool) destinationTokenAccount(account, owner ag_solanago.PublicKey) (ag_solanago.PublicKey, []ag_solanago.Instruction, error) {
+	if !account.IsZero() {
+		return account, nil, nil
+	}
+	ata, err := p.associatedTokenAddress(owner)
+	if err != nil {
+		return ag_solanago.PublicKey{}, nil, err
+	}
+	create := associatedtokenaccount.NewCreateIdempotentInstruction(
+		owner,
+		ata,
+		owner,
+		p.StakePool.PoolMint,
+		ag_solanago.SystemProgramID,
+		p.StakePool.TokenProgramID,
+	).Build()
+	return ata, []ag_solanago.Instruction{create}, nil
+}
+
+// sourceTokenAccount returns the token account pool tokens are burnt from,
+// the associated token account of owner if no account is given.
+func (p *Pool) sourceTokenAccount(account, owner ag_solanago.PublicKey) (ag_solanago.PublicKey, error) {
+	if !account.IsZero() {
+		return account, nil
+	}
+	return p.associatedTokenAddress(owner)
+}
+
+// MinimumWithSlippage returns amount less slippageBps basis points, rounded down,
+// to use as the minimum out of the WithSlippage instructions.
+func MinimumWithSlippage(amount, slippageBps uint64) (uint64, error) {
+	if slippageBps > maxSlippageBps {
+		return 0, fmt.Errorf("slippage of %d bps is above 100%%", slippageBps)
+	}
+	return mulDiv(amount, maxSlippageBps-slippageBps, maxSlippageBps)
+}
+
+// withdrawLamports returns the lamports received for burning poolTokens from source.
+// Withdrawals from the manager fee account pay no fee.
+func (p *Pool) withdrawLamports(fee Fee, poolTokens uint64, source ag_solanago.PublicKey) (uint64, error) {
+	preview, err := p.StakePool.previewWithdraw(fee, poolTokens, source.Equals(p.StakePool.ManagerFeeAccount))
+	if err != nil {
+		return 0, err
+	}
+	return preview.Lamports, nil
+}
+
+// DepositSolParams are the parameters of a SOL deposit.
+type DepositSolParams struct {
+	// The account funding the deposit, signing the transaction.
+	From ag_solanago.PublicKey
+
+	// The lamports to deposit.
+	Lamports uint64
+
+	// The token account receiving the pool tokens. Defaults to the associated
+	// token account of From, created if needed.
+	PoolTokenAccount ag_solanago.PublicKey
+
+	// The token account receiving the referral fees. Defaults to PoolTokenAccount.
+	ReferralPoolTokenAccount ag_solanago.PublicKey
+
+	// The SOL deposit authority, required by pools with one.
+	SolDepositAuthority ag_solanago.PublicKey
+
+	// If set, DepositSolWithSlippage is used, with a minimum of pool tokens
+	// lower than the expected amount by this many basis points.
+	SlippageBps *uint64
+}
+
+// DepositSolInstructions returns the instructions depositing SOL into the pool.
+func (p *Pool) DepositSolInstructions(params DepositSolParams) ([]ag_solanago.Instruction, error) {
+	if p.StakePool.SolDepositAuthority != nil && !p.StakePool.SolDepositAuthority.Equals(params.SolDepositAuthority) {
+		return nil, fmt.Errorf("pool requires the signature of its SOL deposit authority %s", p.StakePool.SolDepositAuthority)
+	}
+	destination, instructions, err := p.destinationTokenAccount(params.PoolTokenAccount, params.From)
+	if err != nil {
+		return nil, err
+	}
+	referral := params.ReferralPoolTokenAccount
+	if referral.IsZero() {
+		referral = destination
+	}
+	preview, err := p.StakePool.PreviewDepositSol(params.Lamports)
+	if err != nil {
+		return nil, err
+	}
+
+	if params.SlippageBps != nil {
+		minPoolTokens, err := MinimumWithSlippage(preview.UserPoolTokens, *params.SlippageBps)
+		if err != nil {
+			return nil, err
+		}
+		deposit := NewDepositSolWithSlippageInstruction(
+			params.Lamports,
+			minPoolTokens,
+			p.Address,
+			p.WithdrawAuthority,
+			p.StakePool.ReserveStake,
+			params.From,
+			destination,
+			p.StakePool.ManagerFeeAccount,
+			referral,
+			p.StakePool.PoolMint,
+			ag_solanago.SystemProgramID,
+			p.StakePool.TokenProgramID,
+		)
+		if p.StakePool.SolDepositAuthority != nil {
+			deposit.SetSolDepositAuthority(params.SolDepositAuthority)
+		}
+		return append(instructions, deposit.Build()), nil
+	}
+
+	deposit := NewDepositSolInstruction(
+		params.Lamports,
+		p.Address,
+		p.WithdrawAuthority,
+		p.StakePool.ReserveStake,
+		params.From,
+		destination,
+		p.StakePool.ManagerFeeAccount,
+		referral,
+		p.StakePool.PoolMint,
+		ag_solanago.SystemProgramID,
+		p.StakePool.TokenProgramID,
+	)
+	if p.StakePool.SolDepositAuthority != nil {
+		deposit.SetSolDepositAuthority(params.SolDepositAuthority)
+	}
+	return append(instructions, deposit.Build()), nil
+}
+
+// WithdrawSolParams are the parameters of a SOL withdrawal.
+type WithdrawSolParams struct {
+	// The owner of the pool tokens, signing the transaction.
+	Owner ag_solanago.PublicKey
+
+	// The pool tokens to burn.
+	PoolTokens uint64
+
+	// The token account to burn from. Defaults to the associated token account of Owner.
+	PoolTokenAccount ag_solanago.PublicKey
+
+	// The account receiving the lamports. Defaults to Owner.
+	Recipient ag_solanago.PublicKey
+
+	// The SOL withdraw authority, required by pools with one.
+	SolWithdrawAuthority ag_solanago.PublicKey
+
+	// If set, WithdrawSolWithSlippage is used, with a minimum of lamports
+	// lower than the expected amount by this many basis points.
+	SlippageBps *uint64
+}
+
+// WithdrawSolInstructions returns the instructions withdrawing SOL from the reserve of the pool.
+func (p *Pool) WithdrawSolInstructions(params WithdrawSolParams) ([]ag_solanago.Instruction, error) {
+	if p.StakePool.SolWithdrawAuthority != nil && !p.StakePool.SolWithdrawAuthority.Equals(params.SolWithdrawAuthority) {
+		return nil, fmt.Errorf("pool requires the signature of its SOL withdraw authority %s", p.StakePool.SolWithdrawAuthority)
+	}
+	source, err := p.sourceTokenAccount(params.PoolTokenAccount, params.Owner)
+	if err != nil {
+		return nil, err
+	}
+	recipient := params.Recipient
+	if recipient.IsZero() {
+		recipient = params.Owner
+	}
+	preview, err := p.StakePool.PreviewWithdrawSol(params.PoolTokens, source.Equals(p.StakePool.ManagerFeeAccount))
+	if err != nil {
+		return nil, err
+	}
+	lamports := preview.Lamports
+	if p.ReserveLamports < p.StakeRentExemption || lamports > p.ReserveLamports-p.StakeRentExemption {
+		return nil, fmt.Errorf("withdrawal of %d lamports is above the reserve of the pool", lamports)
+	}
+
+	if params.SlippageBps != nil {
+		minLamports, err := MinimumWithSlippage(lamports, *params.SlippageBps)
+		if err != nil {
+			return nil, err
+		}
+		withdraw := NewWithdrawSolWithSlippageInstruction(
+			params.PoolTokens,
+			minLamports,
+			p.Address,
+			p.WithdrawAuthority,
+			params.Owner,
+			source,
+			p.StakePool.ReserveStake,
+			recipient,
+			p.StakePool.ManagerFeeAccount,
+			p.StakePool.PoolMint,
+			ag_solanago.SysVarClockPubkey,
+			ag_solanago.SysVarStakeHistoryPubkey,
+			ag_solanago.StakeProgramID,
+			p.StakePool.TokenProgramID,
+		)
+		if p.StakePool.SolWithdrawAuthority != nil {
+			withdraw.SetSolWithdrawAuthority(params.SolWithdrawAuthority)
+		}
+		return []ag_solanago.Instruction{withdraw.Build()}, nil
+	}
+
+	withdraw := NewWithdrawSolInstruction(
+		params.PoolTokens,
+		p.Address,
+		p.WithdrawAuthority,
+		params.Owner,
+		source,
+		p.StakePool.ReserveStake,
+		recipient,
+		p.StakePool.ManagerFeeAccount,
+		p.StakePool.PoolMint,
+		ag_solanago.SysVarClockPubkey,
+		ag_solanago.SysVarStakeHistoryPubkey,
+		ag_solanago.StakeProgramID,
+		p.StakePool.TokenProgramID,
+	)
+	if p.StakePool.SolWithdrawAuthority != nil {
+		withdraw.SetSolWithdrawAuthority(params.SolWithdrawAuthority)
+	}
+	return []ag_solanago.Instruction{withdraw.Build()}, nil
+}
+
+// DepositStakeParams are the parameters of a stake deposit.
+type DepositStakeParams struct {
+	// The token account receiving the pool tokens. Defaults to the associated
+	// token account of the withdrawer of the stake account, created if needed.
+	PoolTokenAccount ag_solanago.PublicKey
+
+	// The token account receiving the referral fees. Defaults to PoolTokenAccount.
+	ReferralPoolTokenAccount ag_solanago.PublicKey
+
+	// If set, DepositStakeWithSlippage is used, with a minimum of pool tokens
+	// lower than the expected amount by this many basis points.
+	SlippageBps *uint64
+}
+
+// DepositStakeInstructions returns the instructions depositing stakeAccount, delegated
+// to a validator of the pool, into the pool. Its staker and withdrawer sign the transaction:
+// the stake authorities are given to the deposit authority of the pool, which then
+// merges the stake account into the stake account of its validator.
+// A pool with a custom deposit authority requires it to sign as well.
+func (p *Pool) DepositStakeInstructions(stakeAccount *stake.StakeAccount, params DepositStakeParams) ([]ag_solanago.Instruction, error) {
+	if stakeAccount.State.Meta == nil || stakeAccount.State.Stake == nil {
+		return nil, fmt.Errorf("stake account %s is not delegated", stakeAccount.Pubkey)
+	}
+	authorized := stakeAccount.State.Meta.Authorized
+	if authorized.Staker == nil || authorized.Withdrawer == nil {
+		return nil, fmt.Errorf("stake account %s has no authorities", stakeAccount.Pubkey)
+	}
+	delegation := stakeAccount.State.Stake.Delegation
+
+	validator := p.Validator(delegation.VoterPubkey)
+	if validator == nil {
+		return nil, fmt.Errorf("validator %s is not part of the pool", delegation.VoterPubkey)
+	}
+	validatorStake, err := p.ValidatorStakeAddress(validator)
+	if err != nil {
+		return nil, err
+	}
+	defaultDepositAuthority, _, err := FindDepositAuthorityProgramAddress(p.ProgramID, p.Address)
+	if err != nil {
+		return nil, err
+	}
+	depositAuthority := p.StakePool.StakeDepositAuthority
+	isDefaultDepositAuthority := depositAuthority.Equals(defaultDepositAuthority)
+
+	destination, instructions, err := p.destinationTokenAccount(params.PoolTokenAccount, *authorized.Withdrawer)
+	if err != nil {
+		return nil, err
+	}
+	referral := params.ReferralPoolTokenAccount
+	if referral.IsZero() {
+		referral = destination
+	}
+
+	instructions = append(instructions,
+		stake.NewAuthorizeInstruction(
+			depositAuthority,
+			stake.StakeAuthorizeStaker,
+			stakeAccount.Pubkey,
+			ag_solanago.SysVarClockPubkey,
+			*authorized.Staker,
+			nil,
+		).Build(),
+		stake.NewAuthorizeInstruction(
+			depositAuthority,
+			stake.StakeAuthorizeWithdrawer,
+			stakeAccount.Pubkey,
+			ag_solanago.SysVarClockPubkey,
+			*authorized.Withdrawer,
+			nil,
+		).Build(),
+	)
+
+	if params.SlippageBps != nil {
+		preview, err := p.StakePool.PreviewDepositStake(stakeAccount.Lamports, delegation.Stake, stakeAccount.State.Meta.RentExemptReserve)
+		if err != nil {
+			return nil, err
+		}
+		minPoolTokens, err := MinimumWithSlippage(preview.UserPoolTokens, *params.SlippageBps)
+		if err != nil {
+			return nil, err
+		}
+		deposit := NewDepositStakeWithSlippageInstruction(
+			minPoolTokens,
+			p.Address,
+			p.StakePool.ValidatorList,
+			depositAuthority,
+			p.WithdrawAuthority,
+			stakeAccount.Pubkey,
+			validatorStake,
+			p.StakePool.ReserveStake,
+			destination,
+			p.StakePool.ManagerFeeAccount,
+			referral,
+			p.StakePool.PoolMint,
+			ag_solanago.SysVarClockPubkey,
+			ag_solanago.SysVarStakeHistoryPubkey,
+			p.StakePool.TokenProgramID,
+			ag_solanago.StakeProgramID,
+		)
+		if isDefaultDepositAuthority {
+			deposit.SetDefaultStakeDepositAuthority(depositAuthority)
+		}
+		return append(instructions, deposit.Build()), nil
+	}
+
+	deposit := NewDepositStakeInstruction(
+		p.Address,
+		p.StakePool.ValidatorList,
+		depositAuthority,
+		p.WithdrawAuthority,
+		stakeAccount.Pubkey,
+		validatorStake,
+		p.StakePool.ReserveStake,
+		destination,
+		p.StakePool.ManagerFeeAccount,
+		referral,
+		p.StakePool.PoolMint,
+		ag_solanago.SysVarClockPubkey,
+		ag_solanago.SysVarStakeHistoryPubkey,
+		p.StakePool.TokenProgramID,
+		ag_solanago.StakeProgramID,
+	)
+	if isDefaultDepositAuthority {
+		deposit.SetDefaultStakeDepositAuthority(depositAuthority)
+	}
+	return append(instructions, deposit.Build()), nil
+}
+
+// WithdrawStakeSplit is the part of a stake withdrawal split from one stake account of the pool.
+type WithdrawStakeSplit struct {
+	// The stake account of the pool split from.
+	StakeAccount ag_solanago.PublicKey
+
+	// The vote account of the validator, zero for the reserve.
+	VoteAccount ag_solanago.PublicKey
+
+	// The pool tokens burnt.
+	PoolTokens uint64
+
+	// The lamports split into the new stake account.
+	Lamports uint64
+}
+
+type withdrawCandidate struct {
+	stakeAccount ag_solanago.PublicKey
+	voteAccount  ag_solanago.PublicKey
+	available    uint64
+}
+
+// SelectWithdrawStakeSplits splits a withdrawal of poolTokens burnt from source among
+// the stake accounts of the pool, following the rules of the program:
+// the active stake of the validators is withdrawn first, starting with the preferred
+// withdraw validator; the transient stake only if no validator has active stake
+// left, and the reserve only if no validator has any stake left.
+//
+// A validator counts as having stake left while it holds more than the minimum
+// stake lamports plus the lamports of a pool token, while a withdrawal may take
+// it down to the minimum itself. Validators are drained to within that pool
+// token of the minimum, so that the program lets the next split go to another
+// validator. Validators being removed from the pool are skipped.
+func (p *Pool) SelectWithdrawStakeSplits(poolTokens uint64, source ag_solanago.PublicKey) ([]WithdrawStakeSplit, error) {
+	lamportsPerPoolToken, err := p.StakePool.LamportsPerPoolToken()
+	if err != nil {
+		return nil, err
+	}
+	required := p.minimumStakeLamports()
+	minimum := required + lamportsPerPoolToken
+
+	var active, transient []withdrawCandidate
+	for i := range p.ValidatorList.Validators {
+		validator := &p.ValidatorList.Validators[i]
+		if validator.Status != StakeStatusActive {
+			continue
+		}
+		if validator.ActiveStakeLamports > minimum {
+			address, err := p.ValidatorStakeAddress(validator)
+			if err != nil {
+				return nil, err
+			}
+			candidate := withdrawCandidate{
+				stakeAccount: address,
+				voteAccount:  validator.VoteAccountAddress,
+				available:    validator.ActiveStakeLamports - required,
+			}
+			preferred := p.StakePool.PreferredWithdrawValidatorVoteAddress
+			if preferred != nil && preferred.Equals(validator.VoteAccountAddress) {
+				// The program requires the preferred validator to be emptied first.
+				active = append([]withdrawCandidate{candidate}, active...)
+			} else {
+				active = append(active, candidate)
+			}
+		}
+		if validator.TransientStakeLamports > minimum {
+			address, err := p.TransientStakeAddress(validator)
+			if err != nil {
+				return nil, err
+			}
+			transient = append(transient, withdrawCandidate{
+				stakeAccount: address,
+				voteAccount:  validator.VoteAccountAddress,
+				available:    validator.TransientStakeLamports - required,
+			})
+		}
+	}
+
+	var candidates []withdrawCandidate
+	switch {
+	case len(active) > 0:
+		candidates = active
+	case len(transient) > 0:
+		candidates = transient
+	case p.ReserveLamports > p.StakeRentExemption:
+		candidates = []withdrawCandidate{{
+			stakeAccount: p.StakePool.ReserveStake,
+			available:    p.ReserveLamports - p.StakeRentExemption,
+		}}
+	}
+	sortFrom := 0
+	if len(candidates) > 0 && len(active) > 0 {
+		preferred := p.StakePool.PreferredWithdrawValidatorVoteAddress
+		if preferred != nil && preferred.Equals(candidates[0].voteAccount) {
+			sortFrom = 1
+		}
+	}
+	rest := candidates[sortFrom:]
+	sort.SliceStable(rest, func(i, j int) bool { return rest[i].available > rest[j].available })
+
+	var withdrawals []WithdrawStakeSplit
+	remaining := poolTokens
+	for _, candidate := range candidates {
+		if remaining == 0 {
+			break
+		}
+		tokens, lamports, err := p.maxWithdrawal(remaining, candidate.available, source)
+		if err != nil {
+			return nil, err
+		}
+		if tokens == 0 || lamports == 0 {
+			continue
+		}
+		withdrawals = append(withdrawals, WithdrawStakeSplit{
+			StakeAccount: candidate.stakeAccount,
+			VoteAccount:  candidate.voteAccount,
+			PoolTokens:   tokens,
+			Lamports:     lamports,
+		})
+		remaining -= tokens
+	}
+	if remaining > 0 {
+		return nil, fmt.Errorf("not enough stake in the pool to withdraw %d pool tokens: %d left", poolTokens, remaining)
+	}
+	return withdrawals, nil
+}
+
+// maxWithdrawal returns the most pool tokens, up to poolTokens, whose withdrawal
+// gives at most available lamports, with the lamports they give.
+func (p *Pool) maxWithdrawal(poolTokens, available uint64, source ag_solanago.PublicKey) (uint64, uint64, error) {
+	lamports, err := p.withdrawLamports(p.StakePool.StakeWithdrawalFee, poolTokens, source)
+	if err != nil {
+		return 0, 0, err
+	}
+	if lamports <= available {
+		return poolTokens, lamports, nil
+	}
+	// The lamports withdrawn grow with the pool tokens burnt.
+	low, high := uint64(0), poolTokens
+	for low < high {
+		mid := low + (high-low+1)/2
+		lamports, err := p.withdrawLamports(p.StakePool.StakeWithdrawalFee, mid, source)
+		if err != nil {
+			return 0, 0, err
+		}
+		if lamports <= available {
+			low = mid
+		} else {
+			high = mid - 1
+		}
+	}
+	lamports, err = p.withdrawLamports(p.StakePool.StakeWithdrawalFee, low, source)
+	if err != nil {
+		return 0, 0, err
+	}
+	return low, lamports, nil
+}
+
+// WithdrawStakeParams are the parameters of a stake withdrawal.
+type WithdrawStakeParams struct {
+	// The owner of the pool tokens, signing the transaction and funding
+	// the rent of the new stake accounts.
+	Owner ag_solanago.PublicKey
+
+	// The pool tokens to burn.
+	PoolTokens uint64
+
+	// The token account to burn from. Defaults to the associated token account of Owner.
+	PoolTokenAccount ag_solanago.PublicKey
+
+	// The staker and withdrawer of the new stake accounts. Defaults to Owner.
+	StakeAuthority ag_solanago.PublicKey
+
+	// If set, WithdrawStakeWithSlippage is used, with minimums of lamports
+	// lower than the expected amounts by this many basis points.
+	SlippageBps *uint64
+}
+
+// WithdrawStakeInstructions returns the instructions withdrawing stake from the pool,
+// split among its stake accounts by SelectWithdrawStakeSplits, and the keys of the new
+// stake accounts, which sign the transaction.
+func (p *Pool) WithdrawStakeInstructions(params WithdrawStakeParams) ([]ag_solanago.Instruction, []ag_solanago.PrivateKey, error) {
+	source, err := p.sourceTokenAccount(params.PoolTokenAccount, params.Owner)
+	if err != nil {
+		return nil, nil, err
+	}
+	stakeAuthority := params.StakeAuthority
+	if stakeAuthority.IsZero() {
+		stakeAuthority = params.Owner
+	}
+	withdrawals, err := p.SelectWithdrawStakeSplits(params.PoolTokens, source)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var instructions []ag_solanago.Instruction
+	var newStakeAccounts []ag_solanago.PrivateKey
+	for _, withdrawal := range withdrawals {
+		newStakeAccount, err := ag_solanago.NewRandomPrivateKey()
+		if err != nil {
+			return nil, nil, err
+		}
+		newStakeAccounts = append(newStakeAccounts, newStakeAccount)
+		instructions = append(instructions, system.NewCreateAccountInstruction(
+			p.StakeRentExemption,
+			stake.StakeStateV2Size,
+			ag_solanago.StakeProgramID,
+			params.Owner,
+			newStakeAccount.PublicKey(),
+		).Build())
+
+		if params.SlippageBps != nil {
+			minLamports, err := MinimumWithSlippage(withdrawal.Lamports, *params.SlippageBps)
+			if err != nil {
+				return nil, nil, err
+			}
+			instructions = append(instructions, NewWithdrawStakeWithSlippageInstruction(
+				withdrawal.PoolTokens,
+				minLamports,
+				p.Address,
+				p.StakePool.ValidatorList,
+				p.WithdrawAuthority,
+				withdrawal.StakeAccount,
+				newStakeAccount.PublicKey(),
+				stakeAuthority,
+				params.Owner,
+				source,
+				p.StakePool.ManagerFeeAccount,
+				p.StakePool.PoolMint,
+				ag_solanago.SysVarClockPubkey,
+				p.StakePool.TokenProgramID,
+				ag_solanago.StakeProgramID,
+			).Build())
+			continue
+		}
+		instructions = append(instructions, NewWithdrawStakeInstruction(
+			withdrawal.PoolTokens,
+			p.Address,
+			p.StakePool.ValidatorList,
+			p.WithdrawAuthority,
+			withdrawal.StakeAccount,
+			newStakeAccount.PublicKey(),
+			stakeAuthority,
+			params.Owner,
+			source,
+			p.StakePool.ManagerFeeAccount,
+			p.StakePool.PoolMint,
+			ag_solanago.SysVarClockPubkey,
+			p.StakePool.TokenProgramID,
+			ag_solanago.StakeProgramID,
+		).Build())
+	}
+	return instructions, newStakeAccounts, nil
+}
+
+// UpdateInstructions returns the instructions updating the pool for the current epoch:
+// the UpdateValidatorListBalance instructions, for MaxValidatorsToUpdate validators
+// each, which can run in parallel, and the UpdateStakePoolBalance and
+// CleanupRemovedValidatorEntries instructions, to run after them.
+func (p *Pool) UpdateInstructions(noMerge bool) (validatorListInstructions []ag_solanago.Instruction, poolInstructions []ag_solanago.Instruction, err error) {
+	validators := p.ValidatorList.Validators
+	for start := 0; start < len(validators); start += MaxValidatorsToUpdate {
+		end := start + MaxValidatorsToUpdate
+		if end > len(validators) {
+			end = len(validators)
+		}
+		var stakeAccounts []ag_solanago.PublicKey
+		for i := start; i < end; i++ {
+			validatorStake, err := p.ValidatorStakeAddress(&validators[i])
+			if err != nil {
+				return nil, nil, err
+			}
+			transientStake, err := p.TransientStakeAddress(&validators[i])
+			if err != nil {
+				return nil, nil, err
+			}
+			stakeAccounts = append(stakeAccounts, validatorStake, transientStake)
+		}
+		validatorListInstructions = append(validatorListInstructions, NewUpdateValidatorListBalanceInstructionBuilder().
+			SetArgs(UpdateValidatorListBalanceArgs{
+				StartIndex: uint32(start),
+				NoMerge:    noMerge,
+			}).
+			SetStakePool(p.Address).
+			SetWithdrawAuthority(p.WithdrawAuthority).
+			SetValidatorList(p.StakePool.ValidatorList).
+			SetReserveStake(p.StakePool.ReserveStake).
+			SetClock(ag_solanago.SysVarClockPubkey).
+			SetStakeHistory(ag_solanago.SysVarStakeHistoryPubkey).
+			SetStakeProgram(ag_solanago.StakeProgramID).
+			SetValidatorAndTransientAccounts(stakeAccounts).
+			Build())
+	}
+
+	poolInstructions = []ag_solanago.Instruction{
+		NewUpdateStakePoolBalanceInstruction(
+			p.Address,
+			p.WithdrawAuthority,
+			p.StakePool.ValidatorList,
+			p.StakePool.ReserveStake,
+			p.StakePool.ManagerFeeAccount,
+			p.StakePool.PoolMint,
+			p.StakePool.TokenProgramID,
+		).Build(),
+		NewCleanupRemovedValidatorEntriesInstruction(
+			p.Address,
+			p.StakePool.ValidatorList,
+		).Build(),
+	}
+	return validatorListInstructions, poolInstructions, nil
+}
@@ -0,0 +1,390 @@
+// Copyright 2021 github.com/gagliardetto
+// Copyright 2025 github.com/liquid-collective
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stakepool
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	ag_solanago "github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/programs/stake"
+)
+
+const stakeRentExemption = 2_282_880
+
+var (
+	voteA = ag_solanago.MustPublicKeyFromBase58("CertusDeBmqN8ZawdkxK5kFGMwBXdudvWHYwtNgNhvLu")
+	voteB = ag_solanago.MustPublicKeyFromBase58("he1iusunGwqrNtafDtLdhsUQDFvo13z9sUa36PauBtk")
+	voteC = ag_solanago.MustPublicKeyFromBase58("Haz7b47sZBpxh9SwggGndN3fAyNQ1S949BPdxWXS3ab6")
+)
+
+// newTestPool returns a pool worth 1.1 lamports per pool token, with three validators:
+// A and B with active stake, C with transient stake only.
+func newTestPool(t *testing.T) *Pool {
+	depositAuthority, _, err := FindDepositAuthorityProgramAddress(ProgramID, jitoStakePool)
+	require.NoError(t, err)
+	stakePool := &StakePool{
+		AccountType:           AccountTypeStakePool,
+		Manager:               ag_solanago.NewWallet().PublicKey(),
+		Staker:                ag_solanago.NewWallet().PublicKey(),
+		StakeDepositAuthority: depositAuthority,
+		ValidatorList:         ag_solanago.NewWallet().PublicKey(),
+		ReserveStake:          ag_solanago.NewWallet().PublicKey(),
+		PoolMint:              ag_solanago.NewWallet().PublicKey(),
+		ManagerFeeAccount:     ag_solanago.NewWallet().PublicKey(),
+		TokenProgramID:        ag_solanago.TokenProgramID,
+		TotalLamports:         1_100_000_000_000,
+		PoolTokenSupply:       1_000_000_000_000,
+		SolDepositFee:         Fee{Denominator: 1000, Numerator: 1},
+		StakeWithdrawalFee:    Fee{Denominator: 1000, Numerator: 3},
+		SolWithdrawalFee:      Fee{Denominator: 1000, Numerator: 5},
+	}
+	validatorList := &ValidatorList{
+		AccountType:   AccountTypeValidatorList,
+		MaxValidators: 10,
+		Validators: []ValidatorStakeInfo{
+			{VoteAccountAddress: voteA, ActiveStakeLamports: 100_000_000_000},
+			{VoteAccountAddress: voteB, ActiveStakeLamports: 300_000_000_000, ValidatorSeedSuffix: 1},
+			{VoteAccountAddress: voteC, ActiveStakeLamports: 3_282_880, TransientStakeLamports: 50_000_000_000, TransientSeedSuffix: 7},
+		},
+	}
+	pool, err := NewPool(ProgramID, jitoStakePool, stakePool, validatorList)
+	require.NoError(t, err)
+	pool.ReserveLamports = 10_000_000_000
+	pool.StakeRentExemption = stakeRentExemption
+	pool.StakeMinimumDelegation = 1
+	return pool
+}
+
+func instructionData(t *testing.T, instruction ag_solanago.Instruction) []byte {
+	data, err := instruction.Data()
+	require.NoError(t, err)
+	return data
+}
+
+func TestPool_DepositSolInstructions(t *testing.T) {
+	pool := newTestPool(t)
+	from := ag_solanago.NewWallet().PublicKey()
+
+	instructions, err := pool.DepositSolInstructions(DepositSolParams{From: from, Lamports: 1_100_000})
+	require.NoError(t, err)
+	require.Len(t, instructions, 2)
+	ata, err := pool.associatedTokenAddress(from)
+	require.NoError(t, err)
+	assert.Equal(t, ag_solanago.SPLAssociatedTokenAccountProgramID, instructions[0].ProgramID())
+
+	deposit := instructions[1]
+	assert.Equal(t, u64Data(Instruction_DepositSol, 1_100_000), instructionData(t, deposit))
+	accounts := deposit.Accounts()
+	require.Len(t, accounts, 10)
+	assert.Equal(t, pool.WithdrawAuthority, accounts[1].PublicKey)
+	assert.Equal(t, ag_solanago.Meta(from).WRITE().SIGNER(), accounts[3])
+	assert.Equal(t, ata, accounts[4].PublicKey)
+	assert.Equal(t, ata, accounts[6].PublicKey)
+
+	// 1_000_000 pool tokens, minus a 1_000 fee, minus 0.5%.
+	slippage := uint64(50)
+	existing := ag_solanago.NewWallet().PublicKey()
+	instructions, err = pool.DepositSolInstructions(DepositSolParams{
+		From:             from,
+		Lamports:         1_100_000,
+		PoolTokenAccount: existing,
+		SlippageBps:      &slippage,
+	})
+	require.NoError(t, err)
+	require.Len(t, instructions, 1)
+	assert.Equal(t, u64Data(Instruction_DepositSolWithSlippage, 1_100_000, 994_005), instructionData(t, instructions[0]))
+	assert.Equal(t, existing, instructions[0].Accounts()[4].PublicKey)
+
+	authority := ag_solanago.NewWallet().PublicKey()
+	pool.StakePool.SolDepositAuthority = &authority
+	_, err = pool.DepositSolInstructions(DepositSolParams{From: from, Lamports: 1_100_000})
+	assert.Error(t, err)
+	instructions, err = pool.DepositSolInstructions(DepositSolParams{From: from, Lamports: 1_100_000, SolDepositAuthority: authority})
+	require.NoError(t, err)
+	accounts = instructions[1].Accounts()
+	require.Len(t, accounts, 11)
+	assert.Equal(t, ag_solanago.Meta(authority).SIGNER(), accounts[10])
+
+	_, err = pool.DepositSolInstructions(DepositSolParams{From: from, Lamports: 1, SolDepositAuthority: authority})
+	assert.ErrorIs(t, err, ErrDepositTooSmall)
+}
+
+func TestPool_WithdrawSolInstructions(t *testing.T) {
+	pool := newTestPool(t)
+	owner := ag_solanago.NewWallet().PublicKey()
+	ata, err := pool.associatedTokenAddress(owner)
+	require.NoError(t, err)
+
+	// 1_000_000 pool tokens, minus a 5_000 fee, at 1.1 lamports each, minus 1%.
+	slippage := uint64(100)
+	instructions, err := pool.WithdrawSolInstructions(WithdrawSolParams{Owner: owner, PoolTokens: 1_000_000, SlippageBps: &slippage})
+	require.NoError(t, err)
+	require.Len(t, instructions, 1)
+	assert.Equal(t, u64Data(Instruction_WithdrawSolWithSlippage, 1_000_000, 1_083_555), instructionData(t, instructions[0]))
+	accounts := instructions[0].Accounts()
+	require.Len(t, accounts, 12)
+	assert.Equal(t, ag_solanago.Meta(owner).SIGNER(), accounts[2])
+	assert.Equal(t, ata, accounts[3].PublicKey)
+	assert.Equal(t, owner, accounts[5].PublicKey)
+
+	// No fee from the manager fee account.
+	recipient := ag_solanago.NewWallet().PublicKey()
+	instructions, err = pool.WithdrawSolInstructions(WithdrawSolParams{
+		Owner:            owner,
+		PoolTokens:       1_000_000,
+		PoolTokenAccount: pool.StakePool.ManagerFeeAccount,
+		Recipient:        recipient,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, u64Data(Instruction_WithdrawSol, 1_000_000), instructionData(t, instructions[0]))
+	assert.Equal(t, recipient, instructions[0].Accounts()[5].PublicKey)
+
+	_, err = pool.WithdrawSolInstructions(WithdrawSolParams{Owner: owner, PoolTokens: 10_000_000_000})
+	assert.Error(t, err, "above the reserve")
+	_, err = pool.WithdrawSolInstructions(WithdrawSolParams{Owner: owner, PoolTokens: 0})
+	assert.ErrorIs(t, err, ErrWithdrawalTooSmall)
+}
+
+func TestPool_DepositStakeInstructions(t *testing.T) {
+	pool := newTestPool(t)
+	staker := ag_solanago.NewWallet().PublicKey()
+	withdrawer := ag_solanago.NewWallet().PublicKey()
+	stakeAccount := &stake.StakeAccount{
+		Pubkey:   ag_solanago.NewWallet().PublicKey(),
+		Lamports: 11_002_282_880,
+		State: &stake.StakeStateV2{
+			Type: stake.StakeStateV2Stake,
+			Meta: &stake.Meta{
+				RentExemptReserve: stakeRentExemption,
+				Authorized:        stake.Authorized{Staker: &staker, Withdrawer: &withdrawer},
+			},
+			Stake: &stake.Stake{Delegation: stake.Delegation{VoterPubkey: voteB, Stake: 11_000_000_000}},
+		},
+	}
+	validatorStake, err := pool.ValidatorStakeAddress(&pool.ValidatorList.Validators[1])
+	require.NoError(t, err)
+
+	slippage := uint64(0)
+	instructions, err := pool.DepositStakeInstructions(stakeAccount, DepositStakeParams{SlippageBps: &slippage})
+	require.NoError(t, err)
+	require.Len(t, instructions, 4)
+
+	for i, authority := range []ag_solanago.PublicKey{staker, withdrawer} {
+		authorize := instructions[1+i]
+		assert.Equal(t, ag_solanago.StakeProgramID, authorize.ProgramID())
+		accounts := authorize.Accounts()
+		assert.Equal(t, stakeAccount.Pubkey, accounts[0].PublicKey)
+		assert.Equal(t, authority, accounts[2].PublicKey)
+		assert.True(t, accounts[2].IsSigner)
+	}
+
+	// 10_000_000_000 pool tokens for the stake, without fee, and 2_075_345 for
+	// the rent, minus a SOL deposit fee of 2_076.
+	deposit := instructions[3]
+	assert.Equal(t, u64Data(Instruction_DepositStakeWithSlippage, 10_002_073_269), instructionData(t, deposit))
+	accounts := deposit.Accounts()
+	require.Len(t, accounts, 15)
+	assert.Equal(t, ag_solanago.Meta(pool.StakePool.StakeDepositAuthority), accounts[2])
+	assert.Equal(t, stakeAccount.Pubkey, accounts[4].PublicKey)
+	assert.Equal(t, validatorStake, accounts[5].PublicKey)
+
+	// Lamports above the delegation and the rent, e.g. tips, are not credited.
+	stakeAccount.Lamports += 1_000_000_000
+	instructions, err = pool.DepositStakeInstructions(stakeAccount, DepositStakeParams{SlippageBps: &slippage})
+	require.NoError(t, err)
+	assert.Equal(t, u64Data(Instruction_DepositStakeWithSlippage, 10_002_073_269), instructionData(t, instructions[3]))
+
+	// A custom deposit authority signs.
+	custom := ag_solanago.NewWallet().PublicKey()
+	pool.StakePool.StakeDepositAuthority = custom
+	instructions, err = pool.DepositStakeInstructions(stakeAccount, DepositStakeParams{})
+	require.NoError(t, err)
+	assert.Equal(t, []byte{Instruction_DepositStake}, instructionData(t, instructions[3]))
+	assert.Equal(t, ag_solanago.Meta(custom).SIGNER(), instructions[3].Accounts()[2])
+
+	stakeAccount.State.Stake.Delegation.VoterPubkey = ag_solanago.NewWallet().PublicKey()
+	_, err = pool.DepositStakeInstructions(stakeAccount, DepositStakeParams{})
+	assert.Error(t, err)
+}
+
+func TestPool_SelectWithdrawStakeSplits(t *testing.T) {
+	pool := newTestPool(t)
+	source := ag_solanago.NewWallet().PublicKey()
+	// Rent exemption and minimum active stake, then the lamports of a pool token.
+	const required = stakeRentExemption + MinimumActiveStake
+	const minimum = required + 2
+
+	t.Run("largest validator first", func(t *testing.T) {
+		splits, err := pool.SelectWithdrawStakeSplits(150_000_000_000, source)
+		require.NoError(t, err)
+		require.Len(t, splits, 1)
+		assert.Equal(t, voteB, splits[0].VoteAccount)
+		assert.Equal(t, uint64(150_000_000_000), splits[0].PoolTokens)
+		assert.Equal(t, uint64(164_505_000_000), splits[0].Lamports)
+	})
+
+	t.Run("preferred validator first", func(t *testing.T) {
+		pool.StakePool.PreferredWithdrawValidatorVoteAddress = &voteA
+		defer func() { pool.StakePool.PreferredWithdrawValidatorVoteAddress = nil }()
+
+		splits, err := pool.SelectWithdrawStakeSplits(150_000_000_000, source)
+		require.NoError(t, err)
+		require.Len(t, splits, 2)
+		assert.Equal(t, voteA, splits[0].VoteAccount)
+		assert.Equal(t, voteB, splits[1].VoteAccount)
+		assert.Equal(t, uint64(150_000_000_000), splits[0].PoolTokens+splits[1].PoolTokens)
+
+		// The preferred validator is emptied down to its minimum, so that the
+		// program accepts the split from the next validator.
+		remaining := 100_000_000_000 - splits[0].Lamports
+		assert.GreaterOrEqual(t, remaining, uint64(required))
+		assert.LessOrEqual(t, remaining, uint64(minimum))
+		stakeAddress, err := pool.ValidatorStakeAddress(&pool.ValidatorList.Validators[0])
+		require.NoError(t, err)
+		assert.Equal(t, stakeAddress, splits[0].StakeAccount)
+	})
+
+	t.Run("preferred validator drained for any balance", func(t *testing.T) {
+		for i := uint64(0); i < 50; i++ {
+			pool := newTestPool(t)
+			pool.StakePool.TotalLamports += i * 7_919_333_331
+			pool.StakePool.PoolTokenSupply += i * 3_141_592_653
+			pool.StakePool.StakeWithdrawalFee = Fee{Denominator: 1000, Numerator: i % 7}
+			preferredStake := 50_000_000_000 + i*1_234_567_891
+			pool.ValidatorList.Validators[0].ActiveStakeLamports = preferredStake
+			pool.StakePool.PreferredWithdrawValidatorVoteAddress = &voteA
+			lamportsPerPoolToken, err := pool.StakePool.LamportsPerPoolToken()
+			require.NoError(t, err)
+
+			splits, err := pool.SelectWithdrawStakeSplits(150_000_000_000, source)
+			require.NoError(t, err)
+			require.Len(t, splits, 2, "balance %d", i)
+			require.Equal(t, voteA, splits[0].VoteAccount)
+			remaining := preferredStake - splits[0].Lamports
+			assert.GreaterOrEqual(t, remaining, uint64(required), "balance %d", i)
+			// What the program checks before a split from another validator.
+			assert.LessOrEqual(t, remaining, required+lamportsPerPoolToken, "balance %d", i)
+		}
+	})
+
+	t.Run("validators being removed are skipped", func(t *testing.T) {
+		pool := newTestPool(t)
+		pool.ValidatorList.Validators[0].ActiveStakeLamports = minimum
+		pool.ValidatorList.Validators[1].ActiveStakeLamports = minimum
+		pool.ValidatorList.Validators[2].Status = StakeStatusDeactivatingTransient
+
+		splits, err := pool.SelectWithdrawStakeSplits(1_000_000_000, source)
+		require.NoError(t, err)
+		require.Len(t, splits, 1)
+		assert.Equal(t, pool.StakePool.ReserveStake, splits[0].StakeAccount)
+	})
+
+	t.Run("transient stake without active stake", func(t *testing.T) {
+		pool := newTestPool(t)
+		pool.ValidatorList.Validators[0].ActiveStakeLamports = minimum
+		pool.ValidatorList.Validators[1].ActiveStakeLamports = minimum
+
+		splits, err := pool.SelectWithdrawStakeSplits(1_000_000_000, source)
+		require.NoError(t, err)
+		require.Len(t, splits, 1)
+		transient, err := pool.TransientStakeAddress(&pool.ValidatorList.Validators[2])
+		require.NoError(t, err)
+		assert.Equal(t, transient, splits[0].StakeAccount)
+		assert.Equal(t, voteC, splits[0].VoteAccount)
+	})
+
+	t.Run("reserve without validator stake", func(t *testing.T) {
+		pool := newTestPool(t)
+		pool.ValidatorList.Validators[0].ActiveStakeLamports = minimum
+		pool.ValidatorList.Validators[1].ActiveStakeLamports = minimum
+		pool.ValidatorList.Validators[2].TransientStakeLamports = 0
+
+		splits, err := pool.SelectWithdrawStakeSplits(1_000_000_000, source)
+		require.NoError(t, err)
+		require.Len(t, splits, 1)
+		assert.Equal(t, pool.StakePool.ReserveStake, splits[0].StakeAccount)
+		assert.True(t, splits[0].VoteAccount.IsZero())
+
+		_, err = pool.SelectWithdrawStakeSplits(10_000_000_000, source)
+		assert.Error(t, err)
+	})
+}
+
+func TestPool_WithdrawStakeInstructions(t *testing.T) {
+	pool := newTestPool(t)
+	owner := ag_solanago.NewWallet().PublicKey()
+	authority := ag_solanago.NewWallet().PublicKey()
+	pool.StakePool.PreferredWithdrawValidatorVoteAddress = &voteA
+
+	slippage := uint64(10)
+	instructions, newStakeAccounts, err := pool.WithdrawStakeInstructions(WithdrawStakeParams{
+		Owner:          owner,
+		PoolTokens:     150_000_000_000,
+		StakeAuthority: authority,
+		SlippageBps:    &slippage,
+	})
+	require.NoError(t, err)
+	require.Len(t, newStakeAccounts, 2)
+	require.Len(t, instructions, 4)
+
+	source, err := pool.associatedTokenAddress(owner)
+	require.NoError(t, err)
+	splits, err := pool.SelectWithdrawStakeSplits(150_000_000_000, source)
+	require.NoError(t, err)
+	for i, split := range splits {
+		create := instructions[2*i]
+		assert.Equal(t, ag_solanago.SystemProgramID, create.ProgramID())
+		assert.Equal(t, newStakeAccounts[i].PublicKey(), create.Accounts()[1].PublicKey)
+
+		withdraw := instructions[2*i+1]
+		minLamports := split.Lamports * 999 / 1000
+		assert.Equal(t, u64Data(Instruction_WithdrawStakeWithSlippage, split.PoolTokens, minLamports), instructionData(t, withdraw))
+		accounts := withdraw.Accounts()
+		require.Len(t, accounts, 13)
+		assert.Equal(t, split.StakeAccount, accounts[3].PublicKey)
+		assert.Equal(t, newStakeAccounts[i].PublicKey(), accounts[4].PublicKey)
+		assert.Equal(t, authority, accounts[5].PublicKey)
+		assert.Equal(t, ag_solanago.Meta(owner).SIGNER(), accounts[6])
+		assert.Equal(t, source, accounts[7].PublicKey)
+	}
+}
+
+func TestPool_UpdateInstructions(t *testing.T) {
+	pool := newTestPool(t)
+	for i := 0; i < 4; i++ {
+		pool.ValidatorList.Validators = append(pool.ValidatorList.Validators, ValidatorStakeInfo{
+			VoteAccountAddress: ag_solanago.NewWallet().PublicKey(),
+		})
+	}
+
+	validatorListInstructions, poolInstructions, err := pool.UpdateInstructions(false)
+	require.NoError(t, err)
+	require.Len(t, validatorListInstructions, 2)
+	assert.Len(t, validatorListInstructions[0].Accounts(), 7+2*MaxValidatorsToUpdate)
+	assert.Len(t, validatorListInstructions[1].Accounts(), 7+2*2)
+	assert.Equal(t, []byte{Instruction_UpdateValidatorListBalance, 5, 0, 0, 0, 0}, instructionData(t, validatorListInstructions[1]))
+
+	transient, err := pool.TransientStakeAddress(&pool.ValidatorList.Validators[2])
+	require.NoError(t, err)
+	assert.Equal(t, transient, validatorListInstructions[0].Accounts()[7+2*2+1].PublicKey)
+
+	require.Len(t, poolInstructions, 2)
+	assert.Equal(t, []byte{Instruction_UpdateStakePoolBalance}, instructionData(t, poolInstructions[0]))
+	assert.Equal(t, []byte{Instruction_CleanupRemovedValidatorEntries}, instructionData(t, poolInstructions[1]))
+}
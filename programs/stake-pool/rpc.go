@@ -0,0 +1,86 @@
+// Copyright 2021 github.com/gagliardetto
+// Copyright 2025 github.com/liquid-collective
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stakepool
+
+import (
+	"context"
+	"fmt"
+
+	bin "github.com/gagliardetto/binary"
+	ag_solanago "github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+)
+
+// DecodeStakePool decodes the data of a stake pool account.
+func DecodeStakePool(data []byte) (*StakePool, error) {
+	if len(data) == 0 || data[0] != AccountTypeStakePool {
+		return nil, fmt.Errorf("not a stake pool account")
+	}
+	pool := new(StakePool)
+	if err := bin.NewBorshDecoder(data).Decode(pool); err != nil {
+		return nil, fmt.Errorf("unable to decode stake pool: %w", err)
+	}
+	return pool, nil
+}
+
+// DecodeValidatorList decodes the data of a validator list account.
+func DecodeValidatorList(data []byte) (*ValidatorList, error) {
+	if len(data) == 0 || data[0] != AccountTypeValidatorList {
+		return nil, fmt.Errorf("not a validator list account")
+	}
+	list := new(ValidatorList)
+	if err := bin.NewBorshDecoder(data).Decode(list); err != nil {
+		return nil, fmt.Errorf("unable to decode validator list: %w", err)
+	}
+	return list, nil
+}
+
+// FetchStakePool fetches and decodes a stake pool account.
+func FetchStakePool(ctx context.Context, rpcCli *rpc.Client, pubkey ag_solanago.PublicKey, commitment rpc.CommitmentType) (*StakePool, error) {
+	data, err := fetchAccountData(ctx, rpcCli, pubkey, commitment)
+	if err != nil {
+		return nil, err
+	}
+	pool, err := DecodeStakePool(data)
+	if err != nil {
+		return nil, fmt.Errorf("account %s: %w", pubkey, err)
+	}
+	return pool, nil
+}
+
+// FetchValidatorList fetches and decodes a validator list account.
+func FetchValidatorList(ctx context.Context, rpcCli *rpc.Client, pubkey ag_solanago.PublicKey, commitment rpc.CommitmentType) (*ValidatorList, error) {
+	data, err := fetchAccountData(ctx, rpcCli, pubkey, commitment)
+	if err != nil {
+		return nil, err
+	}
+	list, err := DecodeValidatorList(data)
+	if err != nil {
+		return nil, fmt.Errorf("account %s: %w", pubkey, err)
+	}
+	return list, nil
+}
+
+func fetchAccountData(ctx context.Context, rpcCli *rpc.Client, pubkey ag_solanago.PublicKey, commitment rpc.CommitmentType) ([]byte, error) {
+	out, err := rpcCli.GetAccountInfoWithOpts(ctx, pubkey, &rpc.GetAccountInfoOpts{
+		Encoding:   ag_solanago.EncodingBase64,
+		Commitment: commitment,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return out.Value.Data.GetBinary(), nil
+}
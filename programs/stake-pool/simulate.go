@@ -0,0 +1,310 @@
+// Copyright 2021 github.com/gagliardetto
+// Copyright 2025 github.com/liquid-collective
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stakepool
+
+// DepositPreview is the outcome of a deposit, as computed by the program.
+type DepositPreview struct {
+	// The lamports deposited.
+	Lamports uint64
+
+	// The pool tokens minted in total, fees included.
+	PoolTokens uint64
+
+	// The pool tokens minted to the depositor. Use this, with some slippage,
+	// as the minimum pool tokens out of the WithSlippage deposit instructions.
+	UserPoolTokens uint64
+
+	// The deposit fee, split between the manager and the referrer.
+	Fee uint64
+
+	// The part of the fee minted to the manager fee account.
+	ManagerFee uint64
+
+	// The part of the fee minted to the referrer.
+	ReferralFee uint64
+}
+
+// WithdrawPreview is the outcome of a withdrawal, as computed by the program.
+type WithdrawPreview struct {
+	// The pool tokens withdrawn, fee included.
+	PoolTokens uint64
+
+	// The withdrawal fee, transferred to the manager fee account.
+	Fee uint64
+
+	// The pool tokens burnt.
+	BurntPoolTokens uint64
+
+	// The lamports withdrawn. Use this, with some slippage, as the minimum
+	// lamports out of the WithSlippage withdraw instructions.
+	Lamports uint64
+}
+
+// UpdatePreview is the outcome of an UpdateStakePoolBalance instruction.
+type UpdatePreview struct {
+	// The lamports earned since the last update.
+	RewardLamports uint64
+
+	// The pool tokens minted to the manager fee account as epoch fee.
+	FeePoolTokens uint64
+}
+
+// PreviewDepositSol returns the outcome of a DepositSol of lamports.
+func (p *StakePool) PreviewDepositSol(lamports uint64) (*DepositPreview, error) {
+	poolTokens, err := p.CalcPoolTokensForDeposit(lamports)
+	if err != nil {
+		return nil, err
+	}
+	fee, err := p.CalcPoolTokensSolDepositFee(poolTokens)
+	if err != nil {
+		return nil, err
+	}
+	referralFee, err := p.CalcPoolTokensSolReferralFee(fee)
+	if err != nil {
+		return nil, err
+	}
+	return newDepositPreview(lamports, poolTokens, fee, referralFee)
+}
+
+// PreviewDepositStake returns the outcome of a DepositStake of a stake account
+// holding lamports, of which stakeLamports are delegated. Only stakeRent (the
+// rent exempt reserve of the stake account) of the undelegated lamports is
+// credited: the pool keeps the rest without minting pool tokens for it.
+// The stake deposit fee applies to the delegated stake and the SOL deposit
+// fee to the credited rent.
+func (p *StakePool) PreviewDepositStake(lamports, stakeLamports, stakeRent uint64) (*DepositPreview, error) {
+	if stakeLamports > lamports {
+		return nil, ErrCalculationFailure
+	}
+	credited := stakeLamports + min64(lamports-stakeLamports, stakeRent)
+	poolTokens, err := p.CalcPoolTokensForDeposit(credited)
+	if err != nil {
+		return nil, err
+	}
+	stakePoolTokens, err := p.CalcPoolTokensForDeposit(stakeLamports)
+	if err != nil {
+		return nil, err
+	}
+	if stakePoolTokens > poolTokens {
+		return nil, ErrCalculationFailure
+	}
+	stakeFee, err := p.CalcPoolTokensStakeDepositFee(stakePoolTokens)
+	if err != nil {
+		return nil, err
+	}
+	solFee, err := p.CalcPoolTokensSolDepositFee(poolTokens - stakePoolTokens)
+	if err != nil {
+		return nil, err
+	}
+	referralFee, err := p.CalcPoolTokensStakeReferralFee(stakeFee + solFee)
+	if err != nil {
+		return nil, err
+	}
+	return newDepositPreview(lamports, poolTokens, stakeFee+solFee, referralFee)
+}
+
+func newDepositPreview(lamports, poolTokens, fee, referralFee uint64) (*DepositPreview, error) {
+	if fee > poolTokens || referralFee > fee {
+		return nil, ErrCalculationFailure
+	}
+	if poolTokens == fee {
+		return nil, ErrDepositTooSmall
+	}
+	return &DepositPreview{
+		Lamports:       lamports,
+		PoolTokens:     poolTokens,
+		UserPoolTokens: poolTokens - fee,
+		Fee:            fee,
+		ManagerFee:     fee - referralFee,
+		ReferralFee:    referralFee,
+	}, nil
+}
+
+// PreviewWithdrawSol returns the outcome of a WithdrawSol of poolTokens.
+// Withdrawals from the manager fee account pay no fee.
+func (p *StakePool) PreviewWithdrawSol(poolTokens uint64, fromManagerFeeAccount bool) (*WithdrawPreview, error) {
+	return nonZeroWithdraw(p.previewWithdraw(p.SolWithdrawalFee, poolTokens, fromManagerFeeAccount))
+}
+
+// PreviewWithdrawStake returns the outcome of a WithdrawStake of poolTokens.
+// Withdrawals from the manager fee account pay no fee.
+func (p *StakePool) PreviewWithdrawStake(poolTokens uint64, fromManagerFeeAccount bool) (*WithdrawPreview, error) {
+	return nonZeroWithdraw(p.previewWithdraw(p.StakeWithdrawalFee, poolTokens, fromManagerFeeAccount))
+}
+
+func (p *StakePool) previewWithdraw(fee Fee, poolTokens uint64, fromManagerFeeAccount bool) (*WithdrawPreview, error) {
+	var feeTokens uint64
+	if !fromManagerFeeAccount {
+		var err error
+		if feeTokens, err = fee.Apply(poolTokens); err != nil {
+			return nil, err
+		}
+	}
+	if feeTokens > poolTokens {
+		return nil, ErrCalculationFailure
+	}
+	lamports, err := p.CalcLamportsWithdrawAmount(poolTokens - feeTokens)
+	if err != nil {
+		return nil, err
+	}
+	return &WithdrawPreview{
+		PoolTokens:      poolTokens,
+		Fee:             feeTokens,
+		BurntPoolTokens: poolTokens - feeTokens,
+		Lamports:        lamports,
+	}, nil
+}
+
+// nonZeroWithdraw rejects withdrawals giving no lamports, as the program does.
+func nonZeroWithdraw(preview *WithdrawPreview, err error) (*WithdrawPreview, error) {
+	if err == nil && preview.Lamports == 0 {
+		return nil, ErrWithdrawalTooSmall
+	}
+	return preview, err
+}
+
+// Simulator applies deposits, withdrawals and balance updates to a copy of
+// a stake pool, the way the program does, to preview a sequence of them.
+type Simulator struct {
+	// The simulated stake pool.
+	StakePool StakePool
+}
+
+// NewSimulator returns a Simulator starting from a copy of stakePool.
+func NewSimulator(stakePool *StakePool) *Simulator {
+	return &Simulator{StakePool: *stakePool}
+}
+
+// DepositSol simulates a DepositSol of lamports.
+func (s *Simulator) DepositSol(lamports uint64) (*DepositPreview, error) {
+	preview, err := s.StakePool.PreviewDepositSol(lamports)
+	if err != nil {
+		return nil, err
+	}
+	return preview, s.deposit(preview)
+}
+
+// DepositStake simulates a DepositStake of a stake account holding lamports,
+// of which stakeLamports are delegated, and stakeRent is its rent exempt reserve.
+// All the lamports are added to the pool, even those not credited.
+func (s *Simulator) DepositStake(lamports, stakeLamports, stakeRent uint64) (*DepositPreview, error) {
+	preview, err := s.StakePool.PreviewDepositStake(lamports, stakeLamports, stakeRent)
+	if err != nil {
+		return nil, err
+	}
+	return preview, s.deposit(preview)
+}
+
+func (s *Simulator) deposit(preview *DepositPreview) error {
+	supply, err := checkedAdd(s.StakePool.PoolTokenSupply, preview.PoolTokens)
+	if err != nil {
+		return err
+	}
+	total, err := checkedAdd(s.StakePool.TotalLamports, preview.Lamports)
+	if err != nil {
+		return err
+	}
+	s.StakePool.PoolTokenSupply, s.StakePool.TotalLamports = supply, total
+	return nil
+}
+
+// WithdrawSol simulates a WithdrawSol of poolTokens.
+func (s *Simulator) WithdrawSol(poolTokens uint64, fromManagerFeeAccount bool) (*WithdrawPreview, error) {
+	preview, err := s.StakePool.PreviewWithdrawSol(poolTokens, fromManagerFeeAccount)
+	if err != nil {
+		return nil, err
+	}
+	return preview, s.withdraw(preview)
+}
+
+// WithdrawStake simulates a WithdrawStake of poolTokens.
+func (s *Simulator) WithdrawStake(poolTokens uint64, fromManagerFeeAccount bool) (*WithdrawPreview, error) {
+	preview, err := s.StakePool.PreviewWithdrawStake(poolTokens, fromManagerFeeAccount)
+	if err != nil {
+		return nil, err
+	}
+	return preview, s.withdraw(preview)
+}
+
+func (s *Simulator) withdraw(preview *WithdrawPreview) error {
+	if preview.BurntPoolTokens > s.StakePool.PoolTokenSupply || preview.Lamports > s.StakePool.TotalLamports {
+		return ErrCalculationFailure
+	}
+	s.StakePool.PoolTokenSupply -= preview.BurntPoolTokens
+	s.StakePool.TotalLamports -= preview.Lamports
+	return nil
+}
+
+// UpdateStakePoolBalance simulates an UpdateStakePoolBalance at epoch finding
+// totalLamports in the pool. The epoch fee is minted on the rewards and, on
+// the first update of an epoch, the fees scheduled for it take effect.
+func (s *Simulator) UpdateStakePoolBalance(epoch, totalLamports uint64) (*UpdatePreview, error) {
+	p := &s.StakePool
+	previousLamports, previousSupply := p.TotalLamports, p.PoolTokenSupply
+
+	var rewardLamports uint64
+	if totalLamports > previousLamports {
+		rewardLamports = totalLamports - previousLamports
+	}
+	fee, err := p.CalcEpochFeeAmount(rewardLamports)
+	if err != nil {
+		return nil, err
+	}
+	supply, err := checkedAdd(previousSupply, fee)
+	if err != nil {
+		return nil, err
+	}
+	p.PoolTokenSupply = supply
+
+	if p.LastUpdateEpoch < epoch {
+		if next := p.NextEpochFee.Next(); next != nil {
+			p.EpochFee = *next
+		}
+		p.NextEpochFee.UpdateEpoch()
+		if next := p.NextStakeWithdrawalFee.Next(); next != nil {
+			p.StakeWithdrawalFee = *next
+		}
+		p.NextStakeWithdrawalFee.UpdateEpoch()
+		if next := p.NextSolWithdrawalFee.Next(); next != nil {
+			p.SolWithdrawalFee = *next
+		}
+		p.NextSolWithdrawalFee.UpdateEpoch()
+		p.LastUpdateEpoch = epoch
+		p.LastEpochTotalLamports = previousLamports
+		p.LastEpochPoolTokenSupply = previousSupply
+	}
+	p.TotalLamports = totalLamports
+
+	return &UpdatePreview{
+		RewardLamports: rewardLamports,
+		FeePoolTokens:  fee,
+	}, nil
+}
+
+func checkedAdd(a, b uint64) (uint64, error) {
+	sum := a + b
+	if sum < a {
+		return 0, ErrCalculationFailure
+	}
+	return sum, nil
+}
+
+func min64(a, b uint64) uint64 {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,198 @@
+// Copyright 2021 github.com/gagliardetto
+// Copyright 2025 github.com/liquid-collective
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stakepool
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestStakePool() *StakePool {
+	return &StakePool{
+		AccountType:        AccountTypeStakePool,
+		TotalLamports:      1_100_000,
+		PoolTokenSupply:    1_000_000,
+		LastUpdateEpoch:    10,
+		EpochFee:           Fee{Denominator: 100, Numerator: 10},
+		StakeDepositFee:    Fee{Denominator: 100, Numerator: 2},
+		StakeWithdrawalFee: Fee{Denominator: 1000, Numerator: 3},
+		StakeReferralFee:   25,
+		SolDepositFee:      Fee{Denominator: 100, Numerator: 1},
+		SolReferralFee:     50,
+		SolWithdrawalFee:   Fee{Denominator: 1000, Numerator: 5},
+	}
+}
+
+func TestStakePool_PreviewDeposit(t *testing.T) {
+	stakePool := newTestStakePool()
+	assert.Equal(t, 1.1, stakePool.ExchangeRate())
+
+	t.Run("sol", func(t *testing.T) {
+		preview, err := stakePool.PreviewDepositSol(110_000)
+		require.NoError(t, err)
+		assert.Equal(t, &DepositPreview{
+			Lamports:       110_000,
+			PoolTokens:     100_000,
+			UserPoolTokens: 99_000,
+			Fee:            1_000,
+			ManagerFee:     500,
+			ReferralFee:    500,
+		}, preview)
+	})
+
+	t.Run("stake", func(t *testing.T) {
+		// 20_000 pool tokens of stake pay 2%, the 10_000 of rent pay 1%.
+		preview, err := stakePool.PreviewDepositStake(33_000, 22_000, 11_000)
+		require.NoError(t, err)
+		assert.Equal(t, &DepositPreview{
+			Lamports:       33_000,
+			PoolTokens:     30_000,
+			UserPoolTokens: 29_500,
+			Fee:            500,
+			ManagerFee:     375,
+			ReferralFee:    125,
+		}, preview)
+	})
+
+	t.Run("stake above rent", func(t *testing.T) {
+		// The 22_000 lamports above the delegation and the rent are not credited.
+		preview, err := stakePool.PreviewDepositStake(55_000, 22_000, 11_000)
+		require.NoError(t, err)
+		assert.Equal(t, &DepositPreview{
+			Lamports:       55_000,
+			PoolTokens:     30_000,
+			UserPoolTokens: 29_500,
+			Fee:            500,
+			ManagerFee:     375,
+			ReferralFee:    125,
+		}, preview)
+	})
+
+	t.Run("too small", func(t *testing.T) {
+		_, err := stakePool.PreviewDepositSol(1)
+		assert.ErrorIs(t, err, ErrDepositTooSmall)
+	})
+}
+
+func TestStakePool_PreviewWithdraw(t *testing.T) {
+	stakePool := newTestStakePool()
+
+	preview, err := stakePool.PreviewWithdrawSol(10_000, false)
+	require.NoError(t, err)
+	assert.Equal(t, &WithdrawPreview{PoolTokens: 10_000, Fee: 50, BurntPoolTokens: 9_950, Lamports: 10_945}, preview)
+
+	preview, err = stakePool.PreviewWithdrawStake(10_000, false)
+	require.NoError(t, err)
+	assert.Equal(t, &WithdrawPreview{PoolTokens: 10_000, Fee: 30, BurntPoolTokens: 9_970, Lamports: 10_967}, preview)
+
+	preview, err = stakePool.PreviewWithdrawSol(10_000, true)
+	require.NoError(t, err)
+	assert.Equal(t, &WithdrawPreview{PoolTokens: 10_000, BurntPoolTokens: 10_000, Lamports: 11_000}, preview)
+
+	_, err = stakePool.PreviewWithdrawSol(1, false)
+	assert.ErrorIs(t, err, ErrWithdrawalTooSmall)
+}
+
+func TestSimulator(t *testing.T) {
+	stakePool := newTestStakePool()
+	stakePool.NextEpochFee = FutureEpoch[Fee]{Enum: 1, One: Fee{Denominator: 100, Numerator: 5}}
+	stakePool.NextSolWithdrawalFee = FutureEpoch[Fee]{Enum: 2, Two: Fee{Denominator: 1000, Numerator: 1}}
+	simulator := NewSimulator(stakePool)
+
+	deposit, err := simulator.DepositSol(110_000)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(99_000), deposit.UserPoolTokens)
+	assert.Equal(t, uint64(1_100_000), simulator.StakePool.PoolTokenSupply)
+	assert.Equal(t, uint64(1_210_000), simulator.StakePool.TotalLamports)
+
+	withdraw, err := simulator.WithdrawStake(100_000, false)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(109_670), withdraw.Lamports)
+	assert.Equal(t, uint64(1_000_300), simulator.StakePool.PoolTokenSupply)
+	assert.Equal(t, uint64(1_100_330), simulator.StakePool.TotalLamports)
+
+	// The original account is left untouched.
+	assert.Equal(t, uint64(1_000_000), stakePool.PoolTokenSupply)
+
+	t.Run("deposit stake", func(t *testing.T) {
+		simulator := NewSimulator(stakePool)
+		deposit, err := simulator.DepositStake(55_000, 22_000, 11_000)
+		require.NoError(t, err)
+		assert.Equal(t, uint64(30_000), deposit.PoolTokens)
+		assert.Equal(t, uint64(1_030_000), simulator.StakePool.PoolTokenSupply)
+		// The uncredited lamports are added to the pool too.
+		assert.Equal(t, uint64(1_155_000), simulator.StakePool.TotalLamports)
+	})
+
+	t.Run("update", func(t *testing.T) {
+		// 10% of 110_000 lamports of rewards, minted at the rate after rewards.
+		update, err := simulator.UpdateStakePoolBalance(11, 1_210_330)
+		require.NoError(t, err)
+		assert.Equal(t, &UpdatePreview{RewardLamports: 110_000, FeePoolTokens: 9_174}, update)
+
+		got := simulator.StakePool
+		assert.Equal(t, uint64(1_009_474), got.PoolTokenSupply)
+		assert.Equal(t, uint64(1_210_330), got.TotalLamports)
+		assert.Equal(t, uint64(11), got.LastUpdateEpoch)
+		assert.Equal(t, uint64(1_100_330), got.LastEpochTotalLamports)
+		assert.Equal(t, uint64(1_000_300), got.LastEpochPoolTokenSupply)
+		assert.Equal(t, Fee{Denominator: 100, Numerator: 5}, got.EpochFee)
+		assert.Nil(t, got.NextEpochFee.Value())
+		assert.Equal(t, Fee{Denominator: 1000, Numerator: 5}, got.SolWithdrawalFee)
+		assert.Equal(t, &Fee{Denominator: 1000, Numerator: 1}, got.NextSolWithdrawalFee.Next())
+	})
+
+	t.Run("same epoch", func(t *testing.T) {
+		update, err := simulator.UpdateStakePoolBalance(11, 1_200_000)
+		require.NoError(t, err)
+		assert.Equal(t, &UpdatePreview{}, update)
+		assert.Equal(t, uint64(1_200_000), simulator.StakePool.TotalLamports)
+		assert.Equal(t, uint64(1_100_330), simulator.StakePool.LastEpochTotalLamports)
+		assert.Equal(t, Fee{Denominator: 1000, Numerator: 5}, simulator.StakePool.SolWithdrawalFee)
+	})
+
+	t.Run("next epoch", func(t *testing.T) {
+		_, err := simulator.UpdateStakePoolBalance(12, 1_200_000)
+		require.NoError(t, err)
+		assert.Equal(t, Fee{Denominator: 1000, Numerator: 1}, simulator.StakePool.SolWithdrawalFee)
+		assert.Nil(t, simulator.StakePool.NextSolWithdrawalFee.Value())
+		assert.Equal(t, uint64(1_200_000), simulator.StakePool.LastEpochTotalLamports)
+	})
+}
+
+func TestSimulator_UpdateEmptyPool(t *testing.T) {
+	// Without pool tokens, all the rewards are minted to the manager, 1:1.
+	stakePool := newTestStakePool()
+	stakePool.TotalLamports, stakePool.PoolTokenSupply = 0, 0
+	simulator := NewSimulator(stakePool)
+
+	update, err := simulator.UpdateStakePoolBalance(11, 5_000)
+	require.NoError(t, err)
+	assert.Equal(t, &UpdatePreview{RewardLamports: 5_000, FeePoolTokens: 5_000}, update)
+	assert.Equal(t, uint64(5_000), simulator.StakePool.PoolTokenSupply)
+	assert.Equal(t, uint64(5_000), simulator.StakePool.TotalLamports)
+}
+
+func TestMinimumWithSlippage(t *testing.T) {
+	minimum, err := MinimumWithSlippage(29_500, 50)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(29_352), minimum)
+
+	_, err = MinimumWithSlippage(29_500, 10_001)
+	assert.Error(t, err)
+}
@@ -36,8 +36,9 @@ type Authorize struct {
 	// [1] = [] ClockSysvar
 	// ··········· Clock sysvar account.
 	//
-	// [2] = [] StakeOrWithdrawAuthority
-	// ··········· Stake or withdraw authority.
+	// [2] = [SIGNER] StakeOrWithdrawAuthority
+	// ··········· Stake or withdraw authority; a signer unless it is a multisig
+	// ··········· whose M signer accounts follow.
 	//
 	// [3...] = [SIGNER] Signers
 	// ··········· M signer accounts.
@@ -156,13 +157,17 @@ func NewAuthorizeInstruction(
 	stakeOrWithdrawAuthority ag_solanago.PublicKey,
 	multisigSigners []ag_solanago.PublicKey,
 ) *Authorize {
+	authority := ag_solanago.Meta(stakeOrWithdrawAuthority)
+	if len(multisigSigners) == 0 {
+		authority.SIGNER()
+	}
 	return &Authorize{
 		NewAuthority:  &newAuthority,
 		AuthorityType: authorityType,
 		Accounts: ag_solanago.AccountMetaSlice{
 			ag_solanago.Meta(stakeAccount).WRITE(),
 			ag_solanago.Meta(clockSysvar),
-			ag_solanago.Meta(stakeOrWithdrawAuthority),
+			authority,
 		},
 		Signers: func(keys []ag_solanago.PublicKey) ag_solanago.AccountMetaSlice {
 			metas := make(ag_solanago.AccountMetaSlice, len(keys))
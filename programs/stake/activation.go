@@ -0,0 +1,366 @@
+// Copyright 2021 github.com/gagliardetto
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stake
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+
+	bin "github.com/gagliardetto/binary"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+)
+
+const (
+	// DefaultWarmupCooldownRate is the fraction of the cluster effective stake
+	// that can be activated or deactivated in an epoch.
+	DefaultWarmupCooldownRate = 0.25
+
+	// NewWarmupCooldownRate is the warmup/cooldown rate since
+	// the activation of the reduce_stake_warmup_cooldown feature.
+	NewWarmupCooldownRate = 0.09
+)
+
+// ReduceStakeWarmupCooldownFeatureID is the feature that lowers
+// the warmup/cooldown rate to NewWarmupCooldownRate.
+var ReduceStakeWarmupCooldownFeatureID = solana.MustPublicKeyFromBase58("GwtDQBghCTBgmX2cpEGNPxTEBUTQRaDMGTr5qychdGMj")
+
+// WarmupCooldownRate returns the warmup/cooldown rate of the epoch.
+// newRateActivationEpoch is the epoch of activation of the
+// reduce_stake_warmup_cooldown feature, nil if not activated.
+func WarmupCooldownRate(epoch uint64, newRateActivationEpoch *uint64) float64 {
+	if newRateActivationEpoch == nil || epoch < *newRateActivationEpoch {
+		return DefaultWarmupCooldownRate
+	}
+	return NewWarmupCooldownRate
+}
+
+// StakeHistoryEntry is the cluster-wide stake of an epoch.
+type StakeHistoryEntry struct {
+	// Effective stake at this epoch.
+	Effective uint64
+
+	// Sum of portion of activations at this epoch.
+	Activating uint64
+
+	// Sum of portion of deactivations at this epoch.
+	Deactivating uint64
+}
+
+// StakeHistoryEpochEntry is an entry of the StakeHistory sysvar.
+type StakeHistoryEpochEntry struct {
+	Epoch uint64
+	StakeHistoryEntry
+}
+
+// StakeHistory is the content of the StakeHistory sysvar:
+// the cluster-wide stake of the recent epochs, the latest first.
+type StakeHistory []StakeHistoryEpochEntry
+
+// DecodeStakeHistory decodes the data of the StakeHistory sysvar.
+func DecodeStakeHistory(data []byte) (StakeHistory, error) {
+	dec := bin.NewBinDecoder(data)
+	length, err := dec.ReadUint64(bin.LE)
+	if err != nil {
+		return nil, fmt.Errorf("unable to decode stake history: %w", err)
+	}
+	if length > uint64(dec.Remaining()/32) {
+		return nil, fmt.Errorf("unable to decode stake history: %d entries in %d bytes", length, dec.Remaining())
+	}
+	history := make(StakeHistory, length)
+	for i := range history {
+		var values [4]uint64
+		for j := range values {
+			if values[j], err = dec.ReadUint64(bin.LE); err != nil {
+				return nil, fmt.Errorf("unable to decode stake history: %w", err)
+			}
+		}
+		history[i] = StakeHistoryEpochEntry{
+			Epoch: values[0],
+			StakeHistoryEntry: StakeHistoryEntry{
+				Effective:    values[1],
+				Activating:   values[2],
+				Deactivating: values[3],
+			},
+		}
+	}
+	return history, nil
+}
+
+// FetchStakeHistory fetches and decodes the StakeHistory sysvar.
+func FetchStakeHistory(ctx context.Context, rpcCli *rpc.Client, commitment rpc.CommitmentType) (StakeHistory, error) {
+	out, err := rpcCli.GetAccountInfoWithOpts(ctx, solana.SysVarStakeHistoryPubkey, &rpc.GetAccountInfoOpts{
+		Encoding:   solana.EncodingBase64,
+		Commitment: commitment,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return DecodeStakeHistory(out.Value.Data.GetBinary())
+}
+
+// Get returns the entry of the epoch, if it is in the history.
+func (history StakeHistory) Get(epoch uint64) (StakeHistoryEntry, bool) {
+	i := sort.Search(len(history), func(i int) bool { return history[i].Epoch <= epoch })
+	if i < len(history) && history[i].Epoch == epoch {
+		return history[i].StakeHistoryEntry, true
+	}
+	return StakeHistoryEntry{}, false
+}
+
+// FetchNewWarmupCooldownRateEpoch returns the epoch of activation
+// of the reduce_stake_warmup_cooldown feature, nil if it is not activated.
+func FetchNewWarmupCooldownRateEpoch(ctx context.Context, rpcCli *rpc.Client, commitment rpc.CommitmentType) (*uint64, error) {
+	out, err := rpcCli.GetAccountInfoWithOpts(ctx, ReduceStakeWarmupCooldownFeatureID, &rpc.GetAccountInfoOpts{
+		Encoding:   solana.EncodingBase64,
+		Commitment: commitment,
+	})
+	if errors.Is(err, rpc.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	// The feature account holds the Option<u64> slot of activation.
+	dec := bin.NewBinDecoder(out.Value.Data.GetBinary())
+	isSome, err := dec.ReadBool()
+	if err != nil {
+		return nil, fmt.Errorf("unable to decode feature: %w", err)
+	}
+	if !isSome {
+		return nil, nil
+	}
+	activatedAt, err := dec.ReadUint64(bin.LE)
+	if err != nil {
+		return nil, fmt.Errorf("unable to decode feature: %w", err)
+	}
+	schedule, err := rpcCli.GetEpochSchedule(ctx)
+	if err != nil {
+		return nil, err
+	}
+	epoch := schedule.EpochSchedule().Epoch(activatedAt)
+	return &epoch, nil
+}
+
+// StakeActivationStatus is the activation status of a delegation at an epoch.
+type StakeActivationStatus struct {
+	// Stake that counts for the rewards and the voting power; for deactivating stake,
+	// the part of the stake that is still effective.
+	Effective uint64
+
+	// Stake that is not yet effective.
+	Activating uint64
+
+	// Stake that is being deactivated.
+	Deactivating uint64
+}
+
+// IsBootstrap returns whether the delegation is a stake of the genesis,
+// effective immediately.
+func (delegation *Delegation) IsBootstrap() bool {
+	return delegation.ActivationEpoch == math.MaxUint64
+}
+
+// StakeActivatingAndDeactivating computes the activation status of the delegation
+// at targetEpoch, like the runtime, from the stake history of the cluster.
+// newRateActivationEpoch is the epoch of activation of the
+// reduce_stake_warmup_cooldown feature, nil if not activated
+// (see FetchNewWarmupCooldownRateEpoch).
+func (delegation *Delegation) StakeActivatingAndDeactivating(
+	targetEpoch uint64,
+	history StakeHistory,
+	newRateActivationEpoch *uint64,
+) StakeActivationStatus {
+	// First, calculate an effective and activating stake.
+	effectiveStake, activatingStake := delegation.stakeAndActivating(targetEpoch, history, newRateActivationEpoch)
+
+	// Then de-activate some portion if necessary.
+	switch {
+	case targetEpoch < delegation.DeactivationEpoch:
+		// Not deactivated.
+		return StakeActivationStatus{Effective: effectiveStake, Activating: activatingStake}
+	case targetEpoch == delegation.DeactivationEpoch:
+		// Can only deactivate what's activated.
+		return StakeActivationStatus{Effective: effectiveStake, Deactivating: effectiveStake}
+	}
+
+	prevEpoch := delegation.DeactivationEpoch
+	prevClusterStake, ok := history.Get(prevEpoch)
+	if !ok {
+		// No history, or dropped out of history: assume fully deactivated.
+		return StakeActivationStatus{}
+	}
+	// Loop from the deactivation epoch until the target epoch;
+	// the effective stake is updated using the cluster stake of the previous epoch.
+	currentEffectiveStake := effectiveStake
+	for {
+		currentEpoch := prevEpoch + 1
+		// No deactivating stake at the previous epoch: fully undelegated.
+		if prevClusterStake.Deactivating == 0 {
+			break
+		}
+
+		// The share of the deactivation in the cluster this stake is entitled to.
+		weight := float64(currentEffectiveStake) / float64(prevClusterStake.Deactivating)
+		rate := WarmupCooldownRate(currentEpoch, newRateActivationEpoch)
+		newlyNotEffectiveClusterStake := float64(prevClusterStake.Effective) * rate
+		newlyNotEffectiveStake := max64(float64ToUint64(weight*newlyNotEffectiveClusterStake), 1)
+
+		currentEffectiveStake = saturatingSub(currentEffectiveStake, newlyNotEffectiveStake)
+		if currentEffectiveStake == 0 || currentEpoch >= targetEpoch {
+			break
+		}
+		currentClusterStake, ok := history.Get(currentEpoch)
+		if !ok {
+			break
+		}
+		prevEpoch = currentEpoch
+		prevClusterStake = currentClusterStake
+	}
+	// The deactivating stake is all the remaining effective stake.
+	return StakeActivationStatus{Effective: currentEffectiveStake, Deactivating: currentEffectiveStake}
+}
+
+// stakeAndActivating returns the effective and activating stake.
+func (delegation *Delegation) stakeAndActivating(
+	targetEpoch uint64,
+	history StakeHistory,
+	newRateActivationEpoch *uint64,
+) (uint64, uint64) {
+	delegatedStake := delegation.Stake
+	switch {
+	case delegation.IsBootstrap():
+		// Fully effective immediately.
+		return delegatedStake, 0
+	case delegation.ActivationEpoch == delegation.DeactivationEpoch:
+		// Activated but instantly deactivated: no stake at all, regardless of targetEpoch.
+		return 0, 0
+	case targetEpoch == delegation.ActivationEpoch:
+		// All is activating.
+		return 0, delegatedStake
+	case targetEpoch < delegation.ActivationEpoch:
+		// Not yet enabled.
+		return 0, 0
+	}
+
+	prevEpoch := delegation.ActivationEpoch
+	prevClusterStake, ok := history.Get(prevEpoch)
+	if !ok {
+		// No history, or dropped out of history: assume fully effective.
+		return delegatedStake, 0
+	}
+	// Loop from the activation epoch until the target epoch, summing up the entitlement;
+	// the effective stake is updated using the cluster stake of the previous epoch.
+	var currentEffectiveStake uint64
+	for {
+		currentEpoch := prevEpoch + 1
+		// No activating stake at the previous epoch: fully effective.
+		if prevClusterStake.Activating == 0 {
+			break
+		}
+
+		// The share of the growth in stake this stake is entitled to.
+		remainingActivatingStake := delegatedStake - currentEffectiveStake
+		weight := float64(remainingActivatingStake) / float64(prevClusterStake.Activating)
+		rate := WarmupCooldownRate(currentEpoch, newRateActivationEpoch)
+		newlyEffectiveClusterStake := float64(prevClusterStake.Effective) * rate
+		newlyEffectiveStake := max64(float64ToUint64(weight*newlyEffectiveClusterStake), 1)
+
+		currentEffectiveStake += newlyEffectiveStake
+		if currentEffectiveStake >= delegatedStake {
+			currentEffectiveStake = delegatedStake
+			break
+		}
+		if currentEpoch >= targetEpoch || currentEpoch >= delegation.DeactivationEpoch {
+			break
+		}
+		currentClusterStake, ok := history.Get(currentEpoch)
+		if !ok {
+			break
+		}
+		prevEpoch = currentEpoch
+		prevClusterStake = currentClusterStake
+	}
+	return currentEffectiveStake, delegatedStake - currentEffectiveStake
+}
+
+// Activation computes the activation of the stake account at the epoch,
+// as reported by the deprecated getStakeActivation RPC method.
+func (account *StakeAccount) Activation(
+	epoch uint64,
+	history StakeHistory,
+	newRateActivationEpoch *uint64,
+) (*rpc.GetStakeActivationResult, error) {
+	state := account.State
+	switch state.Type {
+	case StakeStateV2Initialized:
+		return &rpc.GetStakeActivationResult{
+			State:    rpc.ActivationStateInactive,
+			Inactive: saturatingSub(account.Lamports, state.Meta.RentExemptReserve),
+		}, nil
+	case StakeStateV2Stake:
+	default:
+		return nil, fmt.Errorf("stake account %s is not initialized: %s", account.Pubkey, state.Type)
+	}
+
+	status := state.Stake.Delegation.StakeActivatingAndDeactivating(epoch, history, newRateActivationEpoch)
+	out := &rpc.GetStakeActivationResult{Active: status.Effective}
+	// Like the node, the inactive stake only counts the lamports beyond the
+	// delegation while deactivating or inactive.
+	switch {
+	case status.Deactivating > 0:
+		out.State = rpc.ActivationStateDeactivating
+		out.Inactive = saturatingSub(saturatingSub(account.Lamports, status.Effective), state.Meta.RentExemptReserve)
+	case status.Activating > 0:
+		out.State = rpc.ActivationStateActivating
+		out.Inactive = status.Activating
+	case status.Effective > 0:
+		out.State = rpc.ActivationStateActive
+	default:
+		out.State = rpc.ActivationStateInactive
+		out.Inactive = saturatingSub(account.Lamports, state.Meta.RentExemptReserve)
+	}
+	return out, nil
+}
+
+// float64ToUint64 converts like the `as u64` cast of Rust, which saturates.
+func float64ToUint64(v float64) uint64 {
+	switch {
+	case math.IsNaN(v) || v <= 0:
+		return 0
+	case v >= math.MaxUint64:
+		return math.MaxUint64
+	default:
+		return uint64(v)
+	}
+}
+
+func saturatingSub(a, b uint64) uint64 {
+	if a < b {
+		return 0
+	}
+	return a - b
+}
+
+func max64(a, b uint64) uint64 {
+	if a > b {
+		return a
+	}
+	return b
+}
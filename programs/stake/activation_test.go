@@ -0,0 +1,139 @@
+// Copyright 2021 github.com/gagliardetto
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stake
+
+import (
+	"encoding/binary"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+)
+
+// Same checks as test_stake_activating_and_deactivating of the stake program.
+func TestDelegation_StakeActivatingAndDeactivating(t *testing.T) {
+	delegation := &Delegation{Stake: 1_000, ActivationEpoch: 0, DeactivationEpoch: 5}
+	increment := uint64(1_000 * DefaultWarmupCooldownRate)
+
+	// Step function without history.
+	var history StakeHistory
+	assert.Equal(t, StakeActivationStatus{Activating: 1_000}, delegation.StakeActivatingAndDeactivating(0, history, nil))
+	for epoch := uint64(1); epoch < 5; epoch++ {
+		assert.Equal(t, StakeActivationStatus{Effective: 1_000}, delegation.StakeActivatingAndDeactivating(epoch, history, nil))
+	}
+	assert.Equal(t, StakeActivationStatus{Effective: 1_000, Deactivating: 1_000}, delegation.StakeActivatingAndDeactivating(5, history, nil))
+	assert.Equal(t, StakeActivationStatus{}, delegation.StakeActivatingAndDeactivating(6, history, nil))
+
+	// No activating stake in the history: nothing becomes effective.
+	history = StakeHistory{{Epoch: 0, StakeHistoryEntry: StakeHistoryEntry{Effective: 1_000}}}
+	assert.Equal(t, StakeActivationStatus{Activating: 1_000}, delegation.StakeActivatingAndDeactivating(1, history, nil))
+
+	history = StakeHistory{{Epoch: 0, StakeHistoryEntry: StakeHistoryEntry{Effective: 1_000, Activating: 1_000}}}
+	assert.Equal(t,
+		StakeActivationStatus{Effective: increment, Activating: 1_000 - increment},
+		delegation.StakeActivatingAndDeactivating(2, history, nil),
+	)
+
+	// With the new rate.
+	newRateEpoch := uint64(1)
+	assert.Equal(t,
+		StakeActivationStatus{Effective: 90, Activating: 910},
+		delegation.StakeActivatingAndDeactivating(2, history, &newRateEpoch),
+	)
+}
+
+func TestDelegation_Deactivating(t *testing.T) {
+	delegation := &Delegation{Stake: 1_000, ActivationEpoch: math.MaxUint64, DeactivationEpoch: 5}
+	assert.True(t, delegation.IsBootstrap())
+	history := StakeHistory{
+		{Epoch: 6, StakeHistoryEntry: StakeHistoryEntry{Effective: 750, Deactivating: 750}},
+		{Epoch: 5, StakeHistoryEntry: StakeHistoryEntry{Effective: 1_000, Deactivating: 1_000}},
+	}
+
+	assert.Equal(t, StakeActivationStatus{Effective: 1_000}, delegation.StakeActivatingAndDeactivating(4, history, nil))
+	assert.Equal(t, StakeActivationStatus{Effective: 750, Deactivating: 750}, delegation.StakeActivatingAndDeactivating(6, history, nil))
+	assert.Equal(t, StakeActivationStatus{Effective: 563, Deactivating: 563}, delegation.StakeActivatingAndDeactivating(7, history, nil))
+	// Out of the history: the cooldown stops with the known epochs.
+	assert.Equal(t, StakeActivationStatus{Effective: 563, Deactivating: 563}, delegation.StakeActivatingAndDeactivating(8, history, nil))
+
+	newRateEpoch := uint64(7)
+	assert.Equal(t, StakeActivationStatus{Effective: 683, Deactivating: 683}, delegation.StakeActivatingAndDeactivating(7, history, &newRateEpoch))
+
+	// Activated and deactivated at the same epoch: no stake.
+	instant := &Delegation{Stake: 1_000, ActivationEpoch: 3, DeactivationEpoch: 3}
+	assert.Equal(t, StakeActivationStatus{}, instant.StakeActivatingAndDeactivating(3, history, nil))
+}
+
+func TestDecodeStakeHistory(t *testing.T) {
+	data := make([]byte, 8+2*32)
+	binary.LittleEndian.PutUint64(data, 2)
+	for i, v := range []uint64{11, 100, 10, 1, 10, 90, 20, 2} {
+		binary.LittleEndian.PutUint64(data[8+8*i:], v)
+	}
+	history, err := DecodeStakeHistory(data)
+	require.NoError(t, err)
+	assert.Equal(t, StakeHistory{
+		{Epoch: 11, StakeHistoryEntry: StakeHistoryEntry{Effective: 100, Activating: 10, Deactivating: 1}},
+		{Epoch: 10, StakeHistoryEntry: StakeHistoryEntry{Effective: 90, Activating: 20, Deactivating: 2}},
+	}, history)
+
+	entry, ok := history.Get(10)
+	require.True(t, ok)
+	assert.Equal(t, uint64(90), entry.Effective)
+	_, ok = history.Get(12)
+	assert.False(t, ok)
+	_, ok = history.Get(9)
+	assert.False(t, ok)
+
+	_, err = DecodeStakeHistory(data[:40])
+	assert.Error(t, err)
+}
+
+func TestStakeAccount_Activation(t *testing.T) {
+	state := newTestStakeState(solana.PublicKey{}, solana.PublicKey{}, solana.PublicKey{})
+	state.Stake.Delegation = Delegation{Stake: 1_000, ActivationEpoch: 0, DeactivationEpoch: math.MaxUint64}
+	state.Meta.RentExemptReserve = 100
+	account := &StakeAccount{Lamports: 1_200, State: state}
+	history := StakeHistory{{Epoch: 0, StakeHistoryEntry: StakeHistoryEntry{Effective: 1_000, Activating: 1_000}}}
+
+	got, err := account.Activation(1, history, nil)
+	require.NoError(t, err)
+	assert.Equal(t, &rpc.GetStakeActivationResult{State: rpc.ActivationStateActivating, Active: 250, Inactive: 750}, got)
+
+	got, err = account.Activation(1, nil, nil)
+	require.NoError(t, err)
+	assert.Equal(t, &rpc.GetStakeActivationResult{State: rpc.ActivationStateActive, Active: 1_000}, got)
+
+	// The extra lamports only count as inactive while deactivating.
+	state.Stake.Delegation.DeactivationEpoch = 1
+	deactivatingHistory := StakeHistory{{Epoch: 1, StakeHistoryEntry: StakeHistoryEntry{Effective: 1_000, Deactivating: 1_000}}}
+	got, err = account.Activation(2, deactivatingHistory, nil)
+	require.NoError(t, err)
+	assert.Equal(t, &rpc.GetStakeActivationResult{State: rpc.ActivationStateDeactivating, Active: 750, Inactive: 350}, got)
+	state.Stake.Delegation.DeactivationEpoch = math.MaxUint64
+
+	account.State = &StakeStateV2{Type: StakeStateV2Initialized, Meta: state.Meta}
+	got, err = account.Activation(1, history, nil)
+	require.NoError(t, err)
+	assert.Equal(t, &rpc.GetStakeActivationResult{State: rpc.ActivationStateInactive, Inactive: 1_100}, got)
+
+	account.State = &StakeStateV2{Type: StakeStateV2Uninitialized}
+	_, err = account.Activation(1, history, nil)
+	assert.Error(t, err)
+}
@@ -0,0 +1,48 @@
+// Copyright 2021 github.com/gagliardetto
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stake
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gagliardetto/solana-go"
+)
+
+func TestNewAuthorizeInstruction(t *testing.T) {
+	stakeAccount := solana.NewWallet().PublicKey()
+	authority := solana.NewWallet().PublicKey()
+	newAuthority := solana.NewWallet().PublicKey()
+
+	t.Run("single authority signs", func(t *testing.T) {
+		inst := NewAuthorizeInstruction(newAuthority, StakeAuthorizeStaker, stakeAccount, solana.SysVarClockPubkey, authority, nil).Build()
+		accounts := inst.Accounts()
+		require.Len(t, accounts, 3)
+		assert.Equal(t, authority, accounts[2].PublicKey)
+		assert.True(t, accounts[2].IsSigner)
+	})
+
+	t.Run("multisig signers sign instead", func(t *testing.T) {
+		signers := []solana.PublicKey{solana.NewWallet().PublicKey(), solana.NewWallet().PublicKey()}
+		inst := NewAuthorizeInstruction(newAuthority, StakeAuthorizeWithdrawer, stakeAccount, solana.SysVarClockPubkey, authority, signers).Build()
+		accounts := inst.Accounts()
+		require.Len(t, accounts, 5)
+		assert.False(t, accounts[2].IsSigner)
+		assert.True(t, accounts[3].IsSigner)
+		assert.True(t, accounts[4].IsSigner)
+	})
+}
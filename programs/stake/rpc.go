@@ -0,0 +1,140 @@
+// Copyright 2021 github.com/gagliardetto
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stake
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+)
+
+// maxMultipleAccounts is the maximum number of accounts of a getMultipleAccounts request.
+const maxMultipleAccounts = 100
+
+// StakeAccount is a stake account, with its decoded state.
+type StakeAccount struct {
+	Pubkey   solana.PublicKey
+	Lamports uint64
+	State    *StakeStateV2
+}
+
+func newStakeAccount(pubkey solana.PublicKey, account *rpc.Account) (*StakeAccount, error) {
+	if !account.Owner.Equals(ProgramID) {
+		return nil, fmt.Errorf("account %s is not a stake account: owned by %s", pubkey, account.Owner)
+	}
+	state, err := DecodeStakeStateV2(account.Data.GetBinary())
+	if err != nil {
+		return nil, fmt.Errorf("account %s: %w", pubkey, err)
+	}
+	return &StakeAccount{
+		Pubkey:   pubkey,
+		Lamports: account.Lamports,
+		State:    state,
+	}, nil
+}
+
+// FetchStakeAccount fetches and decodes a stake account.
+func FetchStakeAccount(ctx context.Context, rpcCli *rpc.Client, pubkey solana.PublicKey, commitment rpc.CommitmentType) (*StakeAccount, error) {
+	out, err := rpcCli.GetAccountInfoWithOpts(ctx, pubkey, &rpc.GetAccountInfoOpts{
+		Encoding:   solana.EncodingBase64,
+		Commitment: commitment,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return newStakeAccount(pubkey, out.Value)
+}
+
+// FetchStakeAccounts fetches and decodes stake accounts, in the order of the pubkeys;
+// the accounts that don't exist are nil.
+func FetchStakeAccounts(ctx context.Context, rpcCli *rpc.Client, pubkeys []solana.PublicKey, commitment rpc.CommitmentType) ([]*StakeAccount, error) {
+	out := make([]*StakeAccount, 0, len(pubkeys))
+	for start := 0; start < len(pubkeys); start += maxMultipleAccounts {
+		end := start + maxMultipleAccounts
+		if end > len(pubkeys) {
+			end = len(pubkeys)
+		}
+		resp, err := rpcCli.GetMultipleAccountsWithOpts(ctx, pubkeys[start:end], &rpc.GetMultipleAccountsOpts{
+			Encoding:   solana.EncodingBase64,
+			Commitment: commitment,
+		})
+		if err != nil {
+			return nil, err
+		}
+		if len(resp.Value) != end-start {
+			return nil, fmt.Errorf("expected %d accounts, got %d", end-start, len(resp.Value))
+		}
+		for i, account := range resp.Value {
+			if account == nil {
+				out = append(out, nil)
+				continue
+			}
+			stakeAccount, err := newStakeAccount(pubkeys[start+i], account)
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, stakeAccount)
+		}
+	}
+	return out, nil
+}
+
+// StakerFilter matches the stake accounts with the provided staker authority.
+func StakerFilter(staker solana.PublicKey) rpc.RPCFilter {
+	return memcmpFilter(StakerOffset, staker)
+}
+
+// WithdrawerFilter matches the stake accounts with the provided withdrawer authority.
+func WithdrawerFilter(withdrawer solana.PublicKey) rpc.RPCFilter {
+	return memcmpFilter(WithdrawerOffset, withdrawer)
+}
+
+// VoteAccountFilter matches the stake accounts delegated to the provided vote account.
+func VoteAccountFilter(voteAccount solana.PublicKey) rpc.RPCFilter {
+	return memcmpFilter(VoterPubkeyOffset, voteAccount)
+}
+
+func memcmpFilter(offset uint64, pubkey solana.PublicKey) rpc.RPCFilter {
+	return rpc.RPCFilter{
+		Memcmp: &rpc.RPCFilterMemcmp{
+			Offset: offset,
+			Bytes:  pubkey[:],
+		},
+	}
+}
+
+// FindStakeAccounts fetches and decodes the stake accounts matching all the filters
+// (e.g. StakerFilter, WithdrawerFilter and VoteAccountFilter).
+func FindStakeAccounts(ctx context.Context, rpcCli *rpc.Client, commitment rpc.CommitmentType, filters ...rpc.RPCFilter) ([]*StakeAccount, error) {
+	resp, err := rpcCli.GetProgramAccountsWithOpts(ctx, ProgramID, &rpc.GetProgramAccountsOpts{
+		Encoding:   solana.EncodingBase64,
+		Commitment: commitment,
+		Filters:    append([]rpc.RPCFilter{{DataSize: StakeStateV2Size}}, filters...),
+	})
+	if err != nil {
+		return nil, err
+	}
+	out := make([]*StakeAccount, 0, len(resp))
+	for _, keyedAccount := range resp {
+		stakeAccount, err := newStakeAccount(keyedAccount.Pubkey, keyedAccount.Account)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, stakeAccount)
+	}
+	return out, nil
+}
@@ -0,0 +1,234 @@
+// Copyright 2021 github.com/gagliardetto
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stake
+
+import (
+	"fmt"
+
+	bin "github.com/gagliardetto/binary"
+
+	"github.com/gagliardetto/solana-go"
+)
+
+// StakeStateV2Size is the size of the data of stake accounts.
+const StakeStateV2Size = 200
+
+// Offsets of the fields of the stake state, to filter stake accounts.
+const (
+	StakerOffset      = 12
+	WithdrawerOffset  = 44
+	VoterPubkeyOffset = 124
+)
+
+type StakeStateV2Type uint32
+
+const (
+	StakeStateV2Uninitialized StakeStateV2Type = iota
+	StakeStateV2Initialized
+	StakeStateV2Stake
+	StakeStateV2RewardsPool
+)
+
+func (t StakeStateV2Type) String() string {
+	switch t {
+	case StakeStateV2Uninitialized:
+		return "Uninitialized"
+	case StakeStateV2Initialized:
+		return "Initialized"
+	case StakeStateV2Stake:
+		return "Stake"
+	case StakeStateV2RewardsPool:
+		return "RewardsPool"
+	default:
+		return fmt.Sprintf("StakeStateV2Type(%d)", uint32(t))
+	}
+}
+
+// StakeFlags are the flags of a delegated stake account.
+type StakeFlags uint8
+
+const (
+	// Set by MoveStake and MoveLamports (formerly by Redelegate):
+	// the stake must be fully activated before it can be deactivated.
+	StakeFlagsMustFullyActivateBeforeDeactivationIsPermitted StakeFlags = 1
+)
+
+// Meta holds the authorities of a stake account, and its lockup.
+type Meta struct {
+	RentExemptReserve uint64
+	Authorized        Authorized
+	Lockup            Lockup
+}
+
+func (meta *Meta) UnmarshalWithDecoder(dec *bin.Decoder) (err error) {
+	if meta.RentExemptReserve, err = dec.ReadUint64(bin.LE); err != nil {
+		return err
+	}
+	if err = meta.Authorized.UnmarshalWithDecoder(dec); err != nil {
+		return err
+	}
+	return meta.Lockup.UnmarshalWithDecoder(dec)
+}
+
+func (meta Meta) MarshalWithEncoder(enc *bin.Encoder) error {
+	if err := enc.WriteUint64(meta.RentExemptReserve, bin.LE); err != nil {
+		return err
+	}
+	if err := meta.Authorized.MarshalWithEncoder(enc); err != nil {
+		return err
+	}
+	return meta.Lockup.MarshalWithEncoder(enc)
+}
+
+// Delegation is the delegation of a stake account to a vote account.
+type Delegation struct {
+	// The vote account the stake is delegated to.
+	VoterPubkey solana.PublicKey
+
+	// Activated stake amount, set at delegate() time.
+	Stake uint64
+
+	// Epoch at which this stake was activated, math.MaxUint64 if is a bootstrap stake.
+	ActivationEpoch uint64
+
+	// Epoch the stake was deactivated, math.MaxUint64 if not deactivated.
+	DeactivationEpoch uint64
+
+	// DEPRECATED: the warmup/cooldown rate is now a cluster-wide value.
+	WarmupCooldownRate float64
+}
+
+// Stake is the delegation of a stake account, and the vote credits
+// it has been rewarded for.
+type Stake struct {
+	Delegation Delegation
+
+	// Credits observed is credits from vote account state when delegated or redeemed.
+	CreditsObserved uint64
+}
+
+func (stake *Stake) UnmarshalWithDecoder(dec *bin.Decoder) (err error) {
+	delegation := &stake.Delegation
+	if err = dec.Decode(&delegation.VoterPubkey); err != nil {
+		return err
+	}
+	if delegation.Stake, err = dec.ReadUint64(bin.LE); err != nil {
+		return err
+	}
+	if delegation.ActivationEpoch, err = dec.ReadUint64(bin.LE); err != nil {
+		return err
+	}
+	if delegation.DeactivationEpoch, err = dec.ReadUint64(bin.LE); err != nil {
+		return err
+	}
+	if delegation.WarmupCooldownRate, err = dec.ReadFloat64(bin.LE); err != nil {
+		return err
+	}
+	stake.CreditsObserved, err = dec.ReadUint64(bin.LE)
+	return err
+}
+
+func (stake Stake) MarshalWithEncoder(enc *bin.Encoder) error {
+	delegation := stake.Delegation
+	if err := enc.WriteBytes(delegation.VoterPubkey[:], false); err != nil {
+		return err
+	}
+	for _, v := range []uint64{delegation.Stake, delegation.ActivationEpoch, delegation.DeactivationEpoch} {
+		if err := enc.WriteUint64(v, bin.LE); err != nil {
+			return err
+		}
+	}
+	if err := enc.WriteFloat64(delegation.WarmupCooldownRate, bin.LE); err != nil {
+		return err
+	}
+	return enc.WriteUint64(stake.CreditsObserved, bin.LE)
+}
+
+// StakeStateV2 is the state of a stake account.
+type StakeStateV2 struct {
+	Type StakeStateV2Type
+
+	// Set for the Initialized and Stake states.
+	Meta *Meta
+
+	// Set for the Stake state.
+	Stake *Stake
+	Flags StakeFlags
+}
+
+// DecodeStakeStateV2 decodes the data of a stake account.
+func DecodeStakeStateV2(data []byte) (*StakeStateV2, error) {
+	state := new(StakeStateV2)
+	if err := bin.NewBinDecoder(data).Decode(state); err != nil {
+		return nil, fmt.Errorf("unable to decode stake state: %w", err)
+	}
+	return state, nil
+}
+
+func (state *StakeStateV2) UnmarshalWithDecoder(dec *bin.Decoder) error {
+	typ, err := dec.ReadUint32(bin.LE)
+	if err != nil {
+		return err
+	}
+	*state = StakeStateV2{Type: StakeStateV2Type(typ)}
+	switch state.Type {
+	case StakeStateV2Uninitialized, StakeStateV2RewardsPool:
+		return nil
+	case StakeStateV2Initialized, StakeStateV2Stake:
+		state.Meta = new(Meta)
+		if err := state.Meta.UnmarshalWithDecoder(dec); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("unknown stake state type %d", typ)
+	}
+	if state.Type == StakeStateV2Stake {
+		state.Stake = new(Stake)
+		if err := state.Stake.UnmarshalWithDecoder(dec); err != nil {
+			return err
+		}
+		flags, err := dec.ReadUint8()
+		if err != nil {
+			return err
+		}
+		state.Flags = StakeFlags(flags)
+	}
+	return nil
+}
+
+func (state StakeStateV2) MarshalWithEncoder(enc *bin.Encoder) error {
+	if err := enc.WriteUint32(uint32(state.Type), bin.LE); err != nil {
+		return err
+	}
+	if state.Type != StakeStateV2Initialized && state.Type != StakeStateV2Stake {
+		return nil
+	}
+	if state.Meta == nil {
+		return fmt.Errorf("meta is not set for the %s state", state.Type)
+	}
+	if err := state.Meta.MarshalWithEncoder(enc); err != nil {
+		return err
+	}
+	if state.Type == StakeStateV2Initialized {
+		return nil
+	}
+	if state.Stake == nil {
+		return fmt.Errorf("stake is not set for the %s state", state.Type)
+	}
+	if err := state.Stake.MarshalWithEncoder(enc); err != nil {
+		return err
+	}
+	return enc.WriteUint8(uint8(state.Flags))
+}
@@ -0,0 +1,197 @@
+// Copyright 2021 github.com/gagliardetto
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stake
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"math"
+	"testing"
+
+	bin "github.com/gagliardetto/binary"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+	"github.com/gagliardetto/solana-go/rpc/rpctest"
+)
+
+func newTestStakeState(staker, withdrawer, voter solana.PublicKey) *StakeStateV2 {
+	return &StakeStateV2{
+		Type: StakeStateV2Stake,
+		Meta: &Meta{
+			RentExemptReserve: 2282880,
+			Authorized:        Authorized{Staker: staker.ToPointer(), Withdrawer: withdrawer.ToPointer()},
+			Lockup:            *NewLockup().SetUnixTimestamp(0).SetEpoch(0).SetCustodian(solana.PublicKey{}),
+		},
+		Stake: &Stake{
+			Delegation: Delegation{
+				VoterPubkey:        voter,
+				Stake:              1_000_000_000,
+				ActivationEpoch:    500,
+				DeactivationEpoch:  math.MaxUint64,
+				WarmupCooldownRate: 0.25,
+			},
+			CreditsObserved: 12345,
+		},
+		Flags: StakeFlagsMustFullyActivateBeforeDeactivationIsPermitted,
+	}
+}
+
+func encodeStakeState(t *testing.T, state *StakeStateV2) []byte {
+	buf := new(bytes.Buffer)
+	require.NoError(t, bin.NewBinEncoder(buf).Encode(state))
+	data := make([]byte, StakeStateV2Size)
+	copy(data, buf.Bytes())
+	return data
+}
+
+func TestStakeStateV2(t *testing.T) {
+	staker := solana.NewWallet().PublicKey()
+	withdrawer := solana.NewWallet().PublicKey()
+	voter := solana.NewWallet().PublicKey()
+	state := newTestStakeState(staker, withdrawer, voter)
+
+	data := encodeStakeState(t, state)
+	assert.Equal(t, []byte{2, 0, 0, 0}, data[:4])
+	assert.Equal(t, staker[:], data[StakerOffset:StakerOffset+32])
+	assert.Equal(t, withdrawer[:], data[WithdrawerOffset:WithdrawerOffset+32])
+	assert.Equal(t, voter[:], data[VoterPubkeyOffset:VoterPubkeyOffset+32])
+	assert.Equal(t, byte(1), data[196])
+
+	got, err := DecodeStakeStateV2(data)
+	require.NoError(t, err)
+	assert.Equal(t, state, got)
+
+	initialized := &StakeStateV2{Type: StakeStateV2Initialized, Meta: state.Meta}
+	got, err = DecodeStakeStateV2(encodeStakeState(t, initialized))
+	require.NoError(t, err)
+	assert.Equal(t, initialized, got)
+
+	got, err = DecodeStakeStateV2(make([]byte, StakeStateV2Size))
+	require.NoError(t, err)
+	assert.Equal(t, &StakeStateV2{Type: StakeStateV2Uninitialized}, got)
+
+	_, err = DecodeStakeStateV2([]byte{4, 0, 0, 0})
+	assert.Error(t, err)
+}
+
+// delegatedStakeAccountHex is the data of a delegated stake account, laid out
+// field by field as the stake program stores it, independently of the encoder.
+const delegatedStakeAccountHex = "" +
+	"02000000" + // StakeStateV2::Stake
+	"80d5220000000000" + // rent_exempt_reserve: 2282880
+	"05ea9cf16ce41198f1a49937c88c370a94d4afff89b5bacb8ef45e6324bb78f7" + // staker
+	"7e8c088760bfde1dddcf32c17f209b8242ee52aaf131facd88d0ea2c6d0b06f2" + // withdrawer
+	"00f1536500000000" + // lockup.unix_timestamp: 1700000000
+	"f401000000000000" + // lockup.epoch: 500
+	"7e8c088760bfde1dddcf32c17f209b8242ee52aaf131facd88d0ea2c6d0b06f2" + // lockup.custodian
+	"ad23766daa4f30957a4e90cdf8268f61f81f0f00e839c9ad6efa009f44ec9fa6" + // delegation.voter_pubkey
+	"9d23e52c3f000000" + // delegation.stake: 271336154013
+	"8201000000000000" + // delegation.activation_epoch: 386
+	"ffffffffffffffff" + // delegation.deactivation_epoch: u64::MAX
+	"000000000000d03f" + // delegation.warmup_cooldown_rate: 0.25
+	"9178210a00000000" + // credits_observed: 169965713
+	"00" + // stake_flags
+	"000000" // padding to 200 bytes
+
+func TestDecodeStakeStateV2_KnownAnswer(t *testing.T) {
+	data, err := hex.DecodeString(delegatedStakeAccountHex)
+	require.NoError(t, err)
+	require.Len(t, data, StakeStateV2Size)
+
+	got, err := DecodeStakeStateV2(data)
+	require.NoError(t, err)
+	require.Equal(t, StakeStateV2Stake, got.Type)
+	require.NotNil(t, got.Meta)
+	require.NotNil(t, got.Stake)
+
+	withdrawer := solana.MustPublicKeyFromBase58("9WzDXwBbmkg8ZTbNMqUxvQRAyrZzDsGYdLVL9zYtAWWM")
+	assert.Equal(t, uint64(2282880), got.Meta.RentExemptReserve)
+	assert.Equal(t, solana.MustPublicKeyFromBase58("Q6XprfkF8RQQKoQVG33xT88H7wi8Uk1B1CC7YAs69Gi"), *got.Meta.Authorized.Staker)
+	assert.Equal(t, withdrawer, *got.Meta.Authorized.Withdrawer)
+	assert.Equal(t, int64(1700000000), *got.Meta.Lockup.UnixTimestamp)
+	assert.Equal(t, uint64(500), *got.Meta.Lockup.Epoch)
+	assert.Equal(t, withdrawer, *got.Meta.Lockup.Custodian)
+
+	delegation := got.Stake.Delegation
+	assert.Equal(t, solana.MustPublicKeyFromBase58("CertusDeBmqN8ZawdkxK5kFGMwBXdudvWHYwtNgNhvLu"), delegation.VoterPubkey)
+	assert.Equal(t, uint64(271336154013), delegation.Stake)
+	assert.Equal(t, uint64(386), delegation.ActivationEpoch)
+	assert.Equal(t, uint64(math.MaxUint64), delegation.DeactivationEpoch)
+	assert.Equal(t, 0.25, delegation.WarmupCooldownRate)
+	assert.Equal(t, uint64(169965713), got.Stake.CreditsObserved)
+	assert.Equal(t, StakeFlags(0), got.Flags)
+
+	// The offsets used by the getProgramAccounts filters point at the same bytes.
+	assert.Equal(t, withdrawer[:], data[WithdrawerOffset:WithdrawerOffset+32])
+	assert.Equal(t, delegation.VoterPubkey[:], data[VoterPubkeyOffset:VoterPubkeyOffset+32])
+	assert.Equal(t, data, encodeStakeState(t, got))
+}
+
+func TestFetchStakeAccounts(t *testing.T) {
+	srv := rpctest.NewServer()
+	defer srv.Close()
+	client := rpc.New(srv.URL)
+	ctx := context.Background()
+
+	staker := solana.NewWallet().PublicKey()
+	withdrawer := solana.NewWallet().PublicKey()
+	voters := []solana.PublicKey{solana.NewWallet().PublicKey(), solana.NewWallet().PublicKey()}
+	pubkeys := []solana.PublicKey{solana.NewWallet().PublicKey(), solana.NewWallet().PublicKey()}
+	for i, pubkey := range pubkeys {
+		srv.SetAccount(pubkey, rpctest.Account{
+			Lamports: uint64(i + 1),
+			Owner:    ProgramID,
+			Data:     encodeStakeState(t, newTestStakeState(staker, withdrawer, voters[i])),
+		})
+	}
+	notStake := solana.NewWallet().PublicKey()
+	srv.SetAccount(notStake, rpctest.Account{Lamports: 1, Owner: solana.SystemProgramID})
+
+	got, err := FetchStakeAccount(ctx, client, pubkeys[0], rpc.CommitmentConfirmed)
+	require.NoError(t, err)
+	assert.Equal(t, pubkeys[0], got.Pubkey)
+	assert.Equal(t, uint64(1), got.Lamports)
+	assert.Equal(t, voters[0], got.State.Stake.Delegation.VoterPubkey)
+
+	_, err = FetchStakeAccount(ctx, client, notStake, rpc.CommitmentConfirmed)
+	assert.Error(t, err)
+	_, err = FetchStakeAccount(ctx, client, solana.NewWallet().PublicKey(), rpc.CommitmentConfirmed)
+	assert.ErrorIs(t, err, rpc.ErrNotFound)
+
+	missing := solana.NewWallet().PublicKey()
+	accounts, err := FetchStakeAccounts(ctx, client, []solana.PublicKey{pubkeys[1], missing, pubkeys[0]}, rpc.CommitmentConfirmed)
+	require.NoError(t, err)
+	require.Len(t, accounts, 3)
+	assert.Equal(t, pubkeys[1], accounts[0].Pubkey)
+	assert.Nil(t, accounts[1])
+	assert.Equal(t, pubkeys[0], accounts[2].Pubkey)
+
+	accounts, err = FindStakeAccounts(ctx, client, rpc.CommitmentConfirmed, StakerFilter(staker), VoteAccountFilter(voters[1]))
+	require.NoError(t, err)
+	require.Len(t, accounts, 1)
+	assert.Equal(t, pubkeys[1], accounts[0].Pubkey)
+
+	accounts, err = FindStakeAccounts(ctx, client, rpc.CommitmentConfirmed, WithdrawerFilter(withdrawer))
+	require.NoError(t, err)
+	assert.Len(t, accounts, 2)
+
+	accounts, err = FindStakeAccounts(ctx, client, rpc.CommitmentConfirmed, WithdrawerFilter(staker))
+	require.NoError(t, err)
+	assert.Empty(t, accounts)
+}
@@ -28,9 +28,8 @@ import (
 const MINT_SIZE = 82
 
 func (mint *Mint) Decode(data []byte) error {
-	mint = new(Mint)
 	dec := bin.NewBinDecoder(data)
-	if err := dec.Decode(&mint); err != nil {
+	if err := dec.Decode(mint); err != nil {
 		return fmt.Errorf("unable to decode mint: %w", err)
 	}
 	return nil
@@ -60,7 +59,7 @@ func FetchMints(ctx context.Context, rpcCli *rpc.Client) (out []*Mint, err error
 
 		m := new(Mint)
 		if err := m.Decode(acct.Data.GetBinary()); err != nil {
-			return nil, fmt.Errorf("unable to decode mint %q: %w", acct.Owner.String(), err)
+			return nil, fmt.Errorf("unable to decode mint %q: %w", keyedAcct.Pubkey.String(), err)
 		}
 		out = append(out, m)
 
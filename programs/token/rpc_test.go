@@ -0,0 +1,143 @@
+// Copyright 2021 github.com/gagliardetto
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package token
+
+import (
+	"bytes"
+	"context"
+	"sort"
+	"strconv"
+	"testing"
+
+	bin "github.com/gagliardetto/binary"
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+	"github.com/gagliardetto/solana-go/rpc/rpcreplay"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFetchMints_Replay(t *testing.T) {
+	rep, err := rpcreplay.NewReplayerFromFile("testdata/fetch-mints.replay.json")
+	require.NoError(t, err)
+	client := rpc.NewWithCustomRPCClient(rep)
+
+	mints, err := FetchMints(context.Background(), client)
+	require.NoError(t, err)
+	require.NoError(t, rep.Err())
+
+	require.Len(t, mints, 2)
+	sort.Slice(mints, func(i, j int) bool { return mints[i].Supply < mints[j].Supply })
+	require.Equal(t, uint64(5034943397351005), mints[0].Supply)
+	require.NotNil(t, mints[0].FreezeAuthority)
+	require.Equal(t, uint64(9998022451607088), mints[1].Supply)
+	require.Nil(t, mints[1].FreezeAuthority)
+	for _, mint := range mints {
+		require.True(t, mint.IsInitialized)
+		require.Equal(t, uint8(6), mint.Decimals)
+	}
+}
+
+// TestParsedAccount_MatchesBinary checks that the jsonParsed representations
+// decode to the same values as the binary layouts.
+func TestParsedAccount_MatchesBinary(t *testing.T) {
+	authority := solana.MustPublicKeyFromBase58("Q6XprfkF8RQQKoQVG33xT88H7wi8Uk1B1CC7YAs69Gi")
+	{
+		mint := Mint{
+			MintAuthority: authority.ToPointer(),
+			Supply:        1890000009537801,
+			Decimals:      6,
+			IsInitialized: true,
+		}
+		var binary Mint
+		require.NoError(t, bin.NewBinDecoder(mustEncode(t, &mint)).Decode(&binary))
+
+		parsed := mustParse(t, `{
+			"program": "spl-token",
+			"parsed": {
+				"type": "mint",
+				"info": {
+					"decimals": 6,
+					"freezeAuthority": null,
+					"isInitialized": true,
+					"mintAuthority": "Q6XprfkF8RQQKoQVG33xT88H7wi8Uk1B1CC7YAs69Gi",
+					"supply": "1890000009537801"
+				}
+			},
+			"space": 82
+		}`).(*rpc.ParsedTokenMint)
+
+		require.Equal(t, binary.MintAuthority, parsed.MintAuthority)
+		require.Equal(t, binary.Supply, parsed.Supply)
+		require.Equal(t, binary.Decimals, parsed.Decimals)
+		require.Equal(t, binary.IsInitialized, parsed.IsInitialized)
+		require.Equal(t, binary.FreezeAuthority, parsed.FreezeAuthority)
+	}
+	{
+		account := Account{
+			Mint:            solana.MustPublicKeyFromBase58("EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v"),
+			Owner:           solana.MustPublicKeyFromBase58("9WzDXwBbmkg8ZTbNMqUxvQRAyrZzDsGYdLVL9zYtAWWM"),
+			Amount:          1500000,
+			Delegate:        authority.ToPointer(),
+			State:           Frozen,
+			DelegatedAmount: 1000,
+			CloseAuthority:  authority.ToPointer(),
+		}
+		var binary Account
+		require.NoError(t, bin.NewBinDecoder(mustEncode(t, &account)).Decode(&binary))
+
+		parsed := mustParse(t, `{
+			"program": "spl-token",
+			"parsed": {
+				"type": "account",
+				"info": {
+					"closeAuthority": "Q6XprfkF8RQQKoQVG33xT88H7wi8Uk1B1CC7YAs69Gi",
+					"delegate": "Q6XprfkF8RQQKoQVG33xT88H7wi8Uk1B1CC7YAs69Gi",
+					"delegatedAmount": {"amount": "1000", "decimals": 6, "uiAmount": 0.001, "uiAmountString": "0.001"},
+					"isNative": false,
+					"mint": "EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v",
+					"owner": "9WzDXwBbmkg8ZTbNMqUxvQRAyrZzDsGYdLVL9zYtAWWM",
+					"state": "frozen",
+					"tokenAmount": {"amount": "1500000", "decimals": 6, "uiAmount": 1.5, "uiAmountString": "1.5"}
+				}
+			},
+			"space": 165
+		}`).(*rpc.ParsedTokenAccount)
+
+		require.Equal(t, binary.Mint, parsed.Mint)
+		require.Equal(t, binary.Owner, parsed.Owner)
+		require.Equal(t, strconv.FormatUint(binary.Amount, 10), parsed.TokenAmount.Amount)
+		require.Equal(t, binary.Delegate, parsed.Delegate)
+		require.Equal(t, rpc.ParsedTokenAccountStateFrozen, parsed.State)
+		require.Equal(t, binary.IsNative != nil, parsed.IsNative)
+		require.Equal(t, strconv.FormatUint(binary.DelegatedAmount, 10), parsed.DelegatedAmount.Amount)
+		require.Equal(t, binary.CloseAuthority, parsed.CloseAuthority)
+	}
+}
+
+func mustEncode(t *testing.T, v interface{}) []byte {
+	t.Helper()
+	buf := new(bytes.Buffer)
+	require.NoError(t, bin.NewBinEncoder(buf).Encode(v))
+	return buf.Bytes()
+}
+
+func mustParse(t *testing.T, in string) interface{} {
+	t.Helper()
+	var data rpc.DataBytesOrJSON
+	require.NoError(t, data.UnmarshalJSON([]byte(in)))
+	out, err := data.GetParsedAccount()
+	require.NoError(t, err)
+	return out
+}
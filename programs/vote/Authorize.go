@@ -15,14 +15,24 @@
 package vote
 
 import (
+	"errors"
+	"fmt"
+
+	bin "github.com/gagliardetto/binary"
 	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/text/format"
+	"github.com/gagliardetto/treeout"
 )
 
 type Authorize struct {
-	// TODO
+	// The new authority.
+	NewAuthority *solana.PublicKey
+
+	// The kind of authority to set.
+	VoteAuthorize *VoteAuthorize
 
 	// [0] = [WRITE] VoteAccount
-	// ··········· Unitialized vote account
+	// ··········· Vote account to be updated with the new authority
 	//
 	// [1] = [] SysVarClock
 	// ··········· Clock sysvar
@@ -31,3 +41,121 @@ type Authorize struct {
 	// ··········· Vote or withdraw authority
 	solana.AccountMetaSlice `bin:"-" borsh_skip:"true"`
 }
+
+func (inst *Authorize) UnmarshalWithDecoder(dec *bin.Decoder) error {
+	if err := dec.Decode(&inst.NewAuthority); err != nil {
+		return err
+	}
+	voteAuthorize, err := dec.ReadUint32(bin.LE)
+	if err != nil {
+		return err
+	}
+	inst.VoteAuthorize = (*VoteAuthorize)(&voteAuthorize)
+	return nil
+}
+
+func (inst Authorize) MarshalWithEncoder(encoder *bin.Encoder) error {
+	if err := encoder.Encode(*inst.NewAuthority); err != nil {
+		return err
+	}
+	return encoder.WriteUint32(uint32(*inst.VoteAuthorize), bin.LE)
+}
+
+func (inst *Authorize) Validate() error {
+	if inst.NewAuthority == nil {
+		return errors.New("new authority parameter is not set")
+	}
+	if inst.VoteAuthorize == nil {
+		return errors.New("vote authorize parameter is not set")
+	}
+
+	for accIndex, acc := range inst.AccountMetaSlice {
+		if acc == nil {
+			return fmt.Errorf("ins.AccountMetaSlice[%v] is not set", accIndex)
+		}
+	}
+	return nil
+}
+
+func (inst *Authorize) SetNewAuthority(newAuthority solana.PublicKey) *Authorize {
+	inst.NewAuthority = &newAuthority
+	return inst
+}
+
+func (inst *Authorize) SetVoteAuthorize(voteAuthorize VoteAuthorize) *Authorize {
+	inst.VoteAuthorize = &voteAuthorize
+	return inst
+}
+
+func (inst *Authorize) SetVoteAccount(voteAccount solana.PublicKey) *Authorize {
+	inst.AccountMetaSlice[0] = solana.Meta(voteAccount).WRITE()
+	return inst
+}
+
+func (inst *Authorize) SetClockSysvarAccount(clockSysvar solana.PublicKey) *Authorize {
+	inst.AccountMetaSlice[1] = solana.Meta(clockSysvar)
+	return inst
+}
+
+func (inst *Authorize) SetAuthorityAccount(authority solana.PublicKey) *Authorize {
+	inst.AccountMetaSlice[2] = solana.Meta(authority).SIGNER()
+	return inst
+}
+
+func (inst *Authorize) GetVoteAccount() *solana.AccountMeta        { return inst.AccountMetaSlice[0] }
+func (inst *Authorize) GetClockSysvarAccount() *solana.AccountMeta { return inst.AccountMetaSlice[1] }
+func (inst *Authorize) GetAuthorityAccount() *solana.AccountMeta   { return inst.AccountMetaSlice[2] }
+
+func (inst Authorize) Build() *Instruction {
+	return &Instruction{BaseVariant: bin.BaseVariant{
+		Impl:   inst,
+		TypeID: bin.TypeIDFromUint32(Instruction_Authorize, bin.LE),
+	}}
+}
+
+func (inst *Authorize) EncodeToTree(parent treeout.Branches) {
+	parent.Child(format.Program(ProgramName, ProgramID)).
+		//
+		ParentFunc(func(programBranch treeout.Branches) {
+			programBranch.Child(format.Instruction("Authorize")).
+				//
+				ParentFunc(func(instructionBranch treeout.Branches) {
+					// Parameters of the instruction:
+					instructionBranch.Child("Params").ParentFunc(func(paramsBranch treeout.Branches) {
+						paramsBranch.Child(format.Param("NewAuthority", inst.NewAuthority))
+						paramsBranch.Child(format.Param("VoteAuthorize", inst.VoteAuthorize))
+					})
+
+					// Accounts of the instruction:
+					instructionBranch.Child("Accounts").ParentFunc(func(accountsBranch treeout.Branches) {
+						accountsBranch.Child(format.Meta("VoteAccount", inst.AccountMetaSlice.Get(0)))
+						accountsBranch.Child(format.Meta("ClockSysvar", inst.AccountMetaSlice.Get(1)))
+						accountsBranch.Child(format.Meta("  Authority", inst.AccountMetaSlice.Get(2)))
+					})
+				})
+		})
+}
+
+// NewAuthorizeInstructionBuilder creates a new `Authorize` instruction builder.
+func NewAuthorizeInstructionBuilder() *Authorize {
+	return &Authorize{
+		AccountMetaSlice: make(solana.AccountMetaSlice, 3),
+	}
+}
+
+// NewAuthorizeInstruction declares a new Authorize instruction with the provided parameters and accounts.
+func NewAuthorizeInstruction(
+	// Parameters:
+	newAuthority solana.PublicKey,
+	voteAuthorize VoteAuthorize,
+	// Accounts:
+	voteAccount solana.PublicKey,
+	authority solana.PublicKey,
+) *Authorize {
+	return NewAuthorizeInstructionBuilder().
+		SetNewAuthority(newAuthority).
+		SetVoteAuthorize(voteAuthorize).
+		SetVoteAccount(voteAccount).
+		SetClockSysvarAccount(solana.SysVarClockPubkey).
+		SetAuthorityAccount(authority)
+}
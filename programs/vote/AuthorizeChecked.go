@@ -0,0 +1,165 @@
+// Copyright 2021 github.com/gagliardetto
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vote
+
+import (
+	"errors"
+	"fmt"
+
+	bin "github.com/gagliardetto/binary"
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/text/format"
+	"github.com/gagliardetto/treeout"
+)
+
+// AuthorizeChecked sets a vote or withdraw authority, with the new authority as signer.
+type AuthorizeChecked struct {
+	// The kind of authority to set.
+	VoteAuthorize *VoteAuthorize
+
+	// [0] = [WRITE] VoteAccount
+	// ··········· Vote account to be updated
+	//
+	// [1] = [] ClockSysvar
+	// ··········· Clock sysvar
+	//
+	// [2] = [SIGNER] Authority
+	// ··········· Vote or withdraw authority
+	//
+	// [3] = [SIGNER] NewAuthority
+	// ··········· New vote or withdraw authority
+	solana.AccountMetaSlice `bin:"-" borsh_skip:"true"`
+}
+
+func (inst *AuthorizeChecked) UnmarshalWithDecoder(dec *bin.Decoder) error {
+	{
+		v, err := dec.ReadUint32(bin.LE)
+		if err != nil {
+			return err
+		}
+		inst.VoteAuthorize = (*VoteAuthorize)(&v)
+	}
+	return nil
+}
+
+func (inst AuthorizeChecked) MarshalWithEncoder(encoder *bin.Encoder) error {
+	if err := encoder.WriteUint32(uint32(*inst.VoteAuthorize), bin.LE); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (inst *AuthorizeChecked) Validate() error {
+	if inst.VoteAuthorize == nil {
+		return errors.New("vote authorize parameter is not set")
+	}
+
+	for accIndex, acc := range inst.AccountMetaSlice {
+		if acc == nil {
+			return fmt.Errorf("ins.AccountMetaSlice[%v] is not set", accIndex)
+		}
+	}
+	return nil
+}
+
+func (inst *AuthorizeChecked) SetVoteAuthorize(voteAuthorize VoteAuthorize) *AuthorizeChecked {
+	inst.VoteAuthorize = &voteAuthorize
+	return inst
+}
+
+func (inst *AuthorizeChecked) SetVoteAccount(voteAccount solana.PublicKey) *AuthorizeChecked {
+	inst.AccountMetaSlice[0] = solana.Meta(voteAccount).WRITE()
+	return inst
+}
+
+func (inst *AuthorizeChecked) SetClockSysvarAccount(clockSysvar solana.PublicKey) *AuthorizeChecked {
+	inst.AccountMetaSlice[1] = solana.Meta(clockSysvar)
+	return inst
+}
+
+func (inst *AuthorizeChecked) SetAuthorityAccount(authority solana.PublicKey) *AuthorizeChecked {
+	inst.AccountMetaSlice[2] = solana.Meta(authority).SIGNER()
+	return inst
+}
+
+func (inst *AuthorizeChecked) SetNewAuthorityAccount(newAuthority solana.PublicKey) *AuthorizeChecked {
+	inst.AccountMetaSlice[3] = solana.Meta(newAuthority).SIGNER()
+	return inst
+}
+
+func (inst *AuthorizeChecked) GetVoteAccount() *solana.AccountMeta { return inst.AccountMetaSlice[0] }
+func (inst *AuthorizeChecked) GetClockSysvarAccount() *solana.AccountMeta {
+	return inst.AccountMetaSlice[1]
+}
+func (inst *AuthorizeChecked) GetAuthorityAccount() *solana.AccountMeta {
+	return inst.AccountMetaSlice[2]
+}
+func (inst *AuthorizeChecked) GetNewAuthorityAccount() *solana.AccountMeta {
+	return inst.AccountMetaSlice[3]
+}
+
+func (inst AuthorizeChecked) Build() *Instruction {
+	return &Instruction{BaseVariant: bin.BaseVariant{
+		Impl:   inst,
+		TypeID: bin.TypeIDFromUint32(Instruction_AuthorizeChecked, bin.LE),
+	}}
+}
+
+func (inst *AuthorizeChecked) EncodeToTree(parent treeout.Branches) {
+	parent.Child(format.Program(ProgramName, ProgramID)).
+		//
+		ParentFunc(func(programBranch treeout.Branches) {
+			programBranch.Child(format.Instruction("AuthorizeChecked")).
+				//
+				ParentFunc(func(instructionBranch treeout.Branches) {
+					// Parameters of the instruction:
+					instructionBranch.Child("Params").ParentFunc(func(paramsBranch treeout.Branches) {
+						paramsBranch.Child(format.Param("VoteAuthorize", inst.VoteAuthorize))
+					})
+
+					// Accounts of the instruction:
+					instructionBranch.Child("Accounts").ParentFunc(func(accountsBranch treeout.Branches) {
+						accountsBranch.Child(format.Meta(" VoteAccount", inst.AccountMetaSlice.Get(0)))
+						accountsBranch.Child(format.Meta(" ClockSysvar", inst.AccountMetaSlice.Get(1)))
+						accountsBranch.Child(format.Meta("   Authority", inst.AccountMetaSlice.Get(2)))
+						accountsBranch.Child(format.Meta("NewAuthority", inst.AccountMetaSlice.Get(3)))
+					})
+				})
+		})
+}
+
+// NewAuthorizeCheckedInstructionBuilder creates a new `AuthorizeChecked` instruction builder.
+func NewAuthorizeCheckedInstructionBuilder() *AuthorizeChecked {
+	return &AuthorizeChecked{
+		AccountMetaSlice: make(solana.AccountMetaSlice, 4),
+	}
+}
+
+// NewAuthorizeCheckedInstruction declares a new AuthorizeChecked instruction with the provided parameters and accounts.
+func NewAuthorizeCheckedInstruction(
+	// Parameters:
+	voteAuthorize VoteAuthorize,
+	// Accounts:
+	voteAccount solana.PublicKey,
+	authority solana.PublicKey,
+	newAuthority solana.PublicKey,
+) *AuthorizeChecked {
+	return NewAuthorizeCheckedInstructionBuilder().
+		SetVoteAuthorize(voteAuthorize).
+		SetVoteAccount(voteAccount).
+		SetClockSysvarAccount(solana.SysVarClockPubkey).
+		SetAuthorityAccount(authority).
+		SetNewAuthorityAccount(newAuthority)
+}
@@ -0,0 +1,211 @@
+// Copyright 2021 github.com/gagliardetto
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vote
+
+import (
+	"errors"
+	"fmt"
+
+	bin "github.com/gagliardetto/binary"
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/text/format"
+	"github.com/gagliardetto/treeout"
+)
+
+// AuthorizeCheckedWithSeed sets a vote or withdraw authority, when the current authority is a derived key, with the new authority as signer.
+type AuthorizeCheckedWithSeed struct {
+	// The kind of authority to set.
+	VoteAuthorize *VoteAuthorize
+
+	// The owner of the derived key of the current authority.
+	CurrentAuthorityDerivedKeyOwner *solana.PublicKey
+
+	// The seed of the derived key of the current authority.
+	CurrentAuthorityDerivedKeySeed *string
+
+	// [0] = [WRITE] VoteAccount
+	// ··········· Vote account to be updated
+	//
+	// [1] = [] ClockSysvar
+	// ··········· Clock sysvar
+	//
+	// [2] = [SIGNER] Base
+	// ··········· Base key of the derived key of the current authority
+	//
+	// [3] = [SIGNER] NewAuthority
+	// ··········· New vote or withdraw authority
+	solana.AccountMetaSlice `bin:"-" borsh_skip:"true"`
+}
+
+func (inst *AuthorizeCheckedWithSeed) UnmarshalWithDecoder(dec *bin.Decoder) error {
+	{
+		v, err := dec.ReadUint32(bin.LE)
+		if err != nil {
+			return err
+		}
+		inst.VoteAuthorize = (*VoteAuthorize)(&v)
+	}
+	if err := dec.Decode(&inst.CurrentAuthorityDerivedKeyOwner); err != nil {
+		return err
+	}
+	{
+		v, err := dec.ReadRustString()
+		if err != nil {
+			return err
+		}
+		inst.CurrentAuthorityDerivedKeySeed = &v
+	}
+	return nil
+}
+
+func (inst AuthorizeCheckedWithSeed) MarshalWithEncoder(encoder *bin.Encoder) error {
+	if err := encoder.WriteUint32(uint32(*inst.VoteAuthorize), bin.LE); err != nil {
+		return err
+	}
+	if err := encoder.Encode(*inst.CurrentAuthorityDerivedKeyOwner); err != nil {
+		return err
+	}
+	if err := encoder.WriteRustString(*inst.CurrentAuthorityDerivedKeySeed); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (inst *AuthorizeCheckedWithSeed) Validate() error {
+	if inst.VoteAuthorize == nil {
+		return errors.New("vote authorize parameter is not set")
+	}
+	if inst.CurrentAuthorityDerivedKeyOwner == nil {
+		return errors.New("current authority derived key owner parameter is not set")
+	}
+	if inst.CurrentAuthorityDerivedKeySeed == nil {
+		return errors.New("current authority derived key seed parameter is not set")
+	}
+
+	for accIndex, acc := range inst.AccountMetaSlice {
+		if acc == nil {
+			return fmt.Errorf("ins.AccountMetaSlice[%v] is not set", accIndex)
+		}
+	}
+	return nil
+}
+
+func (inst *AuthorizeCheckedWithSeed) SetVoteAuthorize(voteAuthorize VoteAuthorize) *AuthorizeCheckedWithSeed {
+	inst.VoteAuthorize = &voteAuthorize
+	return inst
+}
+
+func (inst *AuthorizeCheckedWithSeed) SetCurrentAuthorityDerivedKeyOwner(currentAuthorityDerivedKeyOwner solana.PublicKey) *AuthorizeCheckedWithSeed {
+	inst.CurrentAuthorityDerivedKeyOwner = &currentAuthorityDerivedKeyOwner
+	return inst
+}
+
+func (inst *AuthorizeCheckedWithSeed) SetCurrentAuthorityDerivedKeySeed(currentAuthorityDerivedKeySeed string) *AuthorizeCheckedWithSeed {
+	inst.CurrentAuthorityDerivedKeySeed = &currentAuthorityDerivedKeySeed
+	return inst
+}
+
+func (inst *AuthorizeCheckedWithSeed) SetVoteAccount(voteAccount solana.PublicKey) *AuthorizeCheckedWithSeed {
+	inst.AccountMetaSlice[0] = solana.Meta(voteAccount).WRITE()
+	return inst
+}
+
+func (inst *AuthorizeCheckedWithSeed) SetClockSysvarAccount(clockSysvar solana.PublicKey) *AuthorizeCheckedWithSeed {
+	inst.AccountMetaSlice[1] = solana.Meta(clockSysvar)
+	return inst
+}
+
+func (inst *AuthorizeCheckedWithSeed) SetBaseAccount(base solana.PublicKey) *AuthorizeCheckedWithSeed {
+	inst.AccountMetaSlice[2] = solana.Meta(base).SIGNER()
+	return inst
+}
+
+func (inst *AuthorizeCheckedWithSeed) SetNewAuthorityAccount(newAuthority solana.PublicKey) *AuthorizeCheckedWithSeed {
+	inst.AccountMetaSlice[3] = solana.Meta(newAuthority).SIGNER()
+	return inst
+}
+
+func (inst *AuthorizeCheckedWithSeed) GetVoteAccount() *solana.AccountMeta {
+	return inst.AccountMetaSlice[0]
+}
+func (inst *AuthorizeCheckedWithSeed) GetClockSysvarAccount() *solana.AccountMeta {
+	return inst.AccountMetaSlice[1]
+}
+func (inst *AuthorizeCheckedWithSeed) GetBaseAccount() *solana.AccountMeta {
+	return inst.AccountMetaSlice[2]
+}
+func (inst *AuthorizeCheckedWithSeed) GetNewAuthorityAccount() *solana.AccountMeta {
+	return inst.AccountMetaSlice[3]
+}
+
+func (inst AuthorizeCheckedWithSeed) Build() *Instruction {
+	return &Instruction{BaseVariant: bin.BaseVariant{
+		Impl:   inst,
+		TypeID: bin.TypeIDFromUint32(Instruction_AuthorizeCheckedWithSeed, bin.LE),
+	}}
+}
+
+func (inst *AuthorizeCheckedWithSeed) EncodeToTree(parent treeout.Branches) {
+	parent.Child(format.Program(ProgramName, ProgramID)).
+		//
+		ParentFunc(func(programBranch treeout.Branches) {
+			programBranch.Child(format.Instruction("AuthorizeCheckedWithSeed")).
+				//
+				ParentFunc(func(instructionBranch treeout.Branches) {
+					// Parameters of the instruction:
+					instructionBranch.Child("Params").ParentFunc(func(paramsBranch treeout.Branches) {
+						paramsBranch.Child(format.Param("VoteAuthorize", inst.VoteAuthorize))
+						paramsBranch.Child(format.Param("CurrentAuthorityDerivedKeyOwner", inst.CurrentAuthorityDerivedKeyOwner))
+						paramsBranch.Child(format.Param("CurrentAuthorityDerivedKeySeed", inst.CurrentAuthorityDerivedKeySeed))
+					})
+
+					// Accounts of the instruction:
+					instructionBranch.Child("Accounts").ParentFunc(func(accountsBranch treeout.Branches) {
+						accountsBranch.Child(format.Meta(" VoteAccount", inst.AccountMetaSlice.Get(0)))
+						accountsBranch.Child(format.Meta(" ClockSysvar", inst.AccountMetaSlice.Get(1)))
+						accountsBranch.Child(format.Meta("        Base", inst.AccountMetaSlice.Get(2)))
+						accountsBranch.Child(format.Meta("NewAuthority", inst.AccountMetaSlice.Get(3)))
+					})
+				})
+		})
+}
+
+// NewAuthorizeCheckedWithSeedInstructionBuilder creates a new `AuthorizeCheckedWithSeed` instruction builder.
+func NewAuthorizeCheckedWithSeedInstructionBuilder() *AuthorizeCheckedWithSeed {
+	return &AuthorizeCheckedWithSeed{
+		AccountMetaSlice: make(solana.AccountMetaSlice, 4),
+	}
+}
+
+// NewAuthorizeCheckedWithSeedInstruction declares a new AuthorizeCheckedWithSeed instruction with the provided parameters and accounts.
+func NewAuthorizeCheckedWithSeedInstruction(
+	// Parameters:
+	voteAuthorize VoteAuthorize,
+	currentAuthorityDerivedKeyOwner solana.PublicKey,
+	currentAuthorityDerivedKeySeed string,
+	// Accounts:
+	voteAccount solana.PublicKey,
+	base solana.PublicKey,
+	newAuthority solana.PublicKey,
+) *AuthorizeCheckedWithSeed {
+	return NewAuthorizeCheckedWithSeedInstructionBuilder().
+		SetVoteAuthorize(voteAuthorize).
+		SetCurrentAuthorityDerivedKeyOwner(currentAuthorityDerivedKeyOwner).
+		SetCurrentAuthorityDerivedKeySeed(currentAuthorityDerivedKeySeed).
+		SetVoteAccount(voteAccount).
+		SetClockSysvarAccount(solana.SysVarClockPubkey).
+		SetBaseAccount(base).
+		SetNewAuthorityAccount(newAuthority)
+}
@@ -0,0 +1,213 @@
+// Copyright 2021 github.com/gagliardetto
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vote
+
+import (
+	"errors"
+	"fmt"
+
+	bin "github.com/gagliardetto/binary"
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/text/format"
+	"github.com/gagliardetto/treeout"
+)
+
+// AuthorizeWithSeed sets a vote or withdraw authority, when the current authority is a derived key.
+type AuthorizeWithSeed struct {
+	// The kind of authority to set.
+	VoteAuthorize *VoteAuthorize
+
+	// The owner of the derived key of the current authority.
+	CurrentAuthorityDerivedKeyOwner *solana.PublicKey
+
+	// The seed of the derived key of the current authority.
+	CurrentAuthorityDerivedKeySeed *string
+
+	// The new authority.
+	NewAuthority *solana.PublicKey
+
+	// [0] = [WRITE] VoteAccount
+	// ··········· Vote account to be updated
+	//
+	// [1] = [] ClockSysvar
+	// ··········· Clock sysvar
+	//
+	// [2] = [SIGNER] Base
+	// ··········· Base key of the derived key of the current authority
+	solana.AccountMetaSlice `bin:"-" borsh_skip:"true"`
+}
+
+func (inst *AuthorizeWithSeed) UnmarshalWithDecoder(dec *bin.Decoder) error {
+	{
+		v, err := dec.ReadUint32(bin.LE)
+		if err != nil {
+			return err
+		}
+		inst.VoteAuthorize = (*VoteAuthorize)(&v)
+	}
+	if err := dec.Decode(&inst.CurrentAuthorityDerivedKeyOwner); err != nil {
+		return err
+	}
+	{
+		v, err := dec.ReadRustString()
+		if err != nil {
+			return err
+		}
+		inst.CurrentAuthorityDerivedKeySeed = &v
+	}
+	if err := dec.Decode(&inst.NewAuthority); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (inst AuthorizeWithSeed) MarshalWithEncoder(encoder *bin.Encoder) error {
+	if err := encoder.WriteUint32(uint32(*inst.VoteAuthorize), bin.LE); err != nil {
+		return err
+	}
+	if err := encoder.Encode(*inst.CurrentAuthorityDerivedKeyOwner); err != nil {
+		return err
+	}
+	if err := encoder.WriteRustString(*inst.CurrentAuthorityDerivedKeySeed); err != nil {
+		return err
+	}
+	if err := encoder.Encode(*inst.NewAuthority); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (inst *AuthorizeWithSeed) Validate() error {
+	if inst.VoteAuthorize == nil {
+		return errors.New("vote authorize parameter is not set")
+	}
+	if inst.CurrentAuthorityDerivedKeyOwner == nil {
+		return errors.New("current authority derived key owner parameter is not set")
+	}
+	if inst.CurrentAuthorityDerivedKeySeed == nil {
+		return errors.New("current authority derived key seed parameter is not set")
+	}
+	if inst.NewAuthority == nil {
+		return errors.New("new authority parameter is not set")
+	}
+
+	for accIndex, acc := range inst.AccountMetaSlice {
+		if acc == nil {
+			return fmt.Errorf("ins.AccountMetaSlice[%v] is not set", accIndex)
+		}
+	}
+	return nil
+}
+
+func (inst *AuthorizeWithSeed) SetVoteAuthorize(voteAuthorize VoteAuthorize) *AuthorizeWithSeed {
+	inst.VoteAuthorize = &voteAuthorize
+	return inst
+}
+
+func (inst *AuthorizeWithSeed) SetCurrentAuthorityDerivedKeyOwner(currentAuthorityDerivedKeyOwner solana.PublicKey) *AuthorizeWithSeed {
+	inst.CurrentAuthorityDerivedKeyOwner = &currentAuthorityDerivedKeyOwner
+	return inst
+}
+
+func (inst *AuthorizeWithSeed) SetCurrentAuthorityDerivedKeySeed(currentAuthorityDerivedKeySeed string) *AuthorizeWithSeed {
+	inst.CurrentAuthorityDerivedKeySeed = &currentAuthorityDerivedKeySeed
+	return inst
+}
+
+func (inst *AuthorizeWithSeed) SetNewAuthority(newAuthority solana.PublicKey) *AuthorizeWithSeed {
+	inst.NewAuthority = &newAuthority
+	return inst
+}
+
+func (inst *AuthorizeWithSeed) SetVoteAccount(voteAccount solana.PublicKey) *AuthorizeWithSeed {
+	inst.AccountMetaSlice[0] = solana.Meta(voteAccount).WRITE()
+	return inst
+}
+
+func (inst *AuthorizeWithSeed) SetClockSysvarAccount(clockSysvar solana.PublicKey) *AuthorizeWithSeed {
+	inst.AccountMetaSlice[1] = solana.Meta(clockSysvar)
+	return inst
+}
+
+func (inst *AuthorizeWithSeed) SetBaseAccount(base solana.PublicKey) *AuthorizeWithSeed {
+	inst.AccountMetaSlice[2] = solana.Meta(base).SIGNER()
+	return inst
+}
+
+func (inst *AuthorizeWithSeed) GetVoteAccount() *solana.AccountMeta { return inst.AccountMetaSlice[0] }
+func (inst *AuthorizeWithSeed) GetClockSysvarAccount() *solana.AccountMeta {
+	return inst.AccountMetaSlice[1]
+}
+func (inst *AuthorizeWithSeed) GetBaseAccount() *solana.AccountMeta { return inst.AccountMetaSlice[2] }
+
+func (inst AuthorizeWithSeed) Build() *Instruction {
+	return &Instruction{BaseVariant: bin.BaseVariant{
+		Impl:   inst,
+		TypeID: bin.TypeIDFromUint32(Instruction_AuthorizeWithSeed, bin.LE),
+	}}
+}
+
+func (inst *AuthorizeWithSeed) EncodeToTree(parent treeout.Branches) {
+	parent.Child(format.Program(ProgramName, ProgramID)).
+		//
+		ParentFunc(func(programBranch treeout.Branches) {
+			programBranch.Child(format.Instruction("AuthorizeWithSeed")).
+				//
+				ParentFunc(func(instructionBranch treeout.Branches) {
+					// Parameters of the instruction:
+					instructionBranch.Child("Params").ParentFunc(func(paramsBranch treeout.Branches) {
+						paramsBranch.Child(format.Param("VoteAuthorize", inst.VoteAuthorize))
+						paramsBranch.Child(format.Param("CurrentAuthorityDerivedKeyOwner", inst.CurrentAuthorityDerivedKeyOwner))
+						paramsBranch.Child(format.Param("CurrentAuthorityDerivedKeySeed", inst.CurrentAuthorityDerivedKeySeed))
+						paramsBranch.Child(format.Param("NewAuthority", inst.NewAuthority))
+					})
+
+					// Accounts of the instruction:
+					instructionBranch.Child("Accounts").ParentFunc(func(accountsBranch treeout.Branches) {
+						accountsBranch.Child(format.Meta("VoteAccount", inst.AccountMetaSlice.Get(0)))
+						accountsBranch.Child(format.Meta("ClockSysvar", inst.AccountMetaSlice.Get(1)))
+						accountsBranch.Child(format.Meta("       Base", inst.AccountMetaSlice.Get(2)))
+					})
+				})
+		})
+}
+
+// NewAuthorizeWithSeedInstructionBuilder creates a new `AuthorizeWithSeed` instruction builder.
+func NewAuthorizeWithSeedInstructionBuilder() *AuthorizeWithSeed {
+	return &AuthorizeWithSeed{
+		AccountMetaSlice: make(solana.AccountMetaSlice, 3),
+	}
+}
+
+// NewAuthorizeWithSeedInstruction declares a new AuthorizeWithSeed instruction with the provided parameters and accounts.
+func NewAuthorizeWithSeedInstruction(
+	// Parameters:
+	voteAuthorize VoteAuthorize,
+	currentAuthorityDerivedKeyOwner solana.PublicKey,
+	currentAuthorityDerivedKeySeed string,
+	newAuthority solana.PublicKey,
+	// Accounts:
+	voteAccount solana.PublicKey,
+	base solana.PublicKey,
+) *AuthorizeWithSeed {
+	return NewAuthorizeWithSeedInstructionBuilder().
+		SetVoteAuthorize(voteAuthorize).
+		SetCurrentAuthorityDerivedKeyOwner(currentAuthorityDerivedKeyOwner).
+		SetCurrentAuthorityDerivedKeySeed(currentAuthorityDerivedKeySeed).
+		SetNewAuthority(newAuthority).
+		SetVoteAccount(voteAccount).
+		SetClockSysvarAccount(solana.SysVarClockPubkey).
+		SetBaseAccount(base)
+}
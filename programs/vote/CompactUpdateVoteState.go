@@ -0,0 +1,137 @@
+// Copyright 2021 github.com/gagliardetto
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vote
+
+import (
+	"errors"
+	"fmt"
+
+	bin "github.com/gagliardetto/binary"
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/text/format"
+	"github.com/gagliardetto/treeout"
+)
+
+// CompactUpdateVoteState updates the onchain vote state of the vote account, with the compact serialization of the tower.
+type CompactUpdateVoteState struct {
+	// The proposed tower.
+	VoteStateUpdate *VoteStateUpdate
+
+	// [0] = [WRITE] VoteAccount
+	// ··········· Vote account to be updated
+	//
+	// [1] = [SIGNER] VoteAuthority
+	// ··········· Vote authority
+	solana.AccountMetaSlice `bin:"-" borsh_skip:"true"`
+}
+
+func (inst *CompactUpdateVoteState) UnmarshalWithDecoder(dec *bin.Decoder) error {
+	inst.VoteStateUpdate = new(VoteStateUpdate)
+	if err := inst.VoteStateUpdate.unmarshalCompact(dec); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (inst CompactUpdateVoteState) MarshalWithEncoder(encoder *bin.Encoder) error {
+	if err := inst.VoteStateUpdate.marshalCompact(encoder); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (inst *CompactUpdateVoteState) Validate() error {
+	if inst.VoteStateUpdate == nil {
+		return errors.New("vote state update parameter is not set")
+	}
+
+	for accIndex, acc := range inst.AccountMetaSlice {
+		if acc == nil {
+			return fmt.Errorf("ins.AccountMetaSlice[%v] is not set", accIndex)
+		}
+	}
+	return nil
+}
+
+func (inst *CompactUpdateVoteState) SetVoteStateUpdate(voteStateUpdate *VoteStateUpdate) *CompactUpdateVoteState {
+	inst.VoteStateUpdate = voteStateUpdate
+	return inst
+}
+
+func (inst *CompactUpdateVoteState) SetVoteAccount(voteAccount solana.PublicKey) *CompactUpdateVoteState {
+	inst.AccountMetaSlice[0] = solana.Meta(voteAccount).WRITE()
+	return inst
+}
+
+func (inst *CompactUpdateVoteState) SetVoteAuthorityAccount(voteAuthority solana.PublicKey) *CompactUpdateVoteState {
+	inst.AccountMetaSlice[1] = solana.Meta(voteAuthority).SIGNER()
+	return inst
+}
+
+func (inst *CompactUpdateVoteState) GetVoteAccount() *solana.AccountMeta {
+	return inst.AccountMetaSlice[0]
+}
+func (inst *CompactUpdateVoteState) GetVoteAuthorityAccount() *solana.AccountMeta {
+	return inst.AccountMetaSlice[1]
+}
+
+func (inst CompactUpdateVoteState) Build() *Instruction {
+	return &Instruction{BaseVariant: bin.BaseVariant{
+		Impl:   inst,
+		TypeID: bin.TypeIDFromUint32(Instruction_CompactUpdateVoteState, bin.LE),
+	}}
+}
+
+func (inst *CompactUpdateVoteState) EncodeToTree(parent treeout.Branches) {
+	parent.Child(format.Program(ProgramName, ProgramID)).
+		//
+		ParentFunc(func(programBranch treeout.Branches) {
+			programBranch.Child(format.Instruction("CompactUpdateVoteState")).
+				//
+				ParentFunc(func(instructionBranch treeout.Branches) {
+					// Parameters of the instruction:
+					instructionBranch.Child("Params").ParentFunc(func(paramsBranch treeout.Branches) {
+						paramsBranch.Child(format.Param("VoteStateUpdate", inst.VoteStateUpdate))
+					})
+
+					// Accounts of the instruction:
+					instructionBranch.Child("Accounts").ParentFunc(func(accountsBranch treeout.Branches) {
+						accountsBranch.Child(format.Meta("  VoteAccount", inst.AccountMetaSlice.Get(0)))
+						accountsBranch.Child(format.Meta("VoteAuthority", inst.AccountMetaSlice.Get(1)))
+					})
+				})
+		})
+}
+
+// NewCompactUpdateVoteStateInstructionBuilder creates a new `CompactUpdateVoteState` instruction builder.
+func NewCompactUpdateVoteStateInstructionBuilder() *CompactUpdateVoteState {
+	return &CompactUpdateVoteState{
+		AccountMetaSlice: make(solana.AccountMetaSlice, 2),
+	}
+}
+
+// NewCompactUpdateVoteStateInstruction declares a new CompactUpdateVoteState instruction with the provided parameters and accounts.
+func NewCompactUpdateVoteStateInstruction(
+	// Parameters:
+	voteStateUpdate *VoteStateUpdate,
+	// Accounts:
+	voteAccount solana.PublicKey,
+	voteAuthority solana.PublicKey,
+) *CompactUpdateVoteState {
+	return NewCompactUpdateVoteStateInstructionBuilder().
+		SetVoteStateUpdate(voteStateUpdate).
+		SetVoteAccount(voteAccount).
+		SetVoteAuthorityAccount(voteAuthority)
+}
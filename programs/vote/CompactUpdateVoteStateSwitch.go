@@ -0,0 +1,157 @@
+// Copyright 2021 github.com/gagliardetto
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vote
+
+import (
+	"errors"
+	"fmt"
+
+	bin "github.com/gagliardetto/binary"
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/text/format"
+	"github.com/gagliardetto/treeout"
+)
+
+// CompactUpdateVoteStateSwitch updates the onchain vote state of the vote account, with the compact serialization of the tower and a switching proof.
+type CompactUpdateVoteStateSwitch struct {
+	// The proposed tower.
+	VoteStateUpdate *VoteStateUpdate
+
+	// Hash of the switching proof.
+	SwitchProofHash *solana.Hash
+
+	// [0] = [WRITE] VoteAccount
+	// ··········· Vote account to be updated
+	//
+	// [1] = [SIGNER] VoteAuthority
+	// ··········· Vote authority
+	solana.AccountMetaSlice `bin:"-" borsh_skip:"true"`
+}
+
+func (inst *CompactUpdateVoteStateSwitch) UnmarshalWithDecoder(dec *bin.Decoder) error {
+	inst.VoteStateUpdate = new(VoteStateUpdate)
+	if err := inst.VoteStateUpdate.unmarshalCompact(dec); err != nil {
+		return err
+	}
+	if err := dec.Decode(&inst.SwitchProofHash); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (inst CompactUpdateVoteStateSwitch) MarshalWithEncoder(encoder *bin.Encoder) error {
+	if err := inst.VoteStateUpdate.marshalCompact(encoder); err != nil {
+		return err
+	}
+	if err := encoder.WriteBytes(inst.SwitchProofHash[:], false); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (inst *CompactUpdateVoteStateSwitch) Validate() error {
+	if inst.VoteStateUpdate == nil {
+		return errors.New("vote state update parameter is not set")
+	}
+	if inst.SwitchProofHash == nil {
+		return errors.New("switch proof hash parameter is not set")
+	}
+
+	for accIndex, acc := range inst.AccountMetaSlice {
+		if acc == nil {
+			return fmt.Errorf("ins.AccountMetaSlice[%v] is not set", accIndex)
+		}
+	}
+	return nil
+}
+
+func (inst *CompactUpdateVoteStateSwitch) SetVoteStateUpdate(voteStateUpdate *VoteStateUpdate) *CompactUpdateVoteStateSwitch {
+	inst.VoteStateUpdate = voteStateUpdate
+	return inst
+}
+
+func (inst *CompactUpdateVoteStateSwitch) SetSwitchProofHash(switchProofHash solana.Hash) *CompactUpdateVoteStateSwitch {
+	inst.SwitchProofHash = &switchProofHash
+	return inst
+}
+
+func (inst *CompactUpdateVoteStateSwitch) SetVoteAccount(voteAccount solana.PublicKey) *CompactUpdateVoteStateSwitch {
+	inst.AccountMetaSlice[0] = solana.Meta(voteAccount).WRITE()
+	return inst
+}
+
+func (inst *CompactUpdateVoteStateSwitch) SetVoteAuthorityAccount(voteAuthority solana.PublicKey) *CompactUpdateVoteStateSwitch {
+	inst.AccountMetaSlice[1] = solana.Meta(voteAuthority).SIGNER()
+	return inst
+}
+
+func (inst *CompactUpdateVoteStateSwitch) GetVoteAccount() *solana.AccountMeta {
+	return inst.AccountMetaSlice[0]
+}
+func (inst *CompactUpdateVoteStateSwitch) GetVoteAuthorityAccount() *solana.AccountMeta {
+	return inst.AccountMetaSlice[1]
+}
+
+func (inst CompactUpdateVoteStateSwitch) Build() *Instruction {
+	return &Instruction{BaseVariant: bin.BaseVariant{
+		Impl:   inst,
+		TypeID: bin.TypeIDFromUint32(Instruction_CompactUpdateVoteStateSwitch, bin.LE),
+	}}
+}
+
+func (inst *CompactUpdateVoteStateSwitch) EncodeToTree(parent treeout.Branches) {
+	parent.Child(format.Program(ProgramName, ProgramID)).
+		//
+		ParentFunc(func(programBranch treeout.Branches) {
+			programBranch.Child(format.Instruction("CompactUpdateVoteStateSwitch")).
+				//
+				ParentFunc(func(instructionBranch treeout.Branches) {
+					// Parameters of the instruction:
+					instructionBranch.Child("Params").ParentFunc(func(paramsBranch treeout.Branches) {
+						paramsBranch.Child(format.Param("VoteStateUpdate", inst.VoteStateUpdate))
+						paramsBranch.Child(format.Param("SwitchProofHash", inst.SwitchProofHash))
+					})
+
+					// Accounts of the instruction:
+					instructionBranch.Child("Accounts").ParentFunc(func(accountsBranch treeout.Branches) {
+						accountsBranch.Child(format.Meta("  VoteAccount", inst.AccountMetaSlice.Get(0)))
+						accountsBranch.Child(format.Meta("VoteAuthority", inst.AccountMetaSlice.Get(1)))
+					})
+				})
+		})
+}
+
+// NewCompactUpdateVoteStateSwitchInstructionBuilder creates a new `CompactUpdateVoteStateSwitch` instruction builder.
+func NewCompactUpdateVoteStateSwitchInstructionBuilder() *CompactUpdateVoteStateSwitch {
+	return &CompactUpdateVoteStateSwitch{
+		AccountMetaSlice: make(solana.AccountMetaSlice, 2),
+	}
+}
+
+// NewCompactUpdateVoteStateSwitchInstruction declares a new CompactUpdateVoteStateSwitch instruction with the provided parameters and accounts.
+func NewCompactUpdateVoteStateSwitchInstruction(
+	// Parameters:
+	voteStateUpdate *VoteStateUpdate,
+	switchProofHash solana.Hash,
+	// Accounts:
+	voteAccount solana.PublicKey,
+	voteAuthority solana.PublicKey,
+) *CompactUpdateVoteStateSwitch {
+	return NewCompactUpdateVoteStateSwitchInstructionBuilder().
+		SetVoteStateUpdate(voteStateUpdate).
+		SetSwitchProofHash(switchProofHash).
+		SetVoteAccount(voteAccount).
+		SetVoteAuthorityAccount(voteAuthority)
+}
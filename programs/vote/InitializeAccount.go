@@ -15,22 +15,196 @@
 package vote
 
 import (
+	"errors"
+	"fmt"
+
+	bin "github.com/gagliardetto/binary"
 	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/text/format"
+	"github.com/gagliardetto/treeout"
 )
 
 type InitializeAccount struct {
-	// TODO
+	// The validator identity of the vote account.
+	NodePubkey *solana.PublicKey
+
+	// The authority allowed to vote.
+	AuthorizedVoter *solana.PublicKey
+
+	// The authority allowed to withdraw.
+	AuthorizedWithdrawer *solana.PublicKey
+
+	// Commission, in percent.
+	Commission *uint8
 
 	// [0] = [WRITE] VoteAccount
-	// ··········· Vote account to vote with
+	// ··········· Uninitialized vote account
 	//
-	// [1] = [] SysVarSlotHashes
-	// ··········· Slot hashes sysvar
+	// [1] = [] SysVarRent
+	// ··········· Rent sysvar
 	//
 	// [2] = [] SysVarClock
 	// ··········· Clock sysvar
 	//
-	// [3] = [SIGNER] VoteAuthority
+	// [3] = [SIGNER] NodeAccount
 	// ··········· New validator identity (node_pubkey)
 	solana.AccountMetaSlice `bin:"-" borsh_skip:"true"`
 }
+
+func (inst *InitializeAccount) UnmarshalWithDecoder(dec *bin.Decoder) error {
+	if err := dec.Decode(&inst.NodePubkey); err != nil {
+		return err
+	}
+	if err := dec.Decode(&inst.AuthorizedVoter); err != nil {
+		return err
+	}
+	if err := dec.Decode(&inst.AuthorizedWithdrawer); err != nil {
+		return err
+	}
+	return dec.Decode(&inst.Commission)
+}
+
+func (inst InitializeAccount) MarshalWithEncoder(encoder *bin.Encoder) error {
+	if err := encoder.Encode(*inst.NodePubkey); err != nil {
+		return err
+	}
+	if err := encoder.Encode(*inst.AuthorizedVoter); err != nil {
+		return err
+	}
+	if err := encoder.Encode(*inst.AuthorizedWithdrawer); err != nil {
+		return err
+	}
+	return encoder.WriteUint8(*inst.Commission)
+}
+
+func (inst *InitializeAccount) Validate() error {
+	if inst.NodePubkey == nil {
+		return errors.New("node pubkey parameter is not set")
+	}
+	if inst.AuthorizedVoter == nil {
+		return errors.New("authorized voter parameter is not set")
+	}
+	if inst.AuthorizedWithdrawer == nil {
+		return errors.New("authorized withdrawer parameter is not set")
+	}
+	if inst.Commission == nil {
+		return errors.New("commission parameter is not set")
+	}
+
+	for accIndex, acc := range inst.AccountMetaSlice {
+		if acc == nil {
+			return fmt.Errorf("ins.AccountMetaSlice[%v] is not set", accIndex)
+		}
+	}
+	return nil
+}
+
+func (inst *InitializeAccount) SetNodePubkey(nodePubkey solana.PublicKey) *InitializeAccount {
+	inst.NodePubkey = &nodePubkey
+	return inst
+}
+
+func (inst *InitializeAccount) SetAuthorizedVoter(authorizedVoter solana.PublicKey) *InitializeAccount {
+	inst.AuthorizedVoter = &authorizedVoter
+	return inst
+}
+
+func (inst *InitializeAccount) SetAuthorizedWithdrawer(authorizedWithdrawer solana.PublicKey) *InitializeAccount {
+	inst.AuthorizedWithdrawer = &authorizedWithdrawer
+	return inst
+}
+
+func (inst *InitializeAccount) SetCommission(commission uint8) *InitializeAccount {
+	inst.Commission = &commission
+	return inst
+}
+
+func (inst *InitializeAccount) SetVoteAccount(voteAccount solana.PublicKey) *InitializeAccount {
+	inst.AccountMetaSlice[0] = solana.Meta(voteAccount).WRITE()
+	return inst
+}
+
+func (inst *InitializeAccount) SetRentSysvarAccount(rentSysvar solana.PublicKey) *InitializeAccount {
+	inst.AccountMetaSlice[1] = solana.Meta(rentSysvar)
+	return inst
+}
+
+func (inst *InitializeAccount) SetClockSysvarAccount(clockSysvar solana.PublicKey) *InitializeAccount {
+	inst.AccountMetaSlice[2] = solana.Meta(clockSysvar)
+	return inst
+}
+
+func (inst *InitializeAccount) SetNodeAccount(node solana.PublicKey) *InitializeAccount {
+	inst.AccountMetaSlice[3] = solana.Meta(node).SIGNER()
+	return inst
+}
+
+func (inst *InitializeAccount) GetVoteAccount() *solana.AccountMeta { return inst.AccountMetaSlice[0] }
+func (inst *InitializeAccount) GetRentSysvarAccount() *solana.AccountMeta {
+	return inst.AccountMetaSlice[1]
+}
+func (inst *InitializeAccount) GetClockSysvarAccount() *solana.AccountMeta {
+	return inst.AccountMetaSlice[2]
+}
+func (inst *InitializeAccount) GetNodeAccount() *solana.AccountMeta { return inst.AccountMetaSlice[3] }
+
+func (inst InitializeAccount) Build() *Instruction {
+	return &Instruction{BaseVariant: bin.BaseVariant{
+		Impl:   inst,
+		TypeID: bin.TypeIDFromUint32(Instruction_InitializeAccount, bin.LE),
+	}}
+}
+
+func (inst *InitializeAccount) EncodeToTree(parent treeout.Branches) {
+	parent.Child(format.Program(ProgramName, ProgramID)).
+		//
+		ParentFunc(func(programBranch treeout.Branches) {
+			programBranch.Child(format.Instruction("InitializeAccount")).
+				//
+				ParentFunc(func(instructionBranch treeout.Branches) {
+					// Parameters of the instruction:
+					instructionBranch.Child("Params").ParentFunc(func(paramsBranch treeout.Branches) {
+						paramsBranch.Child(format.Param("NodePubkey", inst.NodePubkey))
+						paramsBranch.Child(format.Param("AuthorizedVoter", inst.AuthorizedVoter))
+						paramsBranch.Child(format.Param("AuthorizedWithdrawer", inst.AuthorizedWithdrawer))
+						paramsBranch.Child(format.Param("Commission", inst.Commission))
+					})
+
+					// Accounts of the instruction:
+					instructionBranch.Child("Accounts").ParentFunc(func(accountsBranch treeout.Branches) {
+						accountsBranch.Child(format.Meta("VoteAccount", inst.AccountMetaSlice.Get(0)))
+						accountsBranch.Child(format.Meta(" RentSysvar", inst.AccountMetaSlice.Get(1)))
+						accountsBranch.Child(format.Meta("ClockSysvar", inst.AccountMetaSlice.Get(2)))
+						accountsBranch.Child(format.Meta("       Node", inst.AccountMetaSlice.Get(3)))
+					})
+				})
+		})
+}
+
+// NewInitializeAccountInstructionBuilder creates a new `InitializeAccount` instruction builder.
+func NewInitializeAccountInstructionBuilder() *InitializeAccount {
+	return &InitializeAccount{
+		AccountMetaSlice: make(solana.AccountMetaSlice, 4),
+	}
+}
+
+// NewInitializeAccountInstruction declares a new InitializeAccount instruction with the provided parameters and accounts.
+func NewInitializeAccountInstruction(
+	// Parameters:
+	nodePubkey solana.PublicKey,
+	authorizedVoter solana.PublicKey,
+	authorizedWithdrawer solana.PublicKey,
+	commission uint8,
+	// Accounts:
+	voteAccount solana.PublicKey,
+) *InitializeAccount {
+	return NewInitializeAccountInstructionBuilder().
+		SetNodePubkey(nodePubkey).
+		SetAuthorizedVoter(authorizedVoter).
+		SetAuthorizedWithdrawer(authorizedWithdrawer).
+		SetCommission(commission).
+		SetVoteAccount(voteAccount).
+		SetRentSysvarAccount(solana.SysVarRentPubkey).
+		SetClockSysvarAccount(solana.SysVarClockPubkey).
+		SetNodeAccount(nodePubkey)
+}
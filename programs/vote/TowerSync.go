@@ -0,0 +1,133 @@
+// Copyright 2021 github.com/gagliardetto
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vote
+
+import (
+	"errors"
+	"fmt"
+
+	bin "github.com/gagliardetto/binary"
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/text/format"
+	"github.com/gagliardetto/treeout"
+)
+
+// TowerSync syncs the onchain vote state of the vote account with the local tower.
+type TowerSync struct {
+	// The proposed tower.
+	TowerSync *TowerSyncUpdate
+
+	// [0] = [WRITE] VoteAccount
+	// ··········· Vote account to be updated
+	//
+	// [1] = [SIGNER] VoteAuthority
+	// ··········· Vote authority
+	solana.AccountMetaSlice `bin:"-" borsh_skip:"true"`
+}
+
+func (inst *TowerSync) UnmarshalWithDecoder(dec *bin.Decoder) error {
+	inst.TowerSync = new(TowerSyncUpdate)
+	if err := inst.TowerSync.UnmarshalWithDecoder(dec); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (inst TowerSync) MarshalWithEncoder(encoder *bin.Encoder) error {
+	if err := inst.TowerSync.MarshalWithEncoder(encoder); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (inst *TowerSync) Validate() error {
+	if inst.TowerSync == nil {
+		return errors.New("tower sync parameter is not set")
+	}
+
+	for accIndex, acc := range inst.AccountMetaSlice {
+		if acc == nil {
+			return fmt.Errorf("ins.AccountMetaSlice[%v] is not set", accIndex)
+		}
+	}
+	return nil
+}
+
+func (inst *TowerSync) SetTowerSync(towerSync *TowerSyncUpdate) *TowerSync {
+	inst.TowerSync = towerSync
+	return inst
+}
+
+func (inst *TowerSync) SetVoteAccount(voteAccount solana.PublicKey) *TowerSync {
+	inst.AccountMetaSlice[0] = solana.Meta(voteAccount).WRITE()
+	return inst
+}
+
+func (inst *TowerSync) SetVoteAuthorityAccount(voteAuthority solana.PublicKey) *TowerSync {
+	inst.AccountMetaSlice[1] = solana.Meta(voteAuthority).SIGNER()
+	return inst
+}
+
+func (inst *TowerSync) GetVoteAccount() *solana.AccountMeta          { return inst.AccountMetaSlice[0] }
+func (inst *TowerSync) GetVoteAuthorityAccount() *solana.AccountMeta { return inst.AccountMetaSlice[1] }
+
+func (inst TowerSync) Build() *Instruction {
+	return &Instruction{BaseVariant: bin.BaseVariant{
+		Impl:   inst,
+		TypeID: bin.TypeIDFromUint32(Instruction_TowerSync, bin.LE),
+	}}
+}
+
+func (inst *TowerSync) EncodeToTree(parent treeout.Branches) {
+	parent.Child(format.Program(ProgramName, ProgramID)).
+		//
+		ParentFunc(func(programBranch treeout.Branches) {
+			programBranch.Child(format.Instruction("TowerSync")).
+				//
+				ParentFunc(func(instructionBranch treeout.Branches) {
+					// Parameters of the instruction:
+					instructionBranch.Child("Params").ParentFunc(func(paramsBranch treeout.Branches) {
+						paramsBranch.Child(format.Param("TowerSync", inst.TowerSync))
+					})
+
+					// Accounts of the instruction:
+					instructionBranch.Child("Accounts").ParentFunc(func(accountsBranch treeout.Branches) {
+						accountsBranch.Child(format.Meta("  VoteAccount", inst.AccountMetaSlice.Get(0)))
+						accountsBranch.Child(format.Meta("VoteAuthority", inst.AccountMetaSlice.Get(1)))
+					})
+				})
+		})
+}
+
+// NewTowerSyncInstructionBuilder creates a new `TowerSync` instruction builder.
+func NewTowerSyncInstructionBuilder() *TowerSync {
+	return &TowerSync{
+		AccountMetaSlice: make(solana.AccountMetaSlice, 2),
+	}
+}
+
+// NewTowerSyncInstruction declares a new TowerSync instruction with the provided parameters and accounts.
+func NewTowerSyncInstruction(
+	// Parameters:
+	towerSync *TowerSyncUpdate,
+	// Accounts:
+	voteAccount solana.PublicKey,
+	voteAuthority solana.PublicKey,
+) *TowerSync {
+	return NewTowerSyncInstructionBuilder().
+		SetTowerSync(towerSync).
+		SetVoteAccount(voteAccount).
+		SetVoteAuthorityAccount(voteAuthority)
+}
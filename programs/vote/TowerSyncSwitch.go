@@ -0,0 +1,155 @@
+// Copyright 2021 github.com/gagliardetto
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vote
+
+import (
+	"errors"
+	"fmt"
+
+	bin "github.com/gagliardetto/binary"
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/text/format"
+	"github.com/gagliardetto/treeout"
+)
+
+// TowerSyncSwitch syncs the onchain vote state of the vote account with the local tower, with a switching proof.
+type TowerSyncSwitch struct {
+	// The proposed tower.
+	TowerSync *TowerSyncUpdate
+
+	// Hash of the switching proof.
+	SwitchProofHash *solana.Hash
+
+	// [0] = [WRITE] VoteAccount
+	// ··········· Vote account to be updated
+	//
+	// [1] = [SIGNER] VoteAuthority
+	// ··········· Vote authority
+	solana.AccountMetaSlice `bin:"-" borsh_skip:"true"`
+}
+
+func (inst *TowerSyncSwitch) UnmarshalWithDecoder(dec *bin.Decoder) error {
+	inst.TowerSync = new(TowerSyncUpdate)
+	if err := inst.TowerSync.UnmarshalWithDecoder(dec); err != nil {
+		return err
+	}
+	if err := dec.Decode(&inst.SwitchProofHash); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (inst TowerSyncSwitch) MarshalWithEncoder(encoder *bin.Encoder) error {
+	if err := inst.TowerSync.MarshalWithEncoder(encoder); err != nil {
+		return err
+	}
+	if err := encoder.WriteBytes(inst.SwitchProofHash[:], false); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (inst *TowerSyncSwitch) Validate() error {
+	if inst.TowerSync == nil {
+		return errors.New("tower sync parameter is not set")
+	}
+	if inst.SwitchProofHash == nil {
+		return errors.New("switch proof hash parameter is not set")
+	}
+
+	for accIndex, acc := range inst.AccountMetaSlice {
+		if acc == nil {
+			return fmt.Errorf("ins.AccountMetaSlice[%v] is not set", accIndex)
+		}
+	}
+	return nil
+}
+
+func (inst *TowerSyncSwitch) SetTowerSync(towerSync *TowerSyncUpdate) *TowerSyncSwitch {
+	inst.TowerSync = towerSync
+	return inst
+}
+
+func (inst *TowerSyncSwitch) SetSwitchProofHash(switchProofHash solana.Hash) *TowerSyncSwitch {
+	inst.SwitchProofHash = &switchProofHash
+	return inst
+}
+
+func (inst *TowerSyncSwitch) SetVoteAccount(voteAccount solana.PublicKey) *TowerSyncSwitch {
+	inst.AccountMetaSlice[0] = solana.Meta(voteAccount).WRITE()
+	return inst
+}
+
+func (inst *TowerSyncSwitch) SetVoteAuthorityAccount(voteAuthority solana.PublicKey) *TowerSyncSwitch {
+	inst.AccountMetaSlice[1] = solana.Meta(voteAuthority).SIGNER()
+	return inst
+}
+
+func (inst *TowerSyncSwitch) GetVoteAccount() *solana.AccountMeta { return inst.AccountMetaSlice[0] }
+func (inst *TowerSyncSwitch) GetVoteAuthorityAccount() *solana.AccountMeta {
+	return inst.AccountMetaSlice[1]
+}
+
+func (inst TowerSyncSwitch) Build() *Instruction {
+	return &Instruction{BaseVariant: bin.BaseVariant{
+		Impl:   inst,
+		TypeID: bin.TypeIDFromUint32(Instruction_TowerSyncSwitch, bin.LE),
+	}}
+}
+
+func (inst *TowerSyncSwitch) EncodeToTree(parent treeout.Branches) {
+	parent.Child(format.Program(ProgramName, ProgramID)).
+		//
+		ParentFunc(func(programBranch treeout.Branches) {
+			programBranch.Child(format.Instruction("TowerSyncSwitch")).
+				//
+				ParentFunc(func(instructionBranch treeout.Branches) {
+					// Parameters of the instruction:
+					instructionBranch.Child("Params").ParentFunc(func(paramsBranch treeout.Branches) {
+						paramsBranch.Child(format.Param("TowerSync", inst.TowerSync))
+						paramsBranch.Child(format.Param("SwitchProofHash", inst.SwitchProofHash))
+					})
+
+					// Accounts of the instruction:
+					instructionBranch.Child("Accounts").ParentFunc(func(accountsBranch treeout.Branches) {
+						accountsBranch.Child(format.Meta("  VoteAccount", inst.AccountMetaSlice.Get(0)))
+						accountsBranch.Child(format.Meta("VoteAuthority", inst.AccountMetaSlice.Get(1)))
+					})
+				})
+		})
+}
+
+// NewTowerSyncSwitchInstructionBuilder creates a new `TowerSyncSwitch` instruction builder.
+func NewTowerSyncSwitchInstructionBuilder() *TowerSyncSwitch {
+	return &TowerSyncSwitch{
+		AccountMetaSlice: make(solana.AccountMetaSlice, 2),
+	}
+}
+
+// NewTowerSyncSwitchInstruction declares a new TowerSyncSwitch instruction with the provided parameters and accounts.
+func NewTowerSyncSwitchInstruction(
+	// Parameters:
+	towerSync *TowerSyncUpdate,
+	switchProofHash solana.Hash,
+	// Accounts:
+	voteAccount solana.PublicKey,
+	voteAuthority solana.PublicKey,
+) *TowerSyncSwitch {
+	return NewTowerSyncSwitchInstructionBuilder().
+		SetTowerSync(towerSync).
+		SetSwitchProofHash(switchProofHash).
+		SetVoteAccount(voteAccount).
+		SetVoteAuthorityAccount(voteAuthority)
+}
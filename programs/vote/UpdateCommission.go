@@ -0,0 +1,134 @@
+// Copyright 2021 github.com/gagliardetto
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vote
+
+import (
+	"errors"
+	"fmt"
+
+	bin "github.com/gagliardetto/binary"
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/text/format"
+	"github.com/gagliardetto/treeout"
+)
+
+// UpdateCommission updates the commission of a vote account.
+type UpdateCommission struct {
+	// Commission, in percent.
+	Commission *uint8
+
+	// [0] = [WRITE] VoteAccount
+	// ··········· Vote account to be updated
+	//
+	// [1] = [SIGNER] WithdrawAuthority
+	// ··········· Withdraw authority
+	solana.AccountMetaSlice `bin:"-" borsh_skip:"true"`
+}
+
+func (inst *UpdateCommission) UnmarshalWithDecoder(dec *bin.Decoder) error {
+	if err := dec.Decode(&inst.Commission); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (inst UpdateCommission) MarshalWithEncoder(encoder *bin.Encoder) error {
+	if err := encoder.WriteUint8(*inst.Commission); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (inst *UpdateCommission) Validate() error {
+	if inst.Commission == nil {
+		return errors.New("commission parameter is not set")
+	}
+
+	for accIndex, acc := range inst.AccountMetaSlice {
+		if acc == nil {
+			return fmt.Errorf("ins.AccountMetaSlice[%v] is not set", accIndex)
+		}
+	}
+	return nil
+}
+
+func (inst *UpdateCommission) SetCommission(commission uint8) *UpdateCommission {
+	inst.Commission = &commission
+	return inst
+}
+
+func (inst *UpdateCommission) SetVoteAccount(voteAccount solana.PublicKey) *UpdateCommission {
+	inst.AccountMetaSlice[0] = solana.Meta(voteAccount).WRITE()
+	return inst
+}
+
+func (inst *UpdateCommission) SetWithdrawAuthorityAccount(withdrawAuthority solana.PublicKey) *UpdateCommission {
+	inst.AccountMetaSlice[1] = solana.Meta(withdrawAuthority).SIGNER()
+	return inst
+}
+
+func (inst *UpdateCommission) GetVoteAccount() *solana.AccountMeta { return inst.AccountMetaSlice[0] }
+func (inst *UpdateCommission) GetWithdrawAuthorityAccount() *solana.AccountMeta {
+	return inst.AccountMetaSlice[1]
+}
+
+func (inst UpdateCommission) Build() *Instruction {
+	return &Instruction{BaseVariant: bin.BaseVariant{
+		Impl:   inst,
+		TypeID: bin.TypeIDFromUint32(Instruction_UpdateCommission, bin.LE),
+	}}
+}
+
+func (inst *UpdateCommission) EncodeToTree(parent treeout.Branches) {
+	parent.Child(format.Program(ProgramName, ProgramID)).
+		//
+		ParentFunc(func(programBranch treeout.Branches) {
+			programBranch.Child(format.Instruction("UpdateCommission")).
+				//
+				ParentFunc(func(instructionBranch treeout.Branches) {
+					// Parameters of the instruction:
+					instructionBranch.Child("Params").ParentFunc(func(paramsBranch treeout.Branches) {
+						paramsBranch.Child(format.Param("Commission", inst.Commission))
+					})
+
+					// Accounts of the instruction:
+					instructionBranch.Child("Accounts").ParentFunc(func(accountsBranch treeout.Branches) {
+						accountsBranch.Child(format.Meta("      VoteAccount", inst.AccountMetaSlice.Get(0)))
+						accountsBranch.Child(format.Meta("WithdrawAuthority", inst.AccountMetaSlice.Get(1)))
+					})
+				})
+		})
+}
+
+// NewUpdateCommissionInstructionBuilder creates a new `UpdateCommission` instruction builder.
+func NewUpdateCommissionInstructionBuilder() *UpdateCommission {
+	return &UpdateCommission{
+		AccountMetaSlice: make(solana.AccountMetaSlice, 2),
+	}
+}
+
+// NewUpdateCommissionInstruction declares a new UpdateCommission instruction with the provided parameters and accounts.
+func NewUpdateCommissionInstruction(
+	// Parameters:
+	commission uint8,
+	// Accounts:
+	voteAccount solana.PublicKey,
+	withdrawAuthority solana.PublicKey,
+) *UpdateCommission {
+	return NewUpdateCommissionInstructionBuilder().
+		SetCommission(commission).
+		SetVoteAccount(voteAccount).
+		SetWithdrawAuthorityAccount(withdrawAuthority)
+}
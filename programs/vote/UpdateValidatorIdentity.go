@@ -0,0 +1,127 @@
+// Copyright 2021 github.com/gagliardetto
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vote
+
+import (
+	"fmt"
+
+	bin "github.com/gagliardetto/binary"
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/text/format"
+	"github.com/gagliardetto/treeout"
+)
+
+// UpdateValidatorIdentity updates the validator identity (node_pubkey) of a vote account.
+type UpdateValidatorIdentity struct {
+	// [0] = [WRITE] VoteAccount
+	// ··········· Vote account to be updated
+	//
+	// [1] = [SIGNER] Node
+	// ··········· New validator identity (node_pubkey)
+	//
+	// [2] = [SIGNER] WithdrawAuthority
+	// ··········· Withdraw authority
+	solana.AccountMetaSlice `bin:"-" borsh_skip:"true"`
+}
+
+func (inst *UpdateValidatorIdentity) UnmarshalWithDecoder(dec *bin.Decoder) error {
+	return nil
+}
+
+func (inst UpdateValidatorIdentity) MarshalWithEncoder(encoder *bin.Encoder) error {
+	return nil
+}
+
+func (inst *UpdateValidatorIdentity) Validate() error {
+	for accIndex, acc := range inst.AccountMetaSlice {
+		if acc == nil {
+			return fmt.Errorf("ins.AccountMetaSlice[%v] is not set", accIndex)
+		}
+	}
+	return nil
+}
+
+func (inst *UpdateValidatorIdentity) SetVoteAccount(voteAccount solana.PublicKey) *UpdateValidatorIdentity {
+	inst.AccountMetaSlice[0] = solana.Meta(voteAccount).WRITE()
+	return inst
+}
+
+func (inst *UpdateValidatorIdentity) SetNodeAccount(node solana.PublicKey) *UpdateValidatorIdentity {
+	inst.AccountMetaSlice[1] = solana.Meta(node).SIGNER()
+	return inst
+}
+
+func (inst *UpdateValidatorIdentity) SetWithdrawAuthorityAccount(withdrawAuthority solana.PublicKey) *UpdateValidatorIdentity {
+	inst.AccountMetaSlice[2] = solana.Meta(withdrawAuthority).SIGNER()
+	return inst
+}
+
+func (inst *UpdateValidatorIdentity) GetVoteAccount() *solana.AccountMeta {
+	return inst.AccountMetaSlice[0]
+}
+func (inst *UpdateValidatorIdentity) GetNodeAccount() *solana.AccountMeta {
+	return inst.AccountMetaSlice[1]
+}
+func (inst *UpdateValidatorIdentity) GetWithdrawAuthorityAccount() *solana.AccountMeta {
+	return inst.AccountMetaSlice[2]
+}
+
+func (inst UpdateValidatorIdentity) Build() *Instruction {
+	return &Instruction{BaseVariant: bin.BaseVariant{
+		Impl:   inst,
+		TypeID: bin.TypeIDFromUint32(Instruction_UpdateValidatorIdentity, bin.LE),
+	}}
+}
+
+func (inst *UpdateValidatorIdentity) EncodeToTree(parent treeout.Branches) {
+	parent.Child(format.Program(ProgramName, ProgramID)).
+		//
+		ParentFunc(func(programBranch treeout.Branches) {
+			programBranch.Child(format.Instruction("UpdateValidatorIdentity")).
+				//
+				ParentFunc(func(instructionBranch treeout.Branches) {
+					// Parameters of the instruction:
+					instructionBranch.Child("Params").ParentFunc(func(paramsBranch treeout.Branches) {
+					})
+
+					// Accounts of the instruction:
+					instructionBranch.Child("Accounts").ParentFunc(func(accountsBranch treeout.Branches) {
+						accountsBranch.Child(format.Meta("      VoteAccount", inst.AccountMetaSlice.Get(0)))
+						accountsBranch.Child(format.Meta("             Node", inst.AccountMetaSlice.Get(1)))
+						accountsBranch.Child(format.Meta("WithdrawAuthority", inst.AccountMetaSlice.Get(2)))
+					})
+				})
+		})
+}
+
+// NewUpdateValidatorIdentityInstructionBuilder creates a new `UpdateValidatorIdentity` instruction builder.
+func NewUpdateValidatorIdentityInstructionBuilder() *UpdateValidatorIdentity {
+	return &UpdateValidatorIdentity{
+		AccountMetaSlice: make(solana.AccountMetaSlice, 3),
+	}
+}
+
+// NewUpdateValidatorIdentityInstruction declares a new UpdateValidatorIdentity instruction with the provided parameters and accounts.
+func NewUpdateValidatorIdentityInstruction(
+	// Accounts:
+	voteAccount solana.PublicKey,
+	node solana.PublicKey,
+	withdrawAuthority solana.PublicKey,
+) *UpdateValidatorIdentity {
+	return NewUpdateValidatorIdentityInstructionBuilder().
+		SetVoteAccount(voteAccount).
+		SetNodeAccount(node).
+		SetWithdrawAuthorityAccount(withdrawAuthority)
+}
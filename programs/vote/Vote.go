@@ -43,39 +43,62 @@ type Vote struct {
 	solana.AccountMetaSlice `bin:"-" borsh_skip:"true"`
 }
 
-func (v *Vote) UnmarshalWithDecoder(dec *bin.Decoder) error {
-	v.Slots = nil
+func (v *Vote) UnmarshalWithDecoder(dec *bin.Decoder) (err error) {
+	v.Slots, v.Hash, v.Timestamp, err = decodeVote(dec)
+	return err
+}
+
+func (v Vote) MarshalWithEncoder(encoder *bin.Encoder) error {
+	return encodeVote(encoder, v.Slots, v.Hash, v.Timestamp)
+}
+
+func decodeVote(dec *bin.Decoder) (slots []uint64, hash solana.Hash, timestamp *int64, err error) {
 	var numSlots uint64
-	if err := dec.Decode(&numSlots); err != nil {
-		return err
+	if err = dec.Decode(&numSlots); err != nil {
+		return
 	}
 	for i := uint64(0); i < numSlots; i++ {
 		var slot uint64
-		if err := dec.Decode(&slot); err != nil {
-			return err
+		if err = dec.Decode(&slot); err != nil {
+			return
 		}
-		v.Slots = append(v.Slots, slot)
+		slots = append(slots, slot)
 	}
-	if err := dec.Decode(&v.Hash); err != nil {
-		return err
+	if err = dec.Decode(&hash); err != nil {
+		return
 	}
 	var timestampVariant uint8
-	if err := dec.Decode(&timestampVariant); err != nil {
-		return err
+	if err = dec.Decode(&timestampVariant); err != nil {
+		return
 	}
 	switch timestampVariant {
 	case 0:
 		break
 	case 1:
 		var ts int64
-		if err := dec.Decode(&ts); err != nil {
-			return err
+		if err = dec.Decode(&ts); err != nil {
+			return
 		}
-		v.Timestamp = &ts
+		timestamp = &ts
 	default:
-		return fmt.Errorf("invalid vote timestamp variant %#08x", timestampVariant)
+		err = fmt.Errorf("invalid vote timestamp variant %#08x", timestampVariant)
 	}
-	return nil
+	return
+}
+
+func encodeVote(encoder *bin.Encoder, slots []uint64, hash solana.Hash, timestamp *int64) error {
+	if err := encoder.WriteUint64(uint64(len(slots)), bin.LE); err != nil {
+		return err
+	}
+	for _, slot := range slots {
+		if err := encoder.WriteUint64(slot, bin.LE); err != nil {
+			return err
+		}
+	}
+	if err := encoder.WriteBytes(hash[:], false); err != nil {
+		return err
+	}
+	return encodeOptionalInt64(encoder, timestamp)
 }
 
 func (inst *Vote) Validate() error {
@@ -114,3 +137,78 @@ func (inst *Vote) EncodeToTree(parent treeout.Branches) {
 				})
 		})
 }
+
+func (inst *Vote) SetSlots(slots ...uint64) *Vote {
+	inst.Slots = slots
+	return inst
+}
+
+func (inst *Vote) SetHash(hash solana.Hash) *Vote {
+	inst.Hash = hash
+	return inst
+}
+
+func (inst *Vote) SetTimestamp(timestamp int64) *Vote {
+	inst.Timestamp = &timestamp
+	return inst
+}
+
+func (inst *Vote) SetVoteAccount(voteAccount solana.PublicKey) *Vote {
+	inst.AccountMetaSlice[0] = solana.Meta(voteAccount).WRITE()
+	return inst
+}
+
+func (inst *Vote) SetSlotHashesSysvarAccount(slotHashesSysvar solana.PublicKey) *Vote {
+	inst.AccountMetaSlice[1] = solana.Meta(slotHashesSysvar)
+	return inst
+}
+
+func (inst *Vote) SetClockSysvarAccount(clockSysvar solana.PublicKey) *Vote {
+	inst.AccountMetaSlice[2] = solana.Meta(clockSysvar)
+	return inst
+}
+
+func (inst *Vote) SetVoteAuthorityAccount(voteAuthority solana.PublicKey) *Vote {
+	inst.AccountMetaSlice[3] = solana.Meta(voteAuthority).SIGNER()
+	return inst
+}
+
+func (inst *Vote) GetVoteAccount() *solana.AccountMeta             { return inst.AccountMetaSlice[0] }
+func (inst *Vote) GetSlotHashesSysvarAccount() *solana.AccountMeta { return inst.AccountMetaSlice[1] }
+func (inst *Vote) GetClockSysvarAccount() *solana.AccountMeta      { return inst.AccountMetaSlice[2] }
+func (inst *Vote) GetVoteAuthorityAccount() *solana.AccountMeta    { return inst.AccountMetaSlice[3] }
+
+func (inst Vote) Build() *Instruction {
+	return &Instruction{BaseVariant: bin.BaseVariant{
+		Impl:   inst,
+		TypeID: bin.TypeIDFromUint32(Instruction_Vote, bin.LE),
+	}}
+}
+
+// NewVoteInstructionBuilder creates a new `Vote` instruction builder.
+func NewVoteInstructionBuilder() *Vote {
+	return &Vote{
+		AccountMetaSlice: make(solana.AccountMetaSlice, 4),
+	}
+}
+
+// NewVoteInstruction declares a new Vote instruction with the provided parameters and accounts.
+func NewVoteInstruction(
+	// Parameters:
+	slots []uint64,
+	hash solana.Hash,
+	timestamp *int64,
+	// Accounts:
+	voteAccount solana.PublicKey,
+	voteAuthority solana.PublicKey,
+) *Vote {
+	inst := NewVoteInstructionBuilder().
+		SetSlots(slots...).
+		SetHash(hash).
+		SetVoteAccount(voteAccount).
+		SetSlotHashesSysvarAccount(solana.SysVarSlotHashesPubkey).
+		SetClockSysvarAccount(solana.SysVarClockPubkey).
+		SetVoteAuthorityAccount(voteAuthority)
+	inst.Timestamp = timestamp
+	return inst
+}
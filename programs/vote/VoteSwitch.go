@@ -0,0 +1,186 @@
+// Copyright 2021 github.com/gagliardetto
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vote
+
+import (
+	"fmt"
+	"time"
+
+	bin "github.com/gagliardetto/binary"
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/text/format"
+	"github.com/gagliardetto/treeout"
+)
+
+// VoteSwitch is a Vote with a switching proof.
+type VoteSwitch struct {
+	Slots     []uint64
+	Hash      solana.Hash
+	Timestamp *int64
+
+	// Hash of the switching proof.
+	SwitchProofHash solana.Hash
+
+	// [0] = [WRITE] VoteAccount
+	// ··········· Vote account to vote with
+	//
+	// [1] = [] SysVarSlotHashes
+	// ··········· Slot hashes sysvar
+	//
+	// [2] = [] SysVarClock
+	// ··········· Clock sysvar
+	//
+	// [3] = [SIGNER] VoteAuthority
+	// ··········· Vote authority
+	solana.AccountMetaSlice `bin:"-" borsh_skip:"true"`
+}
+
+func (v *VoteSwitch) UnmarshalWithDecoder(dec *bin.Decoder) (err error) {
+	if v.Slots, v.Hash, v.Timestamp, err = decodeVote(dec); err != nil {
+		return err
+	}
+	return dec.Decode(&v.SwitchProofHash)
+}
+
+func (v VoteSwitch) MarshalWithEncoder(encoder *bin.Encoder) error {
+	if err := encodeVote(encoder, v.Slots, v.Hash, v.Timestamp); err != nil {
+		return err
+	}
+	return encoder.WriteBytes(v.SwitchProofHash[:], false)
+}
+
+func (inst *VoteSwitch) Validate() error {
+	// Check whether all accounts are set:
+	for accIndex, acc := range inst.AccountMetaSlice {
+		if acc == nil {
+			return fmt.Errorf("ins.AccountMetaSlice[%v] is not set", accIndex)
+		}
+	}
+	return nil
+}
+
+func (inst *VoteSwitch) EncodeToTree(parent treeout.Branches) {
+	parent.Child(format.Program(ProgramName, ProgramID)).
+		ParentFunc(func(programBranch treeout.Branches) {
+			programBranch.Child(format.Instruction("VoteSwitch")).
+				ParentFunc(func(instructionBranch treeout.Branches) {
+					// Parameters of the instruction:
+					instructionBranch.Child("Params").ParentFunc(func(paramsBranch treeout.Branches) {
+						paramsBranch.Child(format.Param("Slots", inst.Slots))
+						paramsBranch.Child(format.Param("Hash", inst.Hash))
+						var ts time.Time
+						if inst.Timestamp != nil {
+							ts = time.Unix(*inst.Timestamp, 0).UTC()
+						}
+						paramsBranch.Child(format.Param("Timestamp", ts))
+						paramsBranch.Child(format.Param("SwitchProofHash", inst.SwitchProofHash))
+					})
+
+					// Accounts of the instruction:
+					instructionBranch.Child("Accounts").ParentFunc(func(accountsBranch treeout.Branches) {
+						accountsBranch.Child(format.Meta("Vote Account      ", inst.AccountMetaSlice[0]))
+						accountsBranch.Child(format.Meta("Slot Hashes Sysvar", inst.AccountMetaSlice[1]))
+						accountsBranch.Child(format.Meta("Clock Sysvar      ", inst.AccountMetaSlice[2]))
+						accountsBranch.Child(format.Meta("Vote Authority    ", inst.AccountMetaSlice[3]))
+					})
+				})
+		})
+}
+
+func (inst *VoteSwitch) SetSlots(slots ...uint64) *VoteSwitch {
+	inst.Slots = slots
+	return inst
+}
+
+func (inst *VoteSwitch) SetHash(hash solana.Hash) *VoteSwitch {
+	inst.Hash = hash
+	return inst
+}
+
+func (inst *VoteSwitch) SetTimestamp(timestamp int64) *VoteSwitch {
+	inst.Timestamp = &timestamp
+	return inst
+}
+
+func (inst *VoteSwitch) SetSwitchProofHash(switchProofHash solana.Hash) *VoteSwitch {
+	inst.SwitchProofHash = switchProofHash
+	return inst
+}
+
+func (inst *VoteSwitch) SetVoteAccount(voteAccount solana.PublicKey) *VoteSwitch {
+	inst.AccountMetaSlice[0] = solana.Meta(voteAccount).WRITE()
+	return inst
+}
+
+func (inst *VoteSwitch) SetSlotHashesSysvarAccount(slotHashesSysvar solana.PublicKey) *VoteSwitch {
+	inst.AccountMetaSlice[1] = solana.Meta(slotHashesSysvar)
+	return inst
+}
+
+func (inst *VoteSwitch) SetClockSysvarAccount(clockSysvar solana.PublicKey) *VoteSwitch {
+	inst.AccountMetaSlice[2] = solana.Meta(clockSysvar)
+	return inst
+}
+
+func (inst *VoteSwitch) SetVoteAuthorityAccount(voteAuthority solana.PublicKey) *VoteSwitch {
+	inst.AccountMetaSlice[3] = solana.Meta(voteAuthority).SIGNER()
+	return inst
+}
+
+func (inst *VoteSwitch) GetVoteAccount() *solana.AccountMeta { return inst.AccountMetaSlice[0] }
+func (inst *VoteSwitch) GetSlotHashesSysvarAccount() *solana.AccountMeta {
+	return inst.AccountMetaSlice[1]
+}
+func (inst *VoteSwitch) GetClockSysvarAccount() *solana.AccountMeta { return inst.AccountMetaSlice[2] }
+func (inst *VoteSwitch) GetVoteAuthorityAccount() *solana.AccountMeta {
+	return inst.AccountMetaSlice[3]
+}
+
+func (inst VoteSwitch) Build() *Instruction {
+	return &Instruction{BaseVariant: bin.BaseVariant{
+		Impl:   inst,
+		TypeID: bin.TypeIDFromUint32(Instruction_VoteSwitch, bin.LE),
+	}}
+}
+
+// NewVoteSwitchInstructionBuilder creates a new `VoteSwitch` instruction builder.
+func NewVoteSwitchInstructionBuilder() *VoteSwitch {
+	return &VoteSwitch{
+		AccountMetaSlice: make(solana.AccountMetaSlice, 4),
+	}
+}
+
+// NewVoteSwitchInstruction declares a new VoteSwitch instruction with the provided parameters and accounts.
+func NewVoteSwitchInstruction(
+	// Parameters:
+	slots []uint64,
+	hash solana.Hash,
+	timestamp *int64,
+	switchProofHash solana.Hash,
+	// Accounts:
+	voteAccount solana.PublicKey,
+	voteAuthority solana.PublicKey,
+) *VoteSwitch {
+	inst := NewVoteSwitchInstructionBuilder().
+		SetSlots(slots...).
+		SetHash(hash).
+		SetSwitchProofHash(switchProofHash).
+		SetVoteAccount(voteAccount).
+		SetSlotHashesSysvarAccount(solana.SysVarSlotHashesPubkey).
+		SetClockSysvarAccount(solana.SysVarClockPubkey).
+		SetVoteAuthorityAccount(voteAuthority)
+	inst.Timestamp = timestamp
+	return inst
+}
@@ -52,7 +52,7 @@ func (v *Withdraw) UnmarshalWithDecoder(dec *bin.Decoder) error {
 	return nil
 }
 
-func (inst *Withdraw) MarshalWithEncoder(encoder *bin.Encoder) error {
+func (inst Withdraw) MarshalWithEncoder(encoder *bin.Encoder) error {
 	// Serialize `Lamports` param:
 	{
 		err := encoder.Encode(*inst.Lamports)
@@ -110,6 +110,13 @@ func (inst *Withdraw) SetLamports(lamports uint64) *Withdraw {
 	return inst
 }
 
+func (inst Withdraw) Build() *Instruction {
+	return &Instruction{BaseVariant: bin.BaseVariant{
+		Impl:   inst,
+		TypeID: bin.TypeIDFromUint32(Instruction_Withdraw, bin.LE),
+	}}
+}
+
 func (inst *Withdraw) EncodeToTree(parent treeout.Branches) {
 	parent.Child(format.Program(ProgramName, ProgramID)).
 		//
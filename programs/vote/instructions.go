@@ -39,6 +39,80 @@ func init() {
 	solana.RegisterInstructionDecoder(ProgramID, registryDecodeInstruction)
 }
 
+const (
+	// Initialize a vote account
+	Instruction_InitializeAccount uint32 = iota
+	// Authorize a key to send votes or issue a withdrawal
+	Instruction_Authorize
+	// A Vote instruction with recent votes
+	Instruction_Vote
+	// Withdraw some amount of funds
+	Instruction_Withdraw
+	// Update the vote account's validator identity (node_pubkey)
+	Instruction_UpdateValidatorIdentity
+	// Update the commission for the vote account
+	Instruction_UpdateCommission
+	// A Vote instruction with recent votes, and a switching proof
+	Instruction_VoteSwitch
+	// Authorize a key to send votes or issue a withdrawal, with the new authority as signer
+	Instruction_AuthorizeChecked
+	// Update the onchain vote state for the signer
+	Instruction_UpdateVoteState
+	// Update the onchain vote state for the signer, with a switching proof
+	Instruction_UpdateVoteStateSwitch
+	// Authorize a key to send votes or issue a withdrawal, when the current authority is a derived key
+	Instruction_AuthorizeWithSeed
+	// Authorize a key to send votes or issue a withdrawal, when the current authority is a derived key, with the new authority as signer
+	Instruction_AuthorizeCheckedWithSeed
+	// Update the onchain vote state for the signer, with the compact serialization
+	Instruction_CompactUpdateVoteState
+	// Update the onchain vote state for the signer, with the compact serialization and a switching proof
+	Instruction_CompactUpdateVoteStateSwitch
+	// Sync the onchain vote state with the local tower
+	Instruction_TowerSync
+	// Sync the onchain vote state with the local tower, with a switching proof
+	Instruction_TowerSyncSwitch
+)
+
+func InstructionIDToName(id uint32) string {
+	switch id {
+	case Instruction_InitializeAccount:
+		return "InitializeAccount"
+	case Instruction_Authorize:
+		return "Authorize"
+	case Instruction_Vote:
+		return "Vote"
+	case Instruction_Withdraw:
+		return "Withdraw"
+	case Instruction_UpdateValidatorIdentity:
+		return "UpdateValidatorIdentity"
+	case Instruction_UpdateCommission:
+		return "UpdateCommission"
+	case Instruction_VoteSwitch:
+		return "VoteSwitch"
+	case Instruction_AuthorizeChecked:
+		return "AuthorizeChecked"
+	case Instruction_UpdateVoteState:
+		return "UpdateVoteState"
+	case Instruction_UpdateVoteStateSwitch:
+		return "UpdateVoteStateSwitch"
+	case Instruction_AuthorizeWithSeed:
+		return "AuthorizeWithSeed"
+	case Instruction_AuthorizeCheckedWithSeed:
+		return "AuthorizeCheckedWithSeed"
+	case Instruction_CompactUpdateVoteState:
+		return "CompactUpdateVoteState"
+	case Instruction_CompactUpdateVoteStateSwitch:
+		return "CompactUpdateVoteStateSwitch"
+	case Instruction_TowerSync:
+		return "TowerSync"
+	case Instruction_TowerSyncSwitch:
+		return "TowerSyncSwitch"
+	default:
+		return ""
+	}
+}
+
 type Instruction struct {
 	bin.BaseVariant
 }
@@ -66,6 +140,42 @@ var InstructionImplDef = bin.NewVariantDefinition(
 		{
 			"Withdraw", (*Withdraw)(nil),
 		},
+		{
+			"UpdateValidatorIdentity", (*UpdateValidatorIdentity)(nil),
+		},
+		{
+			"UpdateCommission", (*UpdateCommission)(nil),
+		},
+		{
+			"VoteSwitch", (*VoteSwitch)(nil),
+		},
+		{
+			"AuthorizeChecked", (*AuthorizeChecked)(nil),
+		},
+		{
+			"UpdateVoteState", (*UpdateVoteState)(nil),
+		},
+		{
+			"UpdateVoteStateSwitch", (*UpdateVoteStateSwitch)(nil),
+		},
+		{
+			"AuthorizeWithSeed", (*AuthorizeWithSeed)(nil),
+		},
+		{
+			"AuthorizeCheckedWithSeed", (*AuthorizeCheckedWithSeed)(nil),
+		},
+		{
+			"CompactUpdateVoteState", (*CompactUpdateVoteState)(nil),
+		},
+		{
+			"CompactUpdateVoteStateSwitch", (*CompactUpdateVoteStateSwitch)(nil),
+		},
+		{
+			"TowerSync", (*TowerSync)(nil),
+		},
+		{
+			"TowerSyncSwitch", (*TowerSyncSwitch)(nil),
+		},
 	},
 )
 
@@ -0,0 +1,108 @@
+// Copyright 2021 github.com/gagliardetto
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vote
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gagliardetto/solana-go"
+)
+
+func TestInstructions_RoundTrip(t *testing.T) {
+	voteAccount := solana.NewWallet().PublicKey()
+	authority := solana.NewWallet().PublicKey()
+	newAuthority := solana.NewWallet().PublicKey()
+	hash := solana.Hash{1, 2, 3}
+	root := uint64(90)
+	timestamp := int64(1_700_000_000)
+	update := &VoteStateUpdate{
+		Lockouts:  []Lockout{{Slot: 100, ConfirmationCount: 3}, {Slot: 101, ConfirmationCount: 2}, {Slot: 200, ConfirmationCount: 1}},
+		Root:      &root,
+		Hash:      hash,
+		Timestamp: &timestamp,
+	}
+	towerSync := &TowerSyncUpdate{
+		Lockouts:  update.Lockouts,
+		Hash:      hash,
+		Timestamp: &timestamp,
+		BlockID:   solana.Hash{4, 5, 6},
+	}
+
+	for _, inst := range []interface {
+		Build() *Instruction
+		Validate() error
+	}{
+		NewInitializeAccountInstruction(authority, authority, newAuthority, 10, voteAccount),
+		NewAuthorizeInstruction(newAuthority, VoteAuthorizeWithdrawer, voteAccount, authority),
+		NewVoteInstruction([]uint64{1, 2}, hash, &timestamp, voteAccount, authority),
+		NewWithdrawInstructionBuilder().SetLamports(42).SetVoteAccount(voteAccount).SetRecipientAccount(newAuthority).SetWithdrawAuthorityAccount(authority),
+		NewUpdateValidatorIdentityInstruction(voteAccount, newAuthority, authority),
+		NewUpdateCommissionInstruction(5, voteAccount, authority),
+		NewVoteSwitchInstruction([]uint64{3}, hash, nil, solana.Hash{9}, voteAccount, authority),
+		NewAuthorizeCheckedInstruction(VoteAuthorizeVoter, voteAccount, authority, newAuthority),
+		NewUpdateVoteStateInstruction(update, voteAccount, authority),
+		NewUpdateVoteStateSwitchInstruction(update, solana.Hash{9}, voteAccount, authority),
+		NewAuthorizeWithSeedInstruction(VoteAuthorizeVoter, solana.SystemProgramID, "seed", newAuthority, voteAccount, authority),
+		NewAuthorizeCheckedWithSeedInstruction(VoteAuthorizeWithdrawer, solana.SystemProgramID, "seed", voteAccount, authority, newAuthority),
+		NewCompactUpdateVoteStateInstruction(update, voteAccount, authority),
+		NewCompactUpdateVoteStateSwitchInstruction(update, solana.Hash{9}, voteAccount, authority),
+		NewTowerSyncInstruction(towerSync, voteAccount, authority),
+		NewTowerSyncSwitchInstruction(towerSync, solana.Hash{9}, voteAccount, authority),
+	} {
+		require.NoError(t, inst.Validate())
+		built := inst.Build()
+		data, err := built.Data()
+		require.NoError(t, err)
+
+		decoded, err := DecodeInstruction(built.Accounts(), data)
+		require.NoError(t, err)
+		assert.Equal(t, built.TypeID, decoded.TypeID)
+		assert.Equal(t, inst, decoded.Impl, InstructionIDToName(built.TypeID.Uint32()))
+	}
+}
+
+func TestUpdateCommission_Data(t *testing.T) {
+	data, err := NewUpdateCommissionInstruction(7, solana.PublicKey{}, solana.PublicKey{}).Build().Data()
+	require.NoError(t, err)
+	assert.Equal(t, []byte{5, 0, 0, 0, 7}, data)
+}
+
+func TestCompactUpdateVoteState_Data(t *testing.T) {
+	root := uint64(10)
+	update := &VoteStateUpdate{
+		Lockouts: []Lockout{{Slot: 12, ConfirmationCount: 2}, {Slot: 300, ConfirmationCount: 1}},
+		Root:     &root,
+	}
+	data, err := NewCompactUpdateVoteStateInstruction(update, solana.PublicKey{}, solana.PublicKey{}).Build().Data()
+	require.NoError(t, err)
+
+	expected := []byte{12, 0, 0, 0, 10, 0, 0, 0, 0, 0, 0, 0, 2, 2, 2, 0xa0, 0x02, 1}
+	expected = append(expected, make([]byte, 32)...)
+	expected = append(expected, 0)
+	assert.Equal(t, expected, data)
+
+	// Without root, the offsets start from slot 0.
+	update.Root = nil
+	data, err = NewCompactUpdateVoteStateInstruction(update, solana.PublicKey{}, solana.PublicKey{}).Build().Data()
+	require.NoError(t, err)
+	assert.Equal(t, []byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 2, 12, 2}, data[4:15])
+
+	update.Lockouts = []Lockout{{Slot: 12}, {Slot: 11}}
+	_, err = NewCompactUpdateVoteStateInstruction(update, solana.PublicKey{}, solana.PublicKey{}).Build().Data()
+	assert.Error(t, err)
+}
@@ -0,0 +1,361 @@
+// Copyright 2021 github.com/gagliardetto
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vote
+
+import (
+	"fmt"
+
+	bin "github.com/gagliardetto/binary"
+
+	"github.com/gagliardetto/solana-go"
+)
+
+// VoteStateVersion is the version of the serialization of a vote account.
+type VoteStateVersion uint32
+
+const (
+	VoteStateV0_23_5 VoteStateVersion = iota
+	VoteStateV1_14_11
+	VoteStateCurrent
+)
+
+func (v VoteStateVersion) String() string {
+	switch v {
+	case VoteStateV0_23_5:
+		return "V0_23_5"
+	case VoteStateV1_14_11:
+		return "V1_14_11"
+	case VoteStateCurrent:
+		return "Current"
+	default:
+		return fmt.Sprintf("VoteStateVersion(%d)", uint32(v))
+	}
+}
+
+// MaxPriorVoters is the capacity of the circular buffer of prior voters.
+const MaxPriorVoters = 32
+
+// LandedVote is a lockout of the tower, with the latency of the vote
+// (0 for versions that don't record it).
+type LandedVote struct {
+	Latency uint8
+	Lockout Lockout
+}
+
+// AuthorizedVoter is the voter authorized from an epoch on.
+type AuthorizedVoter struct {
+	Epoch  uint64
+	Pubkey solana.PublicKey
+}
+
+// PriorVoter is a voter that was authorized for [EpochStart, EpochEnd).
+type PriorVoter struct {
+	Pubkey     solana.PublicKey
+	EpochStart uint64
+	EpochEnd   uint64
+}
+
+// EpochCredits are the vote credits earned in an epoch.
+type EpochCredits struct {
+	Epoch       uint64
+	Credits     uint64
+	PrevCredits uint64
+}
+
+// BlockTimestamp is the most recent timestamp submitted with a vote.
+type BlockTimestamp struct {
+	Slot      uint64
+	Timestamp int64
+}
+
+// VoteState is the state of a vote account, decoded from any of the
+// supported versions.
+type VoteState struct {
+	// The version the state was serialized with.
+	Version VoteStateVersion
+
+	// The validator identity.
+	NodePubkey solana.PublicKey
+
+	// The authority allowed to withdraw from the account.
+	AuthorizedWithdrawer solana.PublicKey
+
+	// Commission, in percent.
+	Commission uint8
+
+	// The tower, oldest vote first.
+	Votes []LandedVote
+
+	// The root slot of the tower, if any.
+	RootSlot *uint64
+
+	// The authorized voters, sorted by epoch.
+	AuthorizedVoters []AuthorizedVoter
+
+	// The history of prior voters, oldest first.
+	// Always empty for V0_23_5, as done by the runtime when converting it.
+	PriorVoters []PriorVoter
+
+	// The history of the credits earned, oldest epoch first.
+	EpochCredits []EpochCredits
+
+	// The most recent timestamp submitted with a vote.
+	LastTimestamp BlockTimestamp
+}
+
+// DecodeVoteState decodes the data of a vote account.
+func DecodeVoteState(data []byte) (*VoteState, error) {
+	state := new(VoteState)
+	if err := state.UnmarshalWithDecoder(bin.NewBinDecoder(data)); err != nil {
+		return nil, err
+	}
+	return state, nil
+}
+
+func (state *VoteState) UnmarshalWithDecoder(dec *bin.Decoder) error {
+	version, err := dec.ReadUint32(bin.LE)
+	if err != nil {
+		return err
+	}
+	*state = VoteState{Version: VoteStateVersion(version)}
+	switch state.Version {
+	case VoteStateV0_23_5:
+		return state.unmarshalV0_23_5(dec)
+	case VoteStateV1_14_11, VoteStateCurrent:
+		return state.unmarshal(dec)
+	default:
+		return fmt.Errorf("unsupported vote state version %d", version)
+	}
+}
+
+func (state *VoteState) unmarshalV0_23_5(dec *bin.Decoder) (err error) {
+	if err = dec.Decode(&state.NodePubkey); err != nil {
+		return err
+	}
+	var voter AuthorizedVoter
+	if err = dec.Decode(&voter.Pubkey); err != nil {
+		return err
+	}
+	if voter.Epoch, err = dec.ReadUint64(bin.LE); err != nil {
+		return err
+	}
+	state.AuthorizedVoters = []AuthorizedVoter{voter}
+	// Prior voters: (pubkey, epoch start, epoch end, slot) entries and the index.
+	if err = dec.SkipBytes(MaxPriorVoters*(32+3*8) + 8); err != nil {
+		return err
+	}
+	if err = dec.Decode(&state.AuthorizedWithdrawer); err != nil {
+		return err
+	}
+	if state.Commission, err = dec.ReadUint8(); err != nil {
+		return err
+	}
+	lockouts, err := decodeLockouts(dec)
+	if err != nil {
+		return err
+	}
+	state.Votes = make([]LandedVote, len(lockouts))
+	for i, lockout := range lockouts {
+		state.Votes[i] = LandedVote{Lockout: lockout}
+	}
+	if state.RootSlot, err = decodeOptionalUint64(dec); err != nil {
+		return err
+	}
+	if state.EpochCredits, err = decodeEpochCredits(dec); err != nil {
+		return err
+	}
+	return state.LastTimestamp.unmarshalWithDecoder(dec)
+}
+
+func (state *VoteState) unmarshal(dec *bin.Decoder) (err error) {
+	if err = dec.Decode(&state.NodePubkey); err != nil {
+		return err
+	}
+	if err = dec.Decode(&state.AuthorizedWithdrawer); err != nil {
+		return err
+	}
+	if state.Commission, err = dec.ReadUint8(); err != nil {
+		return err
+	}
+	if state.Version == VoteStateV1_14_11 {
+		lockouts, err := decodeLockouts(dec)
+		if err != nil {
+			return err
+		}
+		state.Votes = make([]LandedVote, len(lockouts))
+		for i, lockout := range lockouts {
+			state.Votes[i] = LandedVote{Lockout: lockout}
+		}
+	} else if state.Votes, err = decodeLandedVotes(dec); err != nil {
+		return err
+	}
+	if state.RootSlot, err = decodeOptionalUint64(dec); err != nil {
+		return err
+	}
+	if state.AuthorizedVoters, err = decodeAuthorizedVoters(dec); err != nil {
+		return err
+	}
+	if state.PriorVoters, err = decodePriorVoters(dec); err != nil {
+		return err
+	}
+	if state.EpochCredits, err = decodeEpochCredits(dec); err != nil {
+		return err
+	}
+	return state.LastTimestamp.unmarshalWithDecoder(dec)
+}
+
+// AuthorizedVoter returns the voter authorized at the given epoch.
+func (state *VoteState) AuthorizedVoter(epoch uint64) (solana.PublicKey, bool) {
+	for i := len(state.AuthorizedVoters) - 1; i >= 0; i-- {
+		if state.AuthorizedVoters[i].Epoch <= epoch {
+			return state.AuthorizedVoters[i].Pubkey, true
+		}
+	}
+	return solana.PublicKey{}, false
+}
+
+// Credits returns the total vote credits earned by the account.
+func (state *VoteState) Credits() uint64 {
+	if len(state.EpochCredits) == 0 {
+		return 0
+	}
+	return state.EpochCredits[len(state.EpochCredits)-1].Credits
+}
+
+// LastVotedSlot returns the most recent slot voted on, if any.
+func (state *VoteState) LastVotedSlot() (uint64, bool) {
+	if len(state.Votes) == 0 {
+		return 0, false
+	}
+	return state.Votes[len(state.Votes)-1].Lockout.Slot, true
+}
+
+func (ts *BlockTimestamp) unmarshalWithDecoder(dec *bin.Decoder) (err error) {
+	if ts.Slot, err = dec.ReadUint64(bin.LE); err != nil {
+		return err
+	}
+	ts.Timestamp, err = dec.ReadInt64(bin.LE)
+	return err
+}
+
+func decodeLength(dec *bin.Decoder, itemSize int) (int, error) {
+	length, err := dec.ReadUint64(bin.LE)
+	if err != nil {
+		return 0, err
+	}
+	if length > uint64(dec.Remaining()/itemSize) {
+		return 0, fmt.Errorf("invalid length %d", length)
+	}
+	return int(length), nil
+}
+
+func decodeLandedVotes(dec *bin.Decoder) ([]LandedVote, error) {
+	length, err := decodeLength(dec, 13)
+	if err != nil {
+		return nil, err
+	}
+	votes := make([]LandedVote, length)
+	for i := range votes {
+		if votes[i].Latency, err = dec.ReadUint8(); err != nil {
+			return nil, err
+		}
+		if votes[i].Lockout.Slot, err = dec.ReadUint64(bin.LE); err != nil {
+			return nil, err
+		}
+		if votes[i].Lockout.ConfirmationCount, err = dec.ReadUint32(bin.LE); err != nil {
+			return nil, err
+		}
+	}
+	return votes, nil
+}
+
+func decodeAuthorizedVoters(dec *bin.Decoder) ([]AuthorizedVoter, error) {
+	length, err := decodeLength(dec, 40)
+	if err != nil {
+		return nil, err
+	}
+	voters := make([]AuthorizedVoter, length)
+	for i := range voters {
+		if voters[i].Epoch, err = dec.ReadUint64(bin.LE); err != nil {
+			return nil, err
+		}
+		if err = dec.Decode(&voters[i].Pubkey); err != nil {
+			return nil, err
+		}
+	}
+	return voters, nil
+}
+
+// decodePriorVoters decodes the circular buffer of prior voters,
+// and returns its entries oldest first.
+func decodePriorVoters(dec *bin.Decoder) ([]PriorVoter, error) {
+	var buf [MaxPriorVoters]PriorVoter
+	var err error
+	for i := range buf {
+		if err = dec.Decode(&buf[i].Pubkey); err != nil {
+			return nil, err
+		}
+		if buf[i].EpochStart, err = dec.ReadUint64(bin.LE); err != nil {
+			return nil, err
+		}
+		if buf[i].EpochEnd, err = dec.ReadUint64(bin.LE); err != nil {
+			return nil, err
+		}
+	}
+	idx, err := dec.ReadUint64(bin.LE)
+	if err != nil {
+		return nil, err
+	}
+	isEmpty, err := dec.ReadBool()
+	if err != nil {
+		return nil, err
+	}
+	if isEmpty {
+		return nil, nil
+	}
+	if idx >= MaxPriorVoters {
+		return nil, fmt.Errorf("invalid prior voters index %d", idx)
+	}
+	// The entry after the last written one is the oldest,
+	// unless the buffer hasn't wrapped around yet.
+	var voters []PriorVoter
+	for i := uint64(1); i <= MaxPriorVoters; i++ {
+		voter := buf[(idx+i)%MaxPriorVoters]
+		if voter != (PriorVoter{}) {
+			voters = append(voters, voter)
+		}
+	}
+	return voters, nil
+}
+
+func decodeEpochCredits(dec *bin.Decoder) ([]EpochCredits, error) {
+	length, err := decodeLength(dec, 24)
+	if err != nil {
+		return nil, err
+	}
+	credits := make([]EpochCredits, length)
+	for i := range credits {
+		if credits[i].Epoch, err = dec.ReadUint64(bin.LE); err != nil {
+			return nil, err
+		}
+		if credits[i].Credits, err = dec.ReadUint64(bin.LE); err != nil {
+			return nil, err
+		}
+		if credits[i].PrevCredits, err = dec.ReadUint64(bin.LE); err != nil {
+			return nil, err
+		}
+	}
+	return credits, nil
+}
@@ -0,0 +1,354 @@
+// Copyright 2021 github.com/gagliardetto
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vote
+
+import (
+	"bytes"
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	bin "github.com/gagliardetto/binary"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gagliardetto/solana-go"
+)
+
+type testVoteAccount struct {
+	node, voter, withdrawer solana.PublicKey
+}
+
+func newTestVoteAccount() *testVoteAccount {
+	return &testVoteAccount{
+		node:       solana.NewWallet().PublicKey(),
+		voter:      solana.NewWallet().PublicKey(),
+		withdrawer: solana.NewWallet().PublicKey(),
+	}
+}
+
+// writeTail writes the fields shared by the 1.14.11 and current versions,
+// after the votes.
+func (acc *testVoteAccount) writeTail(enc *bin.Encoder, priorVoter solana.PublicKey) {
+	root := uint64(99)
+	encodeOptionalUint64(enc, &root)
+	// Authorized voters.
+	enc.WriteUint64(2, bin.LE)
+	enc.WriteUint64(10, bin.LE)
+	enc.Encode(priorVoter)
+	enc.WriteUint64(12, bin.LE)
+	enc.Encode(acc.voter)
+	// Prior voters, with the buffer wrapped around once.
+	for i := 0; i < MaxPriorVoters; i++ {
+		enc.Encode(priorVoter)
+		enc.WriteUint64(uint64(i), bin.LE)
+		enc.WriteUint64(uint64(i+1), bin.LE)
+	}
+	enc.WriteUint64(4, bin.LE)
+	enc.WriteBool(false)
+	// Epoch credits.
+	enc.WriteUint64(2, bin.LE)
+	for _, v := range []uint64{11, 100, 0, 12, 250, 100} {
+		enc.WriteUint64(v, bin.LE)
+	}
+	// Last timestamp.
+	enc.WriteUint64(101, bin.LE)
+	enc.WriteInt64(1_700_000_000, bin.LE)
+}
+
+func (acc *testVoteAccount) check(t *testing.T, state *VoteState) {
+	assert.Equal(t, acc.node, state.NodePubkey)
+	assert.Equal(t, acc.withdrawer, state.AuthorizedWithdrawer)
+	assert.Equal(t, uint8(7), state.Commission)
+	require.NotNil(t, state.RootSlot)
+	assert.Equal(t, uint64(99), *state.RootSlot)
+	assert.Equal(t, uint64(250), state.Credits())
+	assert.Equal(t, []EpochCredits{{Epoch: 11, Credits: 100}, {Epoch: 12, Credits: 250, PrevCredits: 100}}, state.EpochCredits)
+	assert.Equal(t, BlockTimestamp{Slot: 101, Timestamp: 1_700_000_000}, state.LastTimestamp)
+	slot, ok := state.LastVotedSlot()
+	assert.True(t, ok)
+	assert.Equal(t, uint64(101), slot)
+}
+
+func TestDecodeVoteState_Current(t *testing.T) {
+	acc := newTestVoteAccount()
+	priorVoter := solana.NewWallet().PublicKey()
+
+	buf := new(bytes.Buffer)
+	enc := bin.NewBinEncoder(buf)
+	enc.WriteUint32(uint32(VoteStateCurrent), bin.LE)
+	enc.Encode(acc.node)
+	enc.Encode(acc.withdrawer)
+	enc.WriteUint8(7)
+	enc.WriteUint64(2, bin.LE)
+	for _, vote := range []LandedVote{{Latency: 1, Lockout: Lockout{Slot: 100, ConfirmationCount: 2}}, {Latency: 3, Lockout: Lockout{Slot: 101, ConfirmationCount: 1}}} {
+		enc.WriteUint8(vote.Latency)
+		enc.WriteUint64(vote.Lockout.Slot, bin.LE)
+		enc.WriteUint32(vote.Lockout.ConfirmationCount, bin.LE)
+	}
+	acc.writeTail(enc, priorVoter)
+
+	state, err := DecodeVoteState(buf.Bytes())
+	require.NoError(t, err)
+	assert.Equal(t, VoteStateCurrent, state.Version)
+	acc.check(t, state)
+	assert.Equal(t, []LandedVote{{Latency: 1, Lockout: Lockout{Slot: 100, ConfirmationCount: 2}}, {Latency: 3, Lockout: Lockout{Slot: 101, ConfirmationCount: 1}}}, state.Votes)
+
+	assert.Equal(t, []AuthorizedVoter{{Epoch: 10, Pubkey: priorVoter}, {Epoch: 12, Pubkey: acc.voter}}, state.AuthorizedVoters)
+	_, ok := state.AuthorizedVoter(9)
+	assert.False(t, ok)
+	voter, ok := state.AuthorizedVoter(11)
+	assert.True(t, ok)
+	assert.Equal(t, priorVoter, voter)
+	voter, _ = state.AuthorizedVoter(20)
+	assert.Equal(t, acc.voter, voter)
+
+	// The oldest prior voter is the one after the last written.
+	require.Len(t, state.PriorVoters, MaxPriorVoters)
+	assert.Equal(t, PriorVoter{Pubkey: priorVoter, EpochStart: 5, EpochEnd: 6}, state.PriorVoters[0])
+	assert.Equal(t, PriorVoter{Pubkey: priorVoter, EpochStart: 4, EpochEnd: 5}, state.PriorVoters[MaxPriorVoters-1])
+
+	_, err = DecodeVoteState(buf.Bytes()[:buf.Len()-1])
+	assert.Error(t, err)
+}
+
+func TestDecodeVoteState_V1_14_11(t *testing.T) {
+	acc := newTestVoteAccount()
+
+	buf := new(bytes.Buffer)
+	enc := bin.NewBinEncoder(buf)
+	enc.WriteUint32(uint32(VoteStateV1_14_11), bin.LE)
+	enc.Encode(acc.node)
+	enc.Encode(acc.withdrawer)
+	enc.WriteUint8(7)
+	encodeLockouts(enc, []Lockout{{Slot: 100, ConfirmationCount: 2}, {Slot: 101, ConfirmationCount: 1}})
+	acc.writeTail(enc, acc.voter)
+
+	state, err := DecodeVoteState(buf.Bytes())
+	require.NoError(t, err)
+	assert.Equal(t, VoteStateV1_14_11, state.Version)
+	acc.check(t, state)
+	assert.Equal(t, []LandedVote{{Lockout: Lockout{Slot: 100, ConfirmationCount: 2}}, {Lockout: Lockout{Slot: 101, ConfirmationCount: 1}}}, state.Votes)
+}
+
+func TestDecodeVoteState_V0_23_5(t *testing.T) {
+	acc := newTestVoteAccount()
+
+	buf := new(bytes.Buffer)
+	enc := bin.NewBinEncoder(buf)
+	enc.WriteUint32(uint32(VoteStateV0_23_5), bin.LE)
+	enc.Encode(acc.node)
+	enc.Encode(acc.voter)
+	enc.WriteUint64(3, bin.LE)
+	enc.WriteBytes(make([]byte, MaxPriorVoters*56+8), false)
+	enc.Encode(acc.withdrawer)
+	enc.WriteUint8(7)
+	encodeLockouts(enc, []Lockout{{Slot: 101, ConfirmationCount: 1}})
+	root := uint64(99)
+	encodeOptionalUint64(enc, &root)
+	enc.WriteUint64(2, bin.LE)
+	for _, v := range []uint64{11, 100, 0, 12, 250, 100} {
+		enc.WriteUint64(v, bin.LE)
+	}
+	enc.WriteUint64(101, bin.LE)
+	enc.WriteInt64(1_700_000_000, bin.LE)
+
+	state, err := DecodeVoteState(buf.Bytes())
+	require.NoError(t, err)
+	assert.Equal(t, VoteStateV0_23_5, state.Version)
+	acc.check(t, state)
+	assert.Equal(t, []AuthorizedVoter{{Epoch: 3, Pubkey: acc.voter}}, state.AuthorizedVoters)
+	assert.Empty(t, state.PriorVoters)
+}
+
+func TestDecodeVoteState_UnsupportedVersion(t *testing.T) {
+	_, err := DecodeVoteState([]byte{3, 0, 0, 0})
+	assert.Error(t, err)
+}
+
+// voteStateCurrentHex is the data of a vote account in the current version,
+// laid out field by field as the vote program stores it, independently of
+// the encoder. The prior voters buffer has wrapped around.
+var voteStateCurrentHex = "" +
+	"02000000" + // VoteStateVersions::Current
+	"e55d115ae372a6f50c95618bbd212aee120bdaaaa9ca4178a0b53a9bdb49046b" + // node_pubkey
+	"4cbb466e1b11869414e9c50ca0ea13719244e4c32404c3c437b930202aa764ab" + // authorized_withdrawer
+	"07" + // commission: 7
+	"0200000000000000" + // votes: 2
+	"0180b2e60e0000000002000000" + // votes[0]: latency 1, slot 250000000, confirmation_count 2
+	"0381b2e60e0000000001000000" + // votes[1]: latency 3, slot 250000001, confirmation_count 1
+	"0160b2e60e00000000" + // root_slot: Some(249999968)
+	"0200000000000000" + // authorized_voters: 2
+	"5802000000000000cb6a1aa8aef10a0c9cbc153918024bc114018f8393eee334c2dcbdc6b598c6cb" + // authorized_voters[600]
+	"59020000000000004fbb5fe8dfa271b29239068a4c203940465700a210c55a1bd43be7d1a70d78d8" + // authorized_voters[601]
+	"0e0201e1fb82dcf2f3ebb68095df10a6e7bf26c246bff6407879a41b78f3f55a4b020000000000004c02000000000000" + // prior_voters.buf[0]: prior2, epochs [587, 588)
+	"b693afa0903fd9b4fc860e41f99aaf9a454395cea3c74b78ccf0ec060d6413cc4c020000000000004d02000000000000" + // prior_voters.buf[1]: prior1, epochs [588, 589)
+	"0e0201e1fb82dcf2f3ebb68095df10a6e7bf26c246bff6407879a41b78f3f55a4d020000000000004e02000000000000" + // prior_voters.buf[2]: prior2, epochs [589, 590)
+	"b693afa0903fd9b4fc860e41f99aaf9a454395cea3c74b78ccf0ec060d6413cc4e020000000000004f02000000000000" + // prior_voters.buf[3]: prior1, epochs [590, 591)
+	"0e0201e1fb82dcf2f3ebb68095df10a6e7bf26c246bff6407879a41b78f3f55a4f020000000000005002000000000000" + // prior_voters.buf[4]: prior2, epochs [591, 592)
+	"b693afa0903fd9b4fc860e41f99aaf9a454395cea3c74b78ccf0ec060d6413cc30020000000000003102000000000000" + // prior_voters.buf[5]: prior1, epochs [560, 561)
+	"0e0201e1fb82dcf2f3ebb68095df10a6e7bf26c246bff6407879a41b78f3f55a31020000000000003202000000000000" + // prior_voters.buf[6]: prior2, epochs [561, 562)
+	"b693afa0903fd9b4fc860e41f99aaf9a454395cea3c74b78ccf0ec060d6413cc32020000000000003302000000000000" + // prior_voters.buf[7]: prior1, epochs [562, 563)
+	"0e0201e1fb82dcf2f3ebb68095df10a6e7bf26c246bff6407879a41b78f3f55a33020000000000003402000000000000" + // prior_voters.buf[8]: prior2, epochs [563, 564)
+	"b693afa0903fd9b4fc860e41f99aaf9a454395cea3c74b78ccf0ec060d6413cc34020000000000003502000000000000" + // prior_voters.buf[9]: prior1, epochs [564, 565)
+	"0e0201e1fb82dcf2f3ebb68095df10a6e7bf26c246bff6407879a41b78f3f55a35020000000000003602000000000000" + // prior_voters.buf[10]: prior2, epochs [565, 566)
+	"b693afa0903fd9b4fc860e41f99aaf9a454395cea3c74b78ccf0ec060d6413cc36020000000000003702000000000000" + // prior_voters.buf[11]: prior1, epochs [566, 567)
+	"0e0201e1fb82dcf2f3ebb68095df10a6e7bf26c246bff6407879a41b78f3f55a37020000000000003802000000000000" + // prior_voters.buf[12]: prior2, epochs [567, 568)
+	"b693afa0903fd9b4fc860e41f99aaf9a454395cea3c74b78ccf0ec060d6413cc38020000000000003902000000000000" + // prior_voters.buf[13]: prior1, epochs [568, 569)
+	"0e0201e1fb82dcf2f3ebb68095df10a6e7bf26c246bff6407879a41b78f3f55a39020000000000003a02000000000000" + // prior_voters.buf[14]: prior2, epochs [569, 570)
+	"b693afa0903fd9b4fc860e41f99aaf9a454395cea3c74b78ccf0ec060d6413cc3a020000000000003b02000000000000" + // prior_voters.buf[15]: prior1, epochs [570, 571)
+	"0e0201e1fb82dcf2f3ebb68095df10a6e7bf26c246bff6407879a41b78f3f55a3b020000000000003c02000000000000" + // prior_voters.buf[16]: prior2, epochs [571, 572)
+	"b693afa0903fd9b4fc860e41f99aaf9a454395cea3c74b78ccf0ec060d6413cc3c020000000000003d02000000000000" + // prior_voters.buf[17]: prior1, epochs [572, 573)
+	"0e0201e1fb82dcf2f3ebb68095df10a6e7bf26c246bff6407879a41b78f3f55a3d020000000000003e02000000000000" + // prior_voters.buf[18]: prior2, epochs [573, 574)
+	"b693afa0903fd9b4fc860e41f99aaf9a454395cea3c74b78ccf0ec060d6413cc3e020000000000003f02000000000000" + // prior_voters.buf[19]: prior1, epochs [574, 575)
+	"0e0201e1fb82dcf2f3ebb68095df10a6e7bf26c246bff6407879a41b78f3f55a3f020000000000004002000000000000" + // prior_voters.buf[20]: prior2, epochs [575, 576)
+	"b693afa0903fd9b4fc860e41f99aaf9a454395cea3c74b78ccf0ec060d6413cc40020000000000004102000000000000" + // prior_voters.buf[21]: prior1, epochs [576, 577)
+	"0e0201e1fb82dcf2f3ebb68095df10a6e7bf26c246bff6407879a41b78f3f55a41020000000000004202000000000000" + // prior_voters.buf[22]: prior2, epochs [577, 578)
+	"b693afa0903fd9b4fc860e41f99aaf9a454395cea3c74b78ccf0ec060d6413cc42020000000000004302000000000000" + // prior_voters.buf[23]: prior1, epochs [578, 579)
+	"0e0201e1fb82dcf2f3ebb68095df10a6e7bf26c246bff6407879a41b78f3f55a43020000000000004402000000000000" + // prior_voters.buf[24]: prior2, epochs [579, 580)
+	"b693afa0903fd9b4fc860e41f99aaf9a454395cea3c74b78ccf0ec060d6413cc44020000000000004502000000000000" + // prior_voters.buf[25]: prior1, epochs [580, 581)
+	"0e0201e1fb82dcf2f3ebb68095df10a6e7bf26c246bff6407879a41b78f3f55a45020000000000004602000000000000" + // prior_voters.buf[26]: prior2, epochs [581, 582)
+	"b693afa0903fd9b4fc860e41f99aaf9a454395cea3c74b78ccf0ec060d6413cc46020000000000004702000000000000" + // prior_voters.buf[27]: prior1, epochs [582, 583)
+	"0e0201e1fb82dcf2f3ebb68095df10a6e7bf26c246bff6407879a41b78f3f55a47020000000000004802000000000000" + // prior_voters.buf[28]: prior2, epochs [583, 584)
+	"b693afa0903fd9b4fc860e41f99aaf9a454395cea3c74b78ccf0ec060d6413cc48020000000000004902000000000000" + // prior_voters.buf[29]: prior1, epochs [584, 585)
+	"0e0201e1fb82dcf2f3ebb68095df10a6e7bf26c246bff6407879a41b78f3f55a49020000000000004a02000000000000" + // prior_voters.buf[30]: prior2, epochs [585, 586)
+	"b693afa0903fd9b4fc860e41f99aaf9a454395cea3c74b78ccf0ec060d6413cc4a020000000000004b02000000000000" + // prior_voters.buf[31]: prior1, epochs [586, 587)
+	"0400000000000000" + // prior_voters.idx: 4, wrapped around
+	"00" + // prior_voters.is_empty: false
+	"0200000000000000" + // epoch_credits: 2
+	"570200000000000040420f0000000000c027090000000000" + // epoch_credits[0]: epoch 599, credits 1000000, prev_credits 600000
+	"5802000000000000c05c15000000000040420f0000000000" + // epoch_credits[1]: epoch 600, credits 1400000, prev_credits 1000000
+	"81b2e60e0000000000f1536500000000" + // last_timestamp: slot 250000001, timestamp 1700000000
+	strings.Repeat("00", 1945) // padding to 3762 bytes
+
+// voteStateV1_14_11Hex is the data of a vote account in the 1.14.11 version.
+var voteStateV1_14_11Hex = "" +
+	"01000000" + // VoteStateVersions::V1_14_11
+	"e55d115ae372a6f50c95618bbd212aee120bdaaaa9ca4178a0b53a9bdb49046b" + // node_pubkey
+	"4cbb466e1b11869414e9c50ca0ea13719244e4c32404c3c437b930202aa764ab" + // authorized_withdrawer
+	"07" + // commission: 7
+	"0200000000000000" + // votes: 2
+	"80b2e60e0000000002000000" + // votes[0]: slot 250000000, confirmation_count 2
+	"81b2e60e0000000001000000" + // votes[1]: slot 250000001, confirmation_count 1
+	"00" + // root_slot: None
+	"0100000000000000" + // authorized_voters: 1
+	"5802000000000000cb6a1aa8aef10a0c9cbc153918024bc114018f8393eee334c2dcbdc6b598c6cb" + // authorized_voters[600]
+	"b693afa0903fd9b4fc860e41f99aaf9a454395cea3c74b78ccf0ec060d6413cc26020000000000004402000000000000" + // prior_voters.buf[0]: prior1, epochs [550, 580)
+	"0e0201e1fb82dcf2f3ebb68095df10a6e7bf26c246bff6407879a41b78f3f55a44020000000000005802000000000000" + // prior_voters.buf[1]: prior2, epochs [580, 600)
+	strings.Repeat("00", 1440) + // prior_voters.buf[2..32]: unused
+	"0100000000000000" + // prior_voters.idx: 1
+	"00" + // prior_voters.is_empty: false
+	"0100000000000000" + // epoch_credits: 1
+	"5802000000000000c05c15000000000040420f0000000000" + // epoch_credits[0]: epoch 600, credits 1400000, prev_credits 1000000
+	"81b2e60e0000000000f1536500000000" + // last_timestamp: slot 250000001, timestamp 1700000000
+	strings.Repeat("00", 1988) // padding to 3731 bytes
+
+// voteStateV0_23_5Hex is the data of a vote account in the 0.23.5 version,
+// whose prior voters are not decoded.
+var voteStateV0_23_5Hex = "" +
+	"00000000" + // VoteStateVersions::V0_23_5
+	"e55d115ae372a6f50c95618bbd212aee120bdaaaa9ca4178a0b53a9bdb49046b" + // node_pubkey
+	"cb6a1aa8aef10a0c9cbc153918024bc114018f8393eee334c2dcbdc6b598c6cb" + // authorized_voter
+	"c800000000000000" + // authorized_voter_epoch: 200
+	"b693afa0903fd9b4fc860e41f99aaf9a454395cea3c74b78ccf0ec060d6413cc9600000000000000c8000000000000008041200500000000" + // prior_voters.buf[0]: prior1, epochs [150, 200), slot 86000000
+	strings.Repeat("00", 1736) + // prior_voters.buf[1..32]: unused
+	"0000000000000000" + // prior_voters.idx: 0
+	"4cbb466e1b11869414e9c50ca0ea13719244e4c32404c3c437b930202aa764ab" + // authorized_withdrawer
+	"07" + // commission: 7
+	"0100000000000000" + // votes: 1
+	"804a5d050000000001000000" + // votes[0]: slot 90000000, confirmation_count 1
+	"01604a5d0500000000" + // root_slot: Some(89999968)
+	"0100000000000000" + // epoch_credits: 1
+	"d20000000000000020a1070000000000801a060000000000" + // epoch_credits[0]: epoch 210, credits 500000, prev_credits 400000
+	"804a5d050000000080d3276100000000" + // last_timestamp: slot 90000000, timestamp 1630000000
+	strings.Repeat("00", 1745) // padding to 3731 bytes
+
+var (
+	knownNode       = solana.MustPublicKeyFromBase58("GSLg11JUo7dpqXxZndS2PQEECq89dNjUKPHxJX9JSmw8")
+	knownWithdrawer = solana.MustPublicKeyFromBase58("6AXcW5jP2DtBtP8zYWsG329i2mANFu7Ktzq4AQTRs75U")
+	knownVoter      = solana.MustPublicKeyFromBase58("Eh3bu4Jzvf4V387S71yZmovxt2k97JrPwycFUQvRNpzW")
+	knownNextVoter  = solana.MustPublicKeyFromBase58("6NEvYgXj3XrLfuNDZcBQT14n9yYxNLHV2mQ31Rx2s6Dm")
+	knownPrior1     = solana.MustPublicKeyFromBase58("DHhpHbarAKQCipqpzgLF4JZ4gDEGnyABYLCT3JYiLMpo")
+	knownPrior2     = solana.MustPublicKeyFromBase58("wgV26KG4WnGbddZBW7RFZJH2vozdyFJr4n4MynvFYQh")
+)
+
+func decodeKnownAnswer(t *testing.T, fixture string, size int) *VoteState {
+	data, err := hex.DecodeString(fixture)
+	require.NoError(t, err)
+	require.Len(t, data, size)
+	state, err := DecodeVoteState(data)
+	require.NoError(t, err)
+	assert.Equal(t, knownNode, state.NodePubkey)
+	assert.Equal(t, knownWithdrawer, state.AuthorizedWithdrawer)
+	assert.Equal(t, uint8(7), state.Commission)
+	return state
+}
+
+func TestDecodeVoteState_CurrentKnownAnswer(t *testing.T) {
+	state := decodeKnownAnswer(t, voteStateCurrentHex, 3762)
+	assert.Equal(t, VoteStateCurrent, state.Version)
+	assert.Equal(t, []LandedVote{
+		{Latency: 1, Lockout: Lockout{Slot: 250000000, ConfirmationCount: 2}},
+		{Latency: 3, Lockout: Lockout{Slot: 250000001, ConfirmationCount: 1}},
+	}, state.Votes)
+	require.NotNil(t, state.RootSlot)
+	assert.Equal(t, uint64(249999968), *state.RootSlot)
+	assert.Equal(t, []AuthorizedVoter{{Epoch: 600, Pubkey: knownVoter}, {Epoch: 601, Pubkey: knownNextVoter}}, state.AuthorizedVoters)
+
+	// Oldest first: from the entry after prior_voters.idx, around the buffer.
+	require.Len(t, state.PriorVoters, MaxPriorVoters)
+	for i, voter := range state.PriorVoters {
+		pubkey := knownPrior1
+		if i%2 == 1 {
+			pubkey = knownPrior2
+		}
+		assert.Equal(t, PriorVoter{Pubkey: pubkey, EpochStart: uint64(560 + i), EpochEnd: uint64(561 + i)}, voter)
+	}
+
+	assert.Equal(t, []EpochCredits{
+		{Epoch: 599, Credits: 1000000, PrevCredits: 600000},
+		{Epoch: 600, Credits: 1400000, PrevCredits: 1000000},
+	}, state.EpochCredits)
+	assert.Equal(t, BlockTimestamp{Slot: 250000001, Timestamp: 1700000000}, state.LastTimestamp)
+}
+
+func TestDecodeVoteState_V1_14_11KnownAnswer(t *testing.T) {
+	state := decodeKnownAnswer(t, voteStateV1_14_11Hex, 3731)
+	assert.Equal(t, VoteStateV1_14_11, state.Version)
+	assert.Equal(t, []LandedVote{
+		{Lockout: Lockout{Slot: 250000000, ConfirmationCount: 2}},
+		{Lockout: Lockout{Slot: 250000001, ConfirmationCount: 1}},
+	}, state.Votes)
+	assert.Nil(t, state.RootSlot)
+	assert.Equal(t, []AuthorizedVoter{{Epoch: 600, Pubkey: knownVoter}}, state.AuthorizedVoters)
+	assert.Equal(t, []PriorVoter{
+		{Pubkey: knownPrior1, EpochStart: 550, EpochEnd: 580},
+		{Pubkey: knownPrior2, EpochStart: 580, EpochEnd: 600},
+	}, state.PriorVoters)
+	assert.Equal(t, []EpochCredits{{Epoch: 600, Credits: 1400000, PrevCredits: 1000000}}, state.EpochCredits)
+	assert.Equal(t, BlockTimestamp{Slot: 250000001, Timestamp: 1700000000}, state.LastTimestamp)
+}
+
+func TestDecodeVoteState_V0_23_5KnownAnswer(t *testing.T) {
+	state := decodeKnownAnswer(t, voteStateV0_23_5Hex, 3731)
+	assert.Equal(t, VoteStateV0_23_5, state.Version)
+	assert.Equal(t, []LandedVote{{Lockout: Lockout{Slot: 90000000, ConfirmationCount: 1}}}, state.Votes)
+	require.NotNil(t, state.RootSlot)
+	assert.Equal(t, uint64(89999968), *state.RootSlot)
+	assert.Equal(t, []AuthorizedVoter{{Epoch: 200, Pubkey: knownVoter}}, state.AuthorizedVoters)
+	assert.Empty(t, state.PriorVoters)
+	assert.Equal(t, []EpochCredits{{Epoch: 210, Credits: 500000, PrevCredits: 400000}}, state.EpochCredits)
+	assert.Equal(t, BlockTimestamp{Slot: 90000000, Timestamp: 1630000000}, state.LastTimestamp)
+}
@@ -0,0 +1,298 @@
+// Copyright 2021 github.com/gagliardetto
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vote
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+
+	bin "github.com/gagliardetto/binary"
+	"github.com/gagliardetto/solana-go"
+)
+
+// VoteAuthorize is the kind of authority of a vote account.
+type VoteAuthorize uint32
+
+const (
+	VoteAuthorizeVoter VoteAuthorize = iota
+	VoteAuthorizeWithdrawer
+)
+
+func (a VoteAuthorize) String() string {
+	switch a {
+	case VoteAuthorizeVoter:
+		return "Voter"
+	case VoteAuthorizeWithdrawer:
+		return "Withdrawer"
+	default:
+		return fmt.Sprintf("VoteAuthorize(%d)", uint32(a))
+	}
+}
+
+// Lockout is a vote on a slot, locked out for 2^ConfirmationCount slots.
+type Lockout struct {
+	Slot              uint64
+	ConfirmationCount uint32
+}
+
+// VoteStateUpdate is the tower of a validator, as voted with
+// UpdateVoteState and CompactUpdateVoteState.
+type VoteStateUpdate struct {
+	// The proposed tower.
+	Lockouts []Lockout
+
+	// The proposed root.
+	Root *uint64
+
+	// Signature of the bank's state at the last slot.
+	Hash solana.Hash
+
+	// Processing timestamp of last slot.
+	Timestamp *int64
+}
+
+func (update *VoteStateUpdate) UnmarshalWithDecoder(dec *bin.Decoder) (err error) {
+	if update.Lockouts, err = decodeLockouts(dec); err != nil {
+		return err
+	}
+	if update.Root, err = decodeOptionalUint64(dec); err != nil {
+		return err
+	}
+	if err = dec.Decode(&update.Hash); err != nil {
+		return err
+	}
+	update.Timestamp, err = decodeOptionalInt64(dec)
+	return err
+}
+
+func (update VoteStateUpdate) MarshalWithEncoder(enc *bin.Encoder) error {
+	if err := encodeLockouts(enc, update.Lockouts); err != nil {
+		return err
+	}
+	if err := encodeOptionalUint64(enc, update.Root); err != nil {
+		return err
+	}
+	if err := enc.WriteBytes(update.Hash[:], false); err != nil {
+		return err
+	}
+	return encodeOptionalInt64(enc, update.Timestamp)
+}
+
+// unmarshalCompact decodes the compact serialization of the update:
+// the root (math.MaxUint64 if none), then the lockouts as slot offsets.
+func (update *VoteStateUpdate) unmarshalCompact(dec *bin.Decoder) (err error) {
+	if update.Root, update.Lockouts, err = decodeCompactLockouts(dec); err != nil {
+		return err
+	}
+	if err = dec.Decode(&update.Hash); err != nil {
+		return err
+	}
+	update.Timestamp, err = decodeOptionalInt64(dec)
+	return err
+}
+
+func (update VoteStateUpdate) marshalCompact(enc *bin.Encoder) error {
+	if err := encodeCompactLockouts(enc, update.Root, update.Lockouts); err != nil {
+		return err
+	}
+	if err := enc.WriteBytes(update.Hash[:], false); err != nil {
+		return err
+	}
+	return encodeOptionalInt64(enc, update.Timestamp)
+}
+
+// TowerSyncUpdate is the tower of a validator, as voted with TowerSync.
+type TowerSyncUpdate struct {
+	// The proposed tower.
+	Lockouts []Lockout
+
+	// The proposed root.
+	Root *uint64
+
+	// Signature of the bank's state at the last slot.
+	Hash solana.Hash
+
+	// Processing timestamp of last slot.
+	Timestamp *int64
+
+	// The block id of the last slot.
+	BlockID solana.Hash
+}
+
+func (update *TowerSyncUpdate) UnmarshalWithDecoder(dec *bin.Decoder) (err error) {
+	if update.Root, update.Lockouts, err = decodeCompactLockouts(dec); err != nil {
+		return err
+	}
+	if err = dec.Decode(&update.Hash); err != nil {
+		return err
+	}
+	if update.Timestamp, err = decodeOptionalInt64(dec); err != nil {
+		return err
+	}
+	return dec.Decode(&update.BlockID)
+}
+
+func (update TowerSyncUpdate) MarshalWithEncoder(enc *bin.Encoder) error {
+	if err := encodeCompactLockouts(enc, update.Root, update.Lockouts); err != nil {
+		return err
+	}
+	if err := enc.WriteBytes(update.Hash[:], false); err != nil {
+		return err
+	}
+	if err := encodeOptionalInt64(enc, update.Timestamp); err != nil {
+		return err
+	}
+	return enc.WriteBytes(update.BlockID[:], false)
+}
+
+func decodeLockouts(dec *bin.Decoder) ([]Lockout, error) {
+	length, err := decodeLength(dec, 12)
+	if err != nil {
+		return nil, err
+	}
+	lockouts := make([]Lockout, length)
+	for i := range lockouts {
+		if lockouts[i].Slot, err = dec.ReadUint64(bin.LE); err != nil {
+			return nil, err
+		}
+		if lockouts[i].ConfirmationCount, err = dec.ReadUint32(bin.LE); err != nil {
+			return nil, err
+		}
+	}
+	return lockouts, nil
+}
+
+func encodeLockouts(enc *bin.Encoder, lockouts []Lockout) error {
+	if err := enc.WriteUint64(uint64(len(lockouts)), bin.LE); err != nil {
+		return err
+	}
+	for _, lockout := range lockouts {
+		if err := enc.WriteUint64(lockout.Slot, bin.LE); err != nil {
+			return err
+		}
+		if err := enc.WriteUint32(lockout.ConfirmationCount, bin.LE); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// decodeCompactLockouts decodes a root, and lockouts as varint offsets
+// from the previous slot (the first from the root) with 1-byte confirmation counts.
+func decodeCompactLockouts(dec *bin.Decoder) (*uint64, []Lockout, error) {
+	rootSlot, err := dec.ReadUint64(bin.LE)
+	if err != nil {
+		return nil, nil, err
+	}
+	var root *uint64
+	var slot uint64
+	if rootSlot != math.MaxUint64 {
+		root = &rootSlot
+		slot = rootSlot
+	}
+	length, err := dec.ReadCompactU16()
+	if err != nil {
+		return nil, nil, err
+	}
+	lockouts := make([]Lockout, length)
+	for i := range lockouts {
+		offset, err := dec.ReadUvarint64()
+		if err != nil {
+			return nil, nil, err
+		}
+		if slot+offset < slot {
+			return nil, nil, fmt.Errorf("invalid lockout offset %d", offset)
+		}
+		slot += offset
+		confirmationCount, err := dec.ReadUint8()
+		if err != nil {
+			return nil, nil, err
+		}
+		lockouts[i] = Lockout{Slot: slot, ConfirmationCount: uint32(confirmationCount)}
+	}
+	return root, lockouts, nil
+}
+
+func encodeCompactLockouts(enc *bin.Encoder, root *uint64, lockouts []Lockout) error {
+	rootSlot := uint64(math.MaxUint64)
+	var slot uint64
+	if root != nil {
+		rootSlot = *root
+		slot = *root
+	}
+	if err := enc.WriteUint64(rootSlot, bin.LE); err != nil {
+		return err
+	}
+	if err := enc.WriteCompactU16(len(lockouts)); err != nil {
+		return err
+	}
+	for _, lockout := range lockouts {
+		if lockout.Slot < slot {
+			return fmt.Errorf("lockouts are not sorted: slot %d after %d", lockout.Slot, slot)
+		}
+		if lockout.ConfirmationCount > math.MaxUint8 {
+			return fmt.Errorf("invalid confirmation count %d", lockout.ConfirmationCount)
+		}
+		var buf [binary.MaxVarintLen64]byte
+		n := binary.PutUvarint(buf[:], lockout.Slot-slot)
+		if err := enc.WriteBytes(buf[:n], false); err != nil {
+			return err
+		}
+		if err := enc.WriteUint8(uint8(lockout.ConfirmationCount)); err != nil {
+			return err
+		}
+		slot = lockout.Slot
+	}
+	return nil
+}
+
+func decodeOptionalUint64(dec *bin.Decoder) (*uint64, error) {
+	isSome, err := dec.ReadOption()
+	if err != nil || !isSome {
+		return nil, err
+	}
+	v, err := dec.ReadUint64(bin.LE)
+	if err != nil {
+		return nil, err
+	}
+	return &v, nil
+}
+
+func encodeOptionalUint64(enc *bin.Encoder, v *uint64) error {
+	if err := enc.WriteOption(v != nil); err != nil || v == nil {
+		return err
+	}
+	return enc.WriteUint64(*v, bin.LE)
+}
+
+func decodeOptionalInt64(dec *bin.Decoder) (*int64, error) {
+	isSome, err := dec.ReadOption()
+	if err != nil || !isSome {
+		return nil, err
+	}
+	v, err := dec.ReadInt64(bin.LE)
+	if err != nil {
+		return nil, err
+	}
+	return &v, nil
+}
+
+func encodeOptionalInt64(enc *bin.Encoder, v *int64) error {
+	if err := enc.WriteOption(v != nil); err != nil || v == nil {
+		return err
+	}
+	return enc.WriteInt64(*v, bin.LE)
+}
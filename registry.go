@@ -33,19 +33,23 @@ type InstructionDecoder func(instructionAccounts []*AccountMeta, data []byte) (i
 
 var instructionDecoderRegistry = newInstructionDecoderRegistry()
 
-type decoderRegistry struct {
+type decoderRegistry[D any] struct {
 	mu       *sync.RWMutex
-	decoders map[PublicKey]InstructionDecoder
+	decoders map[PublicKey]D
 }
 
-func newInstructionDecoderRegistry() *decoderRegistry {
-	return &decoderRegistry{
+func newInstructionDecoderRegistry() *decoderRegistry[InstructionDecoder] {
+	return newDecoderRegistry[InstructionDecoder]()
+}
+
+func newDecoderRegistry[D any]() *decoderRegistry[D] {
+	return &decoderRegistry[D]{
 		mu:       &sync.RWMutex{},
-		decoders: make(map[PublicKey]InstructionDecoder),
+		decoders: make(map[PublicKey]D),
 	}
 }
 
-func (reg *decoderRegistry) Has(programID PublicKey) bool {
+func (reg *decoderRegistry[D]) Has(programID PublicKey) bool {
 	reg.mu.RLock()
 	defer reg.mu.RUnlock()
 
@@ -53,7 +57,7 @@ func (reg *decoderRegistry) Has(programID PublicKey) bool {
 	return ok
 }
 
-func (reg *decoderRegistry) Get(programID PublicKey) (InstructionDecoder, bool) {
+func (reg *decoderRegistry[D]) Get(programID PublicKey) (D, bool) {
 	reg.mu.RLock()
 	defer reg.mu.RUnlock()
 
@@ -65,7 +69,7 @@ func (reg *decoderRegistry) Get(programID PublicKey) (InstructionDecoder, bool)
 // already a registered decoder for the programID.
 // Returns true if was successfully registered right now (non-previously registered);
 // returns false if there already was a decoder registered.
-func (reg *decoderRegistry) RegisterIfNew(programID PublicKey, decoder InstructionDecoder) bool {
+func (reg *decoderRegistry[D]) RegisterIfNew(programID PublicKey, decoder D) bool {
 	reg.mu.Lock()
 	defer reg.mu.Unlock()
 
@@ -0,0 +1,228 @@
+// Copyright 2021 github.com/gagliardetto
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/gagliardetto/solana-go"
+)
+
+// ErrLatestBlockhashStale is returned by BlockhashProvider when its newest
+// blockhash expired, i.e. the refreshes have been failing for too long.
+var ErrLatestBlockhashStale = errors.New("latest known blockhash is stale")
+
+// BlockhashProviderOpts configures a BlockhashProvider.
+type BlockhashProviderOpts struct {
+	// Commitment of the blockhashes and block heights (default: finalized).
+	Commitment CommitmentType
+
+	// Interval between the refreshes of the latest blockhash (default: 5s).
+	RefreshInterval time.Duration
+
+	// Interval between the refreshes of the block height (default: 1s).
+	BlockHeightInterval time.Duration
+
+	// Optional; called with the errors of the background refreshes.
+	OnError func(error)
+}
+
+const (
+	defaultBlockhashRefreshInterval   = 5 * time.Second
+	defaultBlockHeightRefreshInterval = time.Second
+
+	// Number of blocks the expired blockhashes are remembered for.
+	expiredBlockhashRetention = 300
+)
+
+// BlockhashProvider refreshes the latest blockhash in the background,
+// and tracks the block height to tell whether the blockhashes it served
+// are still valid, without RPC calls.
+//
+// It implements solana.BlockhashSource, for TransactionBuilder.SetBlockhashSource.
+type BlockhashProvider struct {
+	client *Client
+	opts   BlockhashProviderOpts
+
+	mu          sync.RWMutex
+	latest      LatestBlockhashResult
+	blockHeight uint64
+	// lastValidBlockHeight of the blockhashes fetched.
+	known map[solana.Hash]uint64
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+var _ solana.BlockhashSource = &BlockhashProvider{}
+
+// NewBlockhashProvider fetches the latest blockhash and block height,
+// and starts refreshing them in the background until Close is called.
+func NewBlockhashProvider(
+	ctx context.Context,
+	client *Client,
+	opts *BlockhashProviderOpts, // optional
+) (*BlockhashProvider, error) {
+	bp := &BlockhashProvider{
+		client: client,
+		known:  map[solana.Hash]uint64{},
+		done:   make(chan struct{}),
+	}
+	if opts != nil {
+		bp.opts = *opts
+	}
+	if bp.opts.Commitment == "" {
+		bp.opts.Commitment = CommitmentFinalized
+	}
+	if bp.opts.RefreshInterval <= 0 {
+		bp.opts.RefreshInterval = defaultBlockhashRefreshInterval
+	}
+	if bp.opts.BlockHeightInterval <= 0 {
+		bp.opts.BlockHeightInterval = defaultBlockHeightRefreshInterval
+	}
+
+	if err := bp.refreshBlockhash(ctx); err != nil {
+		return nil, err
+	}
+	if err := bp.refreshBlockHeight(ctx); err != nil {
+		return nil, err
+	}
+
+	bgCtx, cancel := context.WithCancel(context.Background())
+	bp.cancel = cancel
+	go bp.run(bgCtx)
+	return bp, nil
+}
+
+// Close stops the background refreshes.
+func (bp *BlockhashProvider) Close() error {
+	bp.cancel()
+	<-bp.done
+	return nil
+}
+
+func (bp *BlockhashProvider) run(ctx context.Context) {
+	defer close(bp.done)
+
+	blockhashTicker := time.NewTicker(bp.opts.RefreshInterval)
+	defer blockhashTicker.Stop()
+	blockHeightTicker := time.NewTicker(bp.opts.BlockHeightInterval)
+	defer blockHeightTicker.Stop()
+
+	for {
+		var err error
+		select {
+		case <-ctx.Done():
+			return
+		case <-blockhashTicker.C:
+			err = bp.refreshBlockhash(ctx)
+		case <-blockHeightTicker.C:
+			err = bp.refreshBlockHeight(ctx)
+		}
+		if err != nil && ctx.Err() == nil && bp.opts.OnError != nil {
+			bp.opts.OnError(err)
+		}
+	}
+}
+
+func (bp *BlockhashProvider) refreshBlockhash(ctx context.Context) error {
+	out, err := bp.client.GetLatestBlockhash(ctx, bp.opts.Commitment)
+	if err != nil {
+		return fmt.Errorf("unable to get latest blockhash: %w", err)
+	}
+	if out == nil || out.Value == nil {
+		return errors.New("unable to get latest blockhash: empty response")
+	}
+
+	bp.mu.Lock()
+	defer bp.mu.Unlock()
+	if out.Value.LastValidBlockHeight >= bp.latest.LastValidBlockHeight {
+		bp.latest = *out.Value
+	}
+	bp.known[out.Value.Blockhash] = out.Value.LastValidBlockHeight
+	return nil
+}
+
+func (bp *BlockhashProvider) refreshBlockHeight(ctx context.Context) error {
+	height, err := bp.client.GetBlockHeight(ctx, bp.opts.Commitment)
+	if err != nil {
+		return fmt.Errorf("unable to get block height: %w", err)
+	}
+
+	bp.mu.Lock()
+	defer bp.mu.Unlock()
+	if height > bp.blockHeight {
+		bp.blockHeight = height
+	}
+	for blockhash, lastValidBlockHeight := range bp.known {
+		if bp.blockHeight > lastValidBlockHeight+expiredBlockhashRetention {
+			delete(bp.known, blockhash)
+		}
+	}
+	return nil
+}
+
+// Latest returns the newest blockhash, with its lastValidBlockHeight.
+// It fails with ErrLatestBlockhashStale if the refreshes have been failing
+// for so long that the newest blockhash expired.
+func (bp *BlockhashProvider) Latest() (*LatestBlockhashResult, error) {
+	bp.mu.RLock()
+	defer bp.mu.RUnlock()
+	if bp.blockHeight > bp.latest.LastValidBlockHeight {
+		return nil, ErrLatestBlockhashStale
+	}
+	latest := bp.latest
+	return &latest, nil
+}
+
+// RecentBlockhash returns the newest blockhash (see Latest).
+func (bp *BlockhashProvider) RecentBlockhash() (solana.Hash, error) {
+	latest, err := bp.Latest()
+	if err != nil {
+		return solana.Hash{}, err
+	}
+	return latest.Blockhash, nil
+}
+
+// BlockHeight returns the latest known block height.
+func (bp *BlockhashProvider) BlockHeight() uint64 {
+	bp.mu.RLock()
+	defer bp.mu.RUnlock()
+	return bp.blockHeight
+}
+
+// IsBlockhashValid tells whether a blockhash is still valid at the latest
+// known block height, without an RPC call (unlike Client.IsBlockhashValid).
+// The answer is only known (ok is true) for the blockhashes fetched by the provider,
+// which are forgotten a few hundred blocks after they expire.
+func (bp *BlockhashProvider) IsBlockhashValid(blockhash solana.Hash) (valid bool, ok bool) {
+	bp.mu.RLock()
+	defer bp.mu.RUnlock()
+	lastValidBlockHeight, ok := bp.known[blockhash]
+	if !ok {
+		return false, false
+	}
+	return bp.blockHeight <= lastValidBlockHeight, true
+}
+
+// IsValidUntil tells whether a blockhash with the provided lastValidBlockHeight
+// is still valid at the latest known block height.
+func (bp *BlockhashProvider) IsValidUntil(lastValidBlockHeight uint64) bool {
+	return bp.BlockHeight() <= lastValidBlockHeight
+}
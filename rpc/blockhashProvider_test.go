@@ -0,0 +1,168 @@
+// Copyright 2021 github.com/gagliardetto
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rpc
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc/jsonrpc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// chainClient answers getLatestBlockhash and getBlockHeight.
+type chainClient struct {
+	mu                   sync.Mutex
+	blockhash            solana.Hash
+	lastValidBlockHeight uint64
+	blockHeight          uint64
+	err                  error
+}
+
+func (c *chainClient) set(blockhash solana.Hash, lastValidBlockHeight uint64, blockHeight uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.blockhash = blockhash
+	c.lastValidBlockHeight = lastValidBlockHeight
+	c.blockHeight = blockHeight
+}
+
+func (c *chainClient) setErr(err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.err = err
+}
+
+func (c *chainClient) CallForInto(ctx context.Context, out interface{}, method string, params []interface{}) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.err != nil {
+		return c.err
+	}
+	var result interface{}
+	switch method {
+	case "getLatestBlockhash":
+		result = &GetLatestBlockhashResult{Value: &LatestBlockhashResult{
+			Blockhash:            c.blockhash,
+			LastValidBlockHeight: c.lastValidBlockHeight,
+		}}
+	case "getBlockHeight":
+		result = c.blockHeight
+	default:
+		return errors.New("unexpected method " + method)
+	}
+	raw, err := json.Marshal(result)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(raw, out)
+}
+
+func (c *chainClient) CallWithCallback(context.Context, string, []interface{}, func(*http.Request, *http.Response) error) error {
+	return errors.New("not supported")
+}
+
+func (c *chainClient) CallBatch(context.Context, jsonrpc.RPCRequests) (jsonrpc.RPCResponses, error) {
+	return nil, errors.New("not supported")
+}
+
+func TestBlockhashProvider(t *testing.T) {
+	first := solana.MustHashFromBase58("EkSnNWid2cvwEVnVx9aBqawnmiCNiDgp3gUdkDPTKN1N")
+	second := solana.MustHashFromBase58("A9QnpgfhCkmiBSjgBuWk76Wo3HxzxvDopUq9x6UUMmjn")
+
+	chain := &chainClient{}
+	chain.set(first, 150, 1)
+
+	var errs []error
+	var errsMu sync.Mutex
+	provider, err := NewBlockhashProvider(context.Background(), NewWithCustomRPCClient(chain), &BlockhashProviderOpts{
+		RefreshInterval:     10 * time.Millisecond,
+		BlockHeightInterval: 10 * time.Millisecond,
+		OnError: func(err error) {
+			errsMu.Lock()
+			defer errsMu.Unlock()
+			errs = append(errs, err)
+		},
+	})
+	require.NoError(t, err)
+	defer provider.Close()
+
+	latest, err := provider.Latest()
+	require.NoError(t, err)
+	assert.Equal(t, first, latest.Blockhash)
+	assert.Equal(t, uint64(150), latest.LastValidBlockHeight)
+	assert.Equal(t, uint64(1), provider.BlockHeight())
+
+	// Refreshed in the background.
+	chain.set(second, 160, 151)
+	require.Eventually(t, func() bool {
+		blockhash, err := provider.RecentBlockhash()
+		return err == nil && blockhash == second && provider.BlockHeight() == 151
+	}, 5*time.Second, 5*time.Millisecond)
+
+	valid, ok := provider.IsBlockhashValid(first)
+	assert.True(t, ok)
+	assert.False(t, valid)
+	valid, ok = provider.IsBlockhashValid(second)
+	assert.True(t, ok)
+	assert.True(t, valid)
+	_, ok = provider.IsBlockhashValid(solana.Hash{})
+	assert.False(t, ok)
+	assert.True(t, provider.IsValidUntil(151))
+	assert.False(t, provider.IsValidUntil(150))
+
+	// Used by the transaction builder.
+	tx, err := solana.NewTransactionBuilder().
+		AddInstruction(solana.NewInstruction(
+			solana.SystemProgramID,
+			solana.AccountMetaSlice{solana.Meta(solana.SystemProgramID).SIGNER()},
+			nil,
+		)).
+		SetBlockhashSource(provider).
+		Build()
+	require.NoError(t, err)
+	assert.Equal(t, second, tx.Message.RecentBlockhash)
+
+	// The refreshes fail; the newest blockhash is kept until it expires.
+	chain.setErr(errors.New("unavailable"))
+	require.Eventually(t, func() bool {
+		errsMu.Lock()
+		defer errsMu.Unlock()
+		return len(errs) > 0
+	}, 5*time.Second, 5*time.Millisecond)
+	_, err = provider.Latest()
+	require.NoError(t, err)
+}
+
+func TestBlockhashProvider_Expired(t *testing.T) {
+	chain := &chainClient{}
+	// Inconsistent node: the latest blockhash is already expired.
+	chain.set(solana.MustHashFromBase58("EkSnNWid2cvwEVnVx9aBqawnmiCNiDgp3gUdkDPTKN1N"), 150, 151)
+
+	provider, err := NewBlockhashProvider(context.Background(), NewWithCustomRPCClient(chain), nil)
+	require.NoError(t, err)
+	defer provider.Close()
+
+	_, err = provider.Latest()
+	assert.ErrorIs(t, err, ErrLatestBlockhashStale)
+	_, err = provider.RecentBlockhash()
+	assert.ErrorIs(t, err, ErrLatestBlockhashStale)
+}
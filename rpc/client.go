@@ -80,9 +80,11 @@ func (cl *Client) Close() error {
 
 // NewWithCustomRPCClient creates a new Solana RPC client
 // with the provided RPC client.
+// Errors carrying a Solana server error code are returned
+// as typed errors (see ParseRPCError).
 func NewWithCustomRPCClient(rpcClient JSONRPCClient) *Client {
 	return &Client{
-		rpcClient: rpcClient,
+		rpcClient: &clientWithTypedErrors{rpcClient: rpcClient},
 	}
 }
 
@@ -22,6 +22,7 @@ import (
 	"context"
 	"encoding/base64"
 	stdjson "encoding/json"
+	"errors"
 	"fmt"
 	"math/big"
 	"testing"
@@ -32,6 +33,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc/jsonrpc"
 )
 
 func TestClient_GetAccountInfo(t *testing.T) {
@@ -1965,6 +1967,35 @@ func TestClient_GetSignatureStatuses(t *testing.T) {
 	assert.Equal(t, expected, got, "both deserialized values must be equal")
 }
 
+func TestSignatureStatusesResult_Reaches(t *testing.T) {
+	confirmations := uint64(3)
+	tests := []struct {
+		status     SignatureStatusesResult
+		commitment CommitmentType
+		want       bool
+	}{
+		{SignatureStatusesResult{ConfirmationStatus: ConfirmationStatusProcessed}, CommitmentProcessed, true},
+		{SignatureStatusesResult{ConfirmationStatus: ConfirmationStatusProcessed}, CommitmentConfirmed, false},
+		{SignatureStatusesResult{ConfirmationStatus: ConfirmationStatusConfirmed}, CommitmentConfirmed, true},
+		{SignatureStatusesResult{ConfirmationStatus: ConfirmationStatusConfirmed}, CommitmentFinalized, false},
+		{SignatureStatusesResult{ConfirmationStatus: ConfirmationStatusFinalized}, CommitmentFinalized, true},
+		// Empty or unknown commitments mean finalized.
+		{SignatureStatusesResult{ConfirmationStatus: ConfirmationStatusConfirmed}, "", false},
+		{SignatureStatusesResult{ConfirmationStatus: ConfirmationStatusConfirmed}, "unknown", false},
+		{SignatureStatusesResult{ConfirmationStatus: ConfirmationStatusFinalized}, "", true},
+		// Deprecated commitments.
+		{SignatureStatusesResult{ConfirmationStatus: ConfirmationStatusProcessed}, CommitmentRecent, true},
+		{SignatureStatusesResult{ConfirmationStatus: ConfirmationStatusConfirmed}, CommitmentSingleGossip, true},
+		{SignatureStatusesResult{ConfirmationStatus: ConfirmationStatusConfirmed}, CommitmentMax, false},
+		// Nodes without confirmation status: null confirmations mean rooted.
+		{SignatureStatusesResult{}, CommitmentFinalized, true},
+		{SignatureStatusesResult{Confirmations: &confirmations}, CommitmentProcessed, false},
+	}
+	for _, test := range tests {
+		assert.Equal(t, test.want, test.status.Reaches(test.commitment), "%+v at %q", test.status, test.commitment)
+	}
+}
+
 func TestClient_GetSlot(t *testing.T) {
 	responseBody := `83999325`
 	server, closer := mockJSONRPC(t, stdjson.RawMessage(wrapIntoRPC(responseBody)))
@@ -2900,6 +2931,101 @@ func TestClient_SendEncodedTransaction(t *testing.T) {
 	assert.Equal(t, expected, got, "both deserialized values must be equal")
 }
 
+func TestClient_SendEncodedTransaction_PreflightFailure(t *testing.T) {
+	responseBody := `{"jsonrpc":"2.0","error":{"code":-32002,"message":"Transaction simulation failed: Blockhash not found","data":{"accounts":null,"err":"BlockhashNotFound","logs":[],"unitsConsumed":0}},"id":1}`
+	server, closer := mockJSONRPC(t, stdjson.RawMessage(responseBody))
+	defer closer()
+
+	client := New(server.URL)
+
+	_, err := client.SendEncodedTransaction(context.Background(), encodedTx)
+	require.Error(t, err)
+
+	var preflightErr *SendTransactionPreflightFailureError
+	require.True(t, errors.As(err, &preflightErr))
+	require.NotNil(t, preflightErr.Result)
+	assert.Equal(t, "BlockhashNotFound", preflightErr.Result.Err)
+	assert.Equal(t, []string{}, preflightErr.Result.Logs)
+	assert.Equal(t, uint64(0), *preflightErr.Result.UnitsConsumed)
+	assert.True(t, IsBlockhashNotFound(err))
+
+	var rpcErr *jsonrpc.RPCError
+	require.True(t, errors.As(err, &rpcErr))
+	assert.Equal(t, ErrorCodeSendTransactionPreflightFailure, rpcErr.Code)
+}
+
+func TestClient_RPCCallBatch_TypedErrors(t *testing.T) {
+	responseBody := `[` +
+		`{"jsonrpc":"2.0","error":{"code":-32002,"message":"Transaction simulation failed: Blockhash not found","data":{"accounts":null,"err":"BlockhashNotFound","logs":[],"unitsConsumed":0}},"id":0},` +
+		`{"jsonrpc":"2.0","error":{"code":-32007,"message":"Slot 42 was skipped, or missing due to ledger jump to recent snapshot"},"id":1},` +
+		`{"jsonrpc":"2.0","result":42,"id":2}` +
+		`]`
+	server, closer := mockJSONRPC(t, stdjson.RawMessage(responseBody))
+	defer closer()
+
+	client := New(server.URL)
+	responses, err := client.RPCCallBatch(context.Background(), jsonrpc.RPCRequests{
+		jsonrpc.NewRequest("sendTransaction", encodedTx),
+		jsonrpc.NewRequest("getBlock", 42),
+		jsonrpc.NewRequest("getSlot"),
+	})
+	require.NoError(t, err)
+	require.Len(t, responses, 3)
+
+	assert.True(t, IsBlockhashNotFound(responses[0].Error))
+	var preflightErr *SendTransactionPreflightFailureError
+	require.True(t, errors.As(ResponseError(responses[0]), &preflightErr))
+	assert.Equal(t, "BlockhashNotFound", preflightErr.Result.Err)
+
+	var slotSkipped *SlotSkippedError
+	require.True(t, errors.As(ResponseError(responses[1]), &slotSkipped))
+	assert.Equal(t, uint64(42), slotSkipped.Slot)
+
+	assert.NoError(t, ResponseError(responses[2]))
+	assert.False(t, IsBlockhashNotFound(responses[2].Error))
+}
+
+func TestParseRPCError(t *testing.T) {
+	err := ParseRPCError(&jsonrpc.RPCError{
+		Code:    ErrorCodeSlotSkipped,
+		Message: "Slot 123456 was skipped, or missing due to ledger jump to recent snapshot",
+	})
+	var slotSkipped *SlotSkippedError
+	require.True(t, errors.As(err, &slotSkipped))
+	assert.Equal(t, uint64(123456), slotSkipped.Slot)
+
+	err = ParseRPCError(&jsonrpc.RPCError{
+		Code:    ErrorCodeBlockCleanedUp,
+		Message: "Block 10 cleaned up, does not exist on node. First available block: 42",
+	})
+	var cleanedUp *BlockCleanedUpError
+	require.True(t, errors.As(err, &cleanedUp))
+	assert.Equal(t, uint64(10), cleanedUp.Slot)
+	assert.Equal(t, uint64(42), cleanedUp.FirstAvailableBlock)
+
+	err = ParseRPCError(&jsonrpc.RPCError{
+		Code:    ErrorCodeNodeUnhealthy,
+		Message: "Node is behind by 42 slots",
+		Data:    map[string]interface{}{"numSlotsBehind": stdjson.Number("42")},
+	})
+	var unhealthy *NodeUnhealthyError
+	require.True(t, errors.As(err, &unhealthy))
+	assert.Equal(t, uint64(42), *unhealthy.NumSlotsBehind)
+
+	err = ParseRPCError(&jsonrpc.RPCError{
+		Code:    ErrorCodeMinContextSlotNotReached,
+		Message: "Minimum context slot has not been reached",
+		Data:    map[string]interface{}{"contextSlot": stdjson.Number("99")},
+	})
+	var minContextSlot *MinContextSlotNotReachedError
+	require.True(t, errors.As(err, &minContextSlot))
+	assert.Equal(t, uint64(99), minContextSlot.ContextSlot)
+
+	other := &jsonrpc.RPCError{Code: -32601, Message: "Method not found"}
+	assert.Equal(t, error(other), ParseRPCError(other))
+	assert.Nil(t, ParseRPCError(nil))
+}
+
 func TestClient_SendRawTransaction(t *testing.T) {
 	responseBody := fmt.Sprintf(`"%s"`, txSignatureString)
 	server, closer := mockJSONRPC(t, stdjson.RawMessage(wrapIntoRPC(responseBody)))
@@ -2966,7 +3092,97 @@ func TestClient_IsBlockhashValid(t *testing.T) {
 }
 
 func TestClient_SimulateTransaction(t *testing.T) {
-	// TODO
+	responseBody := `{"context":{"slot":83986105},"value":{"accounts":null,"innerInstructions":[{"index":0,"instructions":[{"accounts":["7xLk17EQQ5KLDLDe44wCmupJKJjTGd8hs3eSVVhCx932"],"data":"3Bxs4h24hBtQy9rw","programId":"11111111111111111111111111111111","stackHeight":2}]}],"loadedAccountsDataSize":1234,"logs":["Program 11111111111111111111111111111111 invoke [1]","Program 11111111111111111111111111111111 success"],"returnData":{"data":["AQID","base64"],"programId":"11111111111111111111111111111111"},"unitsConsumed":150}}`
+	server, closer := mockJSONRPC(t, stdjson.RawMessage(wrapIntoRPC(responseBody)))
+	defer closer()
+	client := New(server.URL)
+
+	minContextSlot := uint64(83986100)
+	out, err := client.SimulateRawTransactionWithOpts(
+		context.Background(),
+		[]byte{1, 2, 3},
+		&SimulateTransactionOpts{
+			Commitment:             CommitmentConfirmed,
+			ReplaceRecentBlockhash: true,
+			InnerInstructions:      true,
+			MinContextSlot:         &minContextSlot,
+		},
+	)
+	require.NoError(t, err)
+
+	// the ID is random, so we can't assert it; let's check that it is set, and then remove it
+	reqBody := server.RequestBody(t)
+	assert.NotNil(t, reqBody["id"])
+	reqBody["id"] = any(nil)
+
+	assert.Equal(t,
+		map[string]interface{}{
+			"id":      any(nil),
+			"jsonrpc": "2.0",
+			"method":  "simulateTransaction",
+			"params": []interface{}{
+				"AQID",
+				map[string]interface{}{
+					"encoding":               "base64",
+					"commitment":             string(CommitmentConfirmed),
+					"replaceRecentBlockhash": true,
+					"innerInstructions":      true,
+					"minContextSlot":         float64(minContextSlot),
+				},
+			},
+		},
+		reqBody,
+	)
+
+	expected := mustJSONToInterface([]byte(responseBody))
+
+	got := mustJSONToInterface(mustAnyToJSON(out))
+
+	assert.Equal(t, expected, got, "both deserialized values must be equal")
+
+	assert.Equal(t, []byte{1, 2, 3}, out.Value.ReturnData.Data.Content)
+	assert.Equal(t, uint32(1234), *out.Value.LoadedAccountsDataSize)
+	require.Len(t, out.Value.InnerInstructions, 1)
+	assert.Equal(t, int64(2), out.Value.InnerInstructions[0].Instructions[0].StackHeight)
+}
+
+func TestClient_GetStakeMinimumDelegation(t *testing.T) {
+	responseBody := `{"context":{"slot":501},"value":1000000000}`
+	server, closer := mockJSONRPC(t, stdjson.RawMessage(wrapIntoRPC(responseBody)))
+	defer closer()
+	client := New(server.URL)
+
+	out, err := client.GetStakeMinimumDelegation(
+		context.Background(),
+		CommitmentFinalized,
+	)
+	require.NoError(t, err)
+
+	// the ID is random, so we can't assert it; let's check that it is set, and then remove it
+	reqBody := server.RequestBody(t)
+	assert.NotNil(t, reqBody["id"])
+	reqBody["id"] = any(nil)
+
+	assert.Equal(t,
+		map[string]interface{}{
+			"id":      any(nil),
+			"jsonrpc": "2.0",
+			"method":  "getStakeMinimumDelegation",
+			"params": []interface{}{
+				map[string]interface{}{
+					"commitment": string(CommitmentFinalized),
+				},
+			},
+		},
+		reqBody,
+	)
+
+	expected := mustJSONToInterface([]byte(responseBody))
+
+	got := mustJSONToInterface(mustAnyToJSON(out))
+
+	assert.Equal(t, expected, got, "both deserialized values must be equal")
+	assert.Equal(t, uint64(1000000000), out.Value)
 }
 
 func TestClient_GetFeeForMessage(t *testing.T) {
@@ -0,0 +1,226 @@
+// Copyright 2021 github.com/gagliardetto
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/bits"
+	"time"
+)
+
+// MinimumSlotsPerEpoch is the length of the first epoch
+// of a cluster with warmup epochs.
+const MinimumSlotsPerEpoch = 32
+
+// EpochSchedule implements the epoch math of the cluster:
+// the epochs start with MinimumSlotsPerEpoch slots, and double in length
+// until reaching SlotsPerEpoch (if Warmup is set).
+//
+// See https://github.com/solana-labs/solana/blob/master/sdk/program/src/epoch_schedule.rs
+type EpochSchedule struct {
+	// The maximum number of slots in each epoch.
+	SlotsPerEpoch uint64
+
+	// The number of slots before beginning of an epoch to calculate a leader schedule for that epoch.
+	LeaderScheduleSlotOffset uint64
+
+	// Whether epochs start short and grow.
+	Warmup bool
+
+	// First normal-length epoch.
+	FirstNormalEpoch uint64
+
+	// First slot of FirstNormalEpoch.
+	FirstNormalSlot uint64
+}
+
+// NewEpochSchedule creates an EpochSchedule, computing the first normal epoch and slot.
+func NewEpochSchedule(slotsPerEpoch uint64, leaderScheduleSlotOffset uint64, warmup bool) (*EpochSchedule, error) {
+	if slotsPerEpoch < MinimumSlotsPerEpoch {
+		return nil, fmt.Errorf("slotsPerEpoch must be at least %d, got %d", MinimumSlotsPerEpoch, slotsPerEpoch)
+	}
+	schedule := &EpochSchedule{
+		SlotsPerEpoch:            slotsPerEpoch,
+		LeaderScheduleSlotOffset: leaderScheduleSlotOffset,
+		Warmup:                   warmup,
+	}
+	if warmup {
+		nextPowerOfTwo := nextPowerOfTwo(slotsPerEpoch)
+		schedule.FirstNormalEpoch = uint64(bits.TrailingZeros64(nextPowerOfTwo) - bits.TrailingZeros64(MinimumSlotsPerEpoch))
+		schedule.FirstNormalSlot = nextPowerOfTwo - MinimumSlotsPerEpoch
+	}
+	return schedule, nil
+}
+
+// EpochSchedule returns the schedule, for the epoch math.
+func (res *GetEpochScheduleResult) EpochSchedule() *EpochSchedule {
+	return &EpochSchedule{
+		SlotsPerEpoch:            res.SlotsPerEpoch,
+		LeaderScheduleSlotOffset: res.LeaderScheduleSlotOffset,
+		Warmup:                   res.Warmup,
+		FirstNormalEpoch:         res.FirstNormalEpoch,
+		FirstNormalSlot:          res.FirstNormalSlot,
+	}
+}
+
+func nextPowerOfTwo(v uint64) uint64 {
+	if v <= 1 {
+		return 1
+	}
+	return 1 << (64 - bits.LeadingZeros64(v-1))
+}
+
+// SlotsInEpoch returns the number of slots of an epoch.
+func (s *EpochSchedule) SlotsInEpoch(epoch uint64) uint64 {
+	if epoch < s.FirstNormalEpoch {
+		return 1 << (epoch + uint64(bits.TrailingZeros64(MinimumSlotsPerEpoch)))
+	}
+	return s.SlotsPerEpoch
+}
+
+// EpochAndSlotIndex returns the epoch of a slot, and the index of the slot in it.
+func (s *EpochSchedule) EpochAndSlotIndex(slot uint64) (epoch uint64, slotIndex uint64) {
+	if slot < s.FirstNormalSlot {
+		epoch = uint64(bits.TrailingZeros64(nextPowerOfTwo(slot+MinimumSlotsPerEpoch+1))) -
+			uint64(bits.TrailingZeros64(MinimumSlotsPerEpoch)) - 1
+		epochLen := uint64(1) << (epoch + uint64(bits.TrailingZeros64(MinimumSlotsPerEpoch)))
+		return epoch, slot - (epochLen - MinimumSlotsPerEpoch)
+	}
+	normalSlotIndex := slot - s.FirstNormalSlot
+	return s.FirstNormalEpoch + normalSlotIndex/s.SlotsPerEpoch, normalSlotIndex % s.SlotsPerEpoch
+}
+
+// Epoch returns the epoch of a slot.
+func (s *EpochSchedule) Epoch(slot uint64) uint64 {
+	epoch, _ := s.EpochAndSlotIndex(slot)
+	return epoch
+}
+
+// FirstSlotInEpoch returns the first slot of an epoch.
+func (s *EpochSchedule) FirstSlotInEpoch(epoch uint64) uint64 {
+	if epoch <= s.FirstNormalEpoch {
+		return ((uint64(1) << epoch) - 1) * MinimumSlotsPerEpoch
+	}
+	return (epoch-s.FirstNormalEpoch)*s.SlotsPerEpoch + s.FirstNormalSlot
+}
+
+// LastSlotInEpoch returns the last slot of an epoch.
+func (s *EpochSchedule) LastSlotInEpoch(epoch uint64) uint64 {
+	return s.FirstSlotInEpoch(epoch) + s.SlotsInEpoch(epoch) - 1
+}
+
+// SlotsRemainingInEpoch returns the number of slots after slot in its epoch.
+func (s *EpochSchedule) SlotsRemainingInEpoch(slot uint64) uint64 {
+	epoch, slotIndex := s.EpochAndSlotIndex(slot)
+	return s.SlotsInEpoch(epoch) - slotIndex - 1
+}
+
+// LeaderScheduleEpoch returns the epoch whose leader schedule is computed at slot.
+func (s *EpochSchedule) LeaderScheduleEpoch(slot uint64) uint64 {
+	if slot < s.FirstNormalSlot {
+		// Until the first normal epoch, the leader schedule
+		// is computed one epoch ahead.
+		return s.Epoch(slot) + 1
+	}
+	newSlotsSinceFirstNormalSlot := slot - s.FirstNormalSlot
+	newFirstNormalLeaderScheduleSlot := newSlotsSinceFirstNormalSlot + s.LeaderScheduleSlotOffset
+	return s.FirstNormalEpoch + newFirstNormalLeaderScheduleSlot/s.SlotsPerEpoch
+}
+
+// ErrNoPerformanceSamples is returned when estimating durations
+// without performance samples covering any slot.
+var ErrNoPerformanceSamples = errors.New("no performance samples")
+
+// AverageSlotDuration returns the average duration of a slot
+// over the provided performance samples.
+func AverageSlotDuration(samples []*GetRecentPerformanceSamplesResult) (time.Duration, error) {
+	var slots, secs uint64
+	for _, sample := range samples {
+		if sample == nil {
+			continue
+		}
+		slots += sample.NumSlots
+		secs += uint64(sample.SamplePeriodSecs)
+	}
+	if slots == 0 {
+		return 0, ErrNoPerformanceSamples
+	}
+	return time.Duration(secs) * time.Second / time.Duration(slots), nil
+}
+
+// TimeToNextEpoch estimates the time from slot until the first slot of the next epoch.
+func (s *EpochSchedule) TimeToNextEpoch(slot uint64, slotDuration time.Duration) time.Duration {
+	return time.Duration(s.SlotsRemainingInEpoch(slot)+1) * slotDuration
+}
+
+// EpochBoundaryEstimate is an estimate of the start of the next epoch.
+type EpochBoundaryEstimate struct {
+	// Current slot and epoch.
+	Slot  uint64
+	Epoch uint64
+
+	// First slot of the next epoch.
+	NextEpochFirstSlot uint64
+
+	// Number of slots until NextEpochFirstSlot.
+	SlotsRemaining uint64
+
+	// Average duration of the recent slots.
+	SlotDuration time.Duration
+
+	// Estimated time until NextEpochFirstSlot,
+	// and estimated wall-clock time of NextEpochFirstSlot.
+	TimeRemaining time.Duration
+	NextEpochAt   time.Time
+}
+
+// EstimateNextEpochBoundary estimates when the next epoch starts,
+// from the epoch schedule, the current slot, and the recent performance samples.
+func (cl *Client) EstimateNextEpochBoundary(
+	ctx context.Context,
+	commitment CommitmentType, // optional
+) (*EpochBoundaryEstimate, error) {
+	scheduleResult, err := cl.GetEpochSchedule(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get epoch schedule: %w", err)
+	}
+	slot, err := cl.GetSlot(ctx, commitment)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get slot: %w", err)
+	}
+	samples, err := cl.GetRecentPerformanceSamples(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get performance samples: %w", err)
+	}
+	slotDuration, err := AverageSlotDuration(samples)
+	if err != nil {
+		return nil, err
+	}
+
+	schedule := scheduleResult.EpochSchedule()
+	epoch := schedule.Epoch(slot)
+	remaining := schedule.TimeToNextEpoch(slot, slotDuration)
+	return &EpochBoundaryEstimate{
+		Slot:               slot,
+		Epoch:              epoch,
+		NextEpochFirstSlot: schedule.FirstSlotInEpoch(epoch + 1),
+		SlotsRemaining:     schedule.SlotsRemainingInEpoch(slot) + 1,
+		SlotDuration:       slotDuration,
+		TimeRemaining:      remaining,
+		NextEpochAt:        time.Now().Add(remaining),
+	}, nil
+}
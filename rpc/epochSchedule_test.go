@@ -0,0 +1,160 @@
+// Copyright 2021 github.com/gagliardetto
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rpc
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gagliardetto/solana-go/rpc/jsonrpc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Same checks as the epoch_schedule tests of the Solana SDK.
+func TestEpochSchedule_Warmup(t *testing.T) {
+	for slotsPerEpoch := uint64(MinimumSlotsPerEpoch); slotsPerEpoch <= MinimumSlotsPerEpoch*16; slotsPerEpoch++ {
+		schedule, err := NewEpochSchedule(slotsPerEpoch, slotsPerEpoch/2, true)
+		require.NoError(t, err)
+
+		require.Equal(t, uint64(0), schedule.FirstSlotInEpoch(0))
+		require.Equal(t, uint64(MinimumSlotsPerEpoch-1), schedule.LastSlotInEpoch(0))
+
+		var lastLeaderSchedule, lastEpoch uint64
+		lastSlotsInEpoch := uint64(MinimumSlotsPerEpoch)
+		for slot := uint64(0); slot < 2*slotsPerEpoch; slot++ {
+			leaderSchedule := schedule.LeaderScheduleEpoch(slot)
+			if leaderSchedule != lastLeaderSchedule {
+				require.Equal(t, lastLeaderSchedule+1, leaderSchedule)
+				lastLeaderSchedule = leaderSchedule
+			}
+
+			epoch, slotIndex := schedule.EpochAndSlotIndex(slot)
+			if epoch != lastEpoch {
+				require.Equal(t, lastEpoch+1, epoch)
+				lastEpoch = epoch
+				require.Equal(t, slot, schedule.FirstSlotInEpoch(epoch))
+				require.Equal(t, slot-1, schedule.LastSlotInEpoch(epoch-1))
+
+				slotsInEpoch := schedule.SlotsInEpoch(epoch)
+				if slotsInEpoch != lastSlotsInEpoch && slotsInEpoch != slotsPerEpoch {
+					require.Equal(t, lastSlotsInEpoch*2, slotsInEpoch)
+				}
+				lastSlotsInEpoch = slotsInEpoch
+			}
+			require.Less(t, slotIndex, lastSlotsInEpoch)
+			require.Equal(t, lastSlotsInEpoch-slotIndex-1, schedule.SlotsRemainingInEpoch(slot))
+		}
+		require.NotZero(t, lastLeaderSchedule)
+		require.NotZero(t, lastEpoch)
+		require.Equal(t, slotsPerEpoch, lastSlotsInEpoch)
+	}
+}
+
+func TestEpochSchedule_KnownClusters(t *testing.T) {
+	{
+		// Mainnet-beta: no warmup.
+		schedule, err := NewEpochSchedule(432000, 432000, false)
+		require.NoError(t, err)
+		epoch, slotIndex := schedule.EpochAndSlotIndex(250000000)
+		assert.Equal(t, uint64(578), epoch)
+		assert.Equal(t, uint64(304000), slotIndex)
+		assert.Equal(t, uint64(249696000), schedule.FirstSlotInEpoch(578))
+		assert.Equal(t, uint64(250127999), schedule.LastSlotInEpoch(578))
+		assert.Equal(t, uint64(579), schedule.LeaderScheduleEpoch(250000000))
+	}
+	{
+		// Devnet: warmup.
+		schedule, err := NewEpochSchedule(432000, 432000, true)
+		require.NoError(t, err)
+		assert.Equal(t, uint64(14), schedule.FirstNormalEpoch)
+		assert.Equal(t, uint64(524256), schedule.FirstNormalSlot)
+		assert.Equal(t, (&GetEpochScheduleResult{
+			SlotsPerEpoch:            432000,
+			LeaderScheduleSlotOffset: 432000,
+			Warmup:                   true,
+			FirstNormalEpoch:         14,
+			FirstNormalSlot:          524256,
+		}).EpochSchedule(), schedule)
+
+		assert.Equal(t, uint64(3), schedule.Epoch(224))
+		assert.Equal(t, uint64(256), schedule.SlotsInEpoch(3))
+		assert.Equal(t, uint64(524256), schedule.FirstSlotInEpoch(14))
+		assert.Equal(t, uint64(524256+432000), schedule.FirstSlotInEpoch(15))
+	}
+
+	_, err := NewEpochSchedule(MinimumSlotsPerEpoch-1, 0, false)
+	require.Error(t, err)
+}
+
+func TestAverageSlotDuration(t *testing.T) {
+	_, err := AverageSlotDuration(nil)
+	require.ErrorIs(t, err, ErrNoPerformanceSamples)
+
+	got, err := AverageSlotDuration([]*GetRecentPerformanceSamplesResult{
+		{NumSlots: 150, SamplePeriodSecs: 60},
+		{NumSlots: 150, SamplePeriodSecs: 60},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 400*time.Millisecond, got)
+}
+
+// staticClient answers each method with a fixed result.
+type staticClient map[string]interface{}
+
+func (c staticClient) CallForInto(ctx context.Context, out interface{}, method string, params []interface{}) error {
+	result, ok := c[method]
+	if !ok {
+		return errors.New("unexpected method " + method)
+	}
+	raw, err := json.Marshal(result)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(raw, out)
+}
+
+func (c staticClient) CallWithCallback(context.Context, string, []interface{}, func(*http.Request, *http.Response) error) error {
+	return errors.New("not supported")
+}
+
+func (c staticClient) CallBatch(context.Context, jsonrpc.RPCRequests) (jsonrpc.RPCResponses, error) {
+	return nil, errors.New("not supported")
+}
+
+func TestClient_EstimateNextEpochBoundary(t *testing.T) {
+	client := NewWithCustomRPCClient(staticClient{
+		"getEpochSchedule": &GetEpochScheduleResult{SlotsPerEpoch: 432000, LeaderScheduleSlotOffset: 432000},
+		"getSlot":          250000000,
+		"getRecentPerformanceSamples": []*GetRecentPerformanceSamplesResult{
+			{Slot: 250000000, NumSlots: 120, SamplePeriodSecs: 60},
+		},
+	})
+
+	before := time.Now()
+	got, err := client.EstimateNextEpochBoundary(context.Background(), CommitmentFinalized)
+	require.NoError(t, err)
+
+	assert.Equal(t, uint64(250000000), got.Slot)
+	assert.Equal(t, uint64(578), got.Epoch)
+	assert.Equal(t, uint64(250128000), got.NextEpochFirstSlot)
+	assert.Equal(t, uint64(128000), got.SlotsRemaining)
+	assert.Equal(t, 500*time.Millisecond, got.SlotDuration)
+	assert.Equal(t, 64000*time.Second, got.TimeRemaining)
+	assert.False(t, got.NextEpochAt.Before(before.Add(got.TimeRemaining)))
+}
@@ -14,6 +14,17 @@
 
 package rpc
 
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"regexp"
+	"strconv"
+
+	"github.com/gagliardetto/solana-go/rpc/jsonrpc"
+)
+
 // rpc error:
 // - https://github.com/solana-labs/solana/blob/d5961e9d9f005966f409fbddd40c3651591b27fb/client/src/rpc_custom_error.rs
 
@@ -23,3 +34,348 @@ package rpc
 
 // instruction error
 // - https://github.com/solana-labs/solana/blob/f6371cce176d481b4132e5061262ca015db0f8b1/sdk/program/src/instruction.rs
+
+// Solana JSON-RPC server error codes.
+const (
+	ErrorCodeBlockCleanedUp                           = -32001
+	ErrorCodeSendTransactionPreflightFailure          = -32002
+	ErrorCodeTransactionSignatureVerificationFailure  = -32003
+	ErrorCodeBlockNotAvailable                        = -32004
+	ErrorCodeNodeUnhealthy                            = -32005
+	ErrorCodeTransactionPrecompileVerificationFailure = -32006
+	ErrorCodeSlotSkipped                              = -32007
+	ErrorCodeNoSnapshot                               = -32008
+	ErrorCodeLongTermStorageSlotSkipped               = -32009
+	ErrorCodeKeyExcludedFromSecondaryIndex            = -32010
+	ErrorCodeTransactionHistoryNotAvailable           = -32011
+	ErrorCodeScanError                                = -32012
+	ErrorCodeTransactionSignatureLenMismatch          = -32013
+	ErrorCodeBlockStatusNotAvailableYet               = -32014
+	ErrorCodeUnsupportedTransactionVersion            = -32015
+	ErrorCodeMinContextSlotNotReached                 = -32016
+	ErrorCodeEpochRewardsPeriodActive                 = -32017
+	ErrorCodeSlotNotEpochBoundary                     = -32018
+	ErrorCodeLongTermStorageUnreachable               = -32019
+)
+
+// All the typed errors below embed the original *jsonrpc.RPCError,
+// and unwrap to it, so `errors.As(err, &rpcErr)` keeps working.
+
+// BlockCleanedUpError is returned when the requested block
+// was cleaned up from the node ledger.
+type BlockCleanedUpError struct {
+	*jsonrpc.RPCError
+	Slot                uint64
+	FirstAvailableBlock uint64
+}
+
+// SendTransactionPreflightFailureError is returned by sendTransaction
+// when the preflight simulation fails.
+type SendTransactionPreflightFailureError struct {
+	*jsonrpc.RPCError
+	// Result of the preflight simulation (logs, err, units consumed, etc.).
+	Result *SimulateTransactionResult
+}
+
+// TransactionSignatureVerificationFailureError is returned
+// when the transaction signatures can't be verified.
+type TransactionSignatureVerificationFailureError struct {
+	*jsonrpc.RPCError
+}
+
+// BlockNotAvailableError is returned when the requested block is not available yet.
+type BlockNotAvailableError struct {
+	*jsonrpc.RPCError
+	Slot uint64
+}
+
+// NodeUnhealthyError is returned when the node is behind the cluster.
+type NodeUnhealthyError struct {
+	*jsonrpc.RPCError
+	// Number of slots the node is behind, if known.
+	NumSlotsBehind *uint64
+}
+
+// TransactionPrecompileVerificationFailureError is returned
+// when a precompiled program (ed25519, secp256k1) rejects the transaction.
+type TransactionPrecompileVerificationFailureError struct {
+	*jsonrpc.RPCError
+}
+
+// SlotSkippedError is returned when the requested slot was skipped,
+// or is missing due to a ledger jump to a recent snapshot.
+type SlotSkippedError struct {
+	*jsonrpc.RPCError
+	Slot uint64
+}
+
+// NoSnapshotError is returned when the node has no snapshot.
+type NoSnapshotError struct {
+	*jsonrpc.RPCError
+}
+
+// LongTermStorageSlotSkippedError is returned when the requested slot
+// was skipped, or is missing in long-term storage.
+type LongTermStorageSlotSkippedError struct {
+	*jsonrpc.RPCError
+	Slot uint64
+}
+
+// KeyExcludedFromSecondaryIndexError is returned when the requested key
+// is excluded from the node account secondary indexes.
+type KeyExcludedFromSecondaryIndexError struct {
+	*jsonrpc.RPCError
+	IndexKey string
+}
+
+// TransactionHistoryNotAvailableError is returned when the node
+// doesn't have transaction history enabled.
+type TransactionHistoryNotAvailableError struct {
+	*jsonrpc.RPCError
+}
+
+// ScanError is returned when an accounts scan fails on the node.
+type ScanError struct {
+	*jsonrpc.RPCError
+}
+
+// TransactionSignatureLenMismatchError is returned when the number of signatures
+// doesn't match the number of required signers.
+type TransactionSignatureLenMismatchError struct {
+	*jsonrpc.RPCError
+}
+
+// BlockStatusNotAvailableYetError is returned when the status
+// of the requested block is not available yet.
+type BlockStatusNotAvailableYetError struct {
+	*jsonrpc.RPCError
+	Slot uint64
+}
+
+// UnsupportedTransactionVersionError is returned when the response contains
+// a transaction with a version higher than `maxSupportedTransactionVersion`.
+type UnsupportedTransactionVersionError struct {
+	*jsonrpc.RPCError
+	Version uint8
+}
+
+// MinContextSlotNotReachedError is returned when the node
+// has not reached the requested `minContextSlot` yet.
+type MinContextSlotNotReachedError struct {
+	*jsonrpc.RPCError
+	// Slot the node is at.
+	ContextSlot uint64
+}
+
+// EpochRewardsPeriodActiveError is returned when the epoch rewards
+// are being distributed and can't be queried yet.
+type EpochRewardsPeriodActiveError struct {
+	*jsonrpc.RPCError
+	Slot                       uint64
+	CurrentBlockHeight         uint64
+	RewardsCompleteBlockHeight uint64
+}
+
+// SlotNotEpochBoundaryError is returned when rewards are requested
+// for a slot that is not an epoch boundary.
+type SlotNotEpochBoundaryError struct {
+	*jsonrpc.RPCError
+	Slot uint64
+}
+
+// LongTermStorageUnreachableError is returned when the node
+// can't reach its long-term storage.
+type LongTermStorageUnreachableError struct {
+	*jsonrpc.RPCError
+}
+
+func (e *BlockCleanedUpError) Unwrap() error                           { return e.RPCError }
+func (e *SendTransactionPreflightFailureError) Unwrap() error          { return e.RPCError }
+func (e *TransactionSignatureVerificationFailureError) Unwrap() error  { return e.RPCError }
+func (e *BlockNotAvailableError) Unwrap() error                        { return e.RPCError }
+func (e *NodeUnhealthyError) Unwrap() error                            { return e.RPCError }
+func (e *TransactionPrecompileVerificationFailureError) Unwrap() error { return e.RPCError }
+func (e *SlotSkippedError) Unwrap() error                              { return e.RPCError }
+func (e *NoSnapshotError) Unwrap() error                               { return e.RPCError }
+func (e *LongTermStorageSlotSkippedError) Unwrap() error               { return e.RPCError }
+func (e *KeyExcludedFromSecondaryIndexError) Unwrap() error            { return e.RPCError }
+func (e *TransactionHistoryNotAvailableError) Unwrap() error           { return e.RPCError }
+func (e *ScanError) Unwrap() error                                     { return e.RPCError }
+func (e *TransactionSignatureLenMismatchError) Unwrap() error          { return e.RPCError }
+func (e *BlockStatusNotAvailableYetError) Unwrap() error               { return e.RPCError }
+func (e *UnsupportedTransactionVersionError) Unwrap() error            { return e.RPCError }
+func (e *MinContextSlotNotReachedError) Unwrap() error                 { return e.RPCError }
+func (e *EpochRewardsPeriodActiveError) Unwrap() error                 { return e.RPCError }
+func (e *SlotNotEpochBoundaryError) Unwrap() error                     { return e.RPCError }
+func (e *LongTermStorageUnreachableError) Unwrap() error               { return e.RPCError }
+
+var (
+	firstNumberRegex  = regexp.MustCompile(`\d+`)
+	firstAvailableRgx = regexp.MustCompile(`First available block: (\d+)`)
+	indexKeyRegex     = regexp.MustCompile(`^(\S+) excluded from account secondary indexes`)
+)
+
+// ParseRPCError converts a *jsonrpc.RPCError carrying one of the Solana
+// server error codes into the matching typed error (e.g. *SlotSkippedError).
+// Any other error is returned as-is.
+func ParseRPCError(err error) error {
+	var rpcErr *jsonrpc.RPCError
+	if !errors.As(err, &rpcErr) || rpcErr == nil {
+		return err
+	}
+	switch rpcErr.Code {
+	case ErrorCodeBlockCleanedUp:
+		out := &BlockCleanedUpError{RPCError: rpcErr, Slot: firstNumberInMessage(rpcErr)}
+		if m := firstAvailableRgx.FindStringSubmatch(rpcErr.Message); m != nil {
+			out.FirstAvailableBlock, _ = strconv.ParseUint(m[1], 10, 64)
+		}
+		return out
+	case ErrorCodeSendTransactionPreflightFailure:
+		out := &SendTransactionPreflightFailureError{RPCError: rpcErr}
+		if rpcErr.Data != nil {
+			var res SimulateTransactionResult
+			if decodeErrorData(rpcErr.Data, &res) == nil {
+				out.Result = &res
+			}
+		}
+		return out
+	case ErrorCodeTransactionSignatureVerificationFailure:
+		return &TransactionSignatureVerificationFailureError{RPCError: rpcErr}
+	case ErrorCodeBlockNotAvailable:
+		return &BlockNotAvailableError{RPCError: rpcErr, Slot: firstNumberInMessage(rpcErr)}
+	case ErrorCodeNodeUnhealthy:
+		out := &NodeUnhealthyError{RPCError: rpcErr}
+		var data struct {
+			NumSlotsBehind *uint64 `json:"numSlotsBehind"`
+		}
+		if decodeErrorData(rpcErr.Data, &data) == nil {
+			out.NumSlotsBehind = data.NumSlotsBehind
+		}
+		return out
+	case ErrorCodeTransactionPrecompileVerificationFailure:
+		return &TransactionPrecompileVerificationFailureError{RPCError: rpcErr}
+	case ErrorCodeSlotSkipped:
+		return &SlotSkippedError{RPCError: rpcErr, Slot: firstNumberInMessage(rpcErr)}
+	case ErrorCodeNoSnapshot:
+		return &NoSnapshotError{RPCError: rpcErr}
+	case ErrorCodeLongTermStorageSlotSkipped:
+		return &LongTermStorageSlotSkippedError{RPCError: rpcErr, Slot: firstNumberInMessage(rpcErr)}
+	case ErrorCodeKeyExcludedFromSecondaryIndex:
+		out := &KeyExcludedFromSecondaryIndexError{RPCError: rpcErr}
+		if m := indexKeyRegex.FindStringSubmatch(rpcErr.Message); m != nil {
+			out.IndexKey = m[1]
+		}
+		return out
+	case ErrorCodeTransactionHistoryNotAvailable:
+		return &TransactionHistoryNotAvailableError{RPCError: rpcErr}
+	case ErrorCodeScanError:
+		return &ScanError{RPCError: rpcErr}
+	case ErrorCodeTransactionSignatureLenMismatch:
+		return &TransactionSignatureLenMismatchError{RPCError: rpcErr}
+	case ErrorCodeBlockStatusNotAvailableYet:
+		return &BlockStatusNotAvailableYetError{RPCError: rpcErr, Slot: firstNumberInMessage(rpcErr)}
+	case ErrorCodeUnsupportedTransactionVersion:
+		return &UnsupportedTransactionVersionError{RPCError: rpcErr, Version: uint8(firstNumberInMessage(rpcErr))}
+	case ErrorCodeMinContextSlotNotReached:
+		out := &MinContextSlotNotReachedError{RPCError: rpcErr}
+		var data struct {
+			ContextSlot uint64 `json:"contextSlot"`
+		}
+		if decodeErrorData(rpcErr.Data, &data) == nil {
+			out.ContextSlot = data.ContextSlot
+		}
+		return out
+	case ErrorCodeEpochRewardsPeriodActive:
+		out := &EpochRewardsPeriodActiveError{RPCError: rpcErr}
+		var data struct {
+			Slot                       uint64 `json:"slot"`
+			CurrentBlockHeight         uint64 `json:"currentBlockHeight"`
+			RewardsCompleteBlockHeight uint64 `json:"rewardsCompleteBlockHeight"`
+		}
+		if decodeErrorData(rpcErr.Data, &data) == nil {
+			out.Slot = data.Slot
+			out.CurrentBlockHeight = data.CurrentBlockHeight
+			out.RewardsCompleteBlockHeight = data.RewardsCompleteBlockHeight
+		}
+		return out
+	case ErrorCodeSlotNotEpochBoundary:
+		return &SlotNotEpochBoundaryError{RPCError: rpcErr, Slot: firstNumberInMessage(rpcErr)}
+	case ErrorCodeLongTermStorageUnreachable:
+		return &LongTermStorageUnreachableError{RPCError: rpcErr}
+	}
+	return err
+}
+
+// ResponseError returns the typed error of a response of a batch,
+// or nil if the call succeeded.
+func ResponseError(resp *jsonrpc.RPCResponse) error {
+	if resp == nil || resp.Error == nil {
+		return nil
+	}
+	return ParseRPCError(resp.Error)
+}
+
+// IsBlockhashNotFound returns true if the error is a preflight failure
+// caused by an expired (or unknown) recent blockhash.
+// The error may also be the raw error of a batch response.
+func IsBlockhashNotFound(err error) bool {
+	var preflightErr *SendTransactionPreflightFailureError
+	if !errors.As(ParseRPCError(err), &preflightErr) || preflightErr.Result == nil {
+		return false
+	}
+	asString, ok := preflightErr.Result.Err.(string)
+	return ok && asString == "BlockhashNotFound"
+}
+
+func firstNumberInMessage(rpcErr *jsonrpc.RPCError) uint64 {
+	num, _ := strconv.ParseUint(firstNumberRegex.FindString(rpcErr.Message), 10, 64)
+	return num
+}
+
+// decodeErrorData decodes the generic `data` field of an RPC error into `out`.
+func decodeErrorData(data interface{}, out interface{}) error {
+	if data == nil {
+		return errors.New("no data")
+	}
+	buf, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(buf, out)
+}
+
+// clientWithTypedErrors converts the errors returned by the wrapped
+// JSONRPCClient into the typed errors above. The errors of the responses
+// of a batch are *jsonrpc.RPCError values; convert them with ResponseError.
+type clientWithTypedErrors struct {
+	rpcClient JSONRPCClient
+}
+
+func (wr *clientWithTypedErrors) CallForInto(ctx context.Context, out interface{}, method string, params []interface{}) error {
+	return ParseRPCError(wr.rpcClient.CallForInto(ctx, out, method, params))
+}
+
+func (wr *clientWithTypedErrors) CallWithCallback(
+	ctx context.Context,
+	method string,
+	params []interface{},
+	callback func(*http.Request, *http.Response) error,
+) error {
+	return ParseRPCError(wr.rpcClient.CallWithCallback(ctx, method, params, callback))
+}
+
+func (wr *clientWithTypedErrors) CallBatch(
+	ctx context.Context,
+	requests jsonrpc.RPCRequests,
+) (jsonrpc.RPCResponses, error) {
+	responses, err := wr.rpcClient.CallBatch(ctx, requests)
+	return responses, ParseRPCError(err)
+}
+
+// Close closes clientWithTypedErrors.
+func (wr *clientWithTypedErrors) Close() error {
+	if c, ok := wr.rpcClient.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
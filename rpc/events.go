@@ -0,0 +1,110 @@
+// Copyright 2021 github.com/gagliardetto
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rpc
+
+import (
+	"fmt"
+
+	"github.com/gagliardetto/solana-go"
+)
+
+// Event is an event emitted by a program, decoded with
+// the decoder registered with solana.RegisterEventDecoder.
+type Event struct {
+	ProgramID solana.PublicKey
+
+	// Whether the event was emitted with emit_cpi!,
+	// instead of being logged with emit!.
+	CPI bool
+
+	// The event data, starting with its discriminator.
+	Data []byte
+
+	// The decoded event; nil if Err is set.
+	Decoded interface{}
+
+	// The error of the decoder, e.g. for an unknown discriminator or
+	// data logged with sol_log_data that isn't an event.
+	Err error
+}
+
+// Events decodes the "Program data:" events of the programs
+// that have a registered event decoder, in execution order.
+// The data the decoder rejects is returned as events with Err set,
+// so that it doesn't hide the other events.
+func (logs *ProgramLogs) Events() []*Event {
+	var events []*Event
+	logs.Walk(func(invocation *ProgramInvocation) bool {
+		if !solana.HasEventDecoder(invocation.ProgramID) {
+			return true
+		}
+		for _, data := range invocation.Data {
+			if len(data) == 0 {
+				continue
+			}
+			events = append(events, decodeEvent(invocation.ProgramID, data[0], false))
+		}
+		return true
+	})
+	return events
+}
+
+// DecodeTransactionEvents decodes the events of the programs
+// that have a registered event decoder: first the events logged
+// with emit!, in execution order, then the events of the emit_cpi!
+// inner instructions, in execution order. The two kinds are not
+// interleaved. As with ProgramLogs.Events, the data the decoder
+// rejects is returned as events with Err set.
+func DecodeTransactionEvents(tx *solana.Transaction, meta *TransactionMeta) ([]*Event, error) {
+	logs, err := meta.ParseLogs()
+	if err != nil {
+		return nil, err
+	}
+	events := logs.Events()
+
+	keys := append(solana.PublicKeySlice{}, tx.Message.AccountKeys...)
+	if !tx.Message.IsResolved() {
+		keys = append(keys, meta.LoadedAddresses.Writable...)
+		keys = append(keys, meta.LoadedAddresses.ReadOnly...)
+	}
+	for _, inner := range meta.InnerInstructions {
+		for _, instruction := range inner.Instructions {
+			if int(instruction.ProgramIDIndex) >= len(keys) {
+				return nil, fmt.Errorf("programID index not found %d", instruction.ProgramIDIndex)
+			}
+			programID := keys[instruction.ProgramIDIndex]
+			if !solana.IsAnchorEventInstruction(instruction.Data) || !solana.HasEventDecoder(programID) {
+				continue
+			}
+			events = append(events, decodeEvent(programID, instruction.Data[len(solana.AnchorEventInstructionTag):], true))
+		}
+	}
+	return events, nil
+}
+
+func decodeEvent(programID solana.PublicKey, data []byte, cpi bool) *Event {
+	event := &Event{
+		ProgramID: programID,
+		CPI:       cpi,
+		Data:      data,
+	}
+	decoded, err := solana.DecodeEvent(programID, data)
+	if err != nil {
+		event.Err = fmt.Errorf("unable to decode event of program %s: %w", programID, err)
+		return event
+	}
+	event.Decoded = decoded
+	return event
+}
@@ -0,0 +1,99 @@
+// Copyright 2021 github.com/gagliardetto
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rpc
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"testing"
+
+	bin "github.com/gagliardetto/binary"
+	"github.com/gagliardetto/solana-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testEvent struct {
+	Amount uint64
+}
+
+var testEventDiscriminator = solana.AnchorEventDiscriminator("TestEvent")
+
+func encodeTestEvent(t *testing.T, amount uint64) []byte {
+	buf := new(bytes.Buffer)
+	buf.Write(testEventDiscriminator[:])
+	require.NoError(t, bin.NewBorshEncoder(buf).Encode(testEvent{Amount: amount}))
+	return buf.Bytes()
+}
+
+func decodeTestEvent(data []byte) (interface{}, error) {
+	if !bytes.Equal(data[:8], testEventDiscriminator[:]) {
+		return nil, errors.New("unknown event")
+	}
+	event := new(testEvent)
+	return event, bin.NewBorshDecoder(data[8:]).Decode(event)
+}
+
+func TestDecodeTransactionEvents(t *testing.T) {
+	program := solana.NewWallet().PublicKey()
+	solana.RegisterEventDecoder(program, decodeTestEvent)
+	other := solana.NewWallet().PublicKey()
+	payer := solana.NewWallet().PublicKey()
+
+	tx := &solana.Transaction{Message: solana.Message{AccountKeys: solana.PublicKeySlice{payer, program}}}
+	meta := &TransactionMeta{
+		LogMessages: []string{
+			"Program " + program.String() + " invoke [1]",
+			"Program data: " + base64.StdEncoding.EncodeToString(encodeTestEvent(t, 1)),
+			"Program " + other.String() + " invoke [2]",
+			"Program data: AQID",
+			"Program " + other.String() + " success",
+			"Program " + program.String() + " invoke [2]",
+			"Program " + program.String() + " success",
+			"Program " + program.String() + " success",
+		},
+		InnerInstructions: []InnerInstruction{{
+			Index: 0,
+			Instructions: []CompiledInstruction{
+				{ProgramIDIndex: 2, Data: []byte{1, 2, 3}},
+				{ProgramIDIndex: 1, Data: append(append([]byte{}, solana.AnchorEventInstructionTag...), encodeTestEvent(t, 2)...)},
+			},
+		}},
+		LoadedAddresses: LoadedAddresses{ReadOnly: solana.PublicKeySlice{other}},
+	}
+
+	events, err := DecodeTransactionEvents(tx, meta)
+	require.NoError(t, err)
+	require.Len(t, events, 2)
+	assert.Equal(t, program, events[0].ProgramID)
+	assert.False(t, events[0].CPI)
+	assert.Equal(t, &testEvent{Amount: 1}, events[0].Decoded)
+	assert.True(t, events[1].CPI)
+	assert.Equal(t, &testEvent{Amount: 2}, events[1].Decoded)
+	assert.Equal(t, encodeTestEvent(t, 2), events[1].Data)
+
+	// Unknown events of a registered program are reported on their own,
+	// without hiding the other events.
+	meta.LogMessages[1] = "Program data: " + base64.StdEncoding.EncodeToString(make([]byte, 8))
+	events, err = DecodeTransactionEvents(tx, meta)
+	require.NoError(t, err)
+	require.Len(t, events, 2)
+	assert.Error(t, events[0].Err)
+	assert.Nil(t, events[0].Decoded)
+	assert.Equal(t, make([]byte, 8), events[0].Data)
+	assert.NoError(t, events[1].Err)
+	assert.Equal(t, &testEvent{Amount: 2}, events[1].Decoded)
+}
@@ -0,0 +1,63 @@
+// Copyright 2021 github.com/gagliardetto
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"os"
+
+	"github.com/davecgh/go-spew/spew"
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+	"github.com/gagliardetto/solana-go/rpc/jsonrpc"
+	"github.com/gagliardetto/solana-go/rpc/rpcreplay"
+)
+
+// The first run records the calls to mainnet-beta into fixture.json;
+// the next runs replay them without network.
+func main() {
+	const fixture = "fixture.json"
+
+	var rpcClient rpc.JSONRPCClient
+	var recorder *rpcreplay.Recorder
+	if _, err := os.Stat(fixture); err == nil {
+		replayer, err := rpcreplay.NewReplayerFromFile(fixture)
+		if err != nil {
+			panic(err)
+		}
+		rpcClient = replayer
+	} else {
+		recorder = rpcreplay.NewRecorder(jsonrpc.NewClient(rpc.MainNetBeta_RPC), nil)
+		rpcClient = recorder
+	}
+	client := rpc.NewWithCustomRPCClient(rpcClient)
+
+	pubKey := solana.MustPublicKeyFromBase58("SRMuApVNdxXokk5GT7XD5cUUgXMBCoAz2LHeuAoKWRt") // serum token
+	out, err := client.GetBalance(
+		context.TODO(),
+		pubKey,
+		rpc.CommitmentFinalized,
+	)
+	if err != nil {
+		panic(err)
+	}
+	spew.Dump(out)
+
+	if recorder != nil {
+		if err := recorder.Save(fixture); err != nil {
+			panic(err)
+		}
+	}
+}
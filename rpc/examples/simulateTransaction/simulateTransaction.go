@@ -14,6 +14,61 @@
 
 package main
 
+import (
+	"context"
+
+	"github.com/davecgh/go-spew/spew"
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/programs/system"
+	"github.com/gagliardetto/solana-go/rpc"
+)
+
 func main() {
+	endpoint := rpc.TestNet_RPC
+	client := rpc.New(endpoint)
+
+	accountFrom := solana.NewWallet().PrivateKey
+	accountTo := solana.MustPublicKeyFromBase58("EW2p7QCJNHMVj5nQCcW7Q2BDETtNBXn68FyucU4RCjvb")
+
+	tx, err := solana.NewTransaction(
+		[]solana.Instruction{
+			system.NewTransferInstruction(
+				3333,
+				accountFrom.PublicKey(),
+				accountTo,
+			).Build(),
+		},
+		// The blockhash is replaced by the node (see ReplaceRecentBlockhash).
+		solana.Hash{},
+		solana.TransactionPayer(accountFrom.PublicKey()),
+	)
+	if err != nil {
+		panic(err)
+	}
+	_, err = tx.Sign(
+		func(key solana.PublicKey) *solana.PrivateKey {
+			if accountFrom.PublicKey().Equals(key) {
+				return &accountFrom
+			}
+			return nil
+		},
+	)
+	if err != nil {
+		panic(err)
+	}
 
+	out, err := client.SimulateTransactionWithOpts(
+		context.TODO(),
+		tx,
+		&rpc.SimulateTransactionOpts{
+			Commitment:             rpc.CommitmentConfirmed,
+			ReplaceRecentBlockhash: true,
+			InnerInstructions:      true,
+		},
+	)
+	if err != nil {
+		panic(err)
+	}
+	// The payer has no funds: out.Value.Err is set.
+	spew.Dump(out)
 }
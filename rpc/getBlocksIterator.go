@@ -0,0 +1,276 @@
+// Copyright 2021 github.com/gagliardetto
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rpc
+
+import (
+	"context"
+)
+
+// DefaultBlocksPageSize is the number of slots requested per getBlocksWithLimit call
+// by a BlocksIterator, unless configured otherwise.
+const DefaultBlocksPageSize = 1000
+
+type BlocksIteratorOpts struct {
+	// Direction of the walk. Defaults to IterBackward.
+	Direction IterDirection
+
+	// (optional) Number of slots requested per page (default: 1,000).
+	PageSize uint64
+
+	// (optional) Resume token, as returned by `BlocksIterator.Cursor()`.
+	// The walk continues right after this slot, in the configured direction.
+	ResumeAfter *uint64
+
+	// (optional) "processed" is not supported.
+	// If parameter not provided, the default is "finalized".
+	Commitment CommitmentType
+
+	// If true, the full block is fetched for each emitted slot.
+	FetchBlocks bool
+
+	// (optional) Options used to fetch each block.
+	// Defaults to the iterator commitment and MaxSupportedTransactionVersion0.
+	BlockOpts *GetBlockOpts
+
+	// (optional) Maximum number of concurrent getBlock calls (default: 1).
+	// Blocks are always emitted in the order of the walk.
+	Concurrency int
+}
+
+// BlocksIterator walks the confirmed blocks of a slot range,
+// page by page, using getBlocksWithLimit.
+type BlocksIterator struct {
+	cl   *Client
+	opts BlocksIteratorOpts
+
+	startSlot uint64
+	endSlot   uint64
+
+	// Next slot to be requested; the page goes up from it (forward)
+	// or down from it (backward).
+	next uint64
+	done bool
+
+	buf    []uint64
+	blocks []*GetBlockResult
+	pos    int
+	cursor *uint64
+	err    error
+}
+
+// NewBlocksIterator creates a new iterator over the confirmed blocks
+// between startSlot and endSlot, inclusive.
+func (cl *Client) NewBlocksIterator(
+	startSlot uint64,
+	endSlot uint64,
+	opts *BlocksIteratorOpts,
+) *BlocksIterator {
+	it := &BlocksIterator{
+		cl:        cl,
+		startSlot: startSlot,
+		endSlot:   endSlot,
+		pos:       -1,
+	}
+	if opts != nil {
+		it.opts = *opts
+	}
+	if it.opts.PageSize == 0 {
+		it.opts.PageSize = DefaultBlocksPageSize
+	}
+	if it.opts.Concurrency <= 0 {
+		it.opts.Concurrency = 1
+	}
+	if startSlot > endSlot {
+		it.done = true
+		return it
+	}
+
+	if it.opts.Direction == IterBackward {
+		it.next = endSlot
+	} else {
+		it.next = startSlot
+	}
+	if it.opts.ResumeAfter != nil {
+		resume := *it.opts.ResumeAfter
+		it.cursor = &resume
+		if it.opts.Direction == IterBackward {
+			if resume <= startSlot {
+				it.done = true
+			} else if resume-1 < it.next {
+				it.next = resume - 1
+			}
+		} else {
+			if resume >= endSlot {
+				it.done = true
+			} else if resume+1 > it.next {
+				it.next = resume + 1
+			}
+		}
+	}
+	return it
+}
+
+// Next advances the iterator to the next confirmed block.
+// It returns false when the walk is over or an error occurred;
+// check `Err()` to tell them apart.
+func (it *BlocksIterator) Next(ctx context.Context) bool {
+	if it.err != nil {
+		return false
+	}
+	for {
+		if it.pos+1 < len(it.buf) {
+			it.pos++
+			slot := it.buf[it.pos]
+			it.cursor = &slot
+			return true
+		}
+		if it.done {
+			return false
+		}
+		if err := it.fill(ctx); err != nil {
+			it.err = err
+			return false
+		}
+	}
+}
+
+// Slot returns the slot of the current block.
+func (it *BlocksIterator) Slot() uint64 {
+	if it.pos < 0 || it.pos >= len(it.buf) {
+		return 0
+	}
+	return it.buf[it.pos]
+}
+
+// Block returns the current block.
+// It is nil unless `FetchBlocks` is set.
+func (it *BlocksIterator) Block() *GetBlockResult {
+	if it.pos < 0 || it.pos >= len(it.blocks) {
+		return nil
+	}
+	return it.blocks[it.pos]
+}
+
+// Cursor returns the resume token of the iterator: the last slot
+// that was fully processed by `Next`, or nil if none was.
+// Pass it as `ResumeAfter` to continue the walk from there.
+func (it *BlocksIterator) Cursor() *uint64 {
+	return it.cursor
+}
+
+// Err returns the error that stopped the iterator, if any.
+func (it *BlocksIterator) Err() error {
+	return it.err
+}
+
+func (it *BlocksIterator) fill(ctx context.Context) error {
+	var page []uint64
+	var err error
+	if it.opts.Direction == IterBackward {
+		page, err = it.loadBackward(ctx)
+	} else {
+		page, err = it.loadForward(ctx)
+	}
+	if err != nil {
+		return err
+	}
+
+	var blocks []*GetBlockResult
+	if it.opts.FetchBlocks {
+		blocks, err = it.fetchBlocks(ctx, page)
+		if err != nil {
+			return err
+		}
+	}
+	it.buf = page
+	it.blocks = blocks
+	it.pos = -1
+	return nil
+}
+
+func (it *BlocksIterator) loadForward(ctx context.Context) ([]uint64, error) {
+	res, err := it.cl.GetBlocksWithLimit(ctx, it.next, it.opts.PageSize, it.opts.Commitment)
+	if err != nil {
+		return nil, err
+	}
+	var page []uint64
+	if res != nil {
+		for _, slot := range *res {
+			if slot < it.next || slot > it.endSlot {
+				continue
+			}
+			page = append(page, slot)
+		}
+	}
+	if uint64(len(page)) < it.opts.PageSize || page[len(page)-1] >= it.endSlot {
+		it.done = true
+		return page, nil
+	}
+	it.next = page[len(page)-1] + 1
+	return page, nil
+}
+
+// loadBackward requests the window of PageSize slots that ends at `next`;
+// that window can't hold more than PageSize blocks, so a single
+// getBlocksWithLimit call returns all of them.
+func (it *BlocksIterator) loadBackward(ctx context.Context) ([]uint64, error) {
+	from := it.startSlot
+	if it.next-it.startSlot >= it.opts.PageSize {
+		from = it.next - it.opts.PageSize + 1
+	}
+	res, err := it.cl.GetBlocksWithLimit(ctx, from, it.opts.PageSize, it.opts.Commitment)
+	if err != nil {
+		return nil, err
+	}
+	var page []uint64
+	if res != nil {
+		for i := len(*res) - 1; i >= 0; i-- {
+			slot := (*res)[i]
+			if slot < from || slot > it.next {
+				continue
+			}
+			page = append(page, slot)
+		}
+	}
+	if from <= it.startSlot {
+		it.done = true
+		return page, nil
+	}
+	it.next = from - 1
+	return page, nil
+}
+
+func (it *BlocksIterator) fetchBlocks(ctx context.Context, page []uint64) ([]*GetBlockResult, error) {
+	blockOpts := it.opts.BlockOpts
+	if blockOpts == nil {
+		blockOpts = &GetBlockOpts{
+			Commitment:                     it.opts.Commitment,
+			MaxSupportedTransactionVersion: &MaxSupportedTransactionVersion0,
+		}
+	}
+	out := make([]*GetBlockResult, len(page))
+	err := forEachOrdered(ctx, len(page), it.opts.Concurrency, func(ctx context.Context, i int) error {
+		block, err := it.cl.GetBlockWithOpts(ctx, page[i], blockOpts)
+		if err != nil {
+			return err
+		}
+		out[i] = block
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
@@ -81,3 +81,39 @@ const (
 	ConfirmationStatusConfirmed ConfirmationStatusType = "confirmed"
 	ConfirmationStatusFinalized ConfirmationStatusType = "finalized"
 )
+
+// Reaches reports whether the transaction satisfies the commitment.
+// An empty or unknown commitment means finalized, and the deprecated ones
+// mean their current equivalent. For nodes not returning the confirmation
+// status, null confirmations mean rooted, i.e. finalized.
+func (res *SignatureStatusesResult) Reaches(commitment CommitmentType) bool {
+	status := res.ConfirmationStatus
+	if status == "" && res.Confirmations == nil {
+		status = ConfirmationStatusFinalized
+	}
+	return confirmationLevel(status) >= commitmentLevel(commitment)
+}
+
+func confirmationLevel(status ConfirmationStatusType) int {
+	switch status {
+	case ConfirmationStatusProcessed:
+		return 1
+	case ConfirmationStatusConfirmed:
+		return 2
+	case ConfirmationStatusFinalized:
+		return 3
+	default:
+		return 0
+	}
+}
+
+func commitmentLevel(commitment CommitmentType) int {
+	switch commitment {
+	case CommitmentProcessed, CommitmentRecent:
+		return 1
+	case CommitmentConfirmed, CommitmentSingle, CommitmentSingleGossip:
+		return 2
+	default:
+		return 3
+	}
+}
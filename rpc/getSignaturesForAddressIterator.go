@@ -0,0 +1,387 @@
+// Copyright 2021 github.com/gagliardetto
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rpc
+
+import (
+	"context"
+	"sync"
+
+	"github.com/gagliardetto/solana-go"
+)
+
+// IterDirection is the order in which an iterator walks the ledger.
+type IterDirection int
+
+const (
+	// IterBackward walks from the newest entry to the oldest one.
+	IterBackward IterDirection = iota
+	// IterForward walks from the oldest entry to the newest one.
+	IterForward
+)
+
+// MaxSignaturesPageSize is the maximum number of signatures
+// returned by a single getSignaturesForAddress call.
+const MaxSignaturesPageSize = 1000
+
+type SignaturesIteratorOpts struct {
+	// Direction of the walk. Defaults to IterBackward.
+	Direction IterDirection
+
+	// (optional) Number of signatures requested per page (between 1 and 1,000, default: 1,000).
+	PageSize int
+
+	// (optional) Upper bound of the walk: only signatures older than this one are returned.
+	// If not provided, the walk starts (or ends, when walking forward) at the most recent signature.
+	Before solana.Signature
+
+	// (optional) Lower bound of the walk: only signatures newer than this one are returned.
+	// If not provided, the walk ends (or starts, when walking forward) at the oldest signature.
+	Until solana.Signature
+
+	// (optional) Resume token, as returned by `SignaturesIterator.Cursor()`.
+	// The walk continues right after this signature, in the configured direction.
+	ResumeAfter solana.Signature
+
+	// (optional) Commitment; "processed" is not supported.
+	// If parameter not provided, the default is "finalized".
+	Commitment CommitmentType
+
+	// The minimum slot that the request can be evaluated at.
+	// This parameter is optional.
+	MinContextSlot *uint64
+
+	// If true, signatures of failed transactions are not emitted.
+	// They are still used for paging, so the walk stays consistent.
+	SkipFailed bool
+
+	// If true, the full transaction is fetched for each emitted signature.
+	FetchTransactions bool
+
+	// (optional) Options used to fetch each transaction.
+	// Defaults to the iterator commitment and MaxSupportedTransactionVersion0.
+	TransactionOpts *GetTransactionOpts
+
+	// (optional) Maximum number of concurrent getTransaction calls (default: 1).
+	// Transactions are always emitted in the order of the walk.
+	Concurrency int
+}
+
+// SignaturesIterator walks the transaction history of an address,
+// page by page, using getSignaturesForAddress.
+//
+//	it := client.NewSignaturesIterator(account, nil)
+//	for it.Next(ctx) {
+//		sig := it.Signature()
+//		// ...
+//	}
+//	if err := it.Err(); err != nil {
+//		// resume later with it.Cursor()
+//	}
+type SignaturesIterator struct {
+	cl      *Client
+	account solana.PublicKey
+	opts    SignaturesIteratorOpts
+
+	// Signature used as `before` for the next backward page.
+	before solana.Signature
+	done   bool
+
+	// Forward walk: the `before` of the pages left to reload, oldest last,
+	// and the newest page, kept as is since newer signatures may have landed.
+	forwardScanned bool
+	forwardBefores []solana.Signature
+	forwardHead    []*TransactionSignature
+
+	buf    []*TransactionSignature
+	txs    []*GetTransactionResult
+	pos    int
+	cursor solana.Signature
+	err    error
+}
+
+// NewSignaturesIterator creates a new iterator over the signatures
+// of transactions involving the provided account.
+func (cl *Client) NewSignaturesIterator(
+	account solana.PublicKey,
+	opts *SignaturesIteratorOpts,
+) *SignaturesIterator {
+	it := &SignaturesIterator{
+		cl:      cl,
+		account: account,
+		pos:     -1,
+	}
+	if opts != nil {
+		it.opts = *opts
+	}
+	if it.opts.PageSize <= 0 || it.opts.PageSize > MaxSignaturesPageSize {
+		it.opts.PageSize = MaxSignaturesPageSize
+	}
+	if it.opts.Concurrency <= 0 {
+		it.opts.Concurrency = 1
+	}
+	it.before = it.opts.Before
+	if it.opts.Direction == IterBackward && !it.opts.ResumeAfter.IsZero() {
+		it.before = it.opts.ResumeAfter
+	}
+	it.cursor = it.opts.ResumeAfter
+	return it
+}
+
+// Next advances the iterator to the next signature.
+// It returns false when the walk is over or an error occurred;
+// check `Err()` to tell them apart.
+func (it *SignaturesIterator) Next(ctx context.Context) bool {
+	if it.err != nil {
+		return false
+	}
+	for {
+		if it.pos+1 < len(it.buf) {
+			it.pos++
+			it.cursor = it.buf[it.pos].Signature
+			if it.opts.SkipFailed && it.buf[it.pos].Err != nil {
+				continue
+			}
+			return true
+		}
+		if it.done {
+			return false
+		}
+		if err := it.fill(ctx); err != nil {
+			it.err = err
+			return false
+		}
+	}
+}
+
+// Signature returns the current signature.
+func (it *SignaturesIterator) Signature() *TransactionSignature {
+	if it.pos < 0 || it.pos >= len(it.buf) {
+		return nil
+	}
+	return it.buf[it.pos]
+}
+
+// Transaction returns the transaction of the current signature.
+// It is nil unless `FetchTransactions` is set.
+func (it *SignaturesIterator) Transaction() *GetTransactionResult {
+	if it.pos < 0 || it.pos >= len(it.txs) {
+		return nil
+	}
+	return it.txs[it.pos]
+}
+
+// Cursor returns the resume token of the iterator: the last signature
+// that was fully processed by `Next`. Pass it as `ResumeAfter`
+// to continue the walk from there.
+func (it *SignaturesIterator) Cursor() solana.Signature {
+	return it.cursor
+}
+
+// Err returns the error that stopped the iterator, if any.
+func (it *SignaturesIterator) Err() error {
+	return it.err
+}
+
+func (it *SignaturesIterator) fill(ctx context.Context) (err error) {
+	var page []*TransactionSignature
+	if it.opts.Direction == IterForward {
+		page, err = it.loadForward(ctx)
+	} else {
+		page, err = it.loadPage(ctx, it.before, it.opts.Until)
+		if len(page) < it.opts.PageSize {
+			it.done = true
+		}
+		if len(page) > 0 {
+			it.before = page[len(page)-1].Signature
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	var txs []*GetTransactionResult
+	if it.opts.FetchTransactions {
+		txs, err = it.fetchTransactions(ctx, page)
+		if err != nil {
+			return err
+		}
+	}
+	it.buf = page
+	it.txs = txs
+	it.pos = -1
+	return nil
+}
+
+// loadForward returns the next page of a forward walk, oldest first.
+// The RPC only pages backward, so the first call walks backward from the upper
+// bound down to the lower bound (or the resume token), keeping only the page
+// boundaries; the pages are then reloaded one at a time, from the oldest one.
+func (it *SignaturesIterator) loadForward(ctx context.Context) ([]*TransactionSignature, error) {
+	var page []*TransactionSignature
+	switch {
+	case !it.forwardScanned:
+		last, err := it.scanForward(ctx)
+		if err != nil {
+			return nil, err
+		}
+		it.forwardScanned = true
+		page = last
+	case len(it.forwardBefores) > 0:
+		before := it.forwardBefores[len(it.forwardBefores)-1]
+		loaded, err := it.loadPage(ctx, before, it.forwardUntil())
+		if err != nil {
+			return nil, err
+		}
+		it.forwardBefores = it.forwardBefores[:len(it.forwardBefores)-1]
+		page = loaded
+	default:
+		page = it.forwardHead
+		it.forwardHead = nil
+	}
+	if len(it.forwardBefores) == 0 && it.forwardHead == nil {
+		it.done = true
+	}
+	for i, j := 0, len(page)-1; i < j; i, j = i+1, j-1 {
+		page[i], page[j] = page[j], page[i]
+	}
+	return page, nil
+}
+
+// scanForward walks the range backward and returns its oldest page.
+// It keeps the newest page in forwardHead and the `before` of every page
+// in between in forwardBefores.
+func (it *SignaturesIterator) scanForward(ctx context.Context) ([]*TransactionSignature, error) {
+	before := it.opts.Before
+	for first := true; ; first = false {
+		page, err := it.loadPage(ctx, before, it.forwardUntil())
+		if err != nil {
+			return nil, err
+		}
+		full := len(page) == it.opts.PageSize
+		switch {
+		case first && !full:
+			// A single page: emit it right away.
+			return page, nil
+		case first:
+			it.forwardHead = page
+		case full:
+			it.forwardBefores = append(it.forwardBefores, before)
+		default:
+			return page, nil
+		}
+		before = page[len(page)-1].Signature
+	}
+}
+
+func (it *SignaturesIterator) forwardUntil() solana.Signature {
+	if !it.opts.ResumeAfter.IsZero() {
+		return it.opts.ResumeAfter
+	}
+	return it.opts.Until
+}
+
+func (it *SignaturesIterator) loadPage(
+	ctx context.Context,
+	before solana.Signature,
+	until solana.Signature,
+) ([]*TransactionSignature, error) {
+	limit := it.opts.PageSize
+	return it.cl.GetSignaturesForAddressWithOpts(
+		ctx,
+		it.account,
+		&GetSignaturesForAddressOpts{
+			Limit:          &limit,
+			Before:         before,
+			Until:          until,
+			Commitment:     it.opts.Commitment,
+			MinContextSlot: it.opts.MinContextSlot,
+		},
+	)
+}
+
+func (it *SignaturesIterator) fetchTransactions(
+	ctx context.Context,
+	page []*TransactionSignature,
+) ([]*GetTransactionResult, error) {
+	txOpts := it.opts.TransactionOpts
+	if txOpts == nil {
+		txOpts = &GetTransactionOpts{
+			Commitment:                     it.opts.Commitment,
+			MaxSupportedTransactionVersion: &MaxSupportedTransactionVersion0,
+		}
+	}
+	out := make([]*GetTransactionResult, len(page))
+	err := forEachOrdered(ctx, len(page), it.opts.Concurrency, func(ctx context.Context, i int) error {
+		if it.opts.SkipFailed && page[i].Err != nil {
+			return nil
+		}
+		tx, err := it.cl.GetTransaction(ctx, page[i].Signature, txOpts)
+		if err != nil {
+			return err
+		}
+		out[i] = tx
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// forEachOrdered calls fn for every index in [0, n) with at most
+// `concurrency` calls in flight, and returns the first error.
+// Callers store results by index, which keeps them in order.
+func forEachOrdered(
+	ctx context.Context,
+	n int,
+	concurrency int,
+	fn func(ctx context.Context, i int) error,
+) error {
+	if n == 0 {
+		return nil
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg       sync.WaitGroup
+		once     sync.Once
+		firstErr error
+		sem      = make(chan struct{}, concurrency)
+	)
+	for i := 0; i < n; i++ {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if err := ctx.Err(); err != nil {
+			once.Do(func() { firstErr = err })
+			break
+		}
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			if err := fn(ctx, i); err != nil {
+				once.Do(func() {
+					firstErr = err
+					cancel()
+				})
+			}
+		}(i)
+	}
+	wg.Wait()
+	return firstErr
+}
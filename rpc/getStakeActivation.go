@@ -21,6 +21,9 @@ import (
 )
 
 // GetStakeActivation returns epoch activation information for a stake account.
+//
+// DEPRECATED: removed from newer RPC nodes; compute the activation locally
+// with the Activation method of stake.StakeAccount (package programs/stake).
 func (cl *Client) GetStakeActivation(
 	ctx context.Context,
 	// Pubkey of stake account to query
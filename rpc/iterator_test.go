@@ -0,0 +1,251 @@
+// Copyright 2021 github.com/gagliardetto
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rpc
+
+import (
+	"bytes"
+	"context"
+	stdjson "encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/gagliardetto/solana-go"
+)
+
+type iteratorTestRequest struct {
+	ID     stdjson.RawMessage   `json:"id"`
+	Method string               `json:"method"`
+	Params []stdjson.RawMessage `json:"params"`
+}
+
+// newIteratorTestServer serves a fake history of `n` signatures (index 0 is the oldest,
+// at slot 0; every third transaction failed) and a fake ledger where only even slots have blocks.
+func newIteratorTestServer(t *testing.T, sigs []solana.Signature, maxSlot uint64) *httptest.Server {
+	return httptest.NewServer(newIteratorTestHandler(t, sigs, maxSlot))
+}
+
+func newIteratorTestHandler(t *testing.T, sigs []solana.Signature, maxSlot uint64) http.HandlerFunc {
+	indexOf := func(sig solana.Signature) int {
+		for i := range sigs {
+			if sigs[i] == sig {
+				return i
+			}
+		}
+		return -1
+	}
+	return func(rw http.ResponseWriter, req *http.Request) {
+		var rpcReq iteratorTestRequest
+		require.NoError(t, stdjson.NewDecoder(req.Body).Decode(&rpcReq))
+
+		var result interface{}
+		switch rpcReq.Method {
+		case "getSignaturesForAddress":
+			var opts struct {
+				Limit  int              `json:"limit"`
+				Before solana.Signature `json:"before"`
+				Until  solana.Signature `json:"until"`
+			}
+			require.NoError(t, stdjson.Unmarshal(rpcReq.Params[1], &opts))
+			from := len(sigs) - 1
+			if !opts.Before.IsZero() {
+				from = indexOf(opts.Before) - 1
+			}
+			page := []M{}
+			for i := from; i >= 0 && len(page) < opts.Limit; i-- {
+				if !opts.Until.IsZero() && sigs[i] == opts.Until {
+					break
+				}
+				var txErr interface{}
+				if i%3 == 0 {
+					txErr = M{"InstructionError": []interface{}{0, "Custom"}}
+				}
+				page = append(page, M{"signature": sigs[i], "slot": i, "err": txErr})
+			}
+			result = page
+		case "getTransaction":
+			var sig solana.Signature
+			require.NoError(t, stdjson.Unmarshal(rpcReq.Params[0], &sig))
+			result = M{"slot": indexOf(sig), "version": "legacy"}
+		case "getBlocksWithLimit":
+			var start, limit uint64
+			require.NoError(t, stdjson.Unmarshal(rpcReq.Params[0], &start))
+			require.NoError(t, stdjson.Unmarshal(rpcReq.Params[1], &limit))
+			slots := []uint64{}
+			for slot := start; slot <= maxSlot && uint64(len(slots)) < limit; slot++ {
+				if slot%2 == 0 {
+					slots = append(slots, slot)
+				}
+			}
+			result = slots
+		default:
+			t.Fatalf("unexpected method %q", rpcReq.Method)
+		}
+
+		resp, err := stdjson.Marshal(M{"jsonrpc": "2.0", "id": rpcReq.ID, "result": result})
+		require.NoError(t, err)
+		rw.Write(resp)
+	}
+}
+
+func newIteratorTestSignatures(n int) []solana.Signature {
+	sigs := make([]solana.Signature, n)
+	for i := range sigs {
+		copy(sigs[i][:], fmt.Sprintf("signature-%04d", i))
+	}
+	return sigs
+}
+
+func TestSignaturesIterator_Backward(t *testing.T) {
+	sigs := newIteratorTestSignatures(25)
+	server := newIteratorTestServer(t, sigs, 0)
+	defer server.Close()
+	client := New(server.URL)
+
+	it := client.NewSignaturesIterator(solana.SystemProgramID, &SignaturesIteratorOpts{
+		PageSize:          4,
+		SkipFailed:        true,
+		FetchTransactions: true,
+		Concurrency:       3,
+	})
+	var got []int
+	for it.Next(context.Background()) {
+		require.NotNil(t, it.Transaction())
+		require.Equal(t, it.Signature().Slot, it.Transaction().Slot)
+		got = append(got, int(it.Signature().Slot))
+		if len(got) == 5 {
+			break
+		}
+	}
+	require.NoError(t, it.Err())
+	require.Equal(t, []int{23, 22, 20, 19, 17}, got)
+
+	// Resume where the previous walk stopped.
+	it = client.NewSignaturesIterator(solana.SystemProgramID, &SignaturesIteratorOpts{
+		PageSize:    4,
+		SkipFailed:  true,
+		ResumeAfter: it.Cursor(),
+	})
+	got = nil
+	for it.Next(context.Background()) {
+		require.Nil(t, it.Transaction())
+		got = append(got, int(it.Signature().Slot))
+	}
+	require.NoError(t, it.Err())
+	require.Equal(t, []int{16, 14, 13, 11, 10, 8, 7, 5, 4, 2, 1}, got)
+	require.Equal(t, sigs[0], it.Cursor())
+}
+
+func TestSignaturesIterator_Forward(t *testing.T) {
+	sigs := newIteratorTestSignatures(10)
+	server := newIteratorTestServer(t, sigs, 0)
+	defer server.Close()
+	client := New(server.URL)
+
+	it := client.NewSignaturesIterator(solana.SystemProgramID, &SignaturesIteratorOpts{
+		Direction:   IterForward,
+		PageSize:    3,
+		ResumeAfter: sigs[4],
+	})
+	var got []int
+	for it.Next(context.Background()) {
+		got = append(got, int(it.Signature().Slot))
+	}
+	require.NoError(t, it.Err())
+	require.Equal(t, []int{5, 6, 7, 8, 9}, got)
+	require.Equal(t, sigs[9], it.Cursor())
+}
+
+func TestSignaturesIterator_ForwardStreamsPages(t *testing.T) {
+	sigs := newIteratorTestSignatures(25)
+	handler := newIteratorTestHandler(t, sigs, 0)
+	var mu sync.Mutex
+	calls := map[string]int{}
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		body, err := io.ReadAll(req.Body)
+		require.NoError(t, err)
+		var rpcReq iteratorTestRequest
+		require.NoError(t, stdjson.Unmarshal(body, &rpcReq))
+		mu.Lock()
+		calls[rpcReq.Method]++
+		mu.Unlock()
+		req.Body = io.NopCloser(bytes.NewReader(body))
+		handler(rw, req)
+	}))
+	defer server.Close()
+	client := New(server.URL)
+
+	it := client.NewSignaturesIterator(solana.SystemProgramID, &SignaturesIteratorOpts{
+		Direction:         IterForward,
+		PageSize:          4,
+		FetchTransactions: true,
+	})
+	var got []int
+	for it.Next(context.Background()) {
+		require.Equal(t, it.Signature().Slot, it.Transaction().Slot)
+		got = append(got, int(it.Signature().Slot))
+		if len(got) == 1 {
+			// Only the transactions of the oldest page are fetched before the first one is emitted.
+			mu.Lock()
+			require.Equal(t, 1, calls["getTransaction"])
+			mu.Unlock()
+		}
+	}
+	require.NoError(t, it.Err())
+	want := make([]int, 25)
+	for i := range want {
+		want[i] = i
+	}
+	require.Equal(t, want, got)
+	require.Equal(t, 25, calls["getTransaction"])
+}
+
+func TestBlocksIterator(t *testing.T) {
+	server := newIteratorTestServer(t, nil, 30)
+	defer server.Close()
+	client := New(server.URL)
+
+	collect := func(it *BlocksIterator) []uint64 {
+		var got []uint64
+		for it.Next(context.Background()) {
+			got = append(got, it.Slot())
+		}
+		require.NoError(t, it.Err())
+		return got
+	}
+
+	got := collect(client.NewBlocksIterator(3, 17, &BlocksIteratorOpts{
+		Direction: IterForward,
+		PageSize:  3,
+	}))
+	require.Equal(t, []uint64{4, 6, 8, 10, 12, 14, 16}, got)
+
+	got = collect(client.NewBlocksIterator(3, 17, &BlocksIteratorOpts{
+		PageSize: 4,
+	}))
+	require.Equal(t, []uint64{16, 14, 12, 10, 8, 6, 4}, got)
+
+	resume := uint64(10)
+	got = collect(client.NewBlocksIterator(3, 17, &BlocksIteratorOpts{
+		PageSize:    5,
+		ResumeAfter: &resume,
+	}))
+	require.Equal(t, []uint64{8, 6, 4}, got)
+}
@@ -0,0 +1,61 @@
+// Copyright 2021 github.com/gagliardetto
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jsonrpc
+
+import (
+	"context"
+	"net/http"
+)
+
+type contextKey int
+
+const (
+	contextKeyHeaders contextKey = iota
+	contextKeyResponseObserver
+)
+
+// WithHeaders returns a copy of ctx carrying HTTP headers that are added
+// to every request sent with that context.
+// They are applied after the client custom headers, so they can override them.
+func WithHeaders(ctx context.Context, headers http.Header) context.Context {
+	return context.WithValue(ctx, contextKeyHeaders, headers)
+}
+
+// HeadersFromContext returns the HTTP headers set with WithHeaders, if any.
+func HeadersFromContext(ctx context.Context) http.Header {
+	headers, _ := ctx.Value(contextKeyHeaders).(http.Header)
+	return headers
+}
+
+// WithResponseObserver returns a copy of ctx carrying a function that is called
+// with every HTTP response received for the requests sent with that context,
+// before the response body is read.
+// The observers already carried by ctx, if any, are still called.
+func WithResponseObserver(ctx context.Context, observer func(*http.Response)) context.Context {
+	if previous, ok := ctx.Value(contextKeyResponseObserver).(func(*http.Response)); ok && previous != nil {
+		next := observer
+		observer = func(httpResponse *http.Response) {
+			previous(httpResponse)
+			next(httpResponse)
+		}
+	}
+	return context.WithValue(ctx, contextKeyResponseObserver, observer)
+}
+
+func observeResponse(ctx context.Context, httpResponse *http.Response) {
+	if observer, ok := ctx.Value(contextKeyResponseObserver).(func(*http.Response)); ok && observer != nil {
+		observer(httpResponse)
+	}
+}
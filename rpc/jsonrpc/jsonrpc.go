@@ -481,6 +481,14 @@ func (client *rpcClient) newRequest(ctx context.Context, req interface{}) (*http
 		request.Header.Set(k, v)
 	}
 
+	// then the headers attached to the context.
+	for k, values := range HeadersFromContext(ctx) {
+		request.Header.Del(k)
+		for _, v := range values {
+			request.Header.Add(k, v)
+		}
+	}
+
 	return request, nil
 }
 
@@ -568,6 +576,7 @@ func (client *rpcClient) doCallWithCallbackOnHTTPResponse(
 		return fmt.Errorf("rpc call %v() on %v: %w", RPCRequest.Method, httpRequest.URL.String(), err)
 	}
 	defer httpResponse.Body.Close()
+	observeResponse(ctx, httpResponse)
 
 	return callback(httpRequest, httpResponse)
 }
@@ -585,6 +594,7 @@ func (client *rpcClient) doBatchCall(ctx context.Context, rpcRequest []*RPCReque
 		return nil, fmt.Errorf("rpc batch call on %v: %w", httpRequest.URL.String(), err)
 	}
 	defer httpResponse.Body.Close()
+	observeResponse(ctx, httpResponse)
 
 	var rpcResponse RPCResponses
 	decoder := json.NewDecoder(httpResponse.Body)
@@ -0,0 +1,329 @@
+// Copyright 2021 github.com/gagliardetto
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rpc
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gagliardetto/solana-go/rpc/jsonrpc"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// BatchMethod is the method name of the RPCCall describing a CallBatch.
+const BatchMethod = "batch"
+
+// RPCCall describes a JSON-RPC call going through a middleware chain.
+type RPCCall struct {
+	// Name of the JSON-RPC method; BatchMethod for batch calls.
+	Method string
+
+	// Params of the call; nil for batch calls.
+	Params []interface{}
+
+	// Requests of a batch call; nil otherwise.
+	Requests jsonrpc.RPCRequests
+
+	// Extra HTTP headers sent with the request.
+	// Middlewares can add headers before calling the next handler.
+	Header http.Header
+
+	// The fields below are set once the next handler returns.
+
+	// Time spent in the underlying JSONRPCClient.
+	Duration time.Duration
+
+	// Status code of the HTTP response, or 0 if unknown
+	// (e.g. the request never reached the server, or the underlying
+	// JSONRPCClient doesn't report it).
+	HTTPStatus int
+
+	batch     bool
+	out       interface{}
+	callback  func(*http.Request, *http.Response) error
+	responses jsonrpc.RPCResponses
+
+	// If set, an HTTP 401 response is not passed to the callback,
+	// because the call is about to be retried.
+	holdUnauthorized bool
+}
+
+// errUnauthorized is returned for an HTTP 401 response held back from the callback.
+var errUnauthorized = errors.New("rpc call: HTTP 401 Unauthorized")
+
+// RPCErrorOf returns the JSON-RPC error carried by err, if any.
+func RPCErrorOf(err error) *jsonrpc.RPCError {
+	var rpcErr *jsonrpc.RPCError
+	if errors.As(err, &rpcErr) {
+		return rpcErr
+	}
+	return nil
+}
+
+// RPCHandler executes an RPCCall.
+type RPCHandler func(ctx context.Context, call *RPCCall) error
+
+// Middleware wraps an RPCHandler with additional behavior.
+// It can inspect or modify the call before invoking next,
+// and inspect the outcome (duration, HTTP status, error) after.
+type Middleware func(next RPCHandler) RPCHandler
+
+type clientWithMiddleware struct {
+	rpcClient JSONRPCClient
+	handler   RPCHandler
+}
+
+// NewWithMiddleware wraps the provided JSONRPCClient with a chain of middlewares.
+// The first middleware is the outermost one: it sees the call first,
+// and its outcome last.
+//
+//	rpcClient := rpc.NewWithMiddleware(
+//		jsonrpc.NewClient(rpc.MainNetBeta_RPC),
+//		rpc.LoggingMiddleware(logger),
+//		rpc.MetricsMiddleware(hook),
+//	)
+//	client := rpc.NewWithCustomRPCClient(rpcClient)
+func NewWithMiddleware(rpcClient JSONRPCClient, middlewares ...Middleware) JSONRPCClient {
+	wr := &clientWithMiddleware{
+		rpcClient: rpcClient,
+	}
+	handler := wr.do
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		handler = middlewares[i](handler)
+	}
+	wr.handler = handler
+	return wr
+}
+
+func (wr *clientWithMiddleware) CallForInto(ctx context.Context, out interface{}, method string, params []interface{}) error {
+	return wr.handler(ctx, &RPCCall{
+		Method: method,
+		Params: params,
+		Header: make(http.Header),
+		out:    out,
+	})
+}
+
+func (wr *clientWithMiddleware) CallWithCallback(
+	ctx context.Context,
+	method string,
+	params []interface{},
+	callback func(*http.Request, *http.Response) error,
+) error {
+	return wr.handler(ctx, &RPCCall{
+		Method:   method,
+		Params:   params,
+		Header:   make(http.Header),
+		callback: callback,
+	})
+}
+
+func (wr *clientWithMiddleware) CallBatch(
+	ctx context.Context,
+	requests jsonrpc.RPCRequests,
+) (jsonrpc.RPCResponses, error) {
+	call := &RPCCall{
+		Method:   BatchMethod,
+		Requests: requests,
+		Header:   make(http.Header),
+		batch:    true,
+	}
+	err := wr.handler(ctx, call)
+	return call.responses, err
+}
+
+// Close closes clientWithMiddleware.
+func (wr *clientWithMiddleware) Close() error {
+	if c, ok := wr.rpcClient.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
+
+// do is the innermost handler: it sends the call through the wrapped client.
+func (wr *clientWithMiddleware) do(ctx context.Context, call *RPCCall) (err error) {
+	var (
+		mu         sync.Mutex
+		httpStatus int
+	)
+	observe := func(resp *http.Response) {
+		mu.Lock()
+		httpStatus = resp.StatusCode
+		mu.Unlock()
+	}
+	ctx = jsonrpc.WithResponseObserver(ctx, observe)
+	if len(call.Header) > 0 {
+		headers := jsonrpc.HeadersFromContext(ctx).Clone()
+		if headers == nil {
+			headers = make(http.Header)
+		}
+		for k, v := range call.Header {
+			headers[k] = v
+		}
+		ctx = jsonrpc.WithHeaders(ctx, headers)
+	}
+
+	start := time.Now()
+	switch {
+	case call.batch:
+		call.responses, err = wr.rpcClient.CallBatch(ctx, call.Requests)
+	case call.callback != nil:
+		err = wr.rpcClient.CallWithCallback(ctx, call.Method, call.Params, func(req *http.Request, resp *http.Response) error {
+			observe(resp)
+			if call.holdUnauthorized && resp.StatusCode == http.StatusUnauthorized {
+				return errUnauthorized
+			}
+			return call.callback(req, resp)
+		})
+	default:
+		err = wr.rpcClient.CallForInto(ctx, call.out, call.Method, call.Params)
+	}
+	call.Duration = time.Since(start)
+
+	mu.Lock()
+	call.HTTPStatus = httpStatus
+	mu.Unlock()
+	var httpErr *jsonrpc.HTTPError
+	if call.HTTPStatus == 0 && errors.As(err, &httpErr) {
+		call.HTTPStatus = httpErr.Code
+	}
+	return err
+}
+
+// LoggingMiddleware logs every call with its method, duration, HTTP status and error.
+// Successful calls are logged at debug level, failed ones at warn level.
+// If logger is nil, the package logger is used.
+func LoggingMiddleware(logger *zap.Logger) Middleware {
+	return func(next RPCHandler) RPCHandler {
+		return func(ctx context.Context, call *RPCCall) error {
+			err := next(ctx, call)
+
+			log := logger
+			if log == nil {
+				log = zlog
+			}
+			if log == nil {
+				return err
+			}
+			fields := []zap.Field{
+				zap.String("method", call.Method),
+				zap.Duration("duration", call.Duration),
+				zap.Int("http_status", call.HTTPStatus),
+			}
+			if call.batch {
+				fields = append(fields, zap.Int("batch_size", len(call.Requests)))
+			}
+			if err != nil {
+				if rpcErr := RPCErrorOf(err); rpcErr != nil {
+					fields = append(fields, zap.Int("rpc_error_code", rpcErr.Code))
+				}
+				log.Warn("rpc call failed", append(fields, zap.Error(err))...)
+			} else {
+				log.Debug("rpc call", fields...)
+			}
+			return err
+		}
+	}
+}
+
+// MetricsHook is called once per call, after it returns.
+// Use it to feed latency histograms (call.Duration, by call.Method)
+// and error counters (err, RPCErrorOf(err), call.HTTPStatus).
+type MetricsHook func(ctx context.Context, call *RPCCall, err error)
+
+// MetricsMiddleware calls the provided hook after every call.
+// If hook is nil, the calls are passed through unchanged.
+func MetricsMiddleware(hook MetricsHook) Middleware {
+	return func(next RPCHandler) RPCHandler {
+		if hook == nil {
+			return next
+		}
+		return func(ctx context.Context, call *RPCCall) error {
+			err := next(ctx, call)
+			hook(ctx, call, err)
+			return err
+		}
+	}
+}
+
+type requestIDContextKey struct{}
+
+// ContextWithRequestID returns a copy of ctx carrying the provided request ID,
+// which RequestIDMiddleware sends along with the calls made with that context.
+func ContextWithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDContextKey{}, requestID)
+}
+
+// RequestIDFromContext returns the request ID set with ContextWithRequestID, if any.
+func RequestIDFromContext(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDContextKey{}).(string)
+	return requestID
+}
+
+// RequestIDMiddleware sets the request ID of the context (see ContextWithRequestID)
+// as the provided HTTP header; if the context has none, a new UUID is used.
+// The ID is also set in the context passed to the next handlers.
+func RequestIDMiddleware(header string) Middleware {
+	return func(next RPCHandler) RPCHandler {
+		return func(ctx context.Context, call *RPCCall) error {
+			requestID := RequestIDFromContext(ctx)
+			if requestID == "" {
+				requestID = uuid.New().String()
+				ctx = ContextWithRequestID(ctx, requestID)
+			}
+			call.Header.Set(header, requestID)
+			return next(ctx, call)
+		}
+	}
+}
+
+// TokenSource returns the auth token to send with the calls.
+// When refresh is true, the previous token was rejected
+// and a new one must be obtained.
+type TokenSource func(ctx context.Context, refresh bool) (string, error)
+
+// BearerAuthMiddleware sets the "Authorization: Bearer <token>" header on every call.
+// If the server answers with HTTP 401, the token is refreshed
+// and the call is retried once. The callback of CallWithCallback
+// only sees the response of the retry.
+func BearerAuthMiddleware(tokens TokenSource) Middleware {
+	return func(next RPCHandler) RPCHandler {
+		return func(ctx context.Context, call *RPCCall) error {
+			token, err := tokens(ctx, false)
+			if err != nil {
+				return err
+			}
+			call.Header.Set("Authorization", "Bearer "+token)
+			call.holdUnauthorized = true
+			err = next(ctx, call)
+			call.holdUnauthorized = false
+			if call.HTTPStatus != http.StatusUnauthorized {
+				return err
+			}
+
+			token, err = tokens(ctx, true)
+			if err != nil {
+				return err
+			}
+			call.Header.Set("Authorization", "Bearer "+token)
+			return next(ctx, call)
+		}
+	}
+}
@@ -0,0 +1,131 @@
+// Copyright 2021 github.com/gagliardetto
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rpc
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gagliardetto/solana-go/rpc/jsonrpc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestNewWithMiddleware(t *testing.T) {
+	var gotHeaders []http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		gotHeaders = append(gotHeaders, req.Header.Clone())
+		if req.Header.Get("Authorization") != "Bearer fresh" {
+			rw.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		rw.Write([]byte(`{"jsonrpc":"2.0","error":{"code":-32005,"message":"Node is unhealthy","data":{}},"id":1}`))
+	}))
+	defer server.Close()
+
+	var refreshed int
+	tokens := func(ctx context.Context, refresh bool) (string, error) {
+		if refresh {
+			refreshed++
+			return "fresh", nil
+		}
+		return "stale", nil
+	}
+
+	type observed struct {
+		method     string
+		httpStatus int
+		rpcCode    int
+	}
+	var calls []observed
+	hook := func(ctx context.Context, call *RPCCall, err error) {
+		obs := observed{method: call.Method, httpStatus: call.HTTPStatus}
+		if rpcErr := RPCErrorOf(err); rpcErr != nil {
+			obs.rpcCode = rpcErr.Code
+		}
+		calls = append(calls, obs)
+	}
+
+	client := NewWithCustomRPCClient(NewWithMiddleware(
+		jsonrpc.NewClient(server.URL),
+		LoggingMiddleware(zap.NewNop()),
+		RequestIDMiddleware("X-Request-Id"),
+		MetricsMiddleware(hook),
+		BearerAuthMiddleware(tokens),
+	))
+
+	ctx := ContextWithRequestID(context.Background(), "req-1")
+	_, err := client.GetHealth(ctx)
+	require.Error(t, err)
+
+	var unhealthy *NodeUnhealthyError
+	assert.ErrorAs(t, err, &unhealthy)
+
+	assert.Equal(t, 1, refreshed)
+	require.Len(t, gotHeaders, 2)
+	assert.Equal(t, "Bearer stale", gotHeaders[0].Get("Authorization"))
+	assert.Equal(t, "Bearer fresh", gotHeaders[1].Get("Authorization"))
+	for _, h := range gotHeaders {
+		assert.Equal(t, "req-1", h.Get("X-Request-Id"))
+	}
+	assert.Equal(t,
+		[]observed{{method: "getHealth", httpStatus: http.StatusOK, rpcCode: ErrorCodeNodeUnhealthy}},
+		calls,
+	)
+}
+
+func TestBearerAuthMiddleware_CallWithCallback(t *testing.T) {
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		requests++
+		if req.Header.Get("Authorization") != "Bearer fresh" {
+			rw.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		rw.Write([]byte(`{"jsonrpc":"2.0","result":"ok","id":1}`))
+	}))
+	defer server.Close()
+
+	tokens := func(ctx context.Context, refresh bool) (string, error) {
+		if refresh {
+			return "fresh", nil
+		}
+		return "stale", nil
+	}
+	rpcClient := NewWithMiddleware(jsonrpc.NewClient(server.URL), BearerAuthMiddleware(tokens))
+
+	var statuses []int
+	err := rpcClient.CallWithCallback(context.Background(), "getHealth", nil, func(req *http.Request, resp *http.Response) error {
+		statuses = append(statuses, resp.StatusCode)
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 2, requests)
+	assert.Equal(t, []int{http.StatusOK}, statuses)
+}
+
+func TestMetricsMiddleware_NilHook(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.Write([]byte(`{"jsonrpc":"2.0","result":"ok","id":1}`))
+	}))
+	defer server.Close()
+
+	client := NewWithCustomRPCClient(NewWithMiddleware(jsonrpc.NewClient(server.URL), MetricsMiddleware(nil)))
+	_, err := client.GetHealth(context.Background())
+	require.NoError(t, err)
+}
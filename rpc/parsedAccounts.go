@@ -0,0 +1,424 @@
+// Copyright 2021 github.com/gagliardetto
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rpc
+
+import (
+	stdjson "encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/gagliardetto/solana-go"
+)
+
+// Values of the `program` discriminator of jsonParsed account data.
+const (
+	ParsedProgramSplToken           = "spl-token"
+	ParsedProgramSplToken2022       = "spl-token-2022"
+	ParsedProgramStake              = "stake"
+	ParsedProgramVote               = "vote"
+	ParsedProgramNonce              = "nonce"
+	ParsedProgramSysvar             = "sysvar"
+	ParsedProgramAddressLookupTable = "address-lookup-table"
+)
+
+var (
+	// ErrNotJSONParsed is returned when decoding account data
+	// that was not requested with the "jsonParsed" encoding,
+	// or that the node could not parse (it then falls back to binary).
+	ErrNotJSONParsed = errors.New("account data is not jsonParsed")
+
+	// ErrUnknownParsedAccount is returned when decoding jsonParsed account data
+	// of a program or type without a Go representation.
+	ErrUnknownParsedAccount = errors.New("unknown jsonParsed account type")
+)
+
+// ParsedAccountData is the envelope of jsonParsed account data.
+type ParsedAccountData struct {
+	// Name of the program owning the account, e.g. "spl-token".
+	Program string `json:"program"`
+
+	Parsed struct {
+		// Type of the account within the program, e.g. "account" or "mint".
+		Type string `json:"type"`
+
+		// The account state; its layout depends on Program and Type.
+		Info stdjson.RawMessage `json:"info,omitempty"`
+	} `json:"parsed"`
+
+	// Size of the account data, in bytes.
+	Space uint64 `json:"space"`
+}
+
+// GetParsedAccountData returns the envelope of jsonParsed account data.
+func (dt *DataBytesOrJSON) GetParsedAccountData() (*ParsedAccountData, error) {
+	if dt == nil || dt.rawDataEncoding != solana.EncodingJSONParsed || len(dt.asJSON) == 0 {
+		return nil, ErrNotJSONParsed
+	}
+	out := new(ParsedAccountData)
+	if err := json.Unmarshal(dt.asJSON, out); err != nil {
+		return nil, fmt.Errorf("unable to decode jsonParsed account data: %w", err)
+	}
+	return out, nil
+}
+
+// GetParsedAccount decodes jsonParsed account data into its Go type,
+// selected by the `program` and `type` discriminators:
+//
+//	spl-token, spl-token-2022:
+//	  account  -> *ParsedTokenAccount
+//	  mint     -> *ParsedTokenMint
+//	  multisig -> *ParsedTokenMultisig
+//	stake:
+//	  initialized, delegated -> *ParsedStakeAccount
+//	vote:
+//	  vote -> *ParsedVoteAccount
+//	nonce:
+//	  initialized -> *ParsedNonceAccount
+//	sysvar:
+//	  clock             -> *ParsedSysvarClock
+//	  epochSchedule     -> *GetEpochScheduleResult
+//	  fees              -> *ParsedSysvarFees
+//	  recentBlockhashes -> *ParsedSysvarRecentBlockhashes
+//	  rent              -> *ParsedSysvarRent
+//	  rewards           -> *ParsedSysvarRewards
+//	  slotHashes        -> *ParsedSysvarSlotHashes
+//	  slotHistory       -> *ParsedSysvarSlotHistory
+//	  stakeHistory      -> *ParsedSysvarStakeHistory
+//	  lastRestartSlot   -> *ParsedSysvarLastRestartSlot
+//	  epochRewards      -> *ParsedSysvarEpochRewards
+//	address-lookup-table:
+//	  lookupTable -> *ParsedAddressLookupTable
+//
+// Accounts without state (e.g. the "uninitialized" types) are returned
+// as *ParsedUninitializedAccount.
+// Other programs and types fail with ErrUnknownParsedAccount;
+// their raw JSON is still available with GetRawJSON.
+func (dt *DataBytesOrJSON) GetParsedAccount() (interface{}, error) {
+	data, err := dt.GetParsedAccountData()
+	if err != nil {
+		return nil, err
+	}
+	return data.Decode()
+}
+
+// Decode decodes the account state into its Go type (see GetParsedAccount).
+func (data *ParsedAccountData) Decode() (interface{}, error) {
+	program := data.Program
+	if program == ParsedProgramSplToken2022 {
+		// Same layouts, with extensions.
+		program = ParsedProgramSplToken
+	}
+	types, ok := parsedAccountTypes[program]
+	if !ok {
+		return nil, fmt.Errorf("%w: program %q", ErrUnknownParsedAccount, data.Program)
+	}
+	newValue, ok := types[data.Parsed.Type]
+	if !ok {
+		return nil, fmt.Errorf("%w: program %q, type %q", ErrUnknownParsedAccount, data.Program, data.Parsed.Type)
+	}
+	if newValue == nil {
+		return &ParsedUninitializedAccount{Program: data.Program, Type: data.Parsed.Type}, nil
+	}
+	out := newValue()
+	if err := json.Unmarshal(data.Parsed.Info, out); err != nil {
+		return nil, fmt.Errorf("unable to decode %s %s: %w", data.Program, data.Parsed.Type, err)
+	}
+	return out, nil
+}
+
+// parsedAccountTypes maps program and type to a constructor of the Go type;
+// a nil constructor marks a type without state.
+var parsedAccountTypes = map[string]map[string]func() interface{}{
+	ParsedProgramSplToken: {
+		"account":  func() interface{} { return new(ParsedTokenAccount) },
+		"mint":     func() interface{} { return new(ParsedTokenMint) },
+		"multisig": func() interface{} { return new(ParsedTokenMultisig) },
+	},
+	ParsedProgramStake: {
+		"uninitialized": nil,
+		"initialized":   func() interface{} { return new(ParsedStakeAccount) },
+		"delegated":     func() interface{} { return new(ParsedStakeAccount) },
+		"rewardsPool":   nil,
+	},
+	ParsedProgramVote: {
+		"vote": func() interface{} { return new(ParsedVoteAccount) },
+	},
+	ParsedProgramNonce: {
+		"uninitialized": nil,
+		"initialized":   func() interface{} { return new(ParsedNonceAccount) },
+	},
+	ParsedProgramSysvar: {
+		"clock":             func() interface{} { return new(ParsedSysvarClock) },
+		"epochSchedule":     func() interface{} { return new(GetEpochScheduleResult) },
+		"fees":              func() interface{} { return new(ParsedSysvarFees) },
+		"recentBlockhashes": func() interface{} { return new(ParsedSysvarRecentBlockhashes) },
+		"rent":              func() interface{} { return new(ParsedSysvarRent) },
+		"rewards":           func() interface{} { return new(ParsedSysvarRewards) },
+		"slotHashes":        func() interface{} { return new(ParsedSysvarSlotHashes) },
+		"slotHistory":       func() interface{} { return new(ParsedSysvarSlotHistory) },
+		"stakeHistory":      func() interface{} { return new(ParsedSysvarStakeHistory) },
+		"lastRestartSlot":   func() interface{} { return new(ParsedSysvarLastRestartSlot) },
+		"epochRewards":      func() interface{} { return new(ParsedSysvarEpochRewards) },
+	},
+	ParsedProgramAddressLookupTable: {
+		"uninitialized": nil,
+		"lookupTable":   func() interface{} { return new(ParsedAddressLookupTable) },
+	},
+}
+
+// ParsedUninitializedAccount is an account without state,
+// e.g. an uninitialized stake or nonce account.
+type ParsedUninitializedAccount struct {
+	Program string
+	Type    string
+}
+
+// ParsedTokenAccountState is the state of a token account.
+type ParsedTokenAccountState string
+
+const (
+	ParsedTokenAccountStateUninitialized ParsedTokenAccountState = "uninitialized"
+	ParsedTokenAccountStateInitialized   ParsedTokenAccountState = "initialized"
+	ParsedTokenAccountStateFrozen        ParsedTokenAccountState = "frozen"
+)
+
+// ParsedTokenExtension is a token-2022 extension.
+type ParsedTokenExtension struct {
+	// Name of the extension, e.g. "transferFeeConfig".
+	Extension string `json:"extension"`
+
+	// State of the extension; its layout depends on Extension.
+	State stdjson.RawMessage `json:"state,omitempty"`
+}
+
+type ParsedTokenAccount struct {
+	// The mint associated with this account.
+	Mint solana.PublicKey `json:"mint"`
+
+	// The owner of this account.
+	Owner solana.PublicKey `json:"owner"`
+
+	// The amount of tokens this account holds.
+	TokenAmount UiTokenAmount `json:"tokenAmount"`
+
+	// The delegate authorized to transfer DelegatedAmount, if any.
+	Delegate *solana.PublicKey `json:"delegate,omitempty"`
+
+	State ParsedTokenAccountState `json:"state"`
+
+	// Whether this is a wrapped SOL account.
+	IsNative bool `json:"isNative"`
+
+	// The rent-exempt reserve of a wrapped SOL account.
+	RentExemptReserve *UiTokenAmount `json:"rentExemptReserve,omitempty"`
+
+	// The amount delegated, if any.
+	DelegatedAmount *UiTokenAmount `json:"delegatedAmount,omitempty"`
+
+	// Optional authority to close the account.
+	CloseAuthority *solana.PublicKey `json:"closeAuthority,omitempty"`
+
+	// Token-2022 extensions.
+	Extensions []ParsedTokenExtension `json:"extensions,omitempty"`
+}
+
+type ParsedTokenMint struct {
+	// Optional authority used to mint new tokens.
+	MintAuthority *solana.PublicKey `json:"mintAuthority"`
+
+	// Total supply of tokens.
+	Supply uint64 `json:"supply,string"`
+
+	// Number of base 10 digits to the right of the decimal place.
+	Decimals uint8 `json:"decimals"`
+
+	IsInitialized bool `json:"isInitialized"`
+
+	// Optional authority to freeze token accounts.
+	FreezeAuthority *solana.PublicKey `json:"freezeAuthority"`
+
+	// Token-2022 extensions.
+	Extensions []ParsedTokenExtension `json:"extensions,omitempty"`
+}
+
+type ParsedTokenMultisig struct {
+	// Number of signers required.
+	NumRequiredSigners uint8 `json:"numRequiredSigners"`
+
+	// Number of valid signers.
+	NumValidSigners uint8 `json:"numValidSigners"`
+
+	IsInitialized bool `json:"isInitialized"`
+
+	Signers []solana.PublicKey `json:"signers"`
+}
+
+type ParsedStakeAccount struct {
+	Meta ParsedStakeMeta `json:"meta"`
+
+	// Set only for the "delegated" type.
+	Stake *ParsedStake `json:"stake"`
+}
+
+type ParsedStakeMeta struct {
+	RentExemptReserve uint64 `json:"rentExemptReserve,string"`
+
+	Authorized struct {
+		Staker     solana.PublicKey `json:"staker"`
+		Withdrawer solana.PublicKey `json:"withdrawer"`
+	} `json:"authorized"`
+
+	Lockup struct {
+		UnixTimestamp int64            `json:"unixTimestamp"`
+		Epoch         uint64           `json:"epoch"`
+		Custodian     solana.PublicKey `json:"custodian"`
+	} `json:"lockup"`
+}
+
+type ParsedStake struct {
+	Delegation struct {
+		// The vote account the stake is delegated to.
+		Voter solana.PublicKey `json:"voter"`
+
+		// The delegated stake, in lamports.
+		Stake uint64 `json:"stake,string"`
+
+		ActivationEpoch uint64 `json:"activationEpoch,string"`
+
+		// math.MaxUint64 if the stake is not deactivating.
+		DeactivationEpoch uint64 `json:"deactivationEpoch,string"`
+
+		// Deprecated.
+		WarmupCooldownRate float64 `json:"warmupCooldownRate"`
+	} `json:"delegation"`
+
+	CreditsObserved uint64 `json:"creditsObserved"`
+}
+
+type ParsedVoteAccount struct {
+	NodePubkey           solana.PublicKey `json:"nodePubkey"`
+	AuthorizedWithdrawer solana.PublicKey `json:"authorizedWithdrawer"`
+	Commission           uint8            `json:"commission"`
+
+	Votes []struct {
+		Slot              uint64 `json:"slot"`
+		ConfirmationCount uint32 `json:"confirmationCount"`
+	} `json:"votes"`
+
+	RootSlot *uint64 `json:"rootSlot"`
+
+	AuthorizedVoters []struct {
+		Epoch           uint64           `json:"epoch"`
+		AuthorizedVoter solana.PublicKey `json:"authorizedVoter"`
+	} `json:"authorizedVoters"`
+
+	PriorVoters []struct {
+		AuthorizedPubkey            solana.PublicKey `json:"authorizedPubkey"`
+		EpochOfLastAuthorizedSwitch uint64           `json:"epochOfLastAuthorizedSwitch"`
+		TargetEpoch                 uint64           `json:"targetEpoch"`
+	} `json:"priorVoters"`
+
+	EpochCredits []struct {
+		Epoch           uint64 `json:"epoch"`
+		Credits         uint64 `json:"credits,string"`
+		PreviousCredits uint64 `json:"previousCredits,string"`
+	} `json:"epochCredits"`
+
+	LastTimestamp struct {
+		Slot      uint64 `json:"slot"`
+		Timestamp int64  `json:"timestamp"`
+	} `json:"lastTimestamp"`
+}
+
+type ParsedFeeCalculator struct {
+	LamportsPerSignature uint64 `json:"lamportsPerSignature,string"`
+}
+
+type ParsedNonceAccount struct {
+	Authority     solana.PublicKey    `json:"authority"`
+	Blockhash     solana.Hash         `json:"blockhash"`
+	FeeCalculator ParsedFeeCalculator `json:"feeCalculator"`
+}
+
+type ParsedSysvarClock struct {
+	Slot                uint64 `json:"slot"`
+	Epoch               uint64 `json:"epoch"`
+	EpochStartTimestamp int64  `json:"epochStartTimestamp"`
+	LeaderScheduleEpoch uint64 `json:"leaderScheduleEpoch"`
+	UnixTimestamp       int64  `json:"unixTimestamp"`
+}
+
+type ParsedSysvarFees struct {
+	FeeCalculator ParsedFeeCalculator `json:"feeCalculator"`
+}
+
+type ParsedSysvarRecentBlockhashes []struct {
+	Blockhash     solana.Hash         `json:"blockhash"`
+	FeeCalculator ParsedFeeCalculator `json:"feeCalculator"`
+}
+
+type ParsedSysvarRent struct {
+	LamportsPerByteYear uint64  `json:"lamportsPerByteYear,string"`
+	ExemptionThreshold  float64 `json:"exemptionThreshold"`
+	BurnPercent         uint8   `json:"burnPercent"`
+}
+
+type ParsedSysvarRewards struct {
+	ValidatorPointValue float64 `json:"validatorPointValue"`
+}
+
+type ParsedSysvarSlotHashes []struct {
+	Slot uint64      `json:"slot"`
+	Hash solana.Hash `json:"hash"`
+}
+
+type ParsedSysvarSlotHistory struct {
+	NextSlot uint64 `json:"nextSlot"`
+
+	// One character ('0' or '1') per slot.
+	Bits string `json:"bits"`
+}
+
+type ParsedSysvarStakeHistory []struct {
+	Epoch        uint64 `json:"epoch"`
+	StakeHistory struct {
+		Effective    uint64 `json:"effective"`
+		Activating   uint64 `json:"activating"`
+		Deactivating uint64 `json:"deactivating"`
+	} `json:"stakeHistory"`
+}
+
+type ParsedSysvarLastRestartSlot struct {
+	LastRestartSlot uint64 `json:"lastRestartSlot"`
+}
+
+type ParsedSysvarEpochRewards struct {
+	DistributionStartingBlockHeight uint64      `json:"distributionStartingBlockHeight"`
+	NumPartitions                   uint64      `json:"numPartitions"`
+	ParentBlockhash                 solana.Hash `json:"parentBlockhash"`
+	TotalPoints                     string      `json:"totalPoints"` // u128
+	TotalRewards                    uint64      `json:"totalRewards,string"`
+	DistributedRewards              uint64      `json:"distributedRewards,string"`
+	Active                          bool        `json:"active"`
+}
+
+type ParsedAddressLookupTable struct {
+	// math.MaxUint64 if the table is not deactivated.
+	DeactivationSlot           uint64             `json:"deactivationSlot,string"`
+	LastExtendedSlot           uint64             `json:"lastExtendedSlot,string"`
+	LastExtendedSlotStartIndex uint8              `json:"lastExtendedSlotStartIndex"`
+	Authority                  *solana.PublicKey  `json:"authority"`
+	Addresses                  []solana.PublicKey `json:"addresses"`
+}
@@ -0,0 +1,220 @@
+// Copyright 2021 github.com/gagliardetto
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rpc
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func mustParsedAccount(t *testing.T, in string) interface{} {
+	t.Helper()
+	var data DataBytesOrJSON
+	require.NoError(t, data.UnmarshalJSON([]byte(in)))
+	out, err := data.GetParsedAccount()
+	require.NoError(t, err)
+	return out
+}
+
+func TestParsedAccount_TokenAccount(t *testing.T) {
+	in := `{
+		"program": "spl-token",
+		"parsed": {
+			"type": "account",
+			"info": {
+				"isNative": false,
+				"mint": "EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v",
+				"owner": "9WzDXwBbmkg8ZTbNMqUxvQRAyrZzDsGYdLVL9zYtAWWM",
+				"state": "initialized",
+				"delegate": "Q6XprfkF8RQQKoQVG33xT88H7wi8Uk1B1CC7YAs69Gi",
+				"delegatedAmount": {"amount": "1000", "decimals": 6, "uiAmount": 0.001, "uiAmountString": "0.001"},
+				"tokenAmount": {"amount": "1500000", "decimals": 6, "uiAmount": 1.5, "uiAmountString": "1.5"}
+			}
+		},
+		"space": 165
+	}`
+	out := mustParsedAccount(t, in)
+	account, ok := out.(*ParsedTokenAccount)
+	require.True(t, ok, "%T", out)
+
+	assert.Equal(t, solana.MustPublicKeyFromBase58("EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v"), account.Mint)
+	assert.Equal(t, solana.MustPublicKeyFromBase58("9WzDXwBbmkg8ZTbNMqUxvQRAyrZzDsGYdLVL9zYtAWWM"), account.Owner)
+	assert.Equal(t, "1500000", account.TokenAmount.Amount)
+	assert.Equal(t, ParsedTokenAccountStateInitialized, account.State)
+	require.NotNil(t, account.Delegate)
+	require.NotNil(t, account.DelegatedAmount)
+	assert.Equal(t, "1000", account.DelegatedAmount.Amount)
+	assert.Nil(t, account.CloseAuthority)
+	assert.False(t, account.IsNative)
+}
+
+func TestParsedAccount_Token2022Mint(t *testing.T) {
+	in := `{
+		"program": "spl-token-2022",
+		"parsed": {
+			"type": "mint",
+			"info": {
+				"decimals": 9,
+				"freezeAuthority": null,
+				"isInitialized": true,
+				"mintAuthority": "Q6XprfkF8RQQKoQVG33xT88H7wi8Uk1B1CC7YAs69Gi",
+				"supply": "18446744073709551615",
+				"extensions": [
+					{"extension": "mintCloseAuthority", "state": {"closeAuthority": null}}
+				]
+			}
+		},
+		"space": 202
+	}`
+	out := mustParsedAccount(t, in)
+	mint, ok := out.(*ParsedTokenMint)
+	require.True(t, ok, "%T", out)
+
+	assert.Equal(t, uint64(math.MaxUint64), mint.Supply)
+	assert.Equal(t, uint8(9), mint.Decimals)
+	assert.Nil(t, mint.FreezeAuthority)
+	require.NotNil(t, mint.MintAuthority)
+	require.Len(t, mint.Extensions, 1)
+	assert.Equal(t, "mintCloseAuthority", mint.Extensions[0].Extension)
+}
+
+func TestParsedAccount_Stake(t *testing.T) {
+	in := `{
+		"program": "stake",
+		"parsed": {
+			"type": "delegated",
+			"info": {
+				"meta": {
+					"authorized": {
+						"staker": "Q6XprfkF8RQQKoQVG33xT88H7wi8Uk1B1CC7YAs69Gi",
+						"withdrawer": "9WzDXwBbmkg8ZTbNMqUxvQRAyrZzDsGYdLVL9zYtAWWM"
+					},
+					"lockup": {"custodian": "11111111111111111111111111111111", "epoch": 0, "unixTimestamp": 0},
+					"rentExemptReserve": "2282880"
+				},
+				"stake": {
+					"creditsObserved": 169965713,
+					"delegation": {
+						"activationEpoch": "386",
+						"deactivationEpoch": "18446744073709551615",
+						"stake": "271336154013",
+						"voter": "CertusDeBmqN8ZawdkxK5kFGMwBXdudvWHYwtNgNhvLu",
+						"warmupCooldownRate": 0.25
+					}
+				}
+			}
+		},
+		"space": 200
+	}`
+	out := mustParsedAccount(t, in)
+	stake, ok := out.(*ParsedStakeAccount)
+	require.True(t, ok, "%T", out)
+
+	assert.Equal(t, uint64(2282880), stake.Meta.RentExemptReserve)
+	require.NotNil(t, stake.Stake)
+	assert.Equal(t, uint64(386), stake.Stake.Delegation.ActivationEpoch)
+	assert.Equal(t, uint64(math.MaxUint64), stake.Stake.Delegation.DeactivationEpoch)
+	assert.Equal(t, uint64(271336154013), stake.Stake.Delegation.Stake)
+	assert.Equal(t, uint64(169965713), stake.Stake.CreditsObserved)
+}
+
+func TestParsedAccount_Vote(t *testing.T) {
+	in := `{
+		"program": "vote",
+		"parsed": {
+			"type": "vote",
+			"info": {
+				"authorizedVoters": [{"authorizedVoter": "Q6XprfkF8RQQKoQVG33xT88H7wi8Uk1B1CC7YAs69Gi", "epoch": 500}],
+				"authorizedWithdrawer": "9WzDXwBbmkg8ZTbNMqUxvQRAyrZzDsGYdLVL9zYtAWWM",
+				"commission": 7,
+				"epochCredits": [{"credits": "1200", "epoch": 499, "previousCredits": "800"}],
+				"lastTimestamp": {"slot": 216000123, "timestamp": 1690000000},
+				"nodePubkey": "CertusDeBmqN8ZawdkxK5kFGMwBXdudvWHYwtNgNhvLu",
+				"priorVoters": [],
+				"rootSlot": 216000000,
+				"votes": [{"confirmationCount": 31, "slot": 216000001}]
+			}
+		},
+		"space": 3762
+	}`
+	out := mustParsedAccount(t, in)
+	vote, ok := out.(*ParsedVoteAccount)
+	require.True(t, ok, "%T", out)
+
+	assert.Equal(t, uint8(7), vote.Commission)
+	require.NotNil(t, vote.RootSlot)
+	assert.Equal(t, uint64(216000000), *vote.RootSlot)
+	require.Len(t, vote.EpochCredits, 1)
+	assert.Equal(t, uint64(1200), vote.EpochCredits[0].Credits)
+	assert.Equal(t, uint64(800), vote.EpochCredits[0].PreviousCredits)
+	require.Len(t, vote.Votes, 1)
+	assert.Equal(t, uint32(31), vote.Votes[0].ConfirmationCount)
+	assert.Equal(t, int64(1690000000), vote.LastTimestamp.Timestamp)
+}
+
+func TestParsedAccount_Sysvars(t *testing.T) {
+	rent := mustParsedAccount(t, `{
+		"program": "sysvar",
+		"parsed": {"type": "rent", "info": {"burnPercent": 50, "exemptionThreshold": 2.0, "lamportsPerByteYear": "3480"}},
+		"space": 17
+	}`)
+	require.IsType(t, &ParsedSysvarRent{}, rent)
+	assert.Equal(t, uint64(3480), rent.(*ParsedSysvarRent).LamportsPerByteYear)
+	assert.Equal(t, 2.0, rent.(*ParsedSysvarRent).ExemptionThreshold)
+
+	schedule := mustParsedAccount(t, `{
+		"program": "sysvar",
+		"parsed": {"type": "epochSchedule", "info": {"firstNormalEpoch": 0, "firstNormalSlot": 0, "leaderScheduleSlotOffset": 432000, "slotsPerEpoch": 432000, "warmup": false}},
+		"space": 33
+	}`)
+	require.IsType(t, &GetEpochScheduleResult{}, schedule)
+	assert.Equal(t, uint64(432000), schedule.(*GetEpochScheduleResult).SlotsPerEpoch)
+
+	history := mustParsedAccount(t, `{
+		"program": "sysvar",
+		"parsed": {"type": "stakeHistory", "info": [{"epoch": 500, "stakeHistory": {"activating": 1, "deactivating": 2, "effective": 3}}]},
+		"space": 16392
+	}`)
+	require.IsType(t, &ParsedSysvarStakeHistory{}, history)
+	require.Len(t, *history.(*ParsedSysvarStakeHistory), 1)
+	assert.Equal(t, uint64(3), (*history.(*ParsedSysvarStakeHistory))[0].StakeHistory.Effective)
+}
+
+func TestParsedAccount_Uninitialized(t *testing.T) {
+	out := mustParsedAccount(t, `{"program": "nonce", "parsed": {"type": "uninitialized"}, "space": 80}`)
+	assert.Equal(t, &ParsedUninitializedAccount{Program: ParsedProgramNonce, Type: "uninitialized"}, out)
+}
+
+func TestParsedAccount_Errors(t *testing.T) {
+	{
+		var data DataBytesOrJSON
+		require.NoError(t, data.UnmarshalJSON([]byte(`{"program": "bpf-upgradeable-loader", "parsed": {"type": "program", "info": {}}, "space": 36}`)))
+		_, err := data.GetParsedAccount()
+		assert.True(t, errors.Is(err, ErrUnknownParsedAccount), err)
+		// The raw JSON is still available.
+		assert.NotEmpty(t, data.GetRawJSON())
+	}
+	{
+		var data DataBytesOrJSON
+		require.NoError(t, data.UnmarshalJSON([]byte(`["aGVsbG8=", "base64"]`)))
+		_, err := data.GetParsedAccount()
+		assert.True(t, errors.Is(err, ErrNotJSONParsed), err)
+	}
+}
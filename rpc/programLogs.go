@@ -0,0 +1,262 @@
+// Copyright 2021 github.com/gagliardetto
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rpc
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/gagliardetto/solana-go"
+)
+
+// LogTruncatedMessage is the last log line of a transaction
+// whose logs exceeded the log size limit of the runtime.
+const LogTruncatedMessage = "Log truncated"
+
+const (
+	logPrefixProgram = "Program "
+	logPrefixLog     = "Program log: "
+	logPrefixData    = "Program data: "
+	logPrefixReturn  = "Program return: "
+)
+
+// InvocationOutcome is the outcome of a program invocation.
+type InvocationOutcome int
+
+const (
+	// InvocationIncomplete is the outcome of an invocation
+	// whose logs were truncated before its end.
+	InvocationIncomplete InvocationOutcome = iota
+	InvocationSuccess
+	InvocationFailed
+)
+
+func (o InvocationOutcome) String() string {
+	switch o {
+	case InvocationIncomplete:
+		return "incomplete"
+	case InvocationSuccess:
+		return "success"
+	case InvocationFailed:
+		return "failed"
+	default:
+		return fmt.Sprintf("InvocationOutcome(%d)", int(o))
+	}
+}
+
+// ProgramData holds the fields of a "Program data:" log line,
+// as emitted by one sol_log_data call.
+type ProgramData [][]byte
+
+// ProgramInvocation is a node of the invocation tree of a transaction.
+type ProgramInvocation struct {
+	ProgramID solana.PublicKey
+
+	// Stack height of the invocation: 1 for the instructions of the transaction,
+	// 2 for the programs they invoke, and so on.
+	Depth int
+
+	// The "Program log:" messages of the program, without the prefix,
+	// and the lines that are not recognized, as is.
+	Logs []string
+
+	// The "Program data:" events of the program.
+	Data []ProgramData
+
+	// The data set with sol_set_return_data, if any.
+	ReturnData *ReturnData
+
+	// Compute units consumed by the invocation, including its inner invocations,
+	// and the compute units that were available to it.
+	ComputeUnitsConsumed uint64
+	ComputeUnitsLimit    uint64
+
+	Outcome InvocationOutcome
+
+	// The reason of the failure, if Outcome is InvocationFailed.
+	Err string
+
+	// Inner invocations, in execution order.
+	Children []*ProgramInvocation
+}
+
+// ProgramLogs is the invocation tree of a transaction, as parsed by ParseProgramLogs.
+type ProgramLogs struct {
+	// Invocations of the instructions of the transaction, in execution order.
+	Invocations []*ProgramInvocation
+
+	// Whether the logs ended with LogTruncatedMessage.
+	Truncated bool
+}
+
+// Walk calls fn for every invocation of the tree, in execution order
+// (each invocation before its children), until fn returns false.
+func (logs *ProgramLogs) Walk(fn func(*ProgramInvocation) bool) {
+	walkInvocations(logs.Invocations, fn)
+}
+
+func walkInvocations(invocations []*ProgramInvocation, fn func(*ProgramInvocation) bool) bool {
+	for _, invocation := range invocations {
+		if !fn(invocation) || !walkInvocations(invocation.Children, fn) {
+			return false
+		}
+	}
+	return true
+}
+
+// ParseProgramLogs parses the log messages of a transaction
+// (TransactionMeta.LogMessages, or the logs of logsSubscribe notifications)
+// into its invocation tree.
+//
+// The invocations left open by truncated logs have the InvocationIncomplete outcome.
+func ParseProgramLogs(logs []string) (*ProgramLogs, error) {
+	out := &ProgramLogs{}
+	var stack []*ProgramInvocation
+	for i, line := range logs {
+		if line == LogTruncatedMessage {
+			out.Truncated = true
+			break
+		}
+		var current *ProgramInvocation
+		if len(stack) > 0 {
+			current = stack[len(stack)-1]
+		}
+
+		switch {
+		case strings.HasPrefix(line, logPrefixLog):
+			if current == nil {
+				return nil, fmt.Errorf("line %d: log outside of a program invocation", i)
+			}
+			current.Logs = append(current.Logs, strings.TrimPrefix(line, logPrefixLog))
+			continue
+		case strings.HasPrefix(line, logPrefixData):
+			if current == nil {
+				return nil, fmt.Errorf("line %d: data outside of a program invocation", i)
+			}
+			data, err := parseProgramData(strings.TrimPrefix(line, logPrefixData))
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", i, err)
+			}
+			current.Data = append(current.Data, data)
+			continue
+		case strings.HasPrefix(line, logPrefixReturn):
+			if current == nil {
+				return nil, fmt.Errorf("line %d: return data outside of a program invocation", i)
+			}
+			returnData, err := parseReturnData(strings.TrimPrefix(line, logPrefixReturn))
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", i, err)
+			}
+			current.ReturnData = returnData
+			continue
+		}
+
+		if programID, rest, ok := parseProgramLine(line); ok {
+			if strings.HasPrefix(rest, "invoke [") {
+				depth, err := strconv.Atoi(strings.TrimSuffix(strings.TrimPrefix(rest, "invoke ["), "]"))
+				if err != nil {
+					return nil, fmt.Errorf("line %d: invalid invoke depth: %w", i, err)
+				}
+				if depth != len(stack)+1 {
+					return nil, fmt.Errorf("line %d: invoke depth %d at depth %d", i, depth, len(stack))
+				}
+				invocation := &ProgramInvocation{ProgramID: programID, Depth: depth}
+				if current == nil {
+					out.Invocations = append(out.Invocations, invocation)
+				} else {
+					current.Children = append(current.Children, invocation)
+				}
+				stack = append(stack, invocation)
+				continue
+			}
+			if current != nil && current.ProgramID.Equals(programID) {
+				switch {
+				case rest == "success":
+					current.Outcome = InvocationSuccess
+					stack = stack[:len(stack)-1]
+					continue
+				case strings.HasPrefix(rest, "failed: "):
+					current.Outcome = InvocationFailed
+					current.Err = strings.TrimPrefix(rest, "failed: ")
+					stack = stack[:len(stack)-1]
+					continue
+				case strings.HasPrefix(rest, "consumed "):
+					if _, err := fmt.Sscanf(rest, "consumed %d of %d compute units", &current.ComputeUnitsConsumed, &current.ComputeUnitsLimit); err != nil {
+						return nil, fmt.Errorf("line %d: invalid compute units: %w", i, err)
+					}
+					continue
+				}
+			}
+		}
+
+		// Other runtime messages belong to the current invocation.
+		if current == nil {
+			return nil, fmt.Errorf("line %d: unexpected log %q", i, line)
+		}
+		current.Logs = append(current.Logs, line)
+	}
+	return out, nil
+}
+
+// parseProgramLine splits a "Program <id> <rest>" line.
+func parseProgramLine(line string) (solana.PublicKey, string, bool) {
+	if !strings.HasPrefix(line, logPrefixProgram) {
+		return solana.PublicKey{}, "", false
+	}
+	id, rest, ok := strings.Cut(strings.TrimPrefix(line, logPrefixProgram), " ")
+	if !ok {
+		return solana.PublicKey{}, "", false
+	}
+	programID, err := solana.PublicKeyFromBase58(id)
+	if err != nil {
+		return solana.PublicKey{}, "", false
+	}
+	return programID, rest, true
+}
+
+func parseProgramData(s string) (ProgramData, error) {
+	var data ProgramData
+	for _, field := range strings.Fields(s) {
+		decoded, err := base64.StdEncoding.DecodeString(field)
+		if err != nil {
+			return nil, fmt.Errorf("invalid program data: %w", err)
+		}
+		data = append(data, decoded)
+	}
+	return data, nil
+}
+
+func parseReturnData(s string) (*ReturnData, error) {
+	id, encoded, _ := strings.Cut(s, " ")
+	programID, err := solana.PublicKeyFromBase58(id)
+	if err != nil {
+		return nil, fmt.Errorf("invalid return data program: %w", err)
+	}
+	decoded, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("invalid return data: %w", err)
+	}
+	return &ReturnData{
+		ProgramId: programID,
+		Data:      solana.Data{Content: decoded, Encoding: solana.EncodingBase64},
+	}, nil
+}
+
+// ParseLogs parses the LogMessages of the transaction into its invocation tree.
+func (meta *TransactionMeta) ParseLogs() (*ProgramLogs, error) {
+	return ParseProgramLogs(meta.LogMessages)
+}
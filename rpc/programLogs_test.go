@@ -0,0 +1,115 @@
+// Copyright 2021 github.com/gagliardetto
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rpc
+
+import (
+	"testing"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseProgramLogs(t *testing.T) {
+	program := solana.MustPublicKeyFromBase58("whirLbMiicVdio4qvUfM5KAg6Ct8VwpYzGff3uctyCc")
+	logs := []string{
+		"Program ComputeBudget111111111111111111111111111111 invoke [1]",
+		"Program ComputeBudget111111111111111111111111111111 success",
+		"Program whirLbMiicVdio4qvUfM5KAg6Ct8VwpYzGff3uctyCc invoke [1]",
+		"Program log: Instruction: Swap",
+		"Program TokenkegQfeZyiNwAJbNbGKPFXCWuBvf9Ss623VQ5DA invoke [2]",
+		"Program log: Instruction: Transfer",
+		"Program TokenkegQfeZyiNwAJbNbGKPFXCWuBvf9Ss623VQ5DA consumed 4645 of 180000 compute units",
+		"Program TokenkegQfeZyiNwAJbNbGKPFXCWuBvf9Ss623VQ5DA success",
+		"Program data: AQID BAU=",
+		"Program return: whirLbMiicVdio4qvUfM5KAg6Ct8VwpYzGff3uctyCc KgAAAAAAAAA=",
+		"Program consumption: 150000 units remaining",
+		"Program whirLbMiicVdio4qvUfM5KAg6Ct8VwpYzGff3uctyCc consumed 50000 of 199850 compute units",
+		"Program whirLbMiicVdio4qvUfM5KAg6Ct8VwpYzGff3uctyCc success",
+		"Program 11111111111111111111111111111111 invoke [1]",
+		"Transfer: insufficient lamports 10, need 20",
+		"Program 11111111111111111111111111111111 failed: custom program error: 0x1",
+	}
+	got, err := ParseProgramLogs(logs)
+	require.NoError(t, err)
+	assert.False(t, got.Truncated)
+	require.Len(t, got.Invocations, 3)
+
+	budget := got.Invocations[0]
+	assert.Equal(t, solana.ComputeBudget, budget.ProgramID)
+	assert.Equal(t, InvocationSuccess, budget.Outcome)
+	assert.Empty(t, budget.Children)
+
+	swap := got.Invocations[1]
+	assert.Equal(t, program, swap.ProgramID)
+	assert.Equal(t, 1, swap.Depth)
+	assert.Equal(t, []string{"Instruction: Swap", "Program consumption: 150000 units remaining"}, swap.Logs)
+	assert.Equal(t, []ProgramData{{{1, 2, 3}, {4, 5}}}, swap.Data)
+	require.NotNil(t, swap.ReturnData)
+	assert.Equal(t, program, swap.ReturnData.ProgramId)
+	assert.Equal(t, []byte{42, 0, 0, 0, 0, 0, 0, 0}, swap.ReturnData.Data.Content)
+	assert.Equal(t, uint64(50000), swap.ComputeUnitsConsumed)
+	assert.Equal(t, uint64(199850), swap.ComputeUnitsLimit)
+	assert.Equal(t, InvocationSuccess, swap.Outcome)
+
+	require.Len(t, swap.Children, 1)
+	transfer := swap.Children[0]
+	assert.Equal(t, solana.TokenProgramID, transfer.ProgramID)
+	assert.Equal(t, 2, transfer.Depth)
+	assert.Equal(t, []string{"Instruction: Transfer"}, transfer.Logs)
+	assert.Equal(t, uint64(4645), transfer.ComputeUnitsConsumed)
+	assert.Equal(t, InvocationSuccess, transfer.Outcome)
+
+	system := got.Invocations[2]
+	assert.Equal(t, InvocationFailed, system.Outcome)
+	assert.Equal(t, "custom program error: 0x1", system.Err)
+	assert.Equal(t, []string{"Transfer: insufficient lamports 10, need 20"}, system.Logs)
+
+	var visited []solana.PublicKey
+	got.Walk(func(invocation *ProgramInvocation) bool {
+		visited = append(visited, invocation.ProgramID)
+		return invocation.ProgramID != solana.TokenProgramID
+	})
+	assert.Equal(t, []solana.PublicKey{solana.ComputeBudget, program, solana.TokenProgramID}, visited)
+}
+
+func TestParseProgramLogs_Truncated(t *testing.T) {
+	got, err := ParseProgramLogs([]string{
+		"Program whirLbMiicVdio4qvUfM5KAg6Ct8VwpYzGff3uctyCc invoke [1]",
+		"Program TokenkegQfeZyiNwAJbNbGKPFXCWuBvf9Ss623VQ5DA invoke [2]",
+		"Program log: Instruction: Transfer",
+		"Log truncated",
+	})
+	require.NoError(t, err)
+	assert.True(t, got.Truncated)
+	require.Len(t, got.Invocations, 1)
+	assert.Equal(t, InvocationIncomplete, got.Invocations[0].Outcome)
+	require.Len(t, got.Invocations[0].Children, 1)
+	assert.Equal(t, InvocationIncomplete, got.Invocations[0].Children[0].Outcome)
+}
+
+func TestParseProgramLogs_Invalid(t *testing.T) {
+	for name, logs := range map[string][]string{
+		"outside invocation": {"Program log: hello"},
+		"depth gap":          {"Program 11111111111111111111111111111111 invoke [2]"},
+		"invalid data": {
+			"Program 11111111111111111111111111111111 invoke [1]",
+			"Program data: !!!",
+		},
+	} {
+		_, err := ParseProgramLogs(logs)
+		assert.Error(t, err, name)
+	}
+}
@@ -0,0 +1,194 @@
+// Copyright 2021 github.com/gagliardetto
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package rpcreplay records JSON-RPC and websocket traffic to a fixture file,
+// and replays it without network, for deterministic tests and examples.
+//
+// Record once against a live endpoint:
+//
+//	rec := rpcreplay.NewRecorder(jsonrpc.NewClient(rpc.MainNetBeta_RPC), nil)
+//	wsURL, err := rec.StartWS(rpc.MainNetBeta_WS)
+//	client := rpc.NewWithCustomRPCClient(rec)
+//	wsClient, err := ws.Connect(ctx, wsURL)
+//	// ... use client and wsClient ...
+//	err = rec.Save("testdata/fixture.json")
+//
+// Then replay in tests:
+//
+//	rep, err := rpcreplay.NewReplayerFromFile("testdata/fixture.json")
+//	client := rpc.NewWithCustomRPCClient(rep)
+//	wsClient, err := ws.Connect(ctx, rep.StartWS())
+//	// ... use client and wsClient ...
+//	err = rep.Err() // reports the unmatched requests, if any
+//
+// Requests are matched by method and params; request IDs are not recorded,
+// and the replayed responses carry the IDs of the replayed requests.
+package rpcreplay
+
+import (
+	stdjson "encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/gagliardetto/solana-go/rpc/jsonrpc"
+	jsoniter "github.com/json-iterator/go"
+)
+
+var json = jsoniter.ConfigCompatibleWithStandardLibrary
+
+// Fixture holds the recorded traffic.
+type Fixture struct {
+	// Calls made over HTTP, in order.
+	Calls []*Call `json:"calls"`
+	// Requests made over websocket, with their notifications, in order.
+	Subscriptions []*Subscription `json:"subscriptions,omitempty"`
+}
+
+// Call is a recorded HTTP JSON-RPC call.
+type Call struct {
+	Method string             `json:"method"`
+	Params stdjson.RawMessage `json:"params,omitempty"`
+
+	// Headers of the request and of the response, with
+	// the sensitive values redacted (see RecorderOpts).
+	RequestHeader  http.Header `json:"requestHeader,omitempty"`
+	ResponseHeader http.Header `json:"responseHeader,omitempty"`
+
+	// Status code of the HTTP response; 0 if there was no response.
+	Status int `json:"status,omitempty"`
+
+	Result stdjson.RawMessage `json:"result,omitempty"`
+	Error  *jsonrpc.RPCError  `json:"error,omitempty"`
+
+	// Message of the error returned by the client when
+	// the call failed without a JSON-RPC error (e.g. network, HTTP status).
+	Failure string `json:"failure,omitempty"`
+}
+
+// Subscription is a recorded websocket request.
+type Subscription struct {
+	Method string             `json:"method"`
+	Params stdjson.RawMessage `json:"params,omitempty"`
+
+	Result stdjson.RawMessage `json:"result,omitempty"`
+	Error  *jsonrpc.RPCError  `json:"error,omitempty"`
+
+	// Method of the notifications, e.g. "accountNotification".
+	NotificationMethod string `json:"notificationMethod,omitempty"`
+	// The `params.result` values of the notifications, in order.
+	Notifications []stdjson.RawMessage `json:"notifications,omitempty"`
+}
+
+// LoadFixture reads a fixture file.
+func LoadFixture(path string) (*Fixture, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("rpcreplay: unable to read fixture: %w", err)
+	}
+	fixture := new(Fixture)
+	if err := json.Unmarshal(data, fixture); err != nil {
+		return nil, fmt.Errorf("rpcreplay: unable to decode fixture %q: %w", path, err)
+	}
+	return fixture, nil
+}
+
+// Save writes the fixture to a file.
+func (f *Fixture) Save(path string) error {
+	data, err := stdjson.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return fmt.Errorf("rpcreplay: unable to encode fixture: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("rpcreplay: unable to write fixture: %w", err)
+	}
+	return nil
+}
+
+// UnmatchedRequestError is returned by the Replayer
+// when a request has no recorded counterpart in the fixture.
+type UnmatchedRequestError struct {
+	Method string
+	Params stdjson.RawMessage
+}
+
+func (e *UnmatchedRequestError) Error() string {
+	return fmt.Sprintf("rpcreplay: no recorded response for %s(%s)", e.Method, string(e.Params))
+}
+
+// normalizeParams returns a canonical encoding of params, so that
+// requests can be matched regardless of how they were built:
+// object keys are sorted, and whitespace is removed.
+func normalizeParams(params interface{}) (stdjson.RawMessage, error) {
+	if params == nil {
+		return nil, nil
+	}
+	var raw []byte
+	switch p := params.(type) {
+	case stdjson.RawMessage:
+		raw = p
+	case []byte:
+		raw = p
+	default:
+		var err error
+		raw, err = json.Marshal(params)
+		if err != nil {
+			return nil, err
+		}
+	}
+	var generic interface{}
+	dec := stdjson.NewDecoder(strings.NewReader(string(raw)))
+	dec.UseNumber()
+	if err := dec.Decode(&generic); err != nil {
+		return nil, err
+	}
+	if generic == nil {
+		return nil, nil
+	}
+	if list, ok := generic.([]interface{}); ok && len(list) == 0 {
+		return nil, nil
+	}
+	// encoding/json sorts map keys.
+	return stdjson.Marshal(generic)
+}
+
+func matchKey(method string, params stdjson.RawMessage) string {
+	return method + string(params)
+}
+
+// DefaultRedactedHeaders are the headers whose values are never recorded.
+var DefaultRedactedHeaders = []string{
+	"Authorization",
+	"Proxy-Authorization",
+	"Cookie",
+	"Set-Cookie",
+	"X-Api-Key",
+}
+
+const redacted = "REDACTED"
+
+func redactHeader(header http.Header, names []string) http.Header {
+	if len(header) == 0 {
+		return nil
+	}
+	out := header.Clone()
+	for _, name := range names {
+		name = http.CanonicalHeaderKey(name)
+		if _, ok := out[name]; ok {
+			out[name] = []string{redacted}
+		}
+	}
+	return out
+}
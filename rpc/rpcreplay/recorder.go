@@ -0,0 +1,246 @@
+// Copyright 2021 github.com/gagliardetto
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rpcreplay
+
+import (
+	"bytes"
+	"context"
+	stdjson "encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+
+	"github.com/gagliardetto/solana-go/rpc"
+	"github.com/gagliardetto/solana-go/rpc/jsonrpc"
+)
+
+// RecorderOpts configures a Recorder.
+type RecorderOpts struct {
+	// Headers whose values are replaced with "REDACTED" in the fixture,
+	// in addition to DefaultRedactedHeaders.
+	RedactHeaders []string
+}
+
+// Recorder is a JSONRPCClient that forwards the calls to another client,
+// and records them with their responses.
+type Recorder struct {
+	rpcClient rpc.JSONRPCClient
+	redact    []string
+
+	mu      sync.Mutex
+	fixture Fixture
+
+	wsServer *httptest.Server
+}
+
+var _ rpc.JSONRPCClient = &Recorder{}
+
+// NewRecorder creates a Recorder forwarding the calls to rpcClient.
+func NewRecorder(rpcClient rpc.JSONRPCClient, opts *RecorderOpts) *Recorder {
+	rec := &Recorder{
+		rpcClient: rpcClient,
+		redact:    append([]string(nil), DefaultRedactedHeaders...),
+	}
+	if opts != nil {
+		rec.redact = append(rec.redact, opts.RedactHeaders...)
+	}
+	return rec
+}
+
+// Fixture returns a copy of the traffic recorded so far.
+func (rec *Recorder) Fixture() *Fixture {
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	return &Fixture{
+		Calls:         append([]*Call(nil), rec.fixture.Calls...),
+		Subscriptions: append([]*Subscription(nil), rec.fixture.Subscriptions...),
+	}
+}
+
+// Save writes the traffic recorded so far to a fixture file.
+func (rec *Recorder) Save(path string) error {
+	return rec.Fixture().Save(path)
+}
+
+// Close stops the websocket proxy, if started,
+// and closes the wrapped client if it implements io.Closer.
+func (rec *Recorder) Close() error {
+	rec.mu.Lock()
+	wsServer := rec.wsServer
+	rec.wsServer = nil
+	rec.mu.Unlock()
+	if wsServer != nil {
+		wsServer.CloseClientConnections()
+		wsServer.Close()
+	}
+	if c, ok := rec.rpcClient.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
+
+func (rec *Recorder) add(call *Call) {
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	rec.fixture.Calls = append(rec.fixture.Calls, call)
+}
+
+// newCall creates a Call, and a context recording the HTTP exchange into it.
+func (rec *Recorder) newCall(ctx context.Context, method string, params interface{}) (context.Context, *Call, error) {
+	normalized, err := normalizeParams(params)
+	if err != nil {
+		return nil, nil, fmt.Errorf("rpcreplay: unable to encode params of %s: %w", method, err)
+	}
+	call := &Call{
+		Method: method,
+		Params: normalized,
+	}
+	ctx = jsonrpc.WithResponseObserver(ctx, func(resp *http.Response) {
+		call.Status = resp.StatusCode
+		call.ResponseHeader = redactHeader(resp.Header, rec.redact)
+		if resp.Request != nil {
+			call.RequestHeader = redactHeader(resp.Request.Header, rec.redact)
+		}
+	})
+	return ctx, call, nil
+}
+
+// setOutcome records the error returned by the wrapped client.
+func (call *Call) setOutcome(err error) {
+	if err == nil {
+		return
+	}
+	var rpcErr *jsonrpc.RPCError
+	if errors.As(err, &rpcErr) {
+		call.Error = rpcErr
+		return
+	}
+	call.Failure = err.Error()
+}
+
+func (rec *Recorder) CallForInto(ctx context.Context, out interface{}, method string, params []interface{}) error {
+	ctx, call, err := rec.newCall(ctx, method, params)
+	if err != nil {
+		return err
+	}
+
+	var raw stdjson.RawMessage
+	err = rec.rpcClient.CallForInto(ctx, &raw, method, params)
+	call.setOutcome(err)
+	if err == nil {
+		call.Result = raw
+	}
+	rec.add(call)
+	if err != nil {
+		return err
+	}
+	return (&jsonrpc.RPCResponse{Result: raw}).GetObject(out)
+}
+
+func (rec *Recorder) CallWithCallback(
+	ctx context.Context,
+	method string,
+	params []interface{},
+	callback func(*http.Request, *http.Response) error,
+) error {
+	ctx, call, err := rec.newCall(ctx, method, params)
+	if err != nil {
+		return err
+	}
+
+	var decodeFailed bool
+	err = rec.rpcClient.CallWithCallback(ctx, method, params, func(req *http.Request, resp *http.Response) error {
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return err
+		}
+		var rpcResponse *jsonrpc.RPCResponse
+		if err := json.Unmarshal(body, &rpcResponse); err != nil || rpcResponse == nil {
+			decodeFailed = true
+			call.Failure = string(body)
+		} else {
+			call.Result = rpcResponse.Result
+			call.Error = rpcResponse.Error
+		}
+		resp.Body = io.NopCloser(bytes.NewReader(body))
+		return callback(req, resp)
+	})
+	if err != nil && call.Error == nil && !decodeFailed {
+		call.setOutcome(err)
+	}
+	rec.add(call)
+	return err
+}
+
+func (rec *Recorder) CallBatch(ctx context.Context, requests jsonrpc.RPCRequests) (jsonrpc.RPCResponses, error) {
+	calls := make([]*Call, len(requests))
+	for i, req := range requests {
+		var err error
+		if i == 0 {
+			ctx, calls[i], err = rec.newCall(ctx, req.Method, req.Params)
+		} else {
+			_, calls[i], err = rec.newCall(ctx, req.Method, req.Params)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// The jsonrpc client renumbers the requests: send copies.
+	sent := make(jsonrpc.RPCRequests, len(requests))
+	for i, req := range requests {
+		copied := *req
+		sent[i] = &copied
+	}
+	responses, err := rec.rpcClient.CallBatch(ctx, sent)
+	for i, call := range calls {
+		if i > 0 {
+			call.Status = calls[0].Status
+			call.RequestHeader = calls[0].RequestHeader
+			call.ResponseHeader = calls[0].ResponseHeader
+		}
+		if err != nil {
+			call.setOutcome(err)
+			continue
+		}
+		resp := responseByID(responses, sent[i].ID)
+		if resp == nil {
+			call.Failure = "rpcreplay: missing response in batch"
+			continue
+		}
+		call.Result = resp.Result
+		call.Error = resp.Error
+	}
+	for _, call := range calls {
+		rec.add(call)
+	}
+	return responses, err
+}
+
+// responseByID finds the response to the request with the provided ID;
+// IDs are compared by their JSON encoding, as numbers may be
+// decoded as json.Number.
+func responseByID(responses jsonrpc.RPCResponses, id interface{}) *jsonrpc.RPCResponse {
+	want := fmt.Sprint(id)
+	for _, resp := range responses {
+		if resp != nil && fmt.Sprint(resp.ID) == want {
+			return resp
+		}
+	}
+	return nil
+}
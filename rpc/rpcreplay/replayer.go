@@ -0,0 +1,282 @@
+// Copyright 2021 github.com/gagliardetto
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rpcreplay
+
+import (
+	"bytes"
+	"context"
+	stdjson "encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/gagliardetto/solana-go/rpc"
+	"github.com/gagliardetto/solana-go/rpc/jsonrpc"
+)
+
+// Replayer is a JSONRPCClient answering the calls with the responses
+// recorded in a fixture, without network.
+//
+// Identical requests are answered with the recorded responses in order;
+// once they are exhausted, the last one is repeated.
+// Requests without a recorded counterpart fail with an
+// *UnmatchedRequestError, and are reported by Err.
+type Replayer struct {
+	mu        sync.Mutex
+	calls     map[string][]*Call
+	used      map[*Call]bool
+	subs      map[string][]*Subscription
+	unmatched []*UnmatchedRequestError
+
+	wsServer *httptest.Server
+}
+
+var _ rpc.JSONRPCClient = &Replayer{}
+
+// NewReplayer creates a Replayer answering with the traffic of fixture.
+func NewReplayer(fixture *Fixture) *Replayer {
+	rep := &Replayer{
+		calls: map[string][]*Call{},
+		used:  map[*Call]bool{},
+		subs:  map[string][]*Subscription{},
+	}
+	// The fixture file may have been reformatted:
+	// normalize the params again.
+	for _, call := range fixture.Calls {
+		params, err := normalizeParams(call.Params)
+		if err != nil {
+			params = call.Params
+		}
+		key := matchKey(call.Method, params)
+		rep.calls[key] = append(rep.calls[key], call)
+	}
+	for _, sub := range fixture.Subscriptions {
+		params, err := normalizeParams(sub.Params)
+		if err != nil {
+			params = sub.Params
+		}
+		key := matchKey(sub.Method, params)
+		rep.subs[key] = append(rep.subs[key], sub)
+	}
+	return rep
+}
+
+// NewReplayerFromFile creates a Replayer answering with the traffic of a fixture file.
+func NewReplayerFromFile(path string) (*Replayer, error) {
+	fixture, err := LoadFixture(path)
+	if err != nil {
+		return nil, err
+	}
+	return NewReplayer(fixture), nil
+}
+
+// Err returns an error listing the requests that had no recorded counterpart,
+// or nil if all of them were matched.
+func (rep *Replayer) Err() error {
+	rep.mu.Lock()
+	defer rep.mu.Unlock()
+	if len(rep.unmatched) == 0 {
+		return nil
+	}
+	msgs := make([]string, len(rep.unmatched))
+	for i, err := range rep.unmatched {
+		msgs[i] = err.Error()
+	}
+	return errors.New(strings.Join(msgs, "\n"))
+}
+
+// Close stops the websocket server, if started.
+func (rep *Replayer) Close() error {
+	rep.mu.Lock()
+	wsServer := rep.wsServer
+	rep.wsServer = nil
+	rep.mu.Unlock()
+	if wsServer != nil {
+		wsServer.CloseClientConnections()
+		wsServer.Close()
+	}
+	return nil
+}
+
+func (rep *Replayer) unmatchedError(method string, params stdjson.RawMessage) error {
+	err := &UnmatchedRequestError{Method: method, Params: params}
+	rep.mu.Lock()
+	rep.unmatched = append(rep.unmatched, err)
+	rep.mu.Unlock()
+	return err
+}
+
+// next returns the recorded call answering a request.
+func (rep *Replayer) next(method string, params interface{}) (*Call, error) {
+	normalized, err := normalizeParams(params)
+	if err != nil {
+		return nil, fmt.Errorf("rpcreplay: unable to encode params of %s: %w", method, err)
+	}
+
+	rep.mu.Lock()
+	candidates := rep.calls[matchKey(method, normalized)]
+	var call *Call
+	for _, candidate := range candidates {
+		if !rep.used[candidate] {
+			call = candidate
+			break
+		}
+	}
+	if call == nil && len(candidates) > 0 {
+		call = candidates[len(candidates)-1]
+	}
+	if call != nil {
+		rep.used[call] = true
+	}
+	rep.mu.Unlock()
+
+	if call == nil {
+		return nil, rep.unmatchedError(method, normalized)
+	}
+	return call, nil
+}
+
+// outcome returns the error the client returned when the call was recorded.
+func (call *Call) outcome() error {
+	switch {
+	case call.Error != nil:
+		return call.Error
+	case call.Failure != "" && call.Status >= 400:
+		return jsonrpc.NewHTTPError(call.Status, errors.New(call.Failure))
+	case call.Failure != "":
+		return errors.New(call.Failure)
+	}
+	return nil
+}
+
+func (rep *Replayer) CallForInto(ctx context.Context, out interface{}, method string, params []interface{}) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	call, err := rep.next(method, params)
+	if err != nil {
+		return err
+	}
+	if err := call.outcome(); err != nil {
+		return err
+	}
+	return (&jsonrpc.RPCResponse{Result: call.Result}).GetObject(out)
+}
+
+func (rep *Replayer) CallWithCallback(
+	ctx context.Context,
+	method string,
+	params []interface{},
+	callback func(*http.Request, *http.Response) error,
+) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	call, err := rep.next(method, params)
+	if err != nil {
+		return err
+	}
+	if call.Status == 0 && call.Failure != "" {
+		// The request never got a response.
+		return errors.New(call.Failure)
+	}
+
+	const id = 1
+	reqBody, err := json.Marshal(&jsonrpc.RPCRequest{
+		Method:  method,
+		Params:  params,
+		ID:      id,
+		JSONRPC: "2.0",
+	})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, "http://rpcreplay.invalid", bytes.NewReader(reqBody))
+	if err != nil {
+		return err
+	}
+	req.Header = call.RequestHeader.Clone()
+
+	var respBody []byte
+	if call.Failure != "" {
+		respBody = []byte(call.Failure)
+	} else {
+		respBody, err = json.Marshal(&jsonrpc.RPCResponse{
+			JSONRPC: "2.0",
+			Result:  call.Result,
+			Error:   call.Error,
+			ID:      id,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	status := call.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	header := call.ResponseHeader.Clone()
+	if header == nil {
+		header = make(http.Header)
+	}
+	resp := &http.Response{
+		Status:        strconv.Itoa(status) + " " + http.StatusText(status),
+		StatusCode:    status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(respBody)),
+		ContentLength: int64(len(respBody)),
+		Request:       req,
+	}
+	defer resp.Body.Close()
+	return callback(req, resp)
+}
+
+func (rep *Replayer) CallBatch(ctx context.Context, requests jsonrpc.RPCRequests) (jsonrpc.RPCResponses, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if len(requests) == 0 {
+		return nil, errors.New("empty request list")
+	}
+
+	responses := make(jsonrpc.RPCResponses, len(requests))
+	for i, req := range requests {
+		call, err := rep.next(req.Method, req.Params)
+		if err != nil {
+			return nil, err
+		}
+		if call.Error == nil && call.Failure != "" {
+			// The whole batch failed.
+			return nil, call.outcome()
+		}
+		responses[i] = &jsonrpc.RPCResponse{
+			JSONRPC: "2.0",
+			Result:  call.Result,
+			Error:   call.Error,
+			// Numbered like the jsonrpc client numbers the requests,
+			// without renumbering the requests of the caller.
+			ID: stdjson.Number(strconv.Itoa(i)),
+		}
+	}
+	return responses, nil
+}
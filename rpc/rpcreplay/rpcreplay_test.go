@@ -0,0 +1,198 @@
+// Copyright 2021 github.com/gagliardetto
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rpcreplay
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+	"github.com/gagliardetto/solana-go/rpc/jsonrpc"
+	"github.com/gagliardetto/solana-go/rpc/rpctest"
+	"github.com/gagliardetto/solana-go/rpc/ws"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecordReplay_HTTP(t *testing.T) {
+	srv := rpctest.NewServer()
+	defer srv.Close()
+
+	pubkey := solana.NewWallet().PublicKey()
+	owner := solana.NewWallet().PublicKey()
+	srv.SetAccount(pubkey, rpctest.Account{Lamports: 42, Owner: owner, Data: []byte{1, 2, 3}})
+	srv.InjectError("getSlot", &jsonrpc.RPCError{Code: -32005, Message: "Node is unhealthy"}, 1)
+
+	rec := NewRecorder(jsonrpc.NewClientWithOpts(srv.URL, &jsonrpc.RPCClientOpts{
+		CustomHeaders: map[string]string{"Authorization": "Bearer secret"},
+	}), nil)
+	client := rpc.NewWithCustomRPCClient(rec)
+	ctx := context.Background()
+
+	account, err := client.GetAccountInfo(ctx, pubkey)
+	require.NoError(t, err)
+	_, err = client.GetSlot(ctx, "")
+	require.Error(t, err)
+	balance, err := client.GetBalance(ctx, pubkey, rpc.CommitmentFinalized)
+	require.NoError(t, err)
+	recorded := jsonrpc.RPCRequests{
+		jsonrpc.NewRequest("getBalance", []interface{}{pubkey}),
+		jsonrpc.NewRequest("getHealth"),
+	}
+	recorded[0].ID, recorded[1].ID = "balance", "health"
+	responses, err := rec.CallBatch(ctx, recorded)
+	require.NoError(t, err)
+	require.Len(t, responses, 2)
+	assert.Equal(t, "balance", recorded[0].ID)
+
+	path := filepath.Join(t.TempDir(), "fixture.json")
+	require.NoError(t, rec.Save(path))
+	fixture, err := LoadFixture(path)
+	require.NoError(t, err)
+	require.Len(t, fixture.Calls, 5)
+	assert.Equal(t, []string{redacted}, fixture.Calls[0].RequestHeader["Authorization"])
+	assert.Equal(t, http.StatusOK, fixture.Calls[0].Status)
+
+	// Replay, without the server.
+	srv.Close()
+	rep := NewReplayer(fixture)
+	client = rpc.NewWithCustomRPCClient(rep)
+
+	replayedAccount, err := client.GetAccountInfo(ctx, pubkey)
+	require.NoError(t, err)
+	assert.Equal(t, account, replayedAccount)
+
+	_, err = client.GetSlot(ctx, "")
+	var unhealthy *rpc.NodeUnhealthyError
+	require.True(t, errors.As(err, &unhealthy))
+
+	replayedBalance, err := client.GetBalance(ctx, pubkey, rpc.CommitmentFinalized)
+	require.NoError(t, err)
+	assert.Equal(t, balance.Value, replayedBalance.Value)
+
+	batch := jsonrpc.RPCRequests{
+		jsonrpc.NewRequest("getBalance", []interface{}{pubkey}),
+		jsonrpc.NewRequest("getHealth"),
+	}
+	batch[0].ID, batch[1].ID = "balance", "health"
+	replayedResponses, err := rep.CallBatch(ctx, batch)
+	require.NoError(t, err)
+	require.Len(t, replayedResponses, 2)
+	assert.Equal(t, responses[1].Result, replayedResponses[1].Result)
+	// The requests of the caller are left as they are.
+	assert.Equal(t, "balance", batch[0].ID)
+	assert.Equal(t, "health", batch[1].ID)
+
+	require.NoError(t, rep.Err())
+
+	// The same request is answered again with the last response...
+	_, err = client.GetAccountInfo(ctx, pubkey)
+	require.NoError(t, err)
+	// ... but a different one fails.
+	_, err = client.GetBalance(ctx, pubkey, rpc.CommitmentProcessed)
+	var unmatched *UnmatchedRequestError
+	require.True(t, errors.As(err, &unmatched))
+	assert.Equal(t, "getBalance", unmatched.Method)
+	require.Error(t, rep.Err())
+}
+
+func TestRecordReplay_Callback(t *testing.T) {
+	srv := rpctest.NewServer()
+	defer srv.Close()
+
+	rec := NewRecorder(jsonrpc.NewClient(srv.URL), nil)
+	var recorded string
+	err := rec.CallWithCallback(context.Background(), "getSlot", nil, func(req *http.Request, resp *http.Response) error {
+		var out jsonrpc.RPCResponse
+		if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+			return err
+		}
+		recorded = string(out.Result)
+		return nil
+	})
+	require.NoError(t, err)
+
+	rep := NewReplayer(rec.Fixture())
+	var replayed string
+	err = rep.CallWithCallback(context.Background(), "getSlot", nil, func(req *http.Request, resp *http.Response) error {
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		var out jsonrpc.RPCResponse
+		if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+			return err
+		}
+		replayed = string(out.Result)
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, recorded, replayed)
+}
+
+func TestRecordReplay_WS(t *testing.T) {
+	srv := rpctest.NewServer()
+	defer srv.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	pubkey := solana.NewWallet().PublicKey()
+	owner := solana.NewWallet().PublicKey()
+
+	rec := NewRecorder(jsonrpc.NewClient(srv.URL), nil)
+	defer rec.Close()
+	proxyURL, err := rec.StartWS(srv.WSURL)
+	require.NoError(t, err)
+
+	wsClient, err := ws.Connect(ctx, proxyURL)
+	require.NoError(t, err)
+	sub, err := wsClient.AccountSubscribe(pubkey, rpc.CommitmentConfirmed)
+	require.NoError(t, err)
+	require.Eventually(t, func() bool { return srv.SubscriptionCount() == 1 }, 5*time.Second, 10*time.Millisecond)
+
+	for i := uint64(1); i <= 2; i++ {
+		srv.SetAccount(pubkey, rpctest.Account{Lamports: i, Owner: owner})
+		got, err := sub.Recv(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, i, got.Value.Lamports)
+	}
+	sub.Unsubscribe()
+	wsClient.Close()
+
+	fixture := rec.Fixture()
+	require.Len(t, fixture.Subscriptions, 1)
+	assert.Equal(t, "accountSubscribe", fixture.Subscriptions[0].Method)
+	assert.Equal(t, "accountNotification", fixture.Subscriptions[0].NotificationMethod)
+	assert.Len(t, fixture.Subscriptions[0].Notifications, 2)
+
+	rep := NewReplayer(fixture)
+	defer rep.Close()
+	wsClient, err = ws.Connect(ctx, rep.StartWS())
+	require.NoError(t, err)
+	defer wsClient.Close()
+
+	sub, err = wsClient.AccountSubscribe(pubkey, rpc.CommitmentConfirmed)
+	require.NoError(t, err)
+	for i := uint64(1); i <= 2; i++ {
+		got, err := sub.Recv(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, i, got.Value.Lamports)
+	}
+	sub.Unsubscribe()
+	require.NoError(t, rep.Err())
+}
@@ -0,0 +1,279 @@
+// Copyright 2021 github.com/gagliardetto
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rpcreplay
+
+import (
+	stdjson "encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/gagliardetto/solana-go/rpc/jsonrpc"
+	"github.com/gorilla/websocket"
+)
+
+// wsMessage is the union of the websocket requests, responses and notifications.
+type wsMessage struct {
+	Version string             `json:"jsonrpc"`
+	ID      stdjson.RawMessage `json:"id,omitempty"`
+	Method  string             `json:"method,omitempty"`
+	Params  stdjson.RawMessage `json:"params,omitempty"`
+	Result  stdjson.RawMessage `json:"result,omitempty"`
+	Error   *jsonrpc.RPCError  `json:"error,omitempty"`
+}
+
+type wsNotificationParams struct {
+	Result       stdjson.RawMessage `json:"result"`
+	Subscription uint64             `json:"subscription"`
+}
+
+func isUnsubscribe(method string) bool {
+	return strings.HasSuffix(method, "Unsubscribe")
+}
+
+func hasID(id stdjson.RawMessage) bool {
+	return len(id) > 0 && string(id) != "null"
+}
+
+func wsURL(httpURL string) string {
+	return "ws" + strings.TrimPrefix(httpURL, "http")
+}
+
+// hopHeaders are the headers of the websocket handshake,
+// which are not forwarded to the upstream endpoint.
+var hopHeaders = []string{
+	"Connection",
+	"Upgrade",
+	"Sec-Websocket-Key",
+	"Sec-Websocket-Version",
+	"Sec-Websocket-Extensions",
+	"Sec-Websocket-Protocol",
+}
+
+// StartWS starts a websocket proxy to the upstream endpoint,
+// recording the subscriptions made through it along with their notifications,
+// and returns the URL to connect to (e.g. with ws.Connect).
+// The proxy is stopped by Close.
+func (rec *Recorder) StartWS(upstream string) (string, error) {
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	if rec.wsServer != nil {
+		return "", errors.New("rpcreplay: websocket proxy already started")
+	}
+	upgrader := websocket.Upgrader{CheckOrigin: func(*http.Request) bool { return true }}
+	rec.wsServer = httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		header := req.Header.Clone()
+		for _, name := range hopHeaders {
+			header.Del(name)
+		}
+		upstreamConn, resp, err := websocket.DefaultDialer.DialContext(req.Context(), upstream, header)
+		if err != nil {
+			status := http.StatusBadGateway
+			if resp != nil {
+				status = resp.StatusCode
+			}
+			http.Error(rw, fmt.Sprintf("rpcreplay: unable to dial upstream: %s", err), status)
+			return
+		}
+		clientConn, err := upgrader.Upgrade(rw, req, nil)
+		if err != nil {
+			upstreamConn.Close()
+			return
+		}
+		rec.proxyWS(clientConn, upstreamConn)
+	}))
+	return wsURL(rec.wsServer.URL), nil
+}
+
+func (rec *Recorder) proxyWS(clientConn, upstreamConn *websocket.Conn) {
+	defer clientConn.Close()
+	defer upstreamConn.Close()
+
+	var (
+		mu      sync.Mutex
+		pending = map[string]*Subscription{}
+		byID    = map[uint64]*Subscription{}
+	)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		defer clientConn.Close()
+		defer upstreamConn.Close()
+		for {
+			typ, data, err := clientConn.ReadMessage()
+			if err != nil {
+				return
+			}
+			var msg wsMessage
+			if json.Unmarshal(data, &msg) == nil && msg.Method != "" && hasID(msg.ID) && !isUnsubscribe(msg.Method) {
+				params, _ := normalizeParams(msg.Params)
+				mu.Lock()
+				pending[string(msg.ID)] = &Subscription{Method: msg.Method, Params: params}
+				mu.Unlock()
+			}
+			if err := upstreamConn.WriteMessage(typ, data); err != nil {
+				return
+			}
+		}
+	}()
+
+	for {
+		typ, data, err := upstreamConn.ReadMessage()
+		if err != nil {
+			break
+		}
+		var msg wsMessage
+		if json.Unmarshal(data, &msg) == nil {
+			mu.Lock()
+			switch {
+			case hasID(msg.ID):
+				if sub, ok := pending[string(msg.ID)]; ok {
+					delete(pending, string(msg.ID))
+					sub.Result = msg.Result
+					sub.Error = msg.Error
+					if subID, err := strconv.ParseUint(string(msg.Result), 10, 64); err == nil {
+						byID[subID] = sub
+					}
+					rec.mu.Lock()
+					rec.fixture.Subscriptions = append(rec.fixture.Subscriptions, sub)
+					rec.mu.Unlock()
+				}
+			case msg.Method != "":
+				var params wsNotificationParams
+				if json.Unmarshal(msg.Params, &params) == nil {
+					if sub, ok := byID[params.Subscription]; ok {
+						rec.mu.Lock()
+						sub.NotificationMethod = msg.Method
+						sub.Notifications = append(sub.Notifications, params.Result)
+						rec.mu.Unlock()
+					}
+				}
+			}
+			mu.Unlock()
+		}
+		if err := clientConn.WriteMessage(typ, data); err != nil {
+			break
+		}
+	}
+	clientConn.Close()
+	<-done
+}
+
+// StartWS starts a websocket server answering the subscriptions with the
+// recorded responses, followed by all their recorded notifications,
+// and returns its URL (e.g. for ws.Connect).
+// Unsubscriptions always succeed. The server is stopped by Close.
+func (rep *Replayer) StartWS() string {
+	rep.mu.Lock()
+	defer rep.mu.Unlock()
+	if rep.wsServer != nil {
+		return wsURL(rep.wsServer.URL)
+	}
+	upgrader := websocket.Upgrader{CheckOrigin: func(*http.Request) bool { return true }}
+	var nextSubID uint64
+	usedSubs := map[*Subscription]bool{}
+	rep.wsServer = httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		conn, err := upgrader.Upgrade(rw, req, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		for {
+			_, data, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			var msg wsMessage
+			if err := json.Unmarshal(data, &msg); err != nil {
+				return
+			}
+			for _, out := range rep.replayWS(&msg, usedSubs, &nextSubID) {
+				if err := conn.WriteJSON(out); err != nil {
+					return
+				}
+			}
+		}
+	}))
+	return wsURL(rep.wsServer.URL)
+}
+
+// replayWS returns the messages answering a websocket request.
+func (rep *Replayer) replayWS(msg *wsMessage, used map[*Subscription]bool, nextSubID *uint64) []interface{} {
+	if isUnsubscribe(msg.Method) {
+		return []interface{}{&wsMessage{Version: "2.0", ID: msg.ID, Result: stdjson.RawMessage("true")}}
+	}
+
+	params, err := normalizeParams(msg.Params)
+	if err != nil {
+		params = msg.Params
+	}
+	rep.mu.Lock()
+	candidates := rep.subs[matchKey(msg.Method, params)]
+	var sub *Subscription
+	for _, candidate := range candidates {
+		if !used[candidate] {
+			sub = candidate
+			break
+		}
+	}
+	if sub == nil && len(candidates) > 0 {
+		sub = candidates[len(candidates)-1]
+	}
+	if sub != nil {
+		used[sub] = true
+	}
+	rep.mu.Unlock()
+
+	if sub == nil {
+		err := rep.unmatchedError(msg.Method, params)
+		return []interface{}{&wsMessage{
+			Version: "2.0",
+			ID:      msg.ID,
+			Error:   &jsonrpc.RPCError{Code: -32601, Message: err.Error()},
+		}}
+	}
+	if sub.Error != nil {
+		return []interface{}{&wsMessage{Version: "2.0", ID: msg.ID, Error: sub.Error}}
+	}
+	if _, err := strconv.ParseUint(string(sub.Result), 10, 64); err != nil {
+		// Not a subscription.
+		return []interface{}{&wsMessage{Version: "2.0", ID: msg.ID, Result: sub.Result}}
+	}
+
+	rep.mu.Lock()
+	*nextSubID++
+	subID := *nextSubID
+	rep.mu.Unlock()
+
+	out := []interface{}{&wsMessage{
+		Version: "2.0",
+		ID:      msg.ID,
+		Result:  stdjson.RawMessage(strconv.FormatUint(subID, 10)),
+	}}
+	for _, notification := range sub.Notifications {
+		params, _ := json.Marshal(&wsNotificationParams{Result: notification, Subscription: subID})
+		out = append(out, &wsMessage{
+			Version: "2.0",
+			Method:  sub.NotificationMethod,
+			Params:  params,
+		})
+	}
+	return out
+}
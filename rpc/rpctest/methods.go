@@ -0,0 +1,420 @@
+// Copyright 2021 github.com/gagliardetto
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rpctest
+
+import (
+	"bytes"
+	"encoding/base64"
+	stdjson "encoding/json"
+	"fmt"
+
+	bin "github.com/gagliardetto/binary"
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+	"github.com/gagliardetto/solana-go/rpc/jsonrpc"
+	"github.com/mr-tron/base58"
+)
+
+type methodHandler func(s *Server, params []stdjson.RawMessage) (interface{}, *jsonrpc.RPCError)
+
+var httpMethods map[string]methodHandler
+
+func init() {
+	httpMethods = map[string]methodHandler{
+		"getAccountInfo":                    (*Server).getAccountInfo,
+		"getMultipleAccounts":               (*Server).getMultipleAccounts,
+		"getProgramAccounts":                (*Server).getProgramAccounts,
+		"getBalance":                        (*Server).getBalance,
+		"getMinimumBalanceForRentExemption": (*Server).getMinimumBalanceForRentExemption,
+		"getStakeMinimumDelegation":         (*Server).getStakeMinimumDelegation,
+		"getLatestBlockhash":                (*Server).getLatestBlockhash,
+		"isBlockhashValid":                  (*Server).isBlockhashValid,
+		"getSlot":                           (*Server).getSlot,
+		"getBlockHeight":                    (*Server).getBlockHeight,
+		"getHealth":                         (*Server).getHealth,
+		"sendTransaction":                   (*Server).sendTransaction,
+		"getSignatureStatuses":              (*Server).getSignatureStatuses,
+		"simulateTransaction":               (*Server).simulateTransaction,
+	}
+}
+
+// config holds the union of the configuration objects of the supported methods.
+type config struct {
+	Commitment               rpc.CommitmentType  `json:"commitment"`
+	Encoding                 solana.EncodingType `json:"encoding"`
+	DataSlice                *dataSlice          `json:"dataSlice"`
+	Filters                  []rpc.RPCFilter     `json:"filters"`
+	WithContext              bool                `json:"withContext"`
+	SearchTransactionHistory bool                `json:"searchTransactionHistory"`
+	SkipPreflight            bool                `json:"skipPreflight"`
+	Accounts                 *struct {
+		Encoding  solana.EncodingType `json:"encoding"`
+		Addresses []solana.PublicKey  `json:"addresses"`
+	} `json:"accounts"`
+}
+
+type dataSlice struct {
+	Offset uint64 `json:"offset"`
+	Length uint64 `json:"length"`
+}
+
+// wireAccount is an account as encoded in the responses.
+type wireAccount struct {
+	Lamports   uint64           `json:"lamports"`
+	Owner      solana.PublicKey `json:"owner"`
+	Data       solana.Data      `json:"data"`
+	Executable bool             `json:"executable"`
+	RentEpoch  uint64           `json:"rentEpoch"`
+	Space      uint64           `json:"space"`
+}
+
+type keyedAccount struct {
+	Pubkey  solana.PublicKey `json:"pubkey"`
+	Account *wireAccount     `json:"account"`
+}
+
+type contextValue struct {
+	Context rpc.Context `json:"context"`
+	Value   interface{} `json:"value"`
+}
+
+func param(params []stdjson.RawMessage, i int, out interface{}) *jsonrpc.RPCError {
+	if i >= len(params) {
+		return newRPCError(ErrorCodeInvalidParams, "missing parameter at index %d", i)
+	}
+	if err := json.Unmarshal(params[i], out); err != nil {
+		return newRPCError(ErrorCodeInvalidParams, "invalid parameter at index %d: %s", i, err)
+	}
+	return nil
+}
+
+func optionalConfig(params []stdjson.RawMessage, i int) (*config, *jsonrpc.RPCError) {
+	conf := new(config)
+	if i >= len(params) {
+		return conf, nil
+	}
+	return conf, param(params, i, conf)
+}
+
+func encodeAccount(account Account, conf *config) *wireAccount {
+	data := account.Data
+	if conf.DataSlice != nil {
+		start := conf.DataSlice.Offset
+		if start > uint64(len(data)) {
+			start = uint64(len(data))
+		}
+		end := start + conf.DataSlice.Length
+		if end > uint64(len(data)) {
+			end = uint64(len(data))
+		}
+		data = data[start:end]
+	}
+	encoding := conf.Encoding
+	switch encoding {
+	case solana.EncodingBase58, solana.EncodingBase64, solana.EncodingBase64Zstd:
+	default:
+		// No jsonParsed support: fall back to binary, as nodes do
+		// when no parser is found.
+		encoding = solana.EncodingBase64
+	}
+	return &wireAccount{
+		Lamports:   account.Lamports,
+		Owner:      account.Owner,
+		Data:       solana.Data{Content: data, Encoding: encoding},
+		Executable: account.Executable,
+		RentEpoch:  account.RentEpoch,
+		Space:      uint64(len(account.Data)),
+	}
+}
+
+func matchesFilters(account Account, filters []rpc.RPCFilter) bool {
+	for _, filter := range filters {
+		if filter.DataSize != 0 && uint64(len(account.Data)) != filter.DataSize {
+			return false
+		}
+		if filter.Memcmp != nil {
+			offset := filter.Memcmp.Offset
+			want := []byte(filter.Memcmp.Bytes)
+			if offset+uint64(len(want)) > uint64(len(account.Data)) {
+				return false
+			}
+			if !bytes.Equal(account.Data[offset:offset+uint64(len(want))], want) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func (s *Server) getAccountInfo(params []stdjson.RawMessage) (interface{}, *jsonrpc.RPCError) {
+	var pubkey solana.PublicKey
+	if err := param(params, 0, &pubkey); err != nil {
+		return nil, err
+	}
+	conf, err := optionalConfig(params, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := contextValue{Context: rpc.Context{Slot: s.slot}}
+	if account, ok := s.accounts[pubkey]; ok {
+		out.Value = encodeAccount(account, conf)
+	}
+	return out, nil
+}
+
+func (s *Server) getMultipleAccounts(params []stdjson.RawMessage) (interface{}, *jsonrpc.RPCError) {
+	var pubkeys []solana.PublicKey
+	if err := param(params, 0, &pubkeys); err != nil {
+		return nil, err
+	}
+	conf, err := optionalConfig(params, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	values := make([]*wireAccount, len(pubkeys))
+	for i, pubkey := range pubkeys {
+		if account, ok := s.accounts[pubkey]; ok {
+			values[i] = encodeAccount(account, conf)
+		}
+	}
+	return contextValue{Context: rpc.Context{Slot: s.slot}, Value: values}, nil
+}
+
+func (s *Server) getProgramAccounts(params []stdjson.RawMessage) (interface{}, *jsonrpc.RPCError) {
+	var program solana.PublicKey
+	if err := param(params, 0, &program); err != nil {
+		return nil, err
+	}
+	conf, err := optionalConfig(params, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	values := []*keyedAccount{}
+	for pubkey, account := range s.accounts {
+		if !account.Owner.Equals(program) || !matchesFilters(account, conf.Filters) {
+			continue
+		}
+		values = append(values, &keyedAccount{
+			Pubkey:  pubkey,
+			Account: encodeAccount(account, conf),
+		})
+	}
+	if conf.WithContext {
+		return contextValue{Context: rpc.Context{Slot: s.slot}, Value: values}, nil
+	}
+	return values, nil
+}
+
+func (s *Server) getBalance(params []stdjson.RawMessage) (interface{}, *jsonrpc.RPCError) {
+	var pubkey solana.PublicKey
+	if err := param(params, 0, &pubkey); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return contextValue{
+		Context: rpc.Context{Slot: s.slot},
+		Value:   s.accounts[pubkey].Lamports,
+	}, nil
+}
+
+func (s *Server) getLatestBlockhash(params []stdjson.RawMessage) (interface{}, *jsonrpc.RPCError) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return contextValue{
+		Context: rpc.Context{Slot: s.slot},
+		Value: &rpc.LatestBlockhashResult{
+			Blockhash:            s.blockhash,
+			LastValidBlockHeight: s.lastValidBlockHeight,
+		},
+	}, nil
+}
+
+func (s *Server) isBlockhashValid(params []stdjson.RawMessage) (interface{}, *jsonrpc.RPCError) {
+	var blockhash solana.Hash
+	if err := param(params, 0, &blockhash); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return contextValue{
+		Context: rpc.Context{Slot: s.slot},
+		Value:   blockhash.Equals(s.blockhash) && s.blockHeight <= s.lastValidBlockHeight,
+	}, nil
+}
+
+func (s *Server) getMinimumBalanceForRentExemption(params []stdjson.RawMessage) (interface{}, *jsonrpc.RPCError) {
+	var dataSize uint64
+	if err := param(params, 0, &dataSize); err != nil {
+		return nil, err
+	}
+	return MinimumBalanceForRentExemption(dataSize), nil
+}
+
+func (s *Server) getStakeMinimumDelegation(params []stdjson.RawMessage) (interface{}, *jsonrpc.RPCError) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return contextValue{
+		Context: rpc.Context{Slot: s.slot},
+		Value:   s.stakeMinimumDelegation,
+	}, nil
+}
+
+func (s *Server) getSlot(params []stdjson.RawMessage) (interface{}, *jsonrpc.RPCError) {
+	return s.Slot(), nil
+}
+
+func (s *Server) getBlockHeight(params []stdjson.RawMessage) (interface{}, *jsonrpc.RPCError) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.blockHeight, nil
+}
+
+func (s *Server) getHealth(params []stdjson.RawMessage) (interface{}, *jsonrpc.RPCError) {
+	return rpc.HealthOk, nil
+}
+
+func decodeTransaction(params []stdjson.RawMessage, conf *config) (*solana.Transaction, *jsonrpc.RPCError) {
+	var encoded string
+	if err := param(params, 0, &encoded); err != nil {
+		return nil, err
+	}
+	var (
+		raw []byte
+		err error
+	)
+	switch conf.Encoding {
+	case solana.EncodingBase64:
+		raw, err = base64.StdEncoding.DecodeString(encoded)
+	case "", solana.EncodingBase58:
+		raw, err = base58.Decode(encoded)
+	default:
+		return nil, newRPCError(ErrorCodeInvalidParams, "unsupported encoding: %s", conf.Encoding)
+	}
+	if err != nil {
+		return nil, newRPCError(ErrorCodeInvalidParams, "invalid transaction encoding: %s", err)
+	}
+	tx, err := solana.TransactionFromDecoder(bin.NewBinDecoder(raw))
+	if err != nil {
+		return nil, newRPCError(ErrorCodeInvalidParams, "failed to deserialize transaction: %s", err)
+	}
+	if len(tx.Signatures) == 0 {
+		return nil, newRPCError(rpc.ErrorCodeTransactionSignatureLenMismatch, "Transaction has no signatures")
+	}
+	return tx, nil
+}
+
+func (s *Server) sendTransaction(params []stdjson.RawMessage) (interface{}, *jsonrpc.RPCError) {
+	conf, rpcErr := optionalConfig(params, 1)
+	if rpcErr != nil {
+		return nil, rpcErr
+	}
+	tx, rpcErr := decodeTransaction(params, conf)
+	if rpcErr != nil {
+		return nil, rpcErr
+	}
+	sig := tx.Signatures[0]
+
+	s.mu.Lock()
+	if !conf.SkipPreflight {
+		if res := s.simulateLocked(tx); res.Err != nil {
+			s.mu.Unlock()
+			return nil, &jsonrpc.RPCError{
+				Code:    rpc.ErrorCodeSendTransactionPreflightFailure,
+				Message: fmt.Sprintf("Transaction simulation failed: %v", res.Err),
+				Data:    res,
+			}
+		}
+	}
+	s.txs = append(s.txs, tx)
+	status := s.sendStatus
+	slot := s.slot
+	s.mu.Unlock()
+
+	if status != "" {
+		res := &rpc.SignatureStatusesResult{
+			Slot:               slot,
+			ConfirmationStatus: status,
+		}
+		if status != rpc.ConfirmationStatusFinalized {
+			confirmations := uint64(0)
+			res.Confirmations = &confirmations
+		}
+		s.SetSignatureStatus(sig, res)
+	}
+	return sig, nil
+}
+
+func (s *Server) getSignatureStatuses(params []stdjson.RawMessage) (interface{}, *jsonrpc.RPCError) {
+	var sigs []solana.Signature
+	if err := param(params, 0, &sigs); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	values := make([]*rpc.SignatureStatusesResult, len(sigs))
+	for i, sig := range sigs {
+		values[i] = s.statuses[sig]
+	}
+	return contextValue{Context: rpc.Context{Slot: s.slot}, Value: values}, nil
+}
+
+func (s *Server) simulateTransaction(params []stdjson.RawMessage) (interface{}, *jsonrpc.RPCError) {
+	conf, rpcErr := optionalConfig(params, 1)
+	if rpcErr != nil {
+		return nil, rpcErr
+	}
+	tx, rpcErr := decodeTransaction(params, conf)
+	if rpcErr != nil {
+		return nil, rpcErr
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	res := *s.simulateLocked(tx)
+	if conf.Accounts != nil {
+		accountsConf := &config{Encoding: conf.Accounts.Encoding}
+		accounts := make([]*wireAccount, len(conf.Accounts.Addresses))
+		for i, pubkey := range conf.Accounts.Addresses {
+			if account, ok := s.accounts[pubkey]; ok {
+				accounts[i] = encodeAccount(account, accountsConf)
+			}
+		}
+		return contextValue{
+			Context: rpc.Context{Slot: s.slot},
+			Value:   simulateResultWithAccounts{SimulateTransactionResult: &res, Accounts: accounts},
+		}, nil
+	}
+	return contextValue{Context: rpc.Context{Slot: s.slot}, Value: &res}, nil
+}
+
+// simulateResultWithAccounts replaces the accounts of a SimulateTransactionResult
+// with the accounts of the store.
+type simulateResultWithAccounts struct {
+	*rpc.SimulateTransactionResult
+	Accounts []*wireAccount `json:"accounts"`
+}
@@ -0,0 +1,270 @@
+// Copyright 2021 github.com/gagliardetto
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package rpctest provides an in-process Solana JSON-RPC server, backed by
+// an in-memory account and transaction store, for testing code that uses
+// rpc.Client and ws.Client without a live cluster.
+//
+//	srv := rpctest.NewServer()
+//	defer srv.Close()
+//	srv.SetAccount(pubkey, rpctest.Account{Lamports: 1, Owner: owner, Data: data})
+//	client := rpc.New(srv.URL)
+//	wsClient, err := ws.Connect(ctx, srv.WSURL)
+//
+// The server keeps a single view of the ledger: the commitment
+// requested by the client is ignored, except by signature subscriptions,
+// which are notified once the transaction reaches it.
+package rpctest
+
+import (
+	"bytes"
+	stdjson "encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+	"github.com/gagliardetto/solana-go/rpc/jsonrpc"
+	"github.com/gorilla/websocket"
+	jsoniter "github.com/json-iterator/go"
+)
+
+var json = jsoniter.ConfigCompatibleWithStandardLibrary
+
+// JSON-RPC error codes used by the server.
+const (
+	ErrorCodeParseError     = -32700
+	ErrorCodeInvalidRequest = -32600
+	ErrorCodeMethodNotFound = -32601
+	ErrorCodeInvalidParams  = -32602
+)
+
+// Server is an in-process Solana JSON-RPC server
+// serving both HTTP and websocket requests.
+type Server struct {
+	// URL of the HTTP endpoint, to be used with rpc.New.
+	URL string
+	// URL of the websocket endpoint, to be used with ws.Connect.
+	WSURL string
+
+	httpServer *httptest.Server
+	upgrader   websocket.Upgrader
+
+	mu       sync.Mutex
+	accounts map[solana.PublicKey]Account
+	statuses map[solana.Signature]*rpc.SignatureStatusesResult
+	txs      []*solana.Transaction
+
+	slot                 uint64
+	blockHeight          uint64
+	blockhash            solana.Hash
+	lastValidBlockHeight uint64
+
+	stakeMinimumDelegation uint64
+
+	simulate   SimulateFunc
+	sendStatus rpc.ConfirmationStatusType
+
+	errors    map[string]*injectedError
+	latencies map[string]time.Duration
+	calls     map[string]int
+
+	subsMu    sync.Mutex
+	subs      map[uint64]*subscription
+	nextSubID uint64
+	conns     map[*wsConn]struct{}
+}
+
+type injectedError struct {
+	err   *jsonrpc.RPCError
+	times int
+}
+
+// SimulateFunc computes the result of simulating a transaction,
+// used by simulateTransaction and by the sendTransaction preflight.
+type SimulateFunc func(tx *solana.Transaction) *rpc.SimulateTransactionResult
+
+// NewServer starts a new server. Call Close when done.
+func NewServer() *Server {
+	s := &Server{
+		accounts:               map[solana.PublicKey]Account{},
+		statuses:               map[solana.Signature]*rpc.SignatureStatusesResult{},
+		slot:                   1,
+		blockHeight:            1,
+		blockhash:              solana.HashFromBytes(bytes.Repeat([]byte{1}, 32)),
+		lastValidBlockHeight:   1 + 150,
+		stakeMinimumDelegation: 1,
+		sendStatus:             rpc.ConfirmationStatusFinalized,
+		errors:                 map[string]*injectedError{},
+		latencies:              map[string]time.Duration{},
+		calls:                  map[string]int{},
+		subs:                   map[uint64]*subscription{},
+		nextSubID:              1,
+		conns:                  map[*wsConn]struct{}{},
+	}
+	s.httpServer = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	s.URL = s.httpServer.URL
+	s.WSURL = "ws" + strings.TrimPrefix(s.httpServer.URL, "http")
+	return s
+}
+
+// Close closes all the websocket connections and shuts down the server.
+func (s *Server) Close() {
+	s.subsMu.Lock()
+	for conn := range s.conns {
+		conn.close()
+	}
+	s.subsMu.Unlock()
+	s.httpServer.Close()
+}
+
+// InjectError makes the next `times` calls of method fail with err.
+// If times <= 0, every call fails until ClearErrors is called.
+func (s *Server) InjectError(method string, err *jsonrpc.RPCError, times int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.errors[method] = &injectedError{err: err, times: times}
+}
+
+// ClearErrors removes all the injected errors.
+func (s *Server) ClearErrors() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.errors = map[string]*injectedError{}
+}
+
+// SetLatency delays every response to method by d.
+// An empty method applies to all methods.
+func (s *Server) SetLatency(method string, d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.latencies[method] = d
+}
+
+// CallCount returns the number of calls received for method.
+func (s *Server) CallCount(method string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.calls[method]
+}
+
+// before is called for every request: it counts the call, applies the latency,
+// and returns the injected error, if any.
+func (s *Server) before(method string) *jsonrpc.RPCError {
+	s.mu.Lock()
+	s.calls[method]++
+	latency := s.latencies[""] + s.latencies[method]
+	var err *jsonrpc.RPCError
+	if injected, ok := s.errors[method]; ok {
+		err = injected.err
+		if injected.times > 0 {
+			injected.times--
+			if injected.times == 0 {
+				delete(s.errors, method)
+			}
+		}
+	}
+	s.mu.Unlock()
+
+	if latency > 0 {
+		time.Sleep(latency)
+	}
+	return err
+}
+
+type request struct {
+	Version string               `json:"jsonrpc"`
+	ID      stdjson.RawMessage   `json:"id"`
+	Method  string               `json:"method"`
+	Params  []stdjson.RawMessage `json:"params"`
+}
+
+type response struct {
+	Version string             `json:"jsonrpc"`
+	ID      stdjson.RawMessage `json:"id"`
+	Result  interface{}        `json:"result"`
+	Error   *jsonrpc.RPCError  `json:"error,omitempty"`
+}
+
+func (s *Server) serveHTTP(rw http.ResponseWriter, req *http.Request) {
+	if websocket.IsWebSocketUpgrade(req) {
+		s.serveWS(rw, req)
+		return
+	}
+
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		http.Error(rw, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var out interface{}
+	if trimmed := bytes.TrimSpace(body); len(trimmed) > 0 && trimmed[0] == '[' {
+		var reqs []*request
+		if err := json.Unmarshal(body, &reqs); err != nil {
+			out = errorResponse(nil, ErrorCodeParseError, err.Error())
+		} else {
+			resps := make([]*response, len(reqs))
+			for i, r := range reqs {
+				resps[i] = s.handle(r)
+			}
+			out = resps
+		}
+	} else {
+		var r request
+		if err := json.Unmarshal(body, &r); err != nil {
+			out = errorResponse(nil, ErrorCodeParseError, err.Error())
+		} else {
+			out = s.handle(&r)
+		}
+	}
+
+	rw.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(rw).Encode(out)
+}
+
+func (s *Server) handle(req *request) *response {
+	if err := s.before(req.Method); err != nil {
+		return &response{Version: "2.0", ID: req.ID, Error: err}
+	}
+	handler, ok := httpMethods[req.Method]
+	if !ok {
+		return errorResponse(req.ID, ErrorCodeMethodNotFound, "Method not found")
+	}
+	result, err := handler(s, req.Params)
+	if err != nil {
+		return &response{Version: "2.0", ID: req.ID, Error: err}
+	}
+	return &response{Version: "2.0", ID: req.ID, Result: result}
+}
+
+func errorResponse(id stdjson.RawMessage, code int, message string) *response {
+	return &response{
+		Version: "2.0",
+		ID:      id,
+		Error:   newRPCError(code, "%s", message),
+	}
+}
+
+func newRPCError(code int, format string, args ...interface{}) *jsonrpc.RPCError {
+	return &jsonrpc.RPCError{
+		Code:    code,
+		Message: fmt.Sprintf(format, args...),
+	}
+}
@@ -0,0 +1,259 @@
+// Copyright 2021 github.com/gagliardetto
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rpctest
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/programs/system"
+	"github.com/gagliardetto/solana-go/rpc"
+	"github.com/gagliardetto/solana-go/rpc/jsonrpc"
+	sendandconfirmtransaction "github.com/gagliardetto/solana-go/rpc/sendAndConfirmTransaction"
+	"github.com/gagliardetto/solana-go/rpc/ws"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestTransaction(t *testing.T, blockhash solana.Hash) *solana.Transaction {
+	payer := solana.NewWallet().PrivateKey
+	tx, err := solana.NewTransaction(
+		[]solana.Instruction{
+			system.NewTransferInstruction(1, payer.PublicKey(), solana.NewWallet().PublicKey()).Build(),
+		},
+		blockhash,
+		solana.TransactionPayer(payer.PublicKey()),
+	)
+	require.NoError(t, err)
+	_, err = tx.Sign(func(key solana.PublicKey) *solana.PrivateKey {
+		return &payer
+	})
+	require.NoError(t, err)
+	return tx
+}
+
+func TestServer_Accounts(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+	client := rpc.New(srv.URL)
+	ctx := context.Background()
+
+	owner := solana.NewWallet().PublicKey()
+	a := solana.NewWallet().PublicKey()
+	b := solana.NewWallet().PublicKey()
+	srv.SetAccount(a, Account{Lamports: 10, Owner: owner, Data: []byte{1, 2, 3, 4}})
+	srv.SetAccount(b, Account{Lamports: 20, Owner: owner, Data: []byte{1, 9}})
+
+	info, err := client.GetAccountInfo(ctx, a)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(10), info.Value.Lamports)
+	assert.Equal(t, owner, info.Value.Owner)
+	assert.Equal(t, []byte{1, 2, 3, 4}, info.GetBinary())
+
+	_, err = client.GetAccountInfo(ctx, solana.NewWallet().PublicKey())
+	assert.Equal(t, rpc.ErrNotFound, err)
+
+	multi, err := client.GetMultipleAccounts(ctx, a, solana.NewWallet().PublicKey(), b)
+	require.NoError(t, err)
+	require.Len(t, multi.Value, 3)
+	assert.Nil(t, multi.Value[1])
+	assert.Equal(t, uint64(20), multi.Value[2].Lamports)
+
+	balance, err := client.GetBalance(ctx, b, rpc.CommitmentFinalized)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(20), balance.Value)
+
+	programAccounts, err := client.GetProgramAccountsWithOpts(ctx, owner, &rpc.GetProgramAccountsOpts{
+		Filters: []rpc.RPCFilter{
+			{DataSize: 4},
+			{Memcmp: &rpc.RPCFilterMemcmp{Offset: 1, Bytes: solana.Base58{2, 3}}},
+		},
+	})
+	require.NoError(t, err)
+	require.Len(t, programAccounts, 1)
+	assert.Equal(t, a, programAccounts[0].Pubkey)
+}
+
+func TestServer_Transactions(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+	client := rpc.New(srv.URL)
+	ctx := context.Background()
+
+	latest, err := client.GetLatestBlockhash(ctx, rpc.CommitmentFinalized)
+	require.NoError(t, err)
+
+	tx := newTestTransaction(t, latest.Value.Blockhash)
+	sig, err := client.SendTransaction(ctx, tx)
+	require.NoError(t, err)
+	assert.Equal(t, tx.Signatures[0], sig)
+	assert.Len(t, srv.Transactions(), 1)
+
+	statuses, err := client.GetSignatureStatuses(ctx, false, sig, solana.Signature{})
+	require.NoError(t, err)
+	require.Len(t, statuses.Value, 2)
+	assert.Equal(t, rpc.ConfirmationStatusFinalized, statuses.Value[0].ConfirmationStatus)
+	assert.Nil(t, statuses.Value[1])
+
+	srv.SetSimulateFunc(func(tx *solana.Transaction) *rpc.SimulateTransactionResult {
+		return &rpc.SimulateTransactionResult{Err: "BlockhashNotFound", Logs: []string{"log"}}
+	})
+	sim, err := client.SimulateTransaction(ctx, tx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"log"}, sim.Value.Logs)
+
+	_, err = client.SendTransaction(ctx, newTestTransaction(t, latest.Value.Blockhash))
+	assert.True(t, rpc.IsBlockhashNotFound(err))
+	assert.Len(t, srv.Transactions(), 1)
+}
+
+func TestServer_StakeAndRent(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+	client := rpc.New(srv.URL)
+	ctx := context.Background()
+
+	rent, err := client.GetMinimumBalanceForRentExemption(ctx, 200, rpc.CommitmentFinalized)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(2_282_880), rent)
+
+	minimum, err := client.GetStakeMinimumDelegation(ctx, rpc.CommitmentFinalized)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1), minimum.Value)
+	srv.SetStakeMinimumDelegation(1_000_000_000)
+	minimum, err = client.GetStakeMinimumDelegation(ctx, rpc.CommitmentFinalized)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1_000_000_000), minimum.Value)
+}
+
+func TestServer_InjectErrorAndLatency(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+	client := rpc.New(srv.URL)
+	ctx := context.Background()
+
+	srv.InjectError("getSlot", &jsonrpc.RPCError{Code: rpc.ErrorCodeNodeUnhealthy, Message: "Node is unhealthy"}, 1)
+	_, err := client.GetSlot(ctx, rpc.CommitmentFinalized)
+	var unhealthy *rpc.NodeUnhealthyError
+	assert.True(t, errors.As(err, &unhealthy))
+
+	srv.SetLatency("getSlot", 50*time.Millisecond)
+	start := time.Now()
+	slot, err := client.GetSlot(ctx, rpc.CommitmentFinalized)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1), slot)
+	assert.GreaterOrEqual(t, time.Since(start), 50*time.Millisecond)
+	assert.Equal(t, 2, srv.CallCount("getSlot"))
+}
+
+func TestServer_Subscriptions(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	wsClient, err := ws.Connect(ctx, srv.WSURL)
+	require.NoError(t, err)
+	defer wsClient.Close()
+
+	owner := solana.NewWallet().PublicKey()
+	account := solana.NewWallet().PublicKey()
+
+	accountSub, err := wsClient.AccountSubscribe(account, rpc.CommitmentConfirmed)
+	require.NoError(t, err)
+	defer accountSub.Unsubscribe()
+	programSub, err := wsClient.ProgramSubscribe(owner, rpc.CommitmentConfirmed)
+	require.NoError(t, err)
+	defer programSub.Unsubscribe()
+	slotSub, err := wsClient.SlotSubscribe()
+	require.NoError(t, err)
+	defer slotSub.Unsubscribe()
+
+	require.Eventually(t, func() bool { return srv.SubscriptionCount() == 3 }, time.Second, 10*time.Millisecond)
+
+	srv.SetAccount(account, Account{Lamports: 42, Owner: owner, Data: []byte{7}})
+
+	accountRes, err := accountSub.Recv(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(42), accountRes.Value.Lamports)
+	assert.Equal(t, []byte{7}, accountRes.Value.Data.GetBinary())
+
+	programRes, err := programSub.Recv(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, account, programRes.Value.Pubkey)
+
+	srv.SetSlot(10, 9)
+	slotRes, err := slotSub.Recv(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(10), slotRes.Slot)
+	assert.Equal(t, uint64(1), slotRes.Parent)
+
+	sig := solana.Signature{1, 2, 3}
+	sigSub, err := wsClient.SignatureSubscribe(sig, rpc.CommitmentConfirmed)
+	require.NoError(t, err)
+	require.Eventually(t, func() bool { return srv.SubscriptionCount() == 4 }, time.Second, 10*time.Millisecond)
+
+	srv.SetSignatureStatus(sig, &rpc.SignatureStatusesResult{Slot: 10, ConfirmationStatus: rpc.ConfirmationStatusProcessed})
+	assert.Equal(t, 4, srv.SubscriptionCount())
+	srv.SetSignatureStatus(sig, &rpc.SignatureStatusesResult{Slot: 10, ConfirmationStatus: rpc.ConfirmationStatusConfirmed})
+	sigRes, err := sigSub.Recv(ctx)
+	require.NoError(t, err)
+	assert.Nil(t, sigRes.Value.Err)
+	assert.Equal(t, 3, srv.SubscriptionCount())
+}
+
+func TestServer_SubscribeToProcessedSignature(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	client := rpc.New(srv.URL)
+
+	wsClient, err := ws.Connect(ctx, srv.WSURL)
+	require.NoError(t, err)
+	defer wsClient.Close()
+	latest, err := client.GetLatestBlockhash(ctx, rpc.CommitmentFinalized)
+	require.NoError(t, err)
+	blockhash := latest.Value.Blockhash
+
+	// The transaction is finalized before the subscription arrives.
+	sig, err := client.SendTransaction(ctx, newTestTransaction(t, blockhash))
+	require.NoError(t, err)
+	sub, err := wsClient.SignatureSubscribe(sig, rpc.CommitmentConfirmed)
+	require.NoError(t, err)
+	res, err := sub.Recv(ctx)
+	require.NoError(t, err)
+	assert.Nil(t, res.Value.Err)
+	require.Eventually(t, func() bool { return srv.SubscriptionCount() == 0 }, time.Second, 10*time.Millisecond)
+
+	// Not before the requested commitment is reached.
+	srv.SetSendConfirmationStatus(rpc.ConfirmationStatusProcessed)
+	sig, err = client.SendTransaction(ctx, newTestTransaction(t, blockhash))
+	require.NoError(t, err)
+	sub, err = wsClient.SignatureSubscribe(sig, rpc.CommitmentFinalized)
+	require.NoError(t, err)
+	require.Eventually(t, func() bool { return srv.SubscriptionCount() == 1 }, time.Second, 10*time.Millisecond)
+	srv.SetSignatureStatus(sig, &rpc.SignatureStatusesResult{ConfirmationStatus: rpc.ConfirmationStatusFinalized})
+	_, err = sub.Recv(ctx)
+	require.NoError(t, err)
+
+	// Send then subscribe, as SendAndConfirmTransaction does.
+	srv.SetSendConfirmationStatus(rpc.ConfirmationStatusFinalized)
+	_, err = sendandconfirmtransaction.SendAndConfirmTransaction(ctx, client, wsClient, newTestTransaction(t, blockhash))
+	require.NoError(t, err)
+}
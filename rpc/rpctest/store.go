@@ -0,0 +1,165 @@
+// Copyright 2021 github.com/gagliardetto
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rpctest
+
+import (
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+)
+
+// Account is an account stored by the server.
+type Account struct {
+	Lamports   uint64
+	Owner      solana.PublicKey
+	Data       []byte
+	Executable bool
+	RentEpoch  uint64
+}
+
+// SetAccount creates or replaces an account,
+// and notifies the matching account and program subscriptions.
+func (s *Server) SetAccount(pubkey solana.PublicKey, account Account) {
+	s.mu.Lock()
+	account.Data = append([]byte(nil), account.Data...)
+	s.accounts[pubkey] = account
+	slot := s.slot
+	s.mu.Unlock()
+
+	s.notifyAccount(slot, pubkey, account)
+}
+
+// DeleteAccount removes an account, and notifies the account subscriptions
+// with an empty account, as a node does when an account is closed.
+func (s *Server) DeleteAccount(pubkey solana.PublicKey) {
+	s.mu.Lock()
+	account, ok := s.accounts[pubkey]
+	delete(s.accounts, pubkey)
+	slot := s.slot
+	s.mu.Unlock()
+
+	if ok {
+		s.notifyAccount(slot, pubkey, Account{Owner: account.Owner})
+	}
+}
+
+// GetAccount returns a stored account.
+func (s *Server) GetAccount(pubkey solana.PublicKey) (Account, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	account, ok := s.accounts[pubkey]
+	return account, ok
+}
+
+// SetSlot sets the current slot and block height,
+// and notifies the slot and root subscriptions.
+// The previous slot is reported as both parent and root.
+func (s *Server) SetSlot(slot uint64, blockHeight uint64) {
+	s.mu.Lock()
+	parent := s.slot
+	s.slot = slot
+	s.blockHeight = blockHeight
+	s.mu.Unlock()
+
+	s.notifySlot(parent, slot)
+}
+
+// Slot returns the current slot.
+func (s *Server) Slot() uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.slot
+}
+
+// SetLatestBlockhash sets the blockhash returned by getLatestBlockhash.
+func (s *Server) SetLatestBlockhash(blockhash solana.Hash, lastValidBlockHeight uint64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.blockhash = blockhash
+	s.lastValidBlockHeight = lastValidBlockHeight
+}
+
+// SetStakeMinimumDelegation sets the value returned by getStakeMinimumDelegation,
+// 1 lamport by default.
+func (s *Server) SetStakeMinimumDelegation(lamports uint64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.stakeMinimumDelegation = lamports
+}
+
+// MinimumBalanceForRentExemption returns the rent exemption of an account
+// with dataSize bytes of data, under the default rent of the clusters;
+// it is the value returned by getMinimumBalanceForRentExemption.
+func MinimumBalanceForRentExemption(dataSize uint64) uint64 {
+	const (
+		accountStorageOverhead = 128
+		lamportsPerByteYear    = 3480
+		exemptionThreshold     = 2
+	)
+	return (accountStorageOverhead + dataSize) * lamportsPerByteYear * exemptionThreshold
+}
+
+// SetSimulateFunc sets the function computing the result of simulateTransaction,
+// and of the sendTransaction preflight checks. By default, every transaction succeeds.
+func (s *Server) SetSimulateFunc(fn SimulateFunc) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.simulate = fn
+}
+
+// SetSendConfirmationStatus sets the status of the transactions received by sendTransaction
+// (default: finalized). An empty status leaves them unknown until SetSignatureStatus is called.
+func (s *Server) SetSendConfirmationStatus(status rpc.ConfirmationStatusType) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sendStatus = status
+}
+
+// SetSignatureStatus sets the status returned by getSignatureStatuses for sig,
+// and notifies the matching signature subscriptions.
+// A nil status makes the signature unknown again.
+func (s *Server) SetSignatureStatus(sig solana.Signature, status *rpc.SignatureStatusesResult) {
+	s.mu.Lock()
+	if status == nil {
+		delete(s.statuses, sig)
+		s.mu.Unlock()
+		return
+	}
+	copied := *status
+	s.statuses[sig] = &copied
+	slot := s.slot
+	s.mu.Unlock()
+
+	s.notifySignature(slot, sig, &copied)
+}
+
+// Transactions returns the transactions received by sendTransaction, in order.
+func (s *Server) Transactions() []*solana.Transaction {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]*solana.Transaction(nil), s.txs...)
+}
+
+func (s *Server) simulateLocked(tx *solana.Transaction) *rpc.SimulateTransactionResult {
+	if s.simulate != nil {
+		if res := s.simulate(tx); res != nil {
+			return res
+		}
+	}
+	unitsConsumed := uint64(0)
+	return &rpc.SimulateTransactionResult{
+		Logs:          []string{},
+		UnitsConsumed: &unitsConsumed,
+	}
+}
@@ -0,0 +1,333 @@
+// Copyright 2021 github.com/gagliardetto
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rpctest
+
+import (
+	stdjson "encoding/json"
+	"net/http"
+	"sync"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+	"github.com/gagliardetto/solana-go/rpc/jsonrpc"
+	"github.com/gorilla/websocket"
+)
+
+type subscriptionKind string
+
+const (
+	subscriptionAccount   subscriptionKind = "account"
+	subscriptionProgram   subscriptionKind = "program"
+	subscriptionSignature subscriptionKind = "signature"
+	subscriptionSlot      subscriptionKind = "slot"
+	subscriptionRoot      subscriptionKind = "root"
+)
+
+type subscription struct {
+	id     uint64
+	kind   subscriptionKind
+	conn   *wsConn
+	pubkey solana.PublicKey
+	sig    solana.Signature
+	conf   *config
+}
+
+type wsConn struct {
+	mu   sync.Mutex
+	conn *websocket.Conn
+}
+
+func (c *wsConn) write(v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.conn.WriteMessage(websocket.TextMessage, data)
+}
+
+func (c *wsConn) close() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.conn.Close()
+}
+
+// SubscriptionCount returns the number of active websocket subscriptions.
+func (s *Server) SubscriptionCount() int {
+	s.subsMu.Lock()
+	defer s.subsMu.Unlock()
+	return len(s.subs)
+}
+
+// CloseConnections closes all the websocket connections,
+// dropping their subscriptions, to simulate a network failure.
+func (s *Server) CloseConnections() {
+	s.subsMu.Lock()
+	defer s.subsMu.Unlock()
+	for conn := range s.conns {
+		conn.close()
+		delete(s.conns, conn)
+	}
+	s.subs = map[uint64]*subscription{}
+}
+
+func (s *Server) serveWS(rw http.ResponseWriter, req *http.Request) {
+	c, err := s.upgrader.Upgrade(rw, req, nil)
+	if err != nil {
+		return
+	}
+	conn := &wsConn{conn: c}
+	s.subsMu.Lock()
+	s.conns[conn] = struct{}{}
+	s.subsMu.Unlock()
+
+	defer func() {
+		s.subsMu.Lock()
+		delete(s.conns, conn)
+		for id, sub := range s.subs {
+			if sub.conn == conn {
+				delete(s.subs, id)
+			}
+		}
+		s.subsMu.Unlock()
+		conn.close()
+	}()
+
+	for {
+		_, message, err := c.ReadMessage()
+		if err != nil {
+			return
+		}
+		var r request
+		if err := json.Unmarshal(message, &r); err != nil {
+			conn.write(errorResponse(nil, ErrorCodeParseError, err.Error()))
+			continue
+		}
+		resp := s.handleWS(conn, &r)
+		conn.write(resp)
+		if r.Method == "signatureSubscribe" && resp.Error == nil {
+			s.notifyKnownSignature(resp.Result.(uint64))
+		}
+	}
+}
+
+func (s *Server) handleWS(conn *wsConn, req *request) *response {
+	if err := s.before(req.Method); err != nil {
+		return &response{Version: "2.0", ID: req.ID, Error: err}
+	}
+
+	var (
+		result interface{}
+		err    *jsonrpc.RPCError
+	)
+	switch req.Method {
+	case "accountSubscribe":
+		result, err = s.subscribe(conn, subscriptionAccount, req.Params)
+	case "programSubscribe":
+		result, err = s.subscribe(conn, subscriptionProgram, req.Params)
+	case "signatureSubscribe":
+		result, err = s.subscribe(conn, subscriptionSignature, req.Params)
+	case "slotSubscribe":
+		result, err = s.subscribe(conn, subscriptionSlot, req.Params)
+	case "rootSubscribe":
+		result, err = s.subscribe(conn, subscriptionRoot, req.Params)
+	case "accountUnsubscribe", "programUnsubscribe", "signatureUnsubscribe", "slotUnsubscribe", "rootUnsubscribe":
+		result, err = s.unsubscribe(conn, req.Params)
+	default:
+		err = newRPCError(ErrorCodeMethodNotFound, "Method not found")
+	}
+	if err != nil {
+		return &response{Version: "2.0", ID: req.ID, Error: err}
+	}
+	return &response{Version: "2.0", ID: req.ID, Result: result}
+}
+
+func (s *Server) subscribe(conn *wsConn, kind subscriptionKind, params []stdjson.RawMessage) (interface{}, *jsonrpc.RPCError) {
+	sub := &subscription{kind: kind, conn: conn, conf: new(config)}
+	switch kind {
+	case subscriptionAccount, subscriptionProgram:
+		if err := param(params, 0, &sub.pubkey); err != nil {
+			return nil, err
+		}
+	case subscriptionSignature:
+		if err := param(params, 0, &sub.sig); err != nil {
+			return nil, err
+		}
+	}
+	if kind != subscriptionSlot && kind != subscriptionRoot {
+		conf, err := optionalConfig(params, 1)
+		if err != nil {
+			return nil, err
+		}
+		sub.conf = conf
+	}
+
+	s.subsMu.Lock()
+	sub.id = s.nextSubID
+	s.nextSubID++
+	s.subs[sub.id] = sub
+	s.subsMu.Unlock()
+	return sub.id, nil
+}
+
+func (s *Server) unsubscribe(conn *wsConn, params []stdjson.RawMessage) (interface{}, *jsonrpc.RPCError) {
+	var id uint64
+	if err := param(params, 0, &id); err != nil {
+		return nil, err
+	}
+	s.subsMu.Lock()
+	defer s.subsMu.Unlock()
+	sub, ok := s.subs[id]
+	if !ok || sub.conn != conn {
+		return nil, newRPCError(ErrorCodeInvalidParams, "Invalid subscription id.")
+	}
+	delete(s.subs, id)
+	return true, nil
+}
+
+type notification struct {
+	Version string             `json:"jsonrpc"`
+	Method  string             `json:"method"`
+	Params  notificationParams `json:"params"`
+}
+
+type notificationParams struct {
+	Result       interface{} `json:"result"`
+	Subscription uint64      `json:"subscription"`
+}
+
+// notify sends a notification to every subscription matching the predicate;
+// `once` subscriptions are removed after their first notification.
+func (s *Server) notify(
+	method string,
+	match func(sub *subscription) bool,
+	result func(sub *subscription) interface{},
+	once bool,
+) {
+	type pending struct {
+		conn *wsConn
+		msg  *notification
+	}
+	var out []pending
+
+	s.subsMu.Lock()
+	for id, sub := range s.subs {
+		if !match(sub) {
+			continue
+		}
+		out = append(out, pending{
+			conn: sub.conn,
+			msg: &notification{
+				Version: "2.0",
+				Method:  method,
+				Params:  notificationParams{Result: result(sub), Subscription: id},
+			},
+		})
+		if once {
+			delete(s.subs, id)
+		}
+	}
+	s.subsMu.Unlock()
+
+	for _, p := range out {
+		p.conn.write(p.msg)
+	}
+}
+
+func (s *Server) notifyAccount(slot uint64, pubkey solana.PublicKey, account Account) {
+	s.notify(
+		"accountNotification",
+		func(sub *subscription) bool {
+			return sub.kind == subscriptionAccount && sub.pubkey.Equals(pubkey)
+		},
+		func(sub *subscription) interface{} {
+			return contextValue{Context: rpc.Context{Slot: slot}, Value: encodeAccount(account, sub.conf)}
+		},
+		false,
+	)
+	s.notify(
+		"programNotification",
+		func(sub *subscription) bool {
+			return sub.kind == subscriptionProgram &&
+				sub.pubkey.Equals(account.Owner) &&
+				matchesFilters(account, sub.conf.Filters)
+		},
+		func(sub *subscription) interface{} {
+			return contextValue{
+				Context: rpc.Context{Slot: slot},
+				Value:   &keyedAccount{Pubkey: pubkey, Account: encodeAccount(account, sub.conf)},
+			}
+		},
+		false,
+	)
+}
+
+func (s *Server) notifySignature(slot uint64, sig solana.Signature, status *rpc.SignatureStatusesResult) {
+	s.notify(
+		"signatureNotification",
+		func(sub *subscription) bool {
+			return sub.kind == subscriptionSignature &&
+				sub.sig == sig &&
+				status.Reaches(sub.conf.Commitment)
+		},
+		func(sub *subscription) interface{} {
+			return contextValue{
+				Context: rpc.Context{Slot: slot},
+				Value:   map[string]interface{}{"err": status.Err},
+			}
+		},
+		true,
+	)
+}
+
+// notifyKnownSignature notifies a new signature subscription right away
+// if its transaction already reached the requested commitment, like a node does.
+func (s *Server) notifyKnownSignature(id uint64) {
+	s.subsMu.Lock()
+	sub, ok := s.subs[id]
+	s.subsMu.Unlock()
+	if !ok {
+		return
+	}
+	s.mu.Lock()
+	status, ok := s.statuses[sub.sig]
+	var copied rpc.SignatureStatusesResult
+	if ok {
+		copied = *status
+	}
+	slot := s.slot
+	s.mu.Unlock()
+	if ok {
+		s.notifySignature(slot, sub.sig, &copied)
+	}
+}
+
+func (s *Server) notifySlot(parent uint64, slot uint64) {
+	s.notify(
+		"slotNotification",
+		func(sub *subscription) bool { return sub.kind == subscriptionSlot },
+		func(sub *subscription) interface{} {
+			return map[string]uint64{"parent": parent, "root": parent, "slot": slot}
+		},
+		false,
+	)
+	s.notify(
+		"rootNotification",
+		func(sub *subscription) bool { return sub.kind == subscriptionRoot },
+		func(sub *subscription) interface{} { return parent },
+		false,
+	)
+}
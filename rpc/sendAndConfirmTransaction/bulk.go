@@ -0,0 +1,539 @@
+// Copyright 2021 github.com/gagliardetto
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sendandconfirmtransaction
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+	"golang.org/x/time/rate"
+)
+
+// MaxSignatureStatusesPerRequest is the maximum number of signatures
+// accepted by a single getSignatureStatuses request.
+const MaxSignatureStatusesPerRequest = 256
+
+// BulkTransaction is a signed transaction submitted to SendBulk.
+type BulkTransaction struct {
+	// Identifier of the transaction, reported in its BulkResult.
+	ID string
+
+	Transaction *solana.Transaction
+
+	// The lastValidBlockHeight returned by GetLatestBlockhash along with
+	// the blockhash of the transaction. Required: transactions without it
+	// fail with ErrMissingLastValidBlockHeight.
+	LastValidBlockHeight uint64
+}
+
+// ErrMissingLastValidBlockHeight is the error of a BulkTransaction without
+// LastValidBlockHeight, whose expiry can't be told.
+var ErrMissingLastValidBlockHeight = errors.New("transaction has no LastValidBlockHeight")
+
+// BulkResult is the final outcome of a transaction submitted to SendBulk.
+type BulkResult struct {
+	ID string
+
+	// Signature of the last signed version of the transaction.
+	Signature solana.Signature
+
+	// Empty if the transaction was still pending when the context was done.
+	Status Status
+
+	// The slot the transaction was processed in (Confirmed and Failed only).
+	Slot uint64
+
+	// Nil if Status is StatusConfirmed. Otherwise, a *TransactionError if the
+	// transaction failed while executing, the error of the first send
+	// (e.g. a preflight failure), ErrBlockhashExpired, or the context error.
+	Err error
+
+	// Number of times the transaction was signed (1 + number of re-signs).
+	Attempts int
+}
+
+// BulkProgress is the aggregate progress of SendBulk.
+type BulkProgress struct {
+	// Transactions read from the input.
+	Submitted int
+
+	// Transactions with a final result, by status.
+	Confirmed int
+	Failed    int
+	Expired   int
+
+	// Re-signs of expired transactions.
+	Resigned int
+
+	// Transactions without a final result yet.
+	Pending int
+}
+
+// ResignFunc signs again a transaction whose blockhash expired,
+// and returns it with its new LastValidBlockHeight (the ID is kept).
+// Returning a nil transaction gives up, and reports the transaction as expired.
+type ResignFunc func(ctx context.Context, expired *BulkTransaction) (*BulkTransaction, error)
+
+// BulkSenderOpts configures SendBulk.
+type BulkSenderOpts struct {
+	// Options of the first send of each transaction;
+	// the rebroadcasts skip the preflight checks.
+	TransactionOpts rpc.TransactionOpts
+
+	// Commitment the transactions must reach (default: finalized).
+	Commitment rpc.CommitmentType
+
+	// Number of concurrent sends (default: 8).
+	Concurrency int
+
+	// Optional limiter shared by all the RPC requests
+	// (sends, status polls and block height polls).
+	Limiter *rate.Limiter
+
+	// Interval between the sends of a pending transaction;
+	// zero disables rebroadcasting.
+	RebroadcastInterval time.Duration
+
+	// Interval between the polls of the signature statuses (default: 2s).
+	PollInterval time.Duration
+
+	// Optional; without it, expired transactions are reported as such.
+	Resign ResignFunc
+
+	// Maximum number of re-signs of a transaction (default: 3).
+	MaxResigns int
+
+	// Optional; called (never concurrently) each time the progress changes.
+	OnProgress func(BulkProgress)
+}
+
+const (
+	defaultBulkConcurrency = 8
+	defaultBulkMaxResigns  = 3
+)
+
+type bulkEntry struct {
+	tx        *BulkTransaction
+	rawTx     []byte
+	sig       solana.Signature
+	attempts  int
+	sent      bool
+	lastSent  time.Time
+	resigning bool
+}
+
+type bulkJob struct {
+	entry       *bulkEntry
+	rebroadcast bool
+}
+
+type bulkSender struct {
+	rpcClient *rpc.Client
+	opts      BulkSenderOpts
+	results   chan *BulkResult
+	jobs      chan bulkJob
+
+	mu       sync.Mutex
+	entries  map[*bulkEntry]struct{}
+	progress BulkProgress
+
+	progressMu sync.Mutex
+	pending    sync.WaitGroup
+}
+
+// SendBulk sends the transactions read from txs with bounded concurrency,
+// tracks their signatures with batched GetSignatureStatuses requests,
+// and emits a BulkResult for each of them.
+//
+// Expired transactions are signed again with opts.Resign, if provided.
+//
+// The results channel is closed once txs is closed and all transactions
+// have a result, or once ctx is done; the transactions still pending
+// are then reported with the context error. The results must be consumed.
+func SendBulk(
+	ctx context.Context,
+	rpcClient *rpc.Client,
+	txs <-chan *BulkTransaction,
+	opts *BulkSenderOpts, // optional
+) <-chan *BulkResult {
+	bs := &bulkSender{
+		rpcClient: rpcClient,
+		results:   make(chan *BulkResult),
+		entries:   map[*bulkEntry]struct{}{},
+	}
+	if opts != nil {
+		bs.opts = *opts
+	}
+	if bs.opts.Commitment == "" {
+		bs.opts.Commitment = rpc.CommitmentFinalized
+	}
+	if bs.opts.Concurrency <= 0 {
+		bs.opts.Concurrency = defaultBulkConcurrency
+	}
+	if bs.opts.PollInterval <= 0 {
+		bs.opts.PollInterval = defaultPollInterval
+	}
+	if bs.opts.MaxResigns <= 0 {
+		bs.opts.MaxResigns = defaultBulkMaxResigns
+	}
+	bs.jobs = make(chan bulkJob, bs.opts.Concurrency)
+
+	ctx, cancel := context.WithCancel(ctx)
+	var workers sync.WaitGroup
+
+	workers.Add(bs.opts.Concurrency)
+	for i := 0; i < bs.opts.Concurrency; i++ {
+		go func() {
+			defer workers.Done()
+			bs.sendLoop(ctx)
+		}()
+	}
+	workers.Add(1)
+	go func() {
+		defer workers.Done()
+		bs.pollLoop(ctx)
+	}()
+
+	fed := make(chan struct{})
+	go func() {
+		defer close(fed)
+		bs.feed(ctx, txs)
+	}()
+
+	go func() {
+		<-fed
+		bs.pending.Wait()
+		cancel()
+		workers.Wait()
+		bs.abortPending(ctx.Err())
+		close(bs.results)
+	}()
+	go func() {
+		// Unblocks the pending WaitGroup when ctx is done.
+		<-ctx.Done()
+		<-fed
+		bs.abortPending(ctx.Err())
+	}()
+
+	return bs.results
+}
+
+// feed reads the input transactions and queues their first send.
+func (bs *bulkSender) feed(ctx context.Context, txs <-chan *BulkTransaction) {
+	for {
+		var tx *BulkTransaction
+		var ok bool
+		select {
+		case <-ctx.Done():
+			return
+		case tx, ok = <-txs:
+			if !ok {
+				return
+			}
+		}
+
+		entry := &bulkEntry{tx: tx, attempts: 1}
+		bs.pending.Add(1)
+		bs.mu.Lock()
+		bs.entries[entry] = struct{}{}
+		bs.progress.Submitted++
+		bs.progress.Pending++
+		bs.mu.Unlock()
+		bs.reportProgress()
+
+		if err := entry.encode(); err != nil {
+			bs.finish(entry, &BulkResult{Status: StatusFailed, Err: err})
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case bs.jobs <- bulkJob{entry: entry}:
+		}
+	}
+}
+
+func (entry *bulkEntry) encode() error {
+	if entry.tx.Transaction == nil || len(entry.tx.Transaction.Signatures) == 0 {
+		return errors.New("transaction is not signed")
+	}
+	if entry.tx.LastValidBlockHeight == 0 {
+		return ErrMissingLastValidBlockHeight
+	}
+	rawTx, err := entry.tx.Transaction.MarshalBinary()
+	if err != nil {
+		return fmt.Errorf("unable to encode transaction: %w", err)
+	}
+	entry.rawTx = rawTx
+	entry.sig = entry.tx.Transaction.Signatures[0]
+	return nil
+}
+
+func (bs *bulkSender) wait(ctx context.Context) error {
+	if bs.opts.Limiter == nil {
+		return nil
+	}
+	return bs.opts.Limiter.Wait(ctx)
+}
+
+func (bs *bulkSender) sendLoop(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case job := <-bs.jobs:
+			bs.send(ctx, job)
+		}
+	}
+}
+
+func (bs *bulkSender) send(ctx context.Context, job bulkJob) {
+	if err := bs.wait(ctx); err != nil {
+		return
+	}
+	bs.mu.Lock()
+	rawTx := job.entry.rawTx
+	bs.mu.Unlock()
+
+	opts := bs.opts.TransactionOpts
+	if job.rebroadcast {
+		opts.SkipPreflight = true
+	}
+	_, err := bs.rpcClient.SendRawTransactionWithOpts(ctx, rawTx, opts)
+	if job.rebroadcast {
+		// Errors (e.g. "already processed") are expected here.
+		bs.mu.Lock()
+		job.entry.lastSent = time.Now()
+		bs.mu.Unlock()
+		return
+	}
+	if err != nil {
+		if ctx.Err() == nil {
+			bs.finish(job.entry, &BulkResult{Status: StatusFailed, Err: err})
+		}
+		return
+	}
+	bs.mu.Lock()
+	job.entry.sent = true
+	job.entry.lastSent = time.Now()
+	bs.mu.Unlock()
+}
+
+func (bs *bulkSender) pollLoop(ctx context.Context) {
+	ticker := time.NewTicker(bs.opts.PollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			bs.poll(ctx)
+		}
+	}
+}
+
+// poll checks the statuses of the sent transactions,
+// and rebroadcasts or re-signs the pending ones.
+func (bs *bulkSender) poll(ctx context.Context) {
+	bs.mu.Lock()
+	var sent []*bulkEntry
+	for entry := range bs.entries {
+		if entry.sent && !entry.resigning {
+			sent = append(sent, entry)
+		}
+	}
+	bs.mu.Unlock()
+	if len(sent) == 0 {
+		return
+	}
+
+	// The block height is fetched before the statuses, so that
+	// a transaction landing in between is not reported as expired.
+	if err := bs.wait(ctx); err != nil {
+		return
+	}
+	blockHeight, heightErr := bs.rpcClient.GetBlockHeight(ctx, bs.opts.Commitment)
+
+	var expired, unconfirmed []*bulkEntry
+	for start := 0; start < len(sent); start += MaxSignatureStatusesPerRequest {
+		end := start + MaxSignatureStatusesPerRequest
+		if end > len(sent) {
+			end = len(sent)
+		}
+		chunk := sent[start:end]
+		sigs := make([]solana.Signature, len(chunk))
+		bs.mu.Lock()
+		for i, entry := range chunk {
+			sigs[i] = entry.sig
+		}
+		bs.mu.Unlock()
+
+		if err := bs.wait(ctx); err != nil {
+			return
+		}
+		statuses, err := bs.rpcClient.GetSignatureStatuses(ctx, false, sigs...)
+		if err != nil || len(statuses.Value) != len(chunk) {
+			continue
+		}
+		for i, status := range statuses.Value {
+			entry := chunk[i]
+			switch {
+			case status == nil && heightErr == nil && blockHeight > entry.tx.LastValidBlockHeight:
+				expired = append(expired, entry)
+			case status == nil:
+				unconfirmed = append(unconfirmed, entry)
+			case status.Reaches(bs.opts.Commitment):
+				result := &BulkResult{Status: StatusConfirmed, Slot: status.Slot}
+				if status.Err != nil {
+					result.Status = StatusFailed
+					result.Err = &TransactionError{Signature: sigs[i], Slot: status.Slot, Err: status.Err}
+				}
+				bs.finish(entry, result)
+			}
+			// Otherwise: landed, but not yet at the target commitment.
+		}
+	}
+
+	for _, entry := range expired {
+		bs.resign(ctx, entry)
+	}
+	if bs.opts.RebroadcastInterval > 0 {
+		now := time.Now()
+		for _, entry := range unconfirmed {
+			bs.mu.Lock()
+			due := now.Sub(entry.lastSent) >= bs.opts.RebroadcastInterval
+			bs.mu.Unlock()
+			if !due {
+				continue
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case bs.jobs <- bulkJob{entry: entry, rebroadcast: true}:
+			}
+		}
+	}
+}
+
+func (bs *bulkSender) resign(ctx context.Context, entry *bulkEntry) {
+	bs.mu.Lock()
+	attempts := entry.attempts
+	bs.mu.Unlock()
+	if bs.opts.Resign == nil || attempts > bs.opts.MaxResigns {
+		bs.finish(entry, &BulkResult{Status: StatusExpired, Err: ErrBlockhashExpired})
+		return
+	}
+
+	bs.mu.Lock()
+	entry.resigning = true
+	bs.mu.Unlock()
+	resigned, err := bs.opts.Resign(ctx, entry.tx)
+	if err != nil {
+		bs.finish(entry, &BulkResult{Status: StatusExpired, Err: fmt.Errorf("%w: unable to re-sign: %s", ErrBlockhashExpired, err)})
+		return
+	}
+	if resigned == nil || resigned.Transaction == nil {
+		bs.finish(entry, &BulkResult{Status: StatusExpired, Err: ErrBlockhashExpired})
+		return
+	}
+
+	next := &bulkEntry{tx: &BulkTransaction{
+		ID:                   entry.tx.ID,
+		Transaction:          resigned.Transaction,
+		LastValidBlockHeight: resigned.LastValidBlockHeight,
+	}}
+	if err := next.encode(); err != nil {
+		bs.finish(entry, &BulkResult{Status: StatusExpired, Err: fmt.Errorf("%w: %s", ErrBlockhashExpired, err)})
+		return
+	}
+
+	bs.mu.Lock()
+	if _, ok := bs.entries[entry]; !ok {
+		// Aborted in the meantime.
+		bs.mu.Unlock()
+		return
+	}
+	entry.tx = next.tx
+	entry.rawTx = next.rawTx
+	entry.sig = next.sig
+	entry.attempts++
+	entry.sent = false
+	entry.resigning = false
+	bs.progress.Resigned++
+	bs.mu.Unlock()
+	bs.reportProgress()
+
+	select {
+	case <-ctx.Done():
+	case bs.jobs <- bulkJob{entry: entry}:
+	}
+}
+
+// finish emits the final result of a transaction, once.
+func (bs *bulkSender) finish(entry *bulkEntry, result *BulkResult) {
+	bs.mu.Lock()
+	if _, ok := bs.entries[entry]; !ok {
+		bs.mu.Unlock()
+		return
+	}
+	delete(bs.entries, entry)
+	result.ID = entry.tx.ID
+	result.Signature = entry.sig
+	result.Attempts = entry.attempts
+	bs.progress.Pending--
+	switch result.Status {
+	case StatusConfirmed:
+		bs.progress.Confirmed++
+	case StatusFailed:
+		bs.progress.Failed++
+	case StatusExpired:
+		bs.progress.Expired++
+	}
+	bs.mu.Unlock()
+
+	bs.reportProgress()
+	bs.results <- result
+	bs.pending.Done()
+}
+
+// abortPending emits the results of the transactions still pending.
+func (bs *bulkSender) abortPending(err error) {
+	bs.mu.Lock()
+	entries := make([]*bulkEntry, 0, len(bs.entries))
+	for entry := range bs.entries {
+		entries = append(entries, entry)
+	}
+	bs.mu.Unlock()
+	for _, entry := range entries {
+		bs.finish(entry, &BulkResult{Err: err})
+	}
+}
+
+func (bs *bulkSender) reportProgress() {
+	if bs.opts.OnProgress == nil {
+		return
+	}
+	bs.progressMu.Lock()
+	defer bs.progressMu.Unlock()
+	bs.mu.Lock()
+	progress := bs.progress
+	bs.mu.Unlock()
+	bs.opts.OnProgress(progress)
+}
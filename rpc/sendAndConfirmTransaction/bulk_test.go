@@ -0,0 +1,193 @@
+// Copyright 2021 github.com/gagliardetto
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sendandconfirmtransaction
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/time/rate"
+)
+
+func collectBulkResults(t *testing.T, results <-chan *BulkResult) map[string]*BulkResult {
+	t.Helper()
+	out := map[string]*BulkResult{}
+	timeout := time.After(10 * time.Second)
+	for {
+		select {
+		case result, ok := <-results:
+			if !ok {
+				return out
+			}
+			_, dup := out[result.ID]
+			require.False(t, dup, "duplicate result for %s", result.ID)
+			out[result.ID] = result
+		case <-timeout:
+			t.Fatal("timeout waiting for the results")
+		}
+	}
+}
+
+func TestSendBulk_Confirmed(t *testing.T) {
+	srv, client, blockhash := newTestServer(t)
+	srv.SetSendConfirmationStatus(rpc.ConfirmationStatusFinalized)
+
+	const count = 2*MaxSignatureStatusesPerRequest + 10
+	txs := make(chan *BulkTransaction)
+	go func() {
+		defer close(txs)
+		for i := 0; i < count; i++ {
+			txs <- &BulkTransaction{
+				ID:                   strconv.Itoa(i),
+				Transaction:          newTestTransaction(t, blockhash),
+				LastValidBlockHeight: 150,
+			}
+		}
+	}()
+
+	var last BulkProgress
+	results := collectBulkResults(t, SendBulk(context.Background(), client, txs, &BulkSenderOpts{
+		Concurrency:  4,
+		Limiter:      rate.NewLimiter(rate.Inf, 1),
+		PollInterval: 50 * time.Millisecond,
+		OnProgress:   func(progress BulkProgress) { last = progress },
+	}))
+
+	require.Len(t, results, count)
+	for _, result := range results {
+		require.Equal(t, StatusConfirmed, result.Status, result.Err)
+		require.NoError(t, result.Err)
+		require.Equal(t, 1, result.Attempts)
+	}
+	assert.Equal(t, BulkProgress{Submitted: count, Confirmed: count}, last)
+	// At least one poll needed three requests.
+	assert.GreaterOrEqual(t, srv.CallCount("getSignatureStatuses"), 3)
+}
+
+func TestSendBulk_FailedAndResigned(t *testing.T) {
+	srv, client, blockhash := newTestServer(t)
+	srv.SetLatestBlockhash(blockhash, 150)
+
+	failing := newTestTransaction(t, blockhash)
+	srv.SetSimulateFunc(func(tx *solana.Transaction) *rpc.SimulateTransactionResult {
+		if tx.Signatures[0] == failing.Signatures[0] {
+			return &rpc.SimulateTransactionResult{Err: "AccountNotFound"}
+		}
+		return &rpc.SimulateTransactionResult{}
+	})
+
+	txs := make(chan *BulkTransaction, 2)
+	txs <- &BulkTransaction{ID: "failing", Transaction: failing, LastValidBlockHeight: 150}
+	txs <- &BulkTransaction{ID: "expiring", Transaction: newTestTransaction(t, blockhash), LastValidBlockHeight: 150}
+	close(txs)
+
+	// The blockhash expires.
+	srv.SetSlot(200, 151)
+
+	var resigned BulkProgress
+	results := collectBulkResults(t, SendBulk(context.Background(), client, txs, &BulkSenderOpts{
+		PollInterval:        20 * time.Millisecond,
+		RebroadcastInterval: 10 * time.Millisecond,
+		Resign: func(ctx context.Context, expired *BulkTransaction) (*BulkTransaction, error) {
+			newBlockhash := solana.HashFromBytes(solana.NewWallet().PublicKey().Bytes())
+			// The new version lands.
+			srv.SetSendConfirmationStatus(rpc.ConfirmationStatusFinalized)
+			return &BulkTransaction{
+				Transaction:          newTestTransaction(t, newBlockhash),
+				LastValidBlockHeight: 300,
+			}, nil
+		},
+		OnProgress: func(progress BulkProgress) {
+			if progress.Resigned > 0 {
+				resigned = progress
+			}
+		},
+	}))
+
+	require.Len(t, results, 2)
+
+	failed := results["failing"]
+	assert.Equal(t, StatusFailed, failed.Status)
+	var preflightErr *rpc.SendTransactionPreflightFailureError
+	assert.True(t, errors.As(failed.Err, &preflightErr), failed.Err)
+
+	expiring := results["expiring"]
+	assert.Equal(t, StatusConfirmed, expiring.Status, expiring.Err)
+	assert.Equal(t, 2, expiring.Attempts)
+	assert.Equal(t, 1, resigned.Resigned)
+}
+
+func TestSendBulk_ExpiredWithoutResign(t *testing.T) {
+	srv, client, blockhash := newTestServer(t)
+
+	txs := make(chan *BulkTransaction, 1)
+	txs <- &BulkTransaction{ID: "a", Transaction: newTestTransaction(t, blockhash), LastValidBlockHeight: 150}
+	close(txs)
+	srv.SetSlot(200, 151)
+
+	results := collectBulkResults(t, SendBulk(context.Background(), client, txs, &BulkSenderOpts{
+		PollInterval: 10 * time.Millisecond,
+	}))
+	require.Len(t, results, 1)
+	assert.Equal(t, StatusExpired, results["a"].Status)
+	assert.ErrorIs(t, results["a"].Err, ErrBlockhashExpired)
+}
+
+func TestSendBulk_MissingLastValidBlockHeight(t *testing.T) {
+	srv, client, blockhash := newTestServer(t)
+
+	txs := make(chan *BulkTransaction, 1)
+	txs <- &BulkTransaction{ID: "a", Transaction: newTestTransaction(t, blockhash)}
+	close(txs)
+
+	results := collectBulkResults(t, SendBulk(context.Background(), client, txs, &BulkSenderOpts{
+		PollInterval: 10 * time.Millisecond,
+	}))
+	require.Len(t, results, 1)
+	assert.Equal(t, StatusFailed, results["a"].Status)
+	assert.ErrorIs(t, results["a"].Err, ErrMissingLastValidBlockHeight)
+	assert.Empty(t, srv.Transactions())
+}
+
+func TestSendBulk_Canceled(t *testing.T) {
+	_, client, blockhash := newTestServer(t)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	txs := make(chan *BulkTransaction, 3)
+	for i := 0; i < 3; i++ {
+		txs <- &BulkTransaction{ID: strconv.Itoa(i), Transaction: newTestTransaction(t, blockhash), LastValidBlockHeight: 150}
+	}
+	// txs is left open: only the cancellation ends the run.
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		cancel()
+	}()
+
+	results := collectBulkResults(t, SendBulk(ctx, client, txs, &BulkSenderOpts{
+		PollInterval: 10 * time.Millisecond,
+	}))
+	require.Len(t, results, 3)
+	for _, result := range results {
+		assert.Equal(t, Status(""), result.Status)
+		assert.ErrorIs(t, result.Err, context.Canceled)
+	}
+}
@@ -0,0 +1,240 @@
+// Copyright 2021 github.com/gagliardetto
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sendandconfirmtransaction
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+	"github.com/gagliardetto/solana-go/rpc/ws"
+)
+
+// ErrBlockhashExpired is returned when the blockhash of a transaction expired
+// before the transaction landed: it will never be processed,
+// and can safely be signed again with a new blockhash.
+var ErrBlockhashExpired = errors.New("blockhash expired before the transaction was confirmed")
+
+// TransactionError is returned when a transaction was confirmed,
+// but failed while executing (e.g. one of the instructions failed).
+type TransactionError struct {
+	Signature solana.Signature
+	Slot      uint64
+
+	// The transaction error, as returned by the RPC node,
+	// e.g. map[string]interface{}{"InstructionError": []interface{}{0, "InvalidAccountData"}}.
+	Err interface{}
+}
+
+func (e *TransactionError) Error() string {
+	return fmt.Sprintf("transaction %s failed in slot %d: %v", e.Signature, e.Slot, e.Err)
+}
+
+// Status is the final status of a transaction sent with SendAndConfirmWithRebroadcast.
+type Status string
+
+const (
+	// The transaction reached the target commitment, and succeeded.
+	StatusConfirmed Status = "confirmed"
+
+	// The transaction reached the target commitment, but failed (see TransactionError).
+	StatusFailed Status = "failed"
+
+	// The blockhash of the transaction expired before it landed;
+	// it can safely be signed again.
+	StatusExpired Status = "expired"
+)
+
+// Result is the outcome of SendAndConfirmWithRebroadcast.
+type Result struct {
+	Signature solana.Signature
+	Status    Status
+
+	// The slot the transaction was processed in (Confirmed and Failed only).
+	Slot uint64
+
+	// Number of times the transaction was sent.
+	Sends int
+}
+
+// RebroadcastOpts configures SendAndConfirmWithRebroadcast.
+type RebroadcastOpts struct {
+	// Options of the first send; the rebroadcasts skip the preflight checks.
+	TransactionOpts rpc.TransactionOpts
+
+	// Commitment the transaction must reach (default: finalized).
+	Commitment rpc.CommitmentType
+
+	// The lastValidBlockHeight returned by GetLatestBlockhash along with
+	// the blockhash of the transaction. If zero, the lastValidBlockHeight
+	// of the latest blockhash is used, which expires later than
+	// (or at the same time as) the one of the transaction.
+	LastValidBlockHeight uint64
+
+	// Interval between the sends of the transaction (default: 2s).
+	RebroadcastInterval time.Duration
+
+	// Interval between the polls of the signature status and block height (default: 2s).
+	// The status is polled even when a websocket client is provided,
+	// in case notifications are lost.
+	PollInterval time.Duration
+}
+
+const (
+	defaultRebroadcastInterval = 2 * time.Second
+	defaultPollInterval        = 2 * time.Second
+)
+
+// SendAndConfirmWithRebroadcast sends a transaction, and sends it again every
+// RebroadcastInterval until it reaches the target commitment, its blockhash
+// expires, or ctx is done.
+//
+// The confirmation is watched with a signature subscription (if wsClient is not nil),
+// and by polling GetSignatureStatuses.
+//
+// If the transaction failed, the returned error is a *TransactionError;
+// if the blockhash expired, the returned error is ErrBlockhashExpired.
+// In both cases, the returned Result describes the final status.
+func SendAndConfirmWithRebroadcast(
+	ctx context.Context,
+	rpcClient *rpc.Client,
+	wsClient *ws.Client, // optional
+	transaction *solana.Transaction,
+	opts *RebroadcastOpts, // optional
+) (*Result, error) {
+	if len(transaction.Signatures) == 0 {
+		return nil, errors.New("transaction is not signed")
+	}
+	rawTx, err := transaction.MarshalBinary()
+	if err != nil {
+		return nil, fmt.Errorf("unable to encode transaction: %w", err)
+	}
+	return SendRawAndConfirmWithRebroadcast(ctx, rpcClient, wsClient, rawTx, transaction.Signatures[0], opts)
+}
+
+// SendRawAndConfirmWithRebroadcast is like SendAndConfirmWithRebroadcast,
+// for a transaction already encoded in wire format; sig is its first signature.
+func SendRawAndConfirmWithRebroadcast(
+	ctx context.Context,
+	rpcClient *rpc.Client,
+	wsClient *ws.Client, // optional
+	rawTx []byte,
+	sig solana.Signature,
+	opts *RebroadcastOpts, // optional
+) (*Result, error) {
+	if opts == nil {
+		opts = &RebroadcastOpts{}
+	}
+	commitment := opts.Commitment
+	if commitment == "" {
+		commitment = rpc.CommitmentFinalized
+	}
+	rebroadcastInterval := opts.RebroadcastInterval
+	if rebroadcastInterval <= 0 {
+		rebroadcastInterval = defaultRebroadcastInterval
+	}
+	pollInterval := opts.PollInterval
+	if pollInterval <= 0 {
+		pollInterval = defaultPollInterval
+	}
+
+	lastValidBlockHeight := opts.LastValidBlockHeight
+	if lastValidBlockHeight == 0 {
+		latest, err := rpcClient.GetLatestBlockhash(ctx, commitment)
+		if err != nil {
+			return nil, fmt.Errorf("unable to get latest blockhash: %w", err)
+		}
+		lastValidBlockHeight = latest.Value.LastValidBlockHeight
+	}
+
+	result := &Result{Signature: sig}
+	if _, err := rpcClient.SendRawTransactionWithOpts(ctx, rawTx, opts.TransactionOpts); err != nil {
+		return nil, err
+	}
+	result.Sends++
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var notifications <-chan *ws.SignatureResult
+	if wsClient != nil {
+		// Polling takes over if the subscription fails.
+		if sub, err := wsClient.SignatureSubscribe(sig, commitment); err == nil {
+			defer sub.Unsubscribe()
+			ch := make(chan *ws.SignatureResult, 1)
+			go func() {
+				if got, err := sub.Recv(ctx); err == nil {
+					ch <- got
+				}
+			}()
+			notifications = ch
+		}
+	}
+
+	rebroadcastOpts := opts.TransactionOpts
+	rebroadcastOpts.SkipPreflight = true
+
+	rebroadcast := time.NewTicker(rebroadcastInterval)
+	defer rebroadcast.Stop()
+	poll := time.NewTicker(pollInterval)
+	defer poll.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return result, ctx.Err()
+		case got := <-notifications:
+			return result.done(got.Context.Slot, got.Value.Err)
+		case <-rebroadcast.C:
+			// Errors (e.g. "already processed") are expected here:
+			// the outcome is only decided by the status.
+			if _, err := rpcClient.SendRawTransactionWithOpts(ctx, rawTx, rebroadcastOpts); err == nil {
+				result.Sends++
+			}
+		case <-poll.C:
+			// The block height is fetched before the status, so that
+			// a transaction landing in between is not reported as expired.
+			blockHeight, heightErr := rpcClient.GetBlockHeight(ctx, commitment)
+			statuses, err := rpcClient.GetSignatureStatuses(ctx, false, sig)
+			if err != nil || len(statuses.Value) == 0 {
+				continue
+			}
+			if status := statuses.Value[0]; status != nil {
+				if status.Reaches(commitment) {
+					return result.done(status.Slot, status.Err)
+				}
+				// Landed, but not yet at the target commitment.
+				continue
+			}
+			if heightErr == nil && blockHeight > lastValidBlockHeight {
+				result.Status = StatusExpired
+				return result, ErrBlockhashExpired
+			}
+		}
+	}
+}
+
+func (result *Result) done(slot uint64, txErr interface{}) (*Result, error) {
+	result.Slot = slot
+	if txErr != nil {
+		result.Status = StatusFailed
+		return result, &TransactionError{Signature: result.Signature, Slot: slot, Err: txErr}
+	}
+	result.Status = StatusConfirmed
+	return result, nil
+}
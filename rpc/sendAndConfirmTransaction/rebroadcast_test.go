@@ -0,0 +1,156 @@
+// Copyright 2021 github.com/gagliardetto
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sendandconfirmtransaction
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/programs/system"
+	"github.com/gagliardetto/solana-go/rpc"
+	"github.com/gagliardetto/solana-go/rpc/rpctest"
+	"github.com/gagliardetto/solana-go/rpc/ws"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestTransaction(t *testing.T, blockhash solana.Hash) *solana.Transaction {
+	payer := solana.NewWallet().PrivateKey
+	tx, err := solana.NewTransaction(
+		[]solana.Instruction{
+			system.NewTransferInstruction(1, payer.PublicKey(), solana.NewWallet().PublicKey()).Build(),
+		},
+		blockhash,
+		solana.TransactionPayer(payer.PublicKey()),
+	)
+	require.NoError(t, err)
+	_, err = tx.Sign(func(key solana.PublicKey) *solana.PrivateKey {
+		return &payer
+	})
+	require.NoError(t, err)
+	return tx
+}
+
+func newTestServer(t *testing.T) (*rpctest.Server, *rpc.Client, solana.Hash) {
+	srv := rpctest.NewServer()
+	t.Cleanup(srv.Close)
+	blockhash := solana.HashFromBytes(solana.NewWallet().PublicKey().Bytes())
+	srv.SetLatestBlockhash(blockhash, 150)
+	srv.SetSlot(100, 100)
+	// Sent transactions stay unknown until the test sets their status.
+	srv.SetSendConfirmationStatus("")
+	return srv, rpc.New(srv.URL), blockhash
+}
+
+func TestSendAndConfirmWithRebroadcast_ConfirmedBySubscription(t *testing.T) {
+	srv, client, blockhash := newTestServer(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	wsClient, err := ws.Connect(ctx, srv.WSURL)
+	require.NoError(t, err)
+	defer wsClient.Close()
+
+	tx := newTestTransaction(t, blockhash)
+	go func() {
+		for srv.SubscriptionCount() == 0 {
+			time.Sleep(10 * time.Millisecond)
+		}
+		srv.SetSignatureStatus(tx.Signatures[0], &rpc.SignatureStatusesResult{
+			Slot:               101,
+			ConfirmationStatus: rpc.ConfirmationStatusConfirmed,
+		})
+	}()
+
+	result, err := SendAndConfirmWithRebroadcast(ctx, client, wsClient, tx, &RebroadcastOpts{
+		Commitment:   rpc.CommitmentConfirmed,
+		PollInterval: time.Hour,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, StatusConfirmed, result.Status)
+	assert.Equal(t, tx.Signatures[0], result.Signature)
+	assert.Equal(t, uint64(100), result.Slot)
+}
+
+func TestSendAndConfirmWithRebroadcast_FailedByPolling(t *testing.T) {
+	srv, client, blockhash := newTestServer(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	tx := newTestTransaction(t, blockhash)
+	go func() {
+		for srv.CallCount("sendTransaction") < 3 {
+			time.Sleep(5 * time.Millisecond)
+		}
+		srv.SetSignatureStatus(tx.Signatures[0], &rpc.SignatureStatusesResult{
+			Slot:               102,
+			ConfirmationStatus: rpc.ConfirmationStatusFinalized,
+			Err:                map[string]interface{}{"InstructionError": []interface{}{0, "InvalidAccountData"}},
+		})
+	}()
+
+	result, err := SendAndConfirmWithRebroadcast(ctx, client, nil, tx, &RebroadcastOpts{
+		LastValidBlockHeight: 150,
+		RebroadcastInterval:  10 * time.Millisecond,
+		PollInterval:         20 * time.Millisecond,
+	})
+	var txErr *TransactionError
+	require.True(t, errors.As(err, &txErr), err)
+	assert.Equal(t, uint64(102), txErr.Slot)
+	assert.Equal(t, StatusFailed, result.Status)
+	assert.GreaterOrEqual(t, result.Sends, 3)
+
+	// The rebroadcasts skip the preflight checks.
+	assert.GreaterOrEqual(t, len(srv.Transactions()), 3)
+}
+
+func TestSendAndConfirmWithRebroadcast_Expired(t *testing.T) {
+	srv, client, blockhash := newTestServer(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	tx := newTestTransaction(t, blockhash)
+	go func() {
+		for srv.CallCount("getSignatureStatuses") < 2 {
+			time.Sleep(5 * time.Millisecond)
+		}
+		srv.SetSlot(200, 151)
+	}()
+
+	result, err := SendAndConfirmWithRebroadcast(ctx, client, nil, tx, &RebroadcastOpts{
+		RebroadcastInterval: 10 * time.Millisecond,
+		PollInterval:        10 * time.Millisecond,
+	})
+	require.ErrorIs(t, err, ErrBlockhashExpired)
+	assert.Equal(t, StatusExpired, result.Status)
+}
+
+func TestSendAndConfirmWithRebroadcast_Canceled(t *testing.T) {
+	_, client, blockhash := newTestServer(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	tx := newTestTransaction(t, blockhash)
+	result, err := SendAndConfirmWithRebroadcast(ctx, client, nil, tx, &RebroadcastOpts{
+		RebroadcastInterval: 10 * time.Millisecond,
+		PollInterval:        10 * time.Millisecond,
+	})
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Equal(t, Status(""), result.Status)
+	assert.Greater(t, result.Sends, 1)
+}
@@ -43,6 +43,16 @@ type SimulateTransactionResult struct {
 
 	// The number of compute budget units consumed during the processing of this transaction.
 	UnitsConsumed *uint64 `json:"unitsConsumed,omitempty"`
+
+	// The most recent return data generated by an instruction in the transaction.
+	ReturnData *ReturnData `json:"returnData,omitempty"`
+
+	// Inner instructions invoked during the processing of the transaction;
+	// only returned when requested with SimulateTransactionOpts.InnerInstructions.
+	InnerInstructions []ParsedInnerInstruction `json:"innerInstructions,omitempty"`
+
+	// The number of bytes of all the accounts loaded by the transaction.
+	LoadedAccountsDataSize *uint32 `json:"loadedAccountsDataSize,omitempty"`
 }
 
 // SimulateTransaction simulates sending a transaction.
@@ -71,6 +81,14 @@ type SimulateTransactionOpts struct {
 	ReplaceRecentBlockhash bool
 
 	Accounts *SimulateTransactionAccountsOpts
+
+	// If true the response will include the inner instructions.
+	// (default: false)
+	InnerInstructions bool
+
+	// The minimum slot that the request can be evaluated at.
+	// This parameter is optional.
+	MinContextSlot *uint64
 }
 
 type SimulateTransactionAccountsOpts struct {
@@ -124,6 +142,12 @@ func (cl *Client) SimulateRawTransactionWithOpts(
 				"addresses": opts.Accounts.Addresses,
 			}
 		}
+		if opts.InnerInstructions {
+			obj["innerInstructions"] = opts.InnerInstructions
+		}
+		if opts.MinContextSlot != nil {
+			obj["minContextSlot"] = *opts.MinContextSlot
+		}
 	}
 
 	b64Data := base64.StdEncoding.EncodeToString(txData)
@@ -33,20 +33,35 @@ type AccountResult struct {
 func (cl *Client) AccountSubscribe(
 	account solana.PublicKey,
 	commitment rpc.CommitmentType,
+	subOpts ...SubscriptionOption,
 ) (*AccountSubscription, error) {
 	return cl.AccountSubscribeWithOpts(
 		account,
 		commitment,
 		"",
+		subOpts...,
 	)
 }
 
+// AccountSubscribeContext is like AccountSubscribe, but waits for the server
+// to accept the subscription; if ctx is done first, the subscription is cancelled.
+func (cl *Client) AccountSubscribeContext(
+	ctx context.Context,
+	account solana.PublicKey,
+	commitment rpc.CommitmentType,
+	subOpts ...SubscriptionOption,
+) (*AccountSubscription, error) {
+	sub, err := cl.AccountSubscribe(account, commitment, subOpts...)
+	return confirmed(ctx, sub, err)
+}
+
 // AccountSubscribe subscribes to an account to receive notifications
 // when the lamports or data for a given account public key changes.
 func (cl *Client) AccountSubscribeWithOpts(
 	account solana.PublicKey,
 	commitment rpc.CommitmentType,
 	encoding solana.EncodingType,
+	subOpts ...SubscriptionOption,
 ) (*AccountSubscription, error) {
 
 	params := []interface{}{account.String()}
@@ -70,50 +85,29 @@ func (cl *Client) AccountSubscribeWithOpts(
 			err := decodeResponseFromMessage(msg, &res)
 			return &res, err
 		},
+		subOpts,
 	)
 	if err != nil {
 		return nil, err
 	}
 	return &AccountSubscription{
-		sub: genSub,
+		newTypedSubscription[*AccountResult](genSub),
 	}, nil
 }
 
-type AccountSubscription struct {
-	sub *Subscription
-}
-
-func (sw *AccountSubscription) Recv(ctx context.Context) (*AccountResult, error) {
-	select {
-	case <-ctx.Done():
-		return nil, ctx.Err()
-	case d, ok := <-sw.sub.stream:
-		if !ok {
-			return nil, ErrSubscriptionClosed
-		}
-		return d.(*AccountResult), nil
-	case err := <-sw.sub.err:
-		return nil, err
-	}
-}
-
-func (sw *AccountSubscription) Err() <-chan error {
-	return sw.sub.err
-}
-
-func (sw *AccountSubscription) Response() <-chan *AccountResult {
-	typedChan := make(chan *AccountResult, 1)
-	go func(ch chan *AccountResult) {
-		// TODO: will this subscription yield more than one result?
-		d, ok := <-sw.sub.stream
-		if !ok {
-			return
-		}
-		ch <- d.(*AccountResult)
-	}(typedChan)
-	return typedChan
+// AccountSubscribeWithOptsContext is like AccountSubscribeWithOpts, but waits for the server
+// to accept the subscription; if ctx is done first, the subscription is cancelled.
+func (cl *Client) AccountSubscribeWithOptsContext(
+	ctx context.Context,
+	account solana.PublicKey,
+	commitment rpc.CommitmentType,
+	encoding solana.EncodingType,
+	subOpts ...SubscriptionOption,
+) (*AccountSubscription, error) {
+	sub, err := cl.AccountSubscribeWithOpts(account, commitment, encoding, subOpts...)
+	return confirmed(ctx, sub, err)
 }
 
-func (sw *AccountSubscription) Unsubscribe() {
-	sw.sub.Unsubscribe()
+type AccountSubscription struct {
+	*Subscription[*AccountResult]
 }
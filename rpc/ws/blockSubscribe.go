@@ -84,6 +84,7 @@ type BlockSubscribeOpts struct {
 func (cl *Client) BlockSubscribe(
 	filter BlockSubscribeFilter,
 	opts *BlockSubscribeOpts,
+	subOpts ...SubscriptionOption,
 ) (*BlockSubscription, error) {
 	var params []interface{}
 	if filter != nil {
@@ -136,50 +137,28 @@ func (cl *Client) BlockSubscribe(
 			err := decodeResponseFromMessage(msg, &res)
 			return &res, err
 		},
+		subOpts,
 	)
 	if err != nil {
 		return nil, err
 	}
 	return &BlockSubscription{
-		sub: genSub,
+		newTypedSubscription[*BlockResult](genSub),
 	}, nil
 }
 
-type BlockSubscription struct {
-	sub *Subscription
-}
-
-func (sw *BlockSubscription) Recv(ctx context.Context) (*BlockResult, error) {
-	select {
-	case <-ctx.Done():
-		return nil, ctx.Err()
-	case d, ok := <-sw.sub.stream:
-		if !ok {
-			return nil, ErrSubscriptionClosed
-		}
-		return d.(*BlockResult), nil
-	case err := <-sw.sub.err:
-		return nil, err
-	}
-}
-
-func (sw *BlockSubscription) Err() <-chan error {
-	return sw.sub.err
-}
-
-func (sw *BlockSubscription) Response() <-chan *BlockResult {
-	typedChan := make(chan *BlockResult, 1)
-	go func(ch chan *BlockResult) {
-		// TODO: will this subscription yield more than one result?
-		d, ok := <-sw.sub.stream
-		if !ok {
-			return
-		}
-		ch <- d.(*BlockResult)
-	}(typedChan)
-	return typedChan
+// BlockSubscribeContext is like BlockSubscribe, but waits for the server
+// to accept the subscription; if ctx is done first, the subscription is cancelled.
+func (cl *Client) BlockSubscribeContext(
+	ctx context.Context,
+	filter BlockSubscribeFilter,
+	opts *BlockSubscribeOpts,
+	subOpts ...SubscriptionOption,
+) (*BlockSubscription, error) {
+	sub, err := cl.BlockSubscribe(filter, opts, subOpts...)
+	return confirmed(ctx, sub, err)
 }
 
-func (sw *BlockSubscription) Unsubscribe() {
-	sw.sub.Unsubscribe()
+type BlockSubscription struct {
+	*Subscription[*BlockResult]
 }
@@ -19,6 +19,7 @@ package ws
 
 import (
 	"context"
+	stdjson "encoding/json"
 	"errors"
 	"fmt"
 	"io"
@@ -43,10 +44,26 @@ type Client struct {
 	connCtx                 context.Context
 	connCtxCancel           context.CancelFunc
 	lock                    sync.RWMutex
-	subscriptionByRequestID map[uint64]*Subscription
-	subscriptionByWSSubID   map[uint64]*Subscription
-	reconnectOnErr          bool
+	subscriptionByRequestID map[uint64]*upstream
+	subscriptionByWSSubID   map[uint64]*upstream
 	shortID                 bool
+	subscriptionOpts        SubscriptionOptions
+
+	// Set if identical subscriptions share the upstream.
+	shareSubscriptions bool
+	upstreamByKey      map[string]*upstream
+
+	// Unsubscribe methods of the subscription requests
+	// cancelled before the answer of the server, by request ID.
+	cancelledRequests map[uint64]string
+
+	// Closed when the connection is lost, and not reestablished.
+	lost chan struct{}
+
+	// Set if automatic reconnection is enabled.
+	reconnect  *ReconnectOptions
+	dialer     *websocket.Dialer
+	httpHeader http.Header
 }
 
 const (
@@ -70,11 +87,14 @@ func Connect(ctx context.Context, rpcEndpoint string) (c *Client, err error) {
 func ConnectWithOptions(ctx context.Context, rpcEndpoint string, opt *Options) (c *Client, err error) {
 	c = &Client{
 		rpcURL:                  rpcEndpoint,
-		subscriptionByRequestID: map[uint64]*Subscription{},
-		subscriptionByWSSubID:   map[uint64]*Subscription{},
+		subscriptionByRequestID: map[uint64]*upstream{},
+		subscriptionByWSSubID:   map[uint64]*upstream{},
+		upstreamByKey:           map[string]*upstream{},
+		cancelledRequests:       map[uint64]string{},
+		lost:                    make(chan struct{}),
 	}
 
-	dialer := &websocket.Dialer{
+	c.dialer = &websocket.Dialer{
 		Proxy:             http.ProxyFromEnvironment,
 		HandshakeTimeout:  DefaultHandshakeTimeout,
 		EnableCompression: true,
@@ -85,30 +105,41 @@ func ConnectWithOptions(ctx context.Context, rpcEndpoint string, opt *Options) (
 	}
 
 	if opt != nil && opt.HandshakeTimeout > 0 {
-		dialer.HandshakeTimeout = opt.HandshakeTimeout
+		c.dialer.HandshakeTimeout = opt.HandshakeTimeout
 	}
 
-	var httpHeader http.Header = nil
 	if opt != nil && opt.HttpHeader != nil && len(opt.HttpHeader) > 0 {
-		httpHeader = opt.HttpHeader
+		c.httpHeader = opt.HttpHeader
 	}
-	var resp *http.Response
-	c.conn, resp, err = dialer.DialContext(ctx, rpcEndpoint, httpHeader)
-	if err != nil {
-		if resp != nil {
-			body, _ := io.ReadAll(resp.Body)
-			err = fmt.Errorf("new ws client: dial: %w, status: %s, body: %q", err, resp.Status, string(body))
-		} else {
-			err = fmt.Errorf("new ws client: dial: %w", err)
+
+	if opt != nil {
+		c.shareSubscriptions = opt.ShareSubscriptions
+		c.subscriptionOpts = SubscriptionOptions{
+			BufferSize:     opt.SubscriptionBufferSize,
+			OverflowPolicy: opt.OverflowPolicy,
+		}
+	}
+
+	if opt != nil && opt.Reconnect != nil {
+		reconnect := *opt.Reconnect
+		if reconnect.InitialBackoff <= 0 {
+			reconnect.InitialBackoff = DefaultReconnectInitialBackoff
 		}
+		if reconnect.MaxBackoff <= 0 {
+			reconnect.MaxBackoff = DefaultReconnectMaxBackoff
+		}
+		c.reconnect = &reconnect
+	}
+
+	c.conn, err = c.dial(ctx)
+	if err != nil {
 		return nil, err
 	}
 
 	c.connCtx, c.connCtxCancel = context.WithCancel(context.Background())
 	go func() {
-		c.conn.SetReadDeadline(time.Now().Add(pongWait))
-		c.conn.SetPongHandler(func(string) error { c.conn.SetReadDeadline(time.Now().Add(pongWait)); return nil })
 		ticker := time.NewTicker(pingPeriod)
+		defer ticker.Stop()
 		for {
 			select {
 			case <-c.connCtx.Done():
@@ -122,6 +153,23 @@ func ConnectWithOptions(ctx context.Context, rpcEndpoint string, opt *Options) (
 	return c, nil
 }
 
+// dial opens a new connection to the endpoint.
+func (c *Client) dial(ctx context.Context) (*websocket.Conn, error) {
+	conn, resp, err := c.dialer.DialContext(ctx, c.rpcURL, c.httpHeader)
+	if err != nil {
+		if resp != nil {
+			body, _ := io.ReadAll(resp.Body)
+			err = fmt.Errorf("new ws client: dial: %w, status: %s, body: %q", err, resp.Status, string(body))
+		} else {
+			err = fmt.Errorf("new ws client: dial: %w", err)
+		}
+		return nil, err
+	}
+	conn.SetReadDeadline(time.Now().Add(pongWait))
+	conn.SetPongHandler(func(string) error { conn.SetReadDeadline(time.Now().Add(pongWait)); return nil })
+	return conn, nil
+}
+
 func (c *Client) sendPing() {
 	c.lock.Lock()
 	defer c.lock.Unlock()
@@ -140,21 +188,57 @@ func (c *Client) Close() {
 }
 
 func (c *Client) receiveMessages() {
+	// Only this goroutine replaces the connection.
+	conn := c.conn
 	for {
 		select {
 		case <-c.connCtx.Done():
+			c.connectionLost(ErrSubscriptionClosed)
 			return
 		default:
-			_, message, err := c.conn.ReadMessage()
+			_, message, err := conn.ReadMessage()
 			if err != nil {
-				c.closeAllSubscription(err)
-				return
+				if c.connCtx.Err() != nil {
+					// Closed by Close: no reconnection.
+					c.connectionLost(ErrSubscriptionClosed)
+					return
+				}
+				if c.reconnect == nil {
+					c.connectionLost(err)
+					return
+				}
+				conn, err = c.reconnectAndResubscribe(err)
+				if err != nil {
+					c.connectionLost(err)
+					return
+				}
+				continue
 			}
 			c.handleMessage(message)
 		}
 	}
 }
 
+// connectionLost closes the subscriptions with the error
+// which ended the connection, or ErrSubscriptionClosed if
+// the client was closed.
+func (c *Client) connectionLost(err error) {
+	if c.connCtx.Err() != nil {
+		err = ErrSubscriptionClosed
+	}
+	close(c.lost)
+	c.closeAllSubscription(err)
+}
+
+func (c *Client) isLost() bool {
+	select {
+	case <-c.lost:
+		return true
+	default:
+		return false
+	}
+}
+
 // GetUint64 returns the value retrieved by `Get`, cast to a uint64 if possible.
 // If key data type do not match, it will return an error.
 func getUint64(data []byte, keys ...string) (val uint64, err error) {
@@ -182,6 +266,10 @@ func (c *Client) handleMessage(message []byte) {
 
 	requestID, ok := getUint64WithOk(message, "id")
 	if ok {
+		if _, _, _, err := jsonparser.Get(message, "error"); err == nil {
+			c.handleSubscriptionError(requestID, decodeErrorFromMessage(message))
+			return
+		}
 		subID, _ := getUint64WithOk(message, "result")
 		c.handleNewSubscriptionMessage(requestID, subID)
 		return
@@ -202,16 +290,26 @@ func (c *Client) handleNewSubscriptionMessage(requestID, subID uint64) {
 		)
 	}
 
-	callBack, found := c.subscriptionByRequestID[requestID]
+	up, found := c.subscriptionByRequestID[requestID]
 	if !found {
+		if method, cancelled := c.cancelledRequests[requestID]; cancelled {
+			// Unsubscribed while waiting for the server.
+			delete(c.cancelledRequests, requestID)
+			if err := c.unsubscribe(subID, method); err != nil {
+				zlog.Warn("unable to send rpc unsubscribe call", zap.Error(err))
+			}
+			return
+		}
 		zlog.Error("cannot find websocket message handler for a new stream.... this should not happen",
 			zap.Uint64("request_id", requestID),
 			zap.Uint64("subscription_id", subID),
 		)
 		return
 	}
-	callBack.subID = subID
-	c.subscriptionByWSSubID[subID] = callBack
+	up.subID = subID
+	up.registered = true
+	up.confirm(nil)
+	c.subscriptionByWSSubID[subID] = up
 
 	zlog.Debug("registered ws subscription",
 		zap.Uint64("subscription_id", subID),
@@ -221,6 +319,26 @@ func (c *Client) handleNewSubscriptionMessage(requestID, subID uint64) {
 	return
 }
 
+// handleSubscriptionError closes the subscription rejected by the server.
+func (c *Client) handleSubscriptionError(requestID uint64, err error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	delete(c.cancelledRequests, requestID)
+	up, found := c.subscriptionByRequestID[requestID]
+	if !found {
+		zlog.Debug("received error for unknown request", zap.Uint64("request_id", requestID), zap.Error(err))
+		return
+	}
+	err = fmt.Errorf("subscription rejected: %w", err)
+	up.confirm(err)
+	for _, sub := range up.subs {
+		sub.fail(err)
+	}
+	delete(c.subscriptionByRequestID, requestID)
+	c.forgetUpstream(up)
+}
+
 func (c *Client) handleSubscriptionMessage(subID uint64, message []byte) {
 	if traceEnabled {
 		zlog.Debug("received subscription message",
@@ -229,69 +347,90 @@ func (c *Client) handleSubscriptionMessage(subID uint64, message []byte) {
 	}
 
 	c.lock.RLock()
-	sub, found := c.subscriptionByWSSubID[subID]
+	up, found := c.subscriptionByWSSubID[subID]
+	var subs []*subscription
+	if found {
+		subs = append(subs, up.subs...)
+	}
 	c.lock.RUnlock()
 	if !found {
 		zlog.Warn("unable to find subscription for ws message", zap.Uint64("subscription_id", subID))
 		return
 	}
 
-	// Decode the message using the subscription-provided decoderFunc.
-	result, err := sub.decoderFunc(message)
-	if err != nil {
-		fmt.Println("*****************************")
-		c.closeSubscription(sub.req.ID, fmt.Errorf("unable to decode client response: %w", err))
-		return
-	}
-
-	// this cannot be blocking or else
-	// we  will no read any other message
-	if len(sub.stream) >= cap(sub.stream) {
-		zlog.Warn("closing ws client subscription... not consuming fast en ought",
-			zap.Uint64("request_id", sub.req.ID),
-		)
-		c.closeSubscription(sub.req.ID, fmt.Errorf("reached channel max capacity %d", len(sub.stream)))
-		return
-	}
+	for _, sub := range subs {
+		// Decode the message using the subscription-provided decoderFunc.
+		result, err := sub.decoderFunc(message)
+		if err != nil {
+			c.closeSubscription(sub, fmt.Errorf("unable to decode client response: %w", err))
+			continue
+		}
 
-	if !sub.closed {
-		sub.stream <- result
+		if !sub.push(result) {
+			zlog.Warn("closing ws client subscription... not consuming fast en ought",
+				zap.Uint64("request_id", up.req.ID),
+			)
+			c.closeSubscription(sub, fmt.Errorf("reached channel max capacity %d", len(sub.stream)))
+		}
 	}
-	return
 }
 
 func (c *Client) closeAllSubscription(err error) {
 	c.lock.Lock()
 	defer c.lock.Unlock()
 
-	for _, sub := range c.subscriptionByRequestID {
-		sub.err <- err
+	for _, up := range c.subscriptionByRequestID {
+		for _, sub := range up.subs {
+			sub.fail(err)
+		}
 	}
 
-	c.subscriptionByRequestID = map[uint64]*Subscription{}
-	c.subscriptionByWSSubID = map[uint64]*Subscription{}
+	c.subscriptionByRequestID = map[uint64]*upstream{}
+	c.subscriptionByWSSubID = map[uint64]*upstream{}
+	c.upstreamByKey = map[string]*upstream{}
 }
 
-func (c *Client) closeSubscription(reqID uint64, err error) {
+// closeSubscription closes a local subscription; the subscription
+// on the server is closed with the last local subscription using it.
+func (c *Client) closeSubscription(sub *subscription, err error) {
 	c.lock.Lock()
 	defer c.lock.Unlock()
 
-	sub, found := c.subscriptionByRequestID[reqID]
-	if !found {
+	up := sub.up
+	if !up.remove(sub) {
+		return
+	}
+	sub.fail(err)
+	if len(up.subs) > 0 {
+		return
+	}
+	if _, found := c.subscriptionByRequestID[up.req.ID]; !found {
 		return
 	}
 
-	sub.err <- err
-
-	err = c.unsubscribe(sub.subID, sub.unsubscribeMethod)
-	if err != nil {
-		zlog.Warn("unable to send rpc unsubscribe call",
-			zap.Error(err),
-		)
+	if up.registered {
+		err = c.unsubscribe(up.subID, up.unsubscribeMethod)
+		if err != nil {
+			zlog.Warn("unable to send rpc unsubscribe call",
+				zap.Error(err),
+			)
+		}
+		delete(c.subscriptionByWSSubID, up.subID)
+	} else {
+		// The server subscription ID is not known yet (on this connection,
+		// if resubscribing after a reconnection).
+		c.cancelledRequests[up.req.ID] = up.unsubscribeMethod
 	}
 
-	delete(c.subscriptionByRequestID, sub.req.ID)
-	delete(c.subscriptionByWSSubID, sub.subID)
+	delete(c.subscriptionByRequestID, up.req.ID)
+	c.forgetUpstream(up)
+}
+
+// forgetUpstream prevents new subscriptions from sharing up.
+func (c *Client) forgetUpstream(up *upstream) {
+	if up.key != "" && c.upstreamByKey[up.key] == up {
+		delete(c.upstreamByKey, up.key)
+	}
 }
 
 func (c *Client) unsubscribe(subID uint64, method string) error {
@@ -315,39 +454,88 @@ func (c *Client) subscribe(
 	subscriptionMethod string,
 	unsubscribeMethod string,
 	decoderFunc decoderFunc,
-) (*Subscription, error) {
+	subOpts []SubscriptionOption,
+) (*subscription, error) {
 	c.lock.Lock()
 	defer c.lock.Unlock()
 
+	if c.isLost() {
+		return nil, errors.New("subscribe: connection lost")
+	}
+
 	req := newRequest(params, subscriptionMethod, conf, c.shortID)
+	var key string
+	if c.shareSubscriptions && subscriptionMethod != "signatureSubscribe" {
+		// A signature subscription is closed by the server after its
+		// notification, so a later identical request must not share it.
+		var err error
+		key, err = subscriptionKey(req)
+		if err != nil {
+			return nil, fmt.Errorf("subscribe: %w", err)
+		}
+		if up, found := c.upstreamByKey[key]; found {
+			sub := c.newSubscription(up, decoderFunc, subOpts)
+			zlog.Debug("sharing ws subscription",
+				zap.Uint64("request_id", up.req.ID),
+				zap.Int("local_subscription_count", len(up.subs)),
+			)
+			return sub, nil
+		}
+	}
+
 	data, err := req.encode()
 	if err != nil {
 		return nil, fmt.Errorf("subscribe: unable to encode subsciption request: %w", err)
 	}
 
-	sub := newSubscription(
-		req,
-		func(err error) {
-			c.closeSubscription(req.ID, err)
-		},
-		unsubscribeMethod,
-		decoderFunc,
-	)
+	up := newUpstream(req, unsubscribeMethod)
+	up.key = key
+	sub := c.newSubscription(up, decoderFunc, subOpts)
 
-	c.subscriptionByRequestID[req.ID] = sub
+	c.subscriptionByRequestID[req.ID] = up
+	if key != "" {
+		c.upstreamByKey[key] = up
+	}
 	zlog.Info("added new subscription to websocket client", zap.Int("count", len(c.subscriptionByRequestID)))
 
 	zlog.Debug("writing data to conn", zap.String("data", string(data)))
 	c.conn.SetWriteDeadline(time.Now().Add(writeWait))
 	err = c.conn.WriteMessage(websocket.TextMessage, data)
 	if err != nil {
+		if c.reconnect != nil {
+			// The subscription is sent again once reconnected.
+			zlog.Debug("unable to write subscription request, waiting for reconnection", zap.Error(err))
+			return sub, nil
+		}
 		delete(c.subscriptionByRequestID, req.ID)
+		c.forgetUpstream(up)
 		return nil, fmt.Errorf("unable to write request: %w", err)
 	}
 
 	return sub, nil
 }
 
+// newSubscription adds a local subscription to up.
+// Must be called with the lock held.
+func (c *Client) newSubscription(up *upstream, decoderFunc decoderFunc, subOpts []SubscriptionOption) *subscription {
+	sub := newSubscription(up, nil, decoderFunc, c.newSubscriptionOptions(subOpts))
+	sub.closeFunc = func(err error) {
+		c.closeSubscription(sub, err)
+	}
+	up.subs = append(up.subs, sub)
+	return sub
+}
+
+// newSubscriptionOptions applies the options of a subscription
+// over the defaults of the client.
+func (c *Client) newSubscriptionOptions(subOpts []SubscriptionOption) SubscriptionOptions {
+	opts := c.subscriptionOpts
+	for _, apply := range subOpts {
+		apply(&opts)
+	}
+	return opts
+}
+
 func decodeResponseFromReader(r io.Reader, reply interface{}) (err error) {
 	var c *response
 	if err := json.NewDecoder(r).Decode(&c); err != nil {
@@ -355,14 +543,7 @@ func decodeResponseFromReader(r io.Reader, reply interface{}) (err error) {
 	}
 
 	if c.Error != nil {
-		jsonErr := &json2.Error{}
-		if err := json.Unmarshal(*c.Error, jsonErr); err != nil {
-			return &json2.Error{
-				Code:    json2.E_SERVER,
-				Message: string(*c.Error),
-			}
-		}
-		return jsonErr
+		return decodeError(c.Error)
 	}
 
 	if c.Params == nil {
@@ -372,6 +553,29 @@ func decodeResponseFromReader(r io.Reader, reply interface{}) (err error) {
 	return json.Unmarshal(*c.Params.Result, &reply)
 }
 
+// decodeErrorFromMessage returns the error of a response.
+func decodeErrorFromMessage(r []byte) error {
+	var c *response
+	if err := json.Unmarshal(r, &c); err != nil {
+		return err
+	}
+	if c.Error == nil {
+		return json2.ErrNullResult
+	}
+	return decodeError(c.Error)
+}
+
+func decodeError(raw *stdjson.RawMessage) error {
+	jsonErr := &json2.Error{}
+	if err := json.Unmarshal(*raw, jsonErr); err != nil {
+		return &json2.Error{
+			Code:    json2.E_SERVER,
+			Message: string(*raw),
+		}
+	}
+	return jsonErr
+}
+
 func decodeResponseFromMessage(r []byte, reply interface{}) (err error) {
 	var c *response
 	if err := json.Unmarshal(r, &c); err != nil {
@@ -379,14 +583,7 @@ func decodeResponseFromMessage(r []byte, reply interface{}) (err error) {
 	}
 
 	if c.Error != nil {
-		jsonErr := &json2.Error{}
-		if err := json.Unmarshal(*c.Error, jsonErr); err != nil {
-			return &json2.Error{
-				Code:    json2.E_SERVER,
-				Message: string(*c.Error),
-			}
-		}
-		return jsonErr
+		return decodeError(c.Error)
 	}
 
 	if c.Params == nil {
@@ -38,6 +38,23 @@ type LogResult struct {
 	} `json:"value"`
 }
 
+// ParseLogs parses the logs of the transaction into its invocation tree.
+func (res *LogResult) ParseLogs() (*rpc.ProgramLogs, error) {
+	return rpc.ParseProgramLogs(res.Value.Logs)
+}
+
+// Events decodes the events logged by the programs of the transaction
+// that have a registered event decoder (see solana.RegisterEventDecoder).
+// The data the decoder rejects is returned as events with Err set;
+// the error is only set if the logs can't be parsed.
+func (res *LogResult) Events() ([]*rpc.Event, error) {
+	logs, err := res.ParseLogs()
+	if err != nil {
+		return nil, err
+	}
+	return logs.Events(), nil
+}
+
 type LogsSubscribeFilterType string
 
 const (
@@ -52,32 +69,61 @@ func (cl *Client) LogsSubscribe(
 	// Filter criteria for the logs to receive results by account type.
 	filter LogsSubscribeFilterType,
 	commitment rpc.CommitmentType, // (optional)
+	subOpts ...SubscriptionOption,
 ) (*LogSubscription, error) {
 	return cl.logsSubscribe(
 		filter,
 		commitment,
+		subOpts...,
 	)
 }
 
+// LogsSubscribeContext is like LogsSubscribe, but waits for the server
+// to accept the subscription; if ctx is done first, the subscription is cancelled.
+func (cl *Client) LogsSubscribeContext(
+	ctx context.Context,
+	filter LogsSubscribeFilterType,
+	commitment rpc.CommitmentType,
+	subOpts ...SubscriptionOption,
+) (*LogSubscription, error) {
+	sub, err := cl.LogsSubscribe(filter, commitment, subOpts...)
+	return confirmed(ctx, sub, err)
+}
+
 // LogsSubscribe subscribes to all transactions that mention the provided Pubkey.
 func (cl *Client) LogsSubscribeMentions(
 	// Subscribe to all transactions that mention the provided Pubkey.
 	mentions solana.PublicKey,
 	// (optional)
 	commitment rpc.CommitmentType,
+	subOpts ...SubscriptionOption,
 ) (*LogSubscription, error) {
 	return cl.logsSubscribe(
 		rpc.M{
 			"mentions": []string{mentions.String()},
 		},
 		commitment,
+		subOpts...,
 	)
 }
 
+// LogsSubscribeMentionsContext is like LogsSubscribeMentions, but waits for the server
+// to accept the subscription; if ctx is done first, the subscription is cancelled.
+func (cl *Client) LogsSubscribeMentionsContext(
+	ctx context.Context,
+	mentions solana.PublicKey,
+	commitment rpc.CommitmentType,
+	subOpts ...SubscriptionOption,
+) (*LogSubscription, error) {
+	sub, err := cl.LogsSubscribeMentions(mentions, commitment, subOpts...)
+	return confirmed(ctx, sub, err)
+}
+
 // LogsSubscribe subscribes to transaction logging.
 func (cl *Client) logsSubscribe(
 	filter interface{},
 	commitment rpc.CommitmentType,
+	subOpts ...SubscriptionOption,
 ) (*LogSubscription, error) {
 
 	params := []interface{}{filter}
@@ -96,50 +142,16 @@ func (cl *Client) logsSubscribe(
 			err := decodeResponseFromMessage(msg, &res)
 			return &res, err
 		},
+		subOpts,
 	)
 	if err != nil {
 		return nil, err
 	}
 	return &LogSubscription{
-		sub: genSub,
+		newTypedSubscription[*LogResult](genSub),
 	}, nil
 }
 
 type LogSubscription struct {
-	sub *Subscription
-}
-
-func (sw *LogSubscription) Recv(ctx context.Context) (*LogResult, error) {
-	select {
-	case <-ctx.Done():
-		return nil, ctx.Err()
-	case d, ok := <-sw.sub.stream:
-		if !ok {
-			return nil, ErrSubscriptionClosed
-		}
-		return d.(*LogResult), nil
-	case err := <-sw.sub.err:
-		return nil, err
-	}
-}
-
-func (sw *LogSubscription) Err() <-chan error {
-	return sw.sub.err
-}
-
-func (sw *LogSubscription) Response() <-chan *LogResult {
-	typedChan := make(chan *LogResult, 1)
-	go func(ch chan *LogResult) {
-		// TODO: will this subscription yield more than one result?
-		d, ok := <-sw.sub.stream
-		if !ok {
-			return
-		}
-		ch <- d.(*LogResult)
-	}(typedChan)
-	return typedChan
-}
-
-func (sw *LogSubscription) Unsubscribe() {
-	sw.sub.Unsubscribe()
+	*Subscription[*LogResult]
 }
@@ -42,6 +42,7 @@ type ParsedBlockResult struct {
 func (cl *Client) ParsedBlockSubscribe(
 	filter BlockSubscribeFilter,
 	opts *BlockSubscribeOpts,
+	subOpts ...SubscriptionOption,
 ) (*ParsedBlockSubscription, error) {
 	var params []interface{}
 	if filter != nil {
@@ -81,47 +82,28 @@ func (cl *Client) ParsedBlockSubscribe(
 			err := decodeResponseFromMessage(msg, &res)
 			return &res, err
 		},
+		subOpts,
 	)
 	if err != nil {
 		return nil, err
 	}
 	return &ParsedBlockSubscription{
-		sub: genSub,
+		newTypedSubscription[*ParsedBlockResult](genSub),
 	}, nil
 }
 
-type ParsedBlockSubscription struct {
-	sub *Subscription
-}
-
-func (sw *ParsedBlockSubscription) Recv(ctx context.Context) (*ParsedBlockResult, error) {
-	select {
-	case <-ctx.Done():
-		return nil, ctx.Err()
-	case d := <-sw.sub.stream:
-		return d.(*ParsedBlockResult), nil
-	case err := <-sw.sub.err:
-		return nil, err
-	}
-}
-
-func (sw *ParsedBlockSubscription) Err() <-chan error {
-	return sw.sub.err
-}
-
-func (sw *ParsedBlockSubscription) Response() <-chan *ParsedBlockResult {
-	typedChan := make(chan *ParsedBlockResult, 1)
-	go func(ch chan *ParsedBlockResult) {
-		// TODO: will this subscription yield more than one result?
-		d, ok := <-sw.sub.stream
-		if !ok {
-			return
-		}
-		ch <- d.(*ParsedBlockResult)
-	}(typedChan)
-	return typedChan
+// ParsedBlockSubscribeContext is like ParsedBlockSubscribe, but waits for the server
+// to accept the subscription; if ctx is done first, the subscription is cancelled.
+func (cl *Client) ParsedBlockSubscribeContext(
+	ctx context.Context,
+	filter BlockSubscribeFilter,
+	opts *BlockSubscribeOpts,
+	subOpts ...SubscriptionOption,
+) (*ParsedBlockSubscription, error) {
+	sub, err := cl.ParsedBlockSubscribe(filter, opts, subOpts...)
+	return confirmed(ctx, sub, err)
 }
 
-func (sw *ParsedBlockSubscription) Unsubscribe() {
-	sw.sub.Unsubscribe()
+type ParsedBlockSubscription struct {
+	*Subscription[*ParsedBlockResult]
 }
@@ -0,0 +1,383 @@
+// Copyright 2021 github.com/gagliardetto
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ws
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+)
+
+// Subscriber is implemented by Client and PollingClient,
+// to choose the transport of the subscriptions by configuration.
+type Subscriber interface {
+	AccountSubscribe(account solana.PublicKey, commitment rpc.CommitmentType, subOpts ...SubscriptionOption) (*AccountSubscription, error)
+	AccountSubscribeWithOpts(account solana.PublicKey, commitment rpc.CommitmentType, encoding solana.EncodingType, subOpts ...SubscriptionOption) (*AccountSubscription, error)
+	ProgramSubscribe(programID solana.PublicKey, commitment rpc.CommitmentType, subOpts ...SubscriptionOption) (*ProgramSubscription, error)
+	ProgramSubscribeWithOpts(programID solana.PublicKey, commitment rpc.CommitmentType, encoding solana.EncodingType, filters []rpc.RPCFilter, subOpts ...SubscriptionOption) (*ProgramSubscription, error)
+	SignatureSubscribe(signature solana.Signature, commitment rpc.CommitmentType, subOpts ...SubscriptionOption) (*SignatureSubscription, error)
+	SlotSubscribe(subOpts ...SubscriptionOption) (*SlotSubscription, error)
+	Close()
+}
+
+var (
+	_ Subscriber = (*Client)(nil)
+	_ Subscriber = (*PollingClient)(nil)
+)
+
+// DefaultPollingInterval is the default interval between two polls.
+var DefaultPollingInterval = time.Second
+
+// PollingOptions configures a PollingClient.
+type PollingOptions struct {
+	// Interval between two polls of each subscription (default: DefaultPollingInterval).
+	Interval time.Duration
+
+	// Called with the errors of the polls, which are retried at the next interval.
+	OnError func(err error)
+
+	// Defaults of the subscriptions, overridable per subscription
+	// with SubscriptionOption (see SubscriptionOptions).
+	SubscriptionBufferSize int
+	OverflowPolicy         OverflowPolicy
+}
+
+// PollingClient emulates the websocket subscriptions by polling the RPC API,
+// for the endpoints which do not support websockets:
+//
+//   - AccountSubscribe polls GetAccountInfo;
+//   - ProgramSubscribe polls GetProgramAccounts;
+//   - SignatureSubscribe polls GetSignatureStatuses;
+//   - SlotSubscribe polls GetSlot.
+//
+// A notification is delivered only when the polled value changes,
+// i.e. the changes between two polls are not all observed.
+type PollingClient struct {
+	rpcClient        *rpc.Client
+	interval         time.Duration
+	onError          func(err error)
+	subscriptionOpts SubscriptionOptions
+
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	lock sync.Mutex
+	subs map[*subscription]struct{}
+	wg   sync.WaitGroup
+}
+
+// NewPollingClient creates a PollingClient using rpcClient.
+func NewPollingClient(rpcClient *rpc.Client, opts *PollingOptions) *PollingClient {
+	if opts == nil {
+		opts = &PollingOptions{}
+	}
+	pc := &PollingClient{
+		rpcClient: rpcClient,
+		interval:  opts.Interval,
+		onError:   opts.OnError,
+		subscriptionOpts: SubscriptionOptions{
+			BufferSize:     opts.SubscriptionBufferSize,
+			OverflowPolicy: opts.OverflowPolicy,
+		},
+		subs: map[*subscription]struct{}{},
+	}
+	if pc.interval <= 0 {
+		pc.interval = DefaultPollingInterval
+	}
+	pc.ctx, pc.cancel = context.WithCancel(context.Background())
+	return pc
+}
+
+// Close stops the polling, and closes all the subscriptions.
+func (pc *PollingClient) Close() {
+	pc.cancel()
+	pc.lock.Lock()
+	subs := make([]*subscription, 0, len(pc.subs))
+	for sub := range pc.subs {
+		subs = append(subs, sub)
+	}
+	pc.lock.Unlock()
+	for _, sub := range subs {
+		sub.Unsubscribe()
+	}
+	pc.wg.Wait()
+}
+
+// pollFunc polls once, delivering the notifications with emit;
+// it returns true when the subscription is complete.
+type pollFunc func(ctx context.Context, emit func(result) bool) (bool, error)
+
+// poll starts a subscription calling fn at every interval.
+func (pc *PollingClient) poll(subOpts []SubscriptionOption, fn pollFunc) (*subscription, error) {
+	pc.lock.Lock()
+	defer pc.lock.Unlock()
+	if err := pc.ctx.Err(); err != nil {
+		return nil, fmt.Errorf("polling client closed: %w", err)
+	}
+
+	opts := pc.subscriptionOpts
+	for _, apply := range subOpts {
+		apply(&opts)
+	}
+	ctx, cancel := context.WithCancel(pc.ctx)
+	up := newUpstream(nil, "")
+	up.confirm(nil)
+	sub := newSubscription(up, nil, nil, opts)
+	sub.closeFunc = func(error) {
+		cancel()
+		pc.lock.Lock()
+		delete(pc.subs, sub)
+		pc.lock.Unlock()
+	}
+	pc.subs[sub] = struct{}{}
+
+	emit := func(v result) bool {
+		if !sub.push(v) {
+			sub.fail(fmt.Errorf("reached channel max capacity %d", len(sub.stream)))
+			return false
+		}
+		return true
+	}
+
+	pc.wg.Add(1)
+	go func() {
+		defer pc.wg.Done()
+		defer cancel()
+		ticker := time.NewTicker(pc.interval)
+		defer ticker.Stop()
+		for {
+			complete, err := fn(ctx, emit)
+			if err != nil && ctx.Err() == nil && pc.onError != nil {
+				pc.onError(err)
+			}
+			if complete {
+				return
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+	return sub, nil
+}
+
+// AccountSubscribe polls an account, and delivers it when it changes.
+func (pc *PollingClient) AccountSubscribe(
+	account solana.PublicKey,
+	commitment rpc.CommitmentType,
+	subOpts ...SubscriptionOption,
+) (*AccountSubscription, error) {
+	return pc.AccountSubscribeWithOpts(account, commitment, "", subOpts...)
+}
+
+// AccountSubscribeWithOpts polls an account, and delivers it when it changes.
+// A deleted account is delivered with zero lamports, owned by the system program.
+func (pc *PollingClient) AccountSubscribeWithOpts(
+	account solana.PublicKey,
+	commitment rpc.CommitmentType,
+	encoding solana.EncodingType,
+	subOpts ...SubscriptionOption,
+) (*AccountSubscription, error) {
+	if encoding == "" {
+		encoding = solana.EncodingBase64
+	}
+	var (
+		last        []byte
+		initialized bool
+	)
+	sub, err := pc.poll(subOpts, func(ctx context.Context, emit func(result) bool) (bool, error) {
+		out, err := pc.rpcClient.GetAccountInfoWithOpts(ctx, account, &rpc.GetAccountInfoOpts{
+			Encoding:   encoding,
+			Commitment: commitment,
+		})
+		res := &AccountResult{}
+		switch {
+		case errors.Is(err, rpc.ErrNotFound):
+			res.Value = &rpc.Account{
+				Owner: solana.SystemProgramID,
+				Data:  rpc.DataBytesOrJSONFromBytes([]byte{}),
+			}
+		case err != nil:
+			return false, fmt.Errorf("unable to get account %s: %w", account, err)
+		default:
+			res.Context.Slot = out.Context.Slot
+			res.Value = out.Value
+		}
+
+		fingerprint, err := json.Marshal(res.Value)
+		if err != nil {
+			return false, err
+		}
+		if !initialized {
+			// Like the websocket subscription, only the changes are delivered.
+			initialized = true
+			last = fingerprint
+			return false, nil
+		}
+		if bytes.Equal(fingerprint, last) {
+			return false, nil
+		}
+		last = fingerprint
+		return !emit(res), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &AccountSubscription{
+		newTypedSubscription[*AccountResult](sub),
+	}, nil
+}
+
+// ProgramSubscribe polls the accounts of a program,
+// and delivers each account when it changes.
+func (pc *PollingClient) ProgramSubscribe(
+	programID solana.PublicKey,
+	commitment rpc.CommitmentType,
+	subOpts ...SubscriptionOption,
+) (*ProgramSubscription, error) {
+	return pc.ProgramSubscribeWithOpts(programID, commitment, "", nil, subOpts...)
+}
+
+// ProgramSubscribeWithOpts polls the accounts of a program,
+// and delivers each account when it changes, or appears.
+// The Context.Slot of the results is not set.
+func (pc *PollingClient) ProgramSubscribeWithOpts(
+	programID solana.PublicKey,
+	commitment rpc.CommitmentType,
+	encoding solana.EncodingType,
+	filters []rpc.RPCFilter,
+	subOpts ...SubscriptionOption,
+) (*ProgramSubscription, error) {
+	if encoding == "" {
+		encoding = solana.EncodingBase64
+	}
+	var (
+		last        map[solana.PublicKey][]byte
+		initialized bool
+	)
+	sub, err := pc.poll(subOpts, func(ctx context.Context, emit func(result) bool) (bool, error) {
+		out, err := pc.rpcClient.GetProgramAccountsWithOpts(ctx, programID, &rpc.GetProgramAccountsOpts{
+			Commitment: commitment,
+			Encoding:   encoding,
+			Filters:    filters,
+		})
+		if err != nil {
+			return false, fmt.Errorf("unable to get accounts of program %s: %w", programID, err)
+		}
+
+		current := make(map[solana.PublicKey][]byte, len(out))
+		var changed []*rpc.KeyedAccount
+		for _, keyed := range out {
+			if keyed == nil {
+				continue
+			}
+			fingerprint, err := json.Marshal(keyed.Account)
+			if err != nil {
+				return false, err
+			}
+			current[keyed.Pubkey] = fingerprint
+			if previous, ok := last[keyed.Pubkey]; !ok || !bytes.Equal(previous, fingerprint) {
+				changed = append(changed, keyed)
+			}
+		}
+		last = current
+		if !initialized {
+			initialized = true
+			return false, nil
+		}
+		for _, keyed := range changed {
+			res := &ProgramResult{Value: *keyed}
+			if !emit(res) {
+				return true, nil
+			}
+		}
+		return false, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &ProgramSubscription{
+		newTypedSubscription[*ProgramResult](sub),
+	}, nil
+}
+
+// SignatureSubscribe polls the status of a transaction, and delivers it once
+// it reaches the commitment (default: finalized); the subscription is then complete.
+func (pc *PollingClient) SignatureSubscribe(
+	signature solana.Signature,
+	commitment rpc.CommitmentType,
+	subOpts ...SubscriptionOption,
+) (*SignatureSubscription, error) {
+	if commitment == "" {
+		commitment = rpc.CommitmentFinalized
+	}
+	sub, err := pc.poll(subOpts, func(ctx context.Context, emit func(result) bool) (bool, error) {
+		out, err := pc.rpcClient.GetSignatureStatuses(ctx, false, signature)
+		if err != nil {
+			return false, fmt.Errorf("unable to get status of %s: %w", signature, err)
+		}
+		if len(out.Value) == 0 || out.Value[0] == nil {
+			return false, nil
+		}
+		status := out.Value[0]
+		if !status.Reaches(commitment) {
+			return false, nil
+		}
+		res := &SignatureResult{}
+		res.Context.Slot = out.Context.Slot
+		res.Value.Err = status.Err
+		emit(res)
+		return true, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &SignatureSubscription{
+		newTypedSubscription[*SignatureResult](sub),
+	}, nil
+}
+
+// SlotSubscribe polls the processed slot, and delivers it when it changes.
+// The Parent of the results is the slot of the previous notification,
+// and the Root is not set.
+func (pc *PollingClient) SlotSubscribe(subOpts ...SubscriptionOption) (*SlotSubscription, error) {
+	var last uint64
+	sub, err := pc.poll(subOpts, func(ctx context.Context, emit func(result) bool) (bool, error) {
+		slot, err := pc.rpcClient.GetSlot(ctx, rpc.CommitmentProcessed)
+		if err != nil {
+			return false, fmt.Errorf("unable to get slot: %w", err)
+		}
+		if slot <= last {
+			return false, nil
+		}
+		res := &SlotResult{Parent: last, Slot: slot}
+		last = slot
+		return !emit(res), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &SlotSubscription{
+		newTypedSubscription[*SlotResult](sub),
+	}, nil
+}
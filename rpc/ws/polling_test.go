@@ -0,0 +1,162 @@
+// Copyright 2021 github.com/gagliardetto
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ws
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+	"github.com/gagliardetto/solana-go/rpc/rpctest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestPollingClient(t *testing.T) (*rpctest.Server, *PollingClient) {
+	srv := rpctest.NewServer()
+	t.Cleanup(srv.Close)
+	client := NewPollingClient(rpc.New(srv.URL), &PollingOptions{
+		Interval: 5 * time.Millisecond,
+		OnError:  func(err error) { t.Errorf("unexpected error: %v", err) },
+	})
+	t.Cleanup(client.Close)
+	return srv, client
+}
+
+// waitPolls waits for method to be polled count more times.
+func waitPolls(t *testing.T, srv *rpctest.Server, method string, count int) {
+	target := srv.CallCount(method) + count
+	require.Eventually(t, func() bool { return srv.CallCount(method) >= target }, 5*time.Second, time.Millisecond)
+}
+
+func assertNoNotification[T any](t *testing.T, sub *Subscription[T]) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	got, err := sub.Recv(ctx)
+	require.ErrorIs(t, err, context.DeadlineExceeded, "unexpected notification: %v", got)
+}
+
+func TestPollingClient_Account(t *testing.T) {
+	srv, client := newTestPollingClient(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	pubkey := solana.NewWallet().PublicKey()
+	owner := solana.NewWallet().PublicKey()
+	srv.SetAccount(pubkey, rpctest.Account{Lamports: 1, Owner: owner})
+
+	var sub Subscriber = client
+	accountSub, err := sub.AccountSubscribe(pubkey, rpc.CommitmentConfirmed)
+	require.NoError(t, err)
+	waitPolls(t, srv, "getAccountInfo", 3)
+	assertNoNotification(t, accountSub.Subscription)
+
+	srv.SetAccount(pubkey, rpctest.Account{Lamports: 2, Owner: owner, Data: []byte{1, 2}})
+	got, err := accountSub.Recv(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(2), got.Value.Lamports)
+	assert.Equal(t, []byte{1, 2}, got.Value.Data.GetBinary())
+
+	srv.DeleteAccount(pubkey)
+	got, err = accountSub.Recv(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, got.Value.Lamports)
+	assert.Equal(t, solana.SystemProgramID, got.Value.Owner)
+
+	client.Close()
+	_, err = accountSub.Recv(ctx)
+	assert.ErrorIs(t, err, ErrSubscriptionClosed)
+}
+
+func TestPollingClient_Program(t *testing.T) {
+	srv, client := newTestPollingClient(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	program := solana.NewWallet().PublicKey()
+	first := solana.NewWallet().PublicKey()
+	second := solana.NewWallet().PublicKey()
+	srv.SetAccount(first, rpctest.Account{Lamports: 1, Owner: program})
+	srv.SetAccount(second, rpctest.Account{Lamports: 1, Owner: program})
+
+	sub, err := client.ProgramSubscribe(program, rpc.CommitmentConfirmed)
+	require.NoError(t, err)
+	defer sub.Unsubscribe()
+	waitPolls(t, srv, "getProgramAccounts", 2)
+
+	srv.SetAccount(second, rpctest.Account{Lamports: 2, Owner: program})
+	got, err := sub.Recv(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, second, got.Value.Pubkey)
+	assert.Equal(t, uint64(2), got.Value.Account.Lamports)
+
+	third := solana.NewWallet().PublicKey()
+	srv.SetAccount(third, rpctest.Account{Lamports: 3, Owner: program})
+	got, err = sub.Recv(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, third, got.Value.Pubkey)
+}
+
+func TestPollingClient_Signature(t *testing.T) {
+	srv, client := newTestPollingClient(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	sig := solana.Signature{1, 2, 3}
+	sub, err := client.SignatureSubscribe(sig, rpc.CommitmentFinalized)
+	require.NoError(t, err)
+	defer sub.Unsubscribe()
+
+	srv.SetSignatureStatus(sig, &rpc.SignatureStatusesResult{Slot: 10, ConfirmationStatus: rpc.ConfirmationStatusConfirmed})
+	waitPolls(t, srv, "getSignatureStatuses", 2)
+	assertNoNotification(t, sub.Subscription)
+
+	srv.SetSignatureStatus(sig, &rpc.SignatureStatusesResult{
+		Slot:               10,
+		ConfirmationStatus: rpc.ConfirmationStatusFinalized,
+		Err:                map[string]interface{}{"InstructionError": []interface{}{0, "InvalidArgument"}},
+	})
+	got, err := sub.Recv(ctx)
+	require.NoError(t, err)
+	assert.NotNil(t, got.Value.Err)
+
+	// Complete: no more polls.
+	calls := srv.CallCount("getSignatureStatuses")
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, calls, srv.CallCount("getSignatureStatuses"))
+}
+
+func TestPollingClient_Slot(t *testing.T) {
+	srv, client := newTestPollingClient(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	srv.SetSlot(10, 10)
+	sub, err := client.SlotSubscribe()
+	require.NoError(t, err)
+	defer sub.Unsubscribe()
+
+	got, err := sub.Recv(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(10), got.Slot)
+
+	srv.SetSlot(12, 12)
+	got, err = sub.Recv(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(12), got.Slot)
+	assert.Equal(t, uint64(10), got.Parent)
+}
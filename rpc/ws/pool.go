@@ -0,0 +1,518 @@
+// Copyright 2021 github.com/gagliardetto
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ws
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+	"go.uber.org/zap"
+)
+
+// ErrPoolUnavailable is returned when no connection
+// of the pool can take a new subscription.
+var ErrPoolUnavailable = errors.New("no connection available in the pool")
+
+// Number of notifications buffered by the subscriptions
+// of the connections; the subscriptions of the pool buffer the rest.
+const poolConnectionBufferSize = 64
+
+var _ Subscriber = (*Pool)(nil)
+
+// PoolOptions configures a Pool.
+type PoolOptions struct {
+	// Options of the connections. SubscriptionBufferSize and OverflowPolicy
+	// apply to the subscriptions of the pool; as each subscription allocates
+	// its buffer, set a small SubscriptionBufferSize for thousands of subscriptions.
+	Options *Options
+
+	// Number of connections opened to each endpoint (default: 1).
+	ConnectionsPerEndpoint int
+
+	// Maximum number of subscriptions per connection (default: unlimited).
+	MaxSubscriptionsPerConnection int
+
+	// Delay before redialing a lost connection, or retrying to move
+	// a subscription, doubling up to DefaultReconnectMaxBackoff
+	// (default: DefaultReconnectInitialBackoff).
+	RedialInterval time.Duration
+}
+
+// PoolConnection describes a connection of the pool.
+type PoolConnection struct {
+	Endpoint      string
+	Subscriptions int
+	Alive         bool
+}
+
+// Pool spreads subscriptions over several websocket connections,
+// possibly to different endpoints: each subscription is made
+// on the connection with the fewest subscriptions.
+//
+// When a connection is lost, its subscriptions are moved to the other
+// connections, and the connection is redialed in the background.
+// Notifications may be missed while a subscription is moved.
+// The subscriptions are not moved back once the connection is redialed:
+// it only takes the new subscriptions.
+type Pool struct {
+	connOpts                      *Options
+	subscriptionOpts              SubscriptionOptions
+	maxSubscriptionsPerConnection int
+	redialInterval                time.Duration
+
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	lock    sync.Mutex
+	members []*poolMember
+	subs    map[*subscription]struct{}
+	wg      sync.WaitGroup
+}
+
+type poolMember struct {
+	endpoint string
+	// Nil while redialing.
+	client *Client
+	load   int
+}
+
+// NewPool connects to the endpoints.
+func NewPool(ctx context.Context, endpoints []string, opts *PoolOptions) (*Pool, error) {
+	if len(endpoints) == 0 {
+		return nil, errors.New("no endpoints")
+	}
+	if opts == nil {
+		opts = &PoolOptions{}
+	}
+	p := &Pool{
+		connOpts:                      opts.Options,
+		maxSubscriptionsPerConnection: opts.MaxSubscriptionsPerConnection,
+		redialInterval:                opts.RedialInterval,
+		subs:                          map[*subscription]struct{}{},
+	}
+	if p.connOpts != nil {
+		p.subscriptionOpts = SubscriptionOptions{
+			BufferSize:     p.connOpts.SubscriptionBufferSize,
+			OverflowPolicy: p.connOpts.OverflowPolicy,
+		}
+	}
+	if p.redialInterval <= 0 {
+		p.redialInterval = DefaultReconnectInitialBackoff
+	}
+	connectionsPerEndpoint := opts.ConnectionsPerEndpoint
+	if connectionsPerEndpoint <= 0 {
+		connectionsPerEndpoint = 1
+	}
+
+	for _, endpoint := range endpoints {
+		for i := 0; i < connectionsPerEndpoint; i++ {
+			client, err := ConnectWithOptions(ctx, endpoint, p.connOpts)
+			if err != nil {
+				for _, member := range p.members {
+					member.client.Close()
+				}
+				return nil, fmt.Errorf("unable to connect to %s: %w", endpoint, err)
+			}
+			p.members = append(p.members, &poolMember{endpoint: endpoint, client: client})
+		}
+	}
+
+	p.ctx, p.cancel = context.WithCancel(context.Background())
+	for _, member := range p.members {
+		p.wg.Add(1)
+		go p.watch(member, member.client)
+	}
+	return p, nil
+}
+
+// Connections returns the state of the connections of the pool.
+func (p *Pool) Connections() []PoolConnection {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	out := make([]PoolConnection, len(p.members))
+	for i, member := range p.members {
+		out[i] = PoolConnection{
+			Endpoint:      member.endpoint,
+			Subscriptions: member.load,
+			Alive:         member.client != nil && !member.client.isLost(),
+		}
+	}
+	return out
+}
+
+// Close closes all the subscriptions, and the connections.
+func (p *Pool) Close() {
+	p.cancel()
+	p.lock.Lock()
+	subs := make([]*subscription, 0, len(p.subs))
+	for sub := range p.subs {
+		subs = append(subs, sub)
+	}
+	p.lock.Unlock()
+	for _, sub := range subs {
+		sub.Unsubscribe()
+	}
+	p.wg.Wait()
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	for _, member := range p.members {
+		if member.client != nil {
+			member.client.Close()
+			member.client = nil
+		}
+	}
+}
+
+// watch redials the connection of member once client is lost.
+func (p *Pool) watch(member *poolMember, client *Client) {
+	defer p.wg.Done()
+	select {
+	case <-p.ctx.Done():
+		return
+	case <-client.lost:
+	}
+
+	p.lock.Lock()
+	member.client = nil
+	p.lock.Unlock()
+	client.Close()
+	zlog.Info("ws pool connection lost, redialing", zap.String("endpoint", member.endpoint))
+
+	backoff := p.redialInterval
+	for {
+		if !sleepContext(p.ctx, backoff) {
+			return
+		}
+		backoff = nextBackoff(backoff)
+
+		client, err := ConnectWithOptions(p.ctx, member.endpoint, p.connOpts)
+		if err != nil {
+			zlog.Debug("ws pool redial failed", zap.String("endpoint", member.endpoint), zap.Error(err))
+			continue
+		}
+		p.lock.Lock()
+		if p.ctx.Err() != nil {
+			p.lock.Unlock()
+			client.Close()
+			return
+		}
+		member.client = client
+		p.lock.Unlock()
+
+		p.wg.Add(1)
+		go p.watch(member, client)
+		return
+	}
+}
+
+func sleepContext(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+func nextBackoff(backoff time.Duration) time.Duration {
+	backoff *= 2
+	if backoff > DefaultReconnectMaxBackoff {
+		backoff = DefaultReconnectMaxBackoff
+	}
+	return backoff
+}
+
+// openFunc subscribes on a connection of the pool.
+type openFunc func(client *Client, subOpts []SubscriptionOption) (*subscription, error)
+
+// open subscribes on the connection with the fewest subscriptions.
+func (p *Pool) open(fn openFunc, policy OverflowPolicy) (*poolMember, *Client, *subscription, error) {
+	p.lock.Lock()
+	var member *poolMember
+	for _, candidate := range p.members {
+		if candidate.client == nil || candidate.client.isLost() {
+			continue
+		}
+		if p.maxSubscriptionsPerConnection > 0 && candidate.load >= p.maxSubscriptionsPerConnection {
+			continue
+		}
+		if member == nil || candidate.load < member.load {
+			member = candidate
+		}
+	}
+	if member == nil {
+		p.lock.Unlock()
+		return nil, nil, nil, ErrPoolUnavailable
+	}
+	member.load++
+	client := member.client
+	p.lock.Unlock()
+
+	// The notifications are buffered by the subscription of the pool;
+	// the one on the connection applies its overflow policy too,
+	// so that only OverflowBlock can delay the other subscriptions.
+	sub, err := fn(client, []SubscriptionOption{
+		WithBufferSize(poolConnectionBufferSize),
+		WithOverflowPolicy(policy),
+	})
+	if err != nil {
+		p.release(member)
+		return nil, nil, nil, err
+	}
+	return member, client, sub, nil
+}
+
+func (p *Pool) release(member *poolMember) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	member.load--
+}
+
+// subscribe opens a subscription of the pool, forwarding
+// the notifications of a subscription on one of the connections.
+func (p *Pool) subscribe(subOpts []SubscriptionOption, fn openFunc) (*subscription, error) {
+	opts := p.subscriptionOpts
+	for _, apply := range subOpts {
+		apply(&opts)
+	}
+	member, client, inner, err := p.open(fn, opts.OverflowPolicy)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(p.ctx)
+	up := newUpstream(nil, "")
+	up.confirm(nil)
+	sub := newSubscription(up, nil, nil, opts)
+	sub.closeFunc = func(error) {
+		cancel()
+		p.lock.Lock()
+		delete(p.subs, sub)
+		p.lock.Unlock()
+	}
+
+	p.lock.Lock()
+	if p.ctx.Err() != nil {
+		p.lock.Unlock()
+		cancel()
+		inner.Unsubscribe()
+		p.release(member)
+		return nil, errors.New("pool closed")
+	}
+	p.subs[sub] = struct{}{}
+	p.wg.Add(1)
+	p.lock.Unlock()
+
+	go p.forward(ctx, sub, member, client, inner, fn, opts.OverflowPolicy)
+	return sub, nil
+}
+
+// forward delivers the notifications of inner to sub,
+// moving inner to another connection when its connection is lost.
+func (p *Pool) forward(ctx context.Context, sub *subscription, member *poolMember, client *Client, inner *subscription, fn openFunc, policy OverflowPolicy) {
+	defer p.wg.Done()
+	for {
+		d, err := inner.Recv(ctx)
+		if err == nil {
+			if !sub.push(d) {
+				sub.fail(fmt.Errorf("reached channel max capacity %d", len(sub.stream)))
+				// Forgets sub, which remains readable until unsubscribed.
+				sub.closeFunc(nil)
+				inner.Unsubscribe()
+				p.release(member)
+				return
+			}
+			continue
+		}
+
+		inner.Unsubscribe()
+		p.release(member)
+		if ctx.Err() != nil {
+			return
+		}
+		select {
+		case <-client.lost:
+		default:
+			// Not a connection failure: rejected by the server,
+			// not decodable, or overflowed.
+			sub.fail(err)
+			sub.closeFunc(nil)
+			return
+		}
+
+		backoff := p.redialInterval
+		for {
+			member, client, inner, err = p.open(fn, policy)
+			if err == nil {
+				break
+			}
+			zlog.Debug("unable to move ws pool subscription", zap.Error(err))
+			if !sleepContext(ctx, backoff) {
+				return
+			}
+			backoff = nextBackoff(backoff)
+		}
+	}
+}
+
+// AccountSubscribe is Client.AccountSubscribe on a connection of the pool.
+func (p *Pool) AccountSubscribe(
+	account solana.PublicKey,
+	commitment rpc.CommitmentType,
+	subOpts ...SubscriptionOption,
+) (*AccountSubscription, error) {
+	return p.AccountSubscribeWithOpts(account, commitment, "", subOpts...)
+}
+
+// AccountSubscribeWithOpts is Client.AccountSubscribeWithOpts on a connection of the pool.
+func (p *Pool) AccountSubscribeWithOpts(
+	account solana.PublicKey,
+	commitment rpc.CommitmentType,
+	encoding solana.EncodingType,
+	subOpts ...SubscriptionOption,
+) (*AccountSubscription, error) {
+	sub, err := p.subscribe(subOpts, func(client *Client, subOpts []SubscriptionOption) (*subscription, error) {
+		sub, err := client.AccountSubscribeWithOpts(account, commitment, encoding, subOpts...)
+		if err != nil {
+			return nil, err
+		}
+		return sub.sub, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &AccountSubscription{
+		newTypedSubscription[*AccountResult](sub),
+	}, nil
+}
+
+// ProgramSubscribe is Client.ProgramSubscribe on a connection of the pool.
+func (p *Pool) ProgramSubscribe(
+	programID solana.PublicKey,
+	commitment rpc.CommitmentType,
+	subOpts ...SubscriptionOption,
+) (*ProgramSubscription, error) {
+	return p.ProgramSubscribeWithOpts(programID, commitment, "", nil, subOpts...)
+}
+
+// ProgramSubscribeWithOpts is Client.ProgramSubscribeWithOpts on a connection of the pool.
+func (p *Pool) ProgramSubscribeWithOpts(
+	programID solana.PublicKey,
+	commitment rpc.CommitmentType,
+	encoding solana.EncodingType,
+	filters []rpc.RPCFilter,
+	subOpts ...SubscriptionOption,
+) (*ProgramSubscription, error) {
+	sub, err := p.subscribe(subOpts, func(client *Client, subOpts []SubscriptionOption) (*subscription, error) {
+		sub, err := client.ProgramSubscribeWithOpts(programID, commitment, encoding, filters, subOpts...)
+		if err != nil {
+			return nil, err
+		}
+		return sub.sub, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &ProgramSubscription{
+		newTypedSubscription[*ProgramResult](sub),
+	}, nil
+}
+
+// SignatureSubscribe is Client.SignatureSubscribe on a connection of the pool.
+func (p *Pool) SignatureSubscribe(
+	signature solana.Signature,
+	commitment rpc.CommitmentType,
+	subOpts ...SubscriptionOption,
+) (*SignatureSubscription, error) {
+	sub, err := p.subscribe(subOpts, func(client *Client, subOpts []SubscriptionOption) (*subscription, error) {
+		sub, err := client.SignatureSubscribe(signature, commitment, subOpts...)
+		if err != nil {
+			return nil, err
+		}
+		return sub.sub, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &SignatureSubscription{
+		newTypedSubscription[*SignatureResult](sub),
+	}, nil
+}
+
+// SlotSubscribe is Client.SlotSubscribe on a connection of the pool.
+func (p *Pool) SlotSubscribe(subOpts ...SubscriptionOption) (*SlotSubscription, error) {
+	sub, err := p.subscribe(subOpts, func(client *Client, subOpts []SubscriptionOption) (*subscription, error) {
+		sub, err := client.SlotSubscribe(subOpts...)
+		if err != nil {
+			return nil, err
+		}
+		return sub.sub, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &SlotSubscription{
+		newTypedSubscription[*SlotResult](sub),
+	}, nil
+}
+
+// LogsSubscribe is Client.LogsSubscribe on a connection of the pool.
+func (p *Pool) LogsSubscribe(
+	filter LogsSubscribeFilterType,
+	commitment rpc.CommitmentType,
+	subOpts ...SubscriptionOption,
+) (*LogSubscription, error) {
+	return p.logsSubscribe(subOpts, func(client *Client, subOpts []SubscriptionOption) (*LogSubscription, error) {
+		return client.LogsSubscribe(filter, commitment, subOpts...)
+	})
+}
+
+// LogsSubscribeMentions is Client.LogsSubscribeMentions on a connection of the pool.
+func (p *Pool) LogsSubscribeMentions(
+	mentions solana.PublicKey,
+	commitment rpc.CommitmentType,
+	subOpts ...SubscriptionOption,
+) (*LogSubscription, error) {
+	return p.logsSubscribe(subOpts, func(client *Client, subOpts []SubscriptionOption) (*LogSubscription, error) {
+		return client.LogsSubscribeMentions(mentions, commitment, subOpts...)
+	})
+}
+
+func (p *Pool) logsSubscribe(
+	subOpts []SubscriptionOption,
+	fn func(client *Client, subOpts []SubscriptionOption) (*LogSubscription, error),
+) (*LogSubscription, error) {
+	sub, err := p.subscribe(subOpts, func(client *Client, subOpts []SubscriptionOption) (*subscription, error) {
+		sub, err := fn(client, subOpts)
+		if err != nil {
+			return nil, err
+		}
+		return sub.sub, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &LogSubscription{
+		newTypedSubscription[*LogResult](sub),
+	}, nil
+}
@@ -0,0 +1,159 @@
+// Copyright 2021 github.com/gagliardetto
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ws
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+	"github.com/gagliardetto/solana-go/rpc/rpctest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPool(t *testing.T) {
+	first := rpctest.NewServer()
+	defer first.Close()
+	second := rpctest.NewServer()
+	defer second.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	pool, err := NewPool(ctx, []string{first.WSURL, second.WSURL}, &PoolOptions{
+		RedialInterval: 200 * time.Millisecond,
+	})
+	require.NoError(t, err)
+	defer pool.Close()
+
+	owner := solana.NewWallet().PublicKey()
+	pubkeys := make([]solana.PublicKey, 4)
+	subs := make([]*AccountSubscription, 4)
+	for i := range subs {
+		pubkeys[i] = solana.NewWallet().PublicKey()
+		subs[i], err = pool.AccountSubscribe(pubkeys[i], rpc.CommitmentConfirmed)
+		require.NoError(t, err)
+	}
+
+	// Spread by load.
+	require.Eventually(t, func() bool {
+		return first.SubscriptionCount() == 2 && second.SubscriptionCount() == 2
+	}, 5*time.Second, 5*time.Millisecond)
+	for _, conn := range pool.Connections() {
+		assert.Equal(t, 2, conn.Subscriptions)
+		assert.True(t, conn.Alive)
+	}
+
+	// The subscriptions of the lost connection move to the other one.
+	first.CloseConnections()
+	require.Eventually(t, func() bool { return second.SubscriptionCount() == 4 }, 5*time.Second, 5*time.Millisecond)
+	for i, sub := range subs {
+		second.SetAccount(pubkeys[i], rpctest.Account{Lamports: uint64(i + 1), Owner: owner})
+		got, err := sub.Recv(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, uint64(i+1), got.Value.Lamports)
+	}
+
+	// The lost connection is redialed, and takes the new subscriptions.
+	require.Eventually(t, func() bool { return pool.Connections()[0].Alive }, 5*time.Second, 5*time.Millisecond)
+	assert.Equal(t, []PoolConnection{
+		{Endpoint: first.WSURL, Subscriptions: 0, Alive: true},
+		{Endpoint: second.WSURL, Subscriptions: 4, Alive: true},
+	}, pool.Connections())
+	slotSub, err := pool.SlotSubscribe()
+	require.NoError(t, err)
+	require.Eventually(t, func() bool { return first.SubscriptionCount() == 1 }, 5*time.Second, 5*time.Millisecond)
+
+	first.SetSlot(42, 40)
+	got, err := slotSub.Recv(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(42), got.Slot)
+
+	slotSub.Unsubscribe()
+	subs[0].Unsubscribe()
+	require.Eventually(t, func() bool {
+		return first.SubscriptionCount() == 0 && second.SubscriptionCount() == 3
+	}, 5*time.Second, 5*time.Millisecond)
+	assert.Equal(t, 3, pool.Connections()[1].Subscriptions)
+
+	pool.Close()
+	_, err = subs[1].Recv(ctx)
+	assert.ErrorIs(t, err, ErrSubscriptionClosed)
+}
+
+func TestPool_MaxSubscriptionsPerConnection(t *testing.T) {
+	srv := rpctest.NewServer()
+	defer srv.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	pool, err := NewPool(ctx, []string{srv.WSURL}, &PoolOptions{
+		ConnectionsPerEndpoint:        2,
+		MaxSubscriptionsPerConnection: 1,
+	})
+	require.NoError(t, err)
+	defer pool.Close()
+
+	for i := 0; i < 2; i++ {
+		_, err := pool.SlotSubscribe()
+		require.NoError(t, err)
+	}
+	_, err = pool.SlotSubscribe()
+	assert.ErrorIs(t, err, ErrPoolUnavailable)
+	require.Eventually(t, func() bool { return srv.SubscriptionCount() == 2 }, 5*time.Second, 5*time.Millisecond)
+}
+
+func TestPool_Overflow(t *testing.T) {
+	srv := rpctest.NewServer()
+	defer srv.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	pool, err := NewPool(ctx, []string{srv.WSURL}, &PoolOptions{
+		Options: &Options{SubscriptionBufferSize: 1},
+	})
+	require.NoError(t, err)
+	defer pool.Close()
+
+	slow, err := pool.SlotSubscribe()
+	require.NoError(t, err)
+	fast, err := pool.SlotSubscribe(WithBufferSize(100))
+	require.NoError(t, err)
+	require.Eventually(t, func() bool { return srv.SubscriptionCount() == 2 }, 5*time.Second, 5*time.Millisecond)
+
+	// The slow subscription does not delay the other one.
+	for slot := uint64(1); slot <= 10; slot++ {
+		srv.SetSlot(slot, slot-1)
+		got, err := fast.Recv(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, slot, got.Slot)
+	}
+
+	// It is closed, and forgotten by the pool.
+	for {
+		_, err = slow.Recv(ctx)
+		if err != nil {
+			break
+		}
+	}
+	assert.Contains(t, err.Error(), "reached channel max capacity")
+	require.Eventually(t, func() bool { return srv.SubscriptionCount() == 1 }, 5*time.Second, 5*time.Millisecond)
+	assert.Equal(t, 1, pool.Connections()[0].Subscriptions)
+	pool.lock.Lock()
+	assert.Len(t, pool.subs, 1)
+	pool.lock.Unlock()
+}
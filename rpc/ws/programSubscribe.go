@@ -33,15 +33,29 @@ type ProgramResult struct {
 func (cl *Client) ProgramSubscribe(
 	programID solana.PublicKey,
 	commitment rpc.CommitmentType,
+	subOpts ...SubscriptionOption,
 ) (*ProgramSubscription, error) {
 	return cl.ProgramSubscribeWithOpts(
 		programID,
 		commitment,
 		"",
 		nil,
+		subOpts...,
 	)
 }
 
+// ProgramSubscribeContext is like ProgramSubscribe, but waits for the server
+// to accept the subscription; if ctx is done first, the subscription is cancelled.
+func (cl *Client) ProgramSubscribeContext(
+	ctx context.Context,
+	programID solana.PublicKey,
+	commitment rpc.CommitmentType,
+	subOpts ...SubscriptionOption,
+) (*ProgramSubscription, error) {
+	sub, err := cl.ProgramSubscribe(programID, commitment, subOpts...)
+	return confirmed(ctx, sub, err)
+}
+
 // ProgramSubscribe subscribes to a program to receive notifications
 // when the lamports or data for a given account owned by the program changes.
 func (cl *Client) ProgramSubscribeWithOpts(
@@ -49,6 +63,7 @@ func (cl *Client) ProgramSubscribeWithOpts(
 	commitment rpc.CommitmentType,
 	encoding solana.EncodingType,
 	filters []rpc.RPCFilter,
+	subOpts ...SubscriptionOption,
 ) (*ProgramSubscription, error) {
 
 	params := []interface{}{programID.String()}
@@ -75,50 +90,30 @@ func (cl *Client) ProgramSubscribeWithOpts(
 			err := decodeResponseFromMessage(msg, &res)
 			return &res, err
 		},
+		subOpts,
 	)
 	if err != nil {
 		return nil, err
 	}
 	return &ProgramSubscription{
-		sub: genSub,
+		newTypedSubscription[*ProgramResult](genSub),
 	}, nil
 }
 
-type ProgramSubscription struct {
-	sub *Subscription
-}
-
-func (sw *ProgramSubscription) Recv(ctx context.Context) (*ProgramResult, error) {
-	select {
-	case <-ctx.Done():
-		return nil, ctx.Err()
-	case d, ok := <-sw.sub.stream:
-		if !ok {
-			return nil, ErrSubscriptionClosed
-		}
-		return d.(*ProgramResult), nil
-	case err := <-sw.sub.err:
-		return nil, err
-	}
-}
-
-func (sw *ProgramSubscription) Err() <-chan error {
-	return sw.sub.err
-}
-
-func (sw *ProgramSubscription) Response() <-chan *ProgramResult {
-	typedChan := make(chan *ProgramResult, 1)
-	go func(ch chan *ProgramResult) {
-		// TODO: will this subscription yield more than one result?
-		d, ok := <-sw.sub.stream
-		if !ok {
-			return
-		}
-		ch <- d.(*ProgramResult)
-	}(typedChan)
-	return typedChan
+// ProgramSubscribeWithOptsContext is like ProgramSubscribeWithOpts, but waits for the server
+// to accept the subscription; if ctx is done first, the subscription is cancelled.
+func (cl *Client) ProgramSubscribeWithOptsContext(
+	ctx context.Context,
+	programID solana.PublicKey,
+	commitment rpc.CommitmentType,
+	encoding solana.EncodingType,
+	filters []rpc.RPCFilter,
+	subOpts ...SubscriptionOption,
+) (*ProgramSubscription, error) {
+	sub, err := cl.ProgramSubscribeWithOpts(programID, commitment, encoding, filters, subOpts...)
+	return confirmed(ctx, sub, err)
 }
 
-func (sw *ProgramSubscription) Unsubscribe() {
-	sw.sub.Unsubscribe()
+type ProgramSubscription struct {
+	*Subscription[*ProgramResult]
 }
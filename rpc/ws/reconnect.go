@@ -0,0 +1,151 @@
+// Copyright 2021 github.com/gagliardetto
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ws
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"go.uber.org/zap"
+)
+
+var (
+	DefaultReconnectInitialBackoff = 500 * time.Millisecond
+	DefaultReconnectMaxBackoff     = 30 * time.Second
+)
+
+// ReconnectOptions configures the automatic reconnection of a Client.
+type ReconnectOptions struct {
+	// Delay before the second attempt (the first one is immediate);
+	// it doubles after each failed attempt, up to MaxBackoff.
+	// Defaults to DefaultReconnectInitialBackoff and DefaultReconnectMaxBackoff.
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+
+	// Maximum number of attempts per disconnection (0: unlimited).
+	// Once exhausted, all the subscriptions fail with the connection error.
+	MaxAttempts int
+
+	// Optional; called once reconnected and resubscribed.
+	// Notifications may have been missed in the meantime.
+	// Must not block: the messages are not read until it returns.
+	OnReconnect func(ReconnectEvent)
+}
+
+// ReconnectEvent describes a reconnection.
+type ReconnectEvent struct {
+	// The error that closed the previous connection.
+	Err error
+
+	// Number of attempts needed to reconnect.
+	Attempts int
+
+	DisconnectedAt time.Time
+	ReconnectedAt  time.Time
+
+	// Number of subscriptions issued again on the new connection.
+	Resubscribed int
+}
+
+// reconnectAndResubscribe replaces the lost connection, and issues again
+// all the active subscriptions on the new one; their new server
+// subscription IDs are registered as the responses arrive.
+func (c *Client) reconnectAndResubscribe(cause error) (*websocket.Conn, error) {
+	event := ReconnectEvent{
+		Err:            cause,
+		DisconnectedAt: time.Now(),
+	}
+	zlog.Info("ws connection lost, reconnecting", zap.Error(cause))
+
+	backoff := c.reconnect.InitialBackoff
+	for attempt := 1; ; attempt++ {
+		if c.reconnect.MaxAttempts > 0 && attempt > c.reconnect.MaxAttempts {
+			return nil, fmt.Errorf("unable to reconnect after %d attempts: %w", c.reconnect.MaxAttempts, cause)
+		}
+		if attempt > 1 {
+			timer := time.NewTimer(backoff)
+			select {
+			case <-c.connCtx.Done():
+				timer.Stop()
+				return nil, c.connCtx.Err()
+			case <-timer.C:
+			}
+			backoff *= 2
+			if backoff > c.reconnect.MaxBackoff {
+				backoff = c.reconnect.MaxBackoff
+			}
+		}
+
+		conn, err := c.dial(c.connCtx)
+		if err != nil {
+			zlog.Debug("ws reconnection attempt failed", zap.Int("attempt", attempt), zap.Error(err))
+			continue
+		}
+		resubscribed, err := c.swapConn(conn)
+		if err != nil {
+			conn.Close()
+			if c.connCtx.Err() != nil {
+				return nil, c.connCtx.Err()
+			}
+			zlog.Debug("ws resubscription failed", zap.Int("attempt", attempt), zap.Error(err))
+			continue
+		}
+
+		event.Attempts = attempt
+		event.ReconnectedAt = time.Now()
+		event.Resubscribed = resubscribed
+		zlog.Info("ws reconnected",
+			zap.Int("attempts", attempt),
+			zap.Int("subscription_count", resubscribed),
+		)
+		if c.reconnect.OnReconnect != nil {
+			c.reconnect.OnReconnect(event)
+		}
+		return conn, nil
+	}
+}
+
+// swapConn makes conn the connection of the client,
+// and sends the subscription requests on it.
+func (c *Client) swapConn(conn *websocket.Conn) (int, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if err := c.connCtx.Err(); err != nil {
+		// Closed in the meantime.
+		return 0, err
+	}
+
+	for _, up := range c.subscriptionByRequestID {
+		data, err := up.req.encode()
+		if err != nil {
+			return 0, err
+		}
+		conn.SetWriteDeadline(time.Now().Add(writeWait))
+		if err := conn.WriteMessage(websocket.TextMessage, data); err != nil {
+			return 0, fmt.Errorf("unable to write request: %w", err)
+		}
+	}
+
+	c.conn.Close()
+	c.conn = conn
+	// The previous server subscription IDs are meaningless now.
+	c.subscriptionByWSSubID = map[uint64]*upstream{}
+	for _, up := range c.subscriptionByRequestID {
+		up.registered = false
+	}
+	c.cancelledRequests = map[uint64]string{}
+	return len(c.subscriptionByRequestID), nil
+}
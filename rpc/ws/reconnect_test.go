@@ -0,0 +1,196 @@
+// Copyright 2021 github.com/gagliardetto
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ws
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+	"github.com/gagliardetto/solana-go/rpc/rpctest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_Reconnect(t *testing.T) {
+	srv := rpctest.NewServer()
+	defer srv.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	events := make(chan ReconnectEvent, 10)
+	client, err := ConnectWithOptions(ctx, srv.WSURL, &Options{
+		Reconnect: &ReconnectOptions{
+			InitialBackoff: 10 * time.Millisecond,
+			OnReconnect:    func(event ReconnectEvent) { events <- event },
+		},
+	})
+	require.NoError(t, err)
+	defer client.Close()
+
+	pubkey := solana.NewWallet().PublicKey()
+	owner := solana.NewWallet().PublicKey()
+	accountSub, err := client.AccountSubscribe(pubkey, rpc.CommitmentConfirmed)
+	require.NoError(t, err)
+	slotSub, err := client.SlotSubscribe()
+	require.NoError(t, err)
+	require.Eventually(t, func() bool { return srv.SubscriptionCount() == 2 }, 5*time.Second, 5*time.Millisecond)
+
+	srv.SetAccount(pubkey, rpctest.Account{Lamports: 1, Owner: owner})
+	got, err := accountSub.Recv(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1), got.Value.Lamports)
+
+	// The connection drops: the subscriptions are issued again.
+	srv.CloseConnections()
+	select {
+	case event := <-events:
+		assert.Equal(t, 2, event.Resubscribed)
+		assert.Error(t, event.Err)
+		assert.GreaterOrEqual(t, event.Attempts, 1)
+	case <-ctx.Done():
+		t.Fatal("no reconnection")
+	}
+	require.Eventually(t, func() bool { return srv.SubscriptionCount() == 2 }, 5*time.Second, 5*time.Millisecond)
+
+	// The existing subscriptions receive the new notifications.
+	srv.SetAccount(pubkey, rpctest.Account{Lamports: 2, Owner: owner})
+	got, err = accountSub.Recv(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(2), got.Value.Lamports)
+
+	srv.SetSlot(42, 40)
+	for {
+		slot, err := slotSub.Recv(ctx)
+		require.NoError(t, err)
+		if slot.Slot == 42 {
+			break
+		}
+	}
+
+	// Subscriptions made after the reconnection work too.
+	rootSub, err := client.RootSubscribe()
+	require.NoError(t, err)
+	require.Eventually(t, func() bool { return srv.SubscriptionCount() == 3 }, 5*time.Second, 5*time.Millisecond)
+	rootSub.Unsubscribe()
+	require.Eventually(t, func() bool { return srv.SubscriptionCount() == 2 }, 5*time.Second, 5*time.Millisecond)
+}
+
+func TestClient_ReconnectGivesUp(t *testing.T) {
+	srv := rpctest.NewServer()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	client, err := ConnectWithOptions(ctx, srv.WSURL, &Options{
+		Reconnect: &ReconnectOptions{
+			InitialBackoff: time.Millisecond,
+			MaxAttempts:    3,
+		},
+	})
+	require.NoError(t, err)
+	defer client.Close()
+
+	sub, err := client.SlotSubscribe()
+	require.NoError(t, err)
+	require.Eventually(t, func() bool { return srv.SubscriptionCount() == 1 }, 5*time.Second, 5*time.Millisecond)
+
+	srv.Close()
+	_, err = sub.Recv(ctx)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unable to reconnect after 3 attempts")
+}
+
+func TestClient_NoReconnect(t *testing.T) {
+	srv := rpctest.NewServer()
+	defer srv.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	client, err := Connect(ctx, srv.WSURL)
+	require.NoError(t, err)
+	defer client.Close()
+
+	sub, err := client.SlotSubscribe()
+	require.NoError(t, err)
+	require.Eventually(t, func() bool { return srv.SubscriptionCount() == 1 }, 5*time.Second, 5*time.Millisecond)
+
+	srv.CloseConnections()
+	_, err = sub.Recv(ctx)
+	require.Error(t, err)
+	assert.NotErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestClient_UnsubscribeWhileResubscribing(t *testing.T) {
+	srv := rpctest.NewServer()
+	defer srv.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	subs := make(chan *SlotSubscription, 1)
+	reconnected := make(chan struct{})
+	client, err := ConnectWithOptions(ctx, srv.WSURL, &Options{
+		Reconnect: &ReconnectOptions{
+			InitialBackoff: 10 * time.Millisecond,
+			OnReconnect: func(ReconnectEvent) {
+				// The subscription request is sent again,
+				// but its response is not read yet.
+				(<-subs).Unsubscribe()
+				close(reconnected)
+			},
+		},
+	})
+	require.NoError(t, err)
+	defer client.Close()
+
+	sub, err := client.SlotSubscribe()
+	require.NoError(t, err)
+	subs <- sub
+	require.Eventually(t, func() bool { return srv.SubscriptionCount() == 1 }, 5*time.Second, 5*time.Millisecond)
+
+	srv.CloseConnections()
+	select {
+	case <-reconnected:
+	case <-ctx.Done():
+		t.Fatal("no reconnection")
+	}
+	// The new subscription on the server is closed once confirmed.
+	require.Eventually(t, func() bool { return srv.SubscriptionCount() == 0 }, 5*time.Second, 5*time.Millisecond)
+}
+
+func TestClient_CloseFailsSubscriptions(t *testing.T) {
+	srv := rpctest.NewServer()
+	defer srv.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	for _, reconnect := range []*ReconnectOptions{nil, {InitialBackoff: time.Millisecond}} {
+		client, err := ConnectWithOptions(ctx, srv.WSURL, &Options{Reconnect: reconnect})
+		require.NoError(t, err)
+
+		sub, err := client.SlotSubscribe()
+		require.NoError(t, err)
+		client.Close()
+
+		_, err = sub.Recv(ctx)
+		assert.ErrorIs(t, err, ErrSubscriptionClosed)
+		select {
+		case <-client.lost:
+		case <-ctx.Done():
+			t.Fatal("lost not closed")
+		}
+	}
+}
@@ -20,7 +20,7 @@ type RootResult uint64
 
 // SignatureSubscribe subscribes to receive notification
 // anytime a new root is set by the validator.
-func (cl *Client) RootSubscribe() (*RootSubscription, error) {
+func (cl *Client) RootSubscribe(subOpts ...SubscriptionOption) (*RootSubscription, error) {
 	genSub, err := cl.subscribe(
 		nil,
 		nil,
@@ -31,50 +31,26 @@ func (cl *Client) RootSubscribe() (*RootSubscription, error) {
 			err := decodeResponseFromMessage(msg, &res)
 			return &res, err
 		},
+		subOpts,
 	)
 	if err != nil {
 		return nil, err
 	}
 	return &RootSubscription{
-		sub: genSub,
+		newTypedSubscription[*RootResult](genSub),
 	}, nil
 }
 
-type RootSubscription struct {
-	sub *Subscription
-}
-
-func (sw *RootSubscription) Recv(ctx context.Context) (*RootResult, error) {
-	select {
-	case <-ctx.Done():
-		return nil, ctx.Err()
-	case d, ok := <-sw.sub.stream:
-		if !ok {
-			return nil, ErrSubscriptionClosed
-		}
-		return d.(*RootResult), nil
-	case err := <-sw.sub.err:
-		return nil, err
-	}
+// RootSubscribeContext is like RootSubscribe, but waits for the server
+// to accept the subscription; if ctx is done first, the subscription is cancelled.
+func (cl *Client) RootSubscribeContext(
+	ctx context.Context,
+	subOpts ...SubscriptionOption,
+) (*RootSubscription, error) {
+	sub, err := cl.RootSubscribe(subOpts...)
+	return confirmed(ctx, sub, err)
 }
 
-func (sw *RootSubscription) Err() <-chan error {
-	return sw.sub.err
-}
-
-func (sw *RootSubscription) Response() <-chan *RootResult {
-	typedChan := make(chan *RootResult, 1)
-	go func(ch chan *RootResult) {
-		// TODO: will this subscription yield more than one result?
-		d, ok := <-sw.sub.stream
-		if !ok {
-			return
-		}
-		ch <- d.(*RootResult)
-	}(typedChan)
-	return typedChan
-}
-
-func (sw *RootSubscription) Unsubscribe() {
-	sw.sub.Unsubscribe()
+type RootSubscription struct {
+	*Subscription[*RootResult]
 }
@@ -16,6 +16,7 @@ package ws
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
@@ -38,6 +39,7 @@ type SignatureResult struct {
 func (cl *Client) SignatureSubscribe(
 	signature solana.Signature, // Transaction Signature.
 	commitment rpc.CommitmentType, // (optional)
+	subOpts ...SubscriptionOption,
 ) (*SignatureSubscription, error) {
 	params := []interface{}{signature.String()}
 	conf := map[string]interface{}{}
@@ -55,63 +57,40 @@ func (cl *Client) SignatureSubscribe(
 			err := decodeResponseFromMessage(msg, &res)
 			return &res, err
 		},
+		subOpts,
 	)
 	if err != nil {
 		return nil, err
 	}
 	return &SignatureSubscription{
-		sub: genSub,
+		newTypedSubscription[*SignatureResult](genSub),
 	}, nil
 }
 
-type SignatureSubscription struct {
-	sub *Subscription
-}
-
-func (sw *SignatureSubscription) Recv(ctx context.Context) (*SignatureResult, error) {
-	select {
-	case <-ctx.Done():
-		return nil, ctx.Err()
-	case d, ok := <-sw.sub.stream:
-		if !ok {
-			return nil, ErrSubscriptionClosed
-		}
-		return d.(*SignatureResult), nil
-	case err := <-sw.sub.err:
-		return nil, err
-	}
-}
-
-func (sw *SignatureSubscription) Err() <-chan error {
-	return sw.sub.err
+// SignatureSubscribeContext is like SignatureSubscribe, but waits for the server
+// to accept the subscription; if ctx is done first, the subscription is cancelled.
+func (cl *Client) SignatureSubscribeContext(
+	ctx context.Context,
+	signature solana.Signature,
+	commitment rpc.CommitmentType,
+	subOpts ...SubscriptionOption,
+) (*SignatureSubscription, error) {
+	sub, err := cl.SignatureSubscribe(signature, commitment, subOpts...)
+	return confirmed(ctx, sub, err)
 }
 
-func (sw *SignatureSubscription) Response() <-chan *SignatureResult {
-	typedChan := make(chan *SignatureResult, 1)
-	go func(ch chan *SignatureResult) {
-		// TODO: will this subscription yield more than one result?
-		d, ok := <-sw.sub.stream
-		if !ok {
-			return
-		}
-		ch <- d.(*SignatureResult)
-	}(typedChan)
-	return typedChan
+type SignatureSubscription struct {
+	*Subscription[*SignatureResult]
 }
 
 var ErrTimeout = fmt.Errorf("timeout waiting for confirmation")
 
 func (sw *SignatureSubscription) RecvWithTimeout(timeout time.Duration) (*SignatureResult, error) {
-	select {
-	case <-time.After(timeout):
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	got, err := sw.Recv(ctx)
+	if errors.Is(err, context.DeadlineExceeded) {
 		return nil, ErrTimeout
-	case d := <-sw.sub.stream:
-		return d.(*SignatureResult), nil
-	case err := <-sw.sub.err:
-		return nil, err
 	}
-}
-
-func (sw *SignatureSubscription) Unsubscribe() {
-	sw.sub.Unsubscribe()
+	return got, err
 }
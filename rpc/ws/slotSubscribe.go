@@ -23,7 +23,7 @@ type SlotResult struct {
 }
 
 // SlotSubscribe subscribes to receive notification anytime a slot is processed by the validator.
-func (cl *Client) SlotSubscribe() (*SlotSubscription, error) {
+func (cl *Client) SlotSubscribe(subOpts ...SubscriptionOption) (*SlotSubscription, error) {
 	genSub, err := cl.subscribe(
 		nil,
 		nil,
@@ -34,50 +34,26 @@ func (cl *Client) SlotSubscribe() (*SlotSubscription, error) {
 			err := decodeResponseFromMessage(msg, &res)
 			return &res, err
 		},
+		subOpts,
 	)
 	if err != nil {
 		return nil, err
 	}
 	return &SlotSubscription{
-		sub: genSub,
+		newTypedSubscription[*SlotResult](genSub),
 	}, nil
 }
 
-type SlotSubscription struct {
-	sub *Subscription
-}
-
-func (sw *SlotSubscription) Recv(ctx context.Context) (*SlotResult, error) {
-	select {
-	case <-ctx.Done():
-		return nil, ctx.Err()
-	case d, ok := <-sw.sub.stream:
-		if !ok {
-			return nil, ErrSubscriptionClosed
-		}
-		return d.(*SlotResult), nil
-	case err := <-sw.sub.err:
-		return nil, err
-	}
+// SlotSubscribeContext is like SlotSubscribe, but waits for the server
+// to accept the subscription; if ctx is done first, the subscription is cancelled.
+func (cl *Client) SlotSubscribeContext(
+	ctx context.Context,
+	subOpts ...SubscriptionOption,
+) (*SlotSubscription, error) {
+	sub, err := cl.SlotSubscribe(subOpts...)
+	return confirmed(ctx, sub, err)
 }
 
-func (sw *SlotSubscription) Err() <-chan error {
-	return sw.sub.err
-}
-
-func (sw *SlotSubscription) Response() <-chan *SlotResult {
-	typedChan := make(chan *SlotResult, 1)
-	go func(ch chan *SlotResult) {
-		// TODO: will this subscription yield more than one result?
-		d, ok := <-sw.sub.stream
-		if !ok {
-			return
-		}
-		ch <- d.(*SlotResult)
-	}(typedChan)
-	return typedChan
-}
-
-func (sw *SlotSubscription) Unsubscribe() {
-	sw.sub.Unsubscribe()
+type SlotSubscription struct {
+	*Subscription[*SlotResult]
 }
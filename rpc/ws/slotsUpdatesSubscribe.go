@@ -57,7 +57,7 @@ const (
 //
 // This subscription is unstable; the format of this subscription
 // may change in the future and it may not always be supported.
-func (cl *Client) SlotsUpdatesSubscribe() (*SlotsUpdatesSubscription, error) {
+func (cl *Client) SlotsUpdatesSubscribe(subOpts ...SubscriptionOption) (*SlotsUpdatesSubscription, error) {
 	genSub, err := cl.subscribe(
 		nil,
 		nil,
@@ -68,50 +68,26 @@ func (cl *Client) SlotsUpdatesSubscribe() (*SlotsUpdatesSubscription, error) {
 			err := decodeResponseFromMessage(msg, &res)
 			return &res, err
 		},
+		subOpts,
 	)
 	if err != nil {
 		return nil, err
 	}
 	return &SlotsUpdatesSubscription{
-		sub: genSub,
+		newTypedSubscription[*SlotsUpdatesResult](genSub),
 	}, nil
 }
 
-type SlotsUpdatesSubscription struct {
-	sub *Subscription
-}
-
-func (sw *SlotsUpdatesSubscription) Recv(ctx context.Context) (*SlotsUpdatesResult, error) {
-	select {
-	case <-ctx.Done():
-		return nil, ctx.Err()
-	case d, ok := <-sw.sub.stream:
-		if !ok {
-			return nil, ErrSubscriptionClosed
-		}
-		return d.(*SlotsUpdatesResult), nil
-	case err := <-sw.sub.err:
-		return nil, err
-	}
+// SlotsUpdatesSubscribeContext is like SlotsUpdatesSubscribe, but waits for the server
+// to accept the subscription; if ctx is done first, the subscription is cancelled.
+func (cl *Client) SlotsUpdatesSubscribeContext(
+	ctx context.Context,
+	subOpts ...SubscriptionOption,
+) (*SlotsUpdatesSubscription, error) {
+	sub, err := cl.SlotsUpdatesSubscribe(subOpts...)
+	return confirmed(ctx, sub, err)
 }
 
-func (sw *SlotsUpdatesSubscription) Err() <-chan error {
-	return sw.sub.err
-}
-
-func (sw *SlotsUpdatesSubscription) Response() <-chan *SlotsUpdatesResult {
-	typedChan := make(chan *SlotsUpdatesResult, 1)
-	go func(ch chan *SlotsUpdatesResult) {
-		// TODO: will this subscription yield more than one result?
-		d, ok := <-sw.sub.stream
-		if !ok {
-			return
-		}
-		ch <- d.(*SlotsUpdatesResult)
-	}(typedChan)
-	return typedChan
-}
-
-func (sw *SlotsUpdatesSubscription) Unsubscribe() {
-	sw.sub.Unsubscribe()
+type SlotsUpdatesSubscription struct {
+	*Subscription[*SlotsUpdatesResult]
 }
@@ -17,56 +17,358 @@
 
 package ws
 
-import "context"
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+)
 
-type Subscription struct {
-	req               *request
-	subID             uint64
-	stream            chan result
-	err               chan error
-	closeFunc         func(err error)
-	closed            bool
-	unsubscribeMethod string
-	decoderFunc       decoderFunc
+// OverflowPolicy defines what happens when a notification arrives
+// while the buffer of a subscription is full.
+type OverflowPolicy int
+
+const (
+	// OverflowClose closes the subscription with an error (default).
+	OverflowClose OverflowPolicy = iota
+
+	// OverflowBlock waits for the consumer to make room.
+	// This stops the reading of the connection, i.e. it delays
+	// the notifications of all the subscriptions of the client.
+	OverflowBlock
+
+	// OverflowDropOldest discards the oldest buffered notification.
+	OverflowDropOldest
+
+	// OverflowDropNewest discards the incoming notification.
+	OverflowDropNewest
+)
+
+// DefaultSubscriptionBufferSize is the default number of notifications
+// buffered per subscription.
+var DefaultSubscriptionBufferSize = 200_000
+
+// SubscriptionOptions configures the buffering of a subscription.
+type SubscriptionOptions struct {
+	// Number of notifications buffered (default: DefaultSubscriptionBufferSize).
+	BufferSize int
+
+	// What to do when the buffer is full (default: OverflowClose).
+	OverflowPolicy OverflowPolicy
+}
+
+// SubscriptionOption overrides the SubscriptionOptions
+// of the client (see Options) for one subscription.
+type SubscriptionOption func(*SubscriptionOptions)
+
+// WithBufferSize sets the number of notifications buffered by the subscription.
+func WithBufferSize(size int) SubscriptionOption {
+	return func(opts *SubscriptionOptions) {
+		opts.BufferSize = size
+	}
+}
+
+// WithOverflowPolicy sets what happens when the buffer of the subscription is full.
+func WithOverflowPolicy(policy OverflowPolicy) SubscriptionOption {
+	return func(opts *SubscriptionOptions) {
+		opts.OverflowPolicy = policy
+	}
+}
+
+// subscription is a local subscription, receiving
+// the notifications of a subscription on the server.
+type subscription struct {
+	up          *upstream
+	stream      chan result
+	err         chan error
+	closeFunc   func(err error)
+	closed      bool
+	decoderFunc decoderFunc
+
+	overflowPolicy OverflowPolicy
+	dropped        uint64 // atomic
+
+	// Guards closed, and the sends on stream and err.
+	sendMu    sync.Mutex
+	done      chan struct{}
+	closeOnce sync.Once
 }
 
 type decoderFunc func([]byte) (interface{}, error)
 
 func newSubscription(
-	req *request,
+	up *upstream,
 	closeFunc func(err error),
-	unsubscribeMethod string,
 	decoderFunc decoderFunc,
-) *Subscription {
-	return &Subscription{
-		req:               req,
-		subID:             0,
-		stream:            make(chan result, 200_000),
-		err:               make(chan error, 100_000),
-		closeFunc:         closeFunc,
-		unsubscribeMethod: unsubscribeMethod,
-		decoderFunc:       decoderFunc,
+	opts SubscriptionOptions,
+) *subscription {
+	if opts.BufferSize <= 0 {
+		opts.BufferSize = DefaultSubscriptionBufferSize
 	}
+	return &subscription{
+		up:             up,
+		stream:         make(chan result, opts.BufferSize),
+		err:            make(chan error, 1),
+		closeFunc:      closeFunc,
+		decoderFunc:    decoderFunc,
+		overflowPolicy: opts.OverflowPolicy,
+		done:           make(chan struct{}),
+	}
+}
+
+// Dropped returns the number of notifications discarded
+// because the buffer was full.
+func (s *subscription) Dropped() uint64 {
+	return atomic.LoadUint64(&s.dropped)
 }
 
-func (s *Subscription) Recv(ctx context.Context) (interface{}, error) {
+// push delivers a notification according to the overflow policy;
+// it returns false if the subscription must be closed.
+func (s *subscription) push(v result) bool {
+	s.sendMu.Lock()
+	defer s.sendMu.Unlock()
+	if s.closed {
+		return true
+	}
+
+	switch s.overflowPolicy {
+	case OverflowBlock:
+		select {
+		case s.stream <- v:
+		case <-s.done:
+		}
+	case OverflowDropOldest:
+		for {
+			select {
+			case s.stream <- v:
+				return true
+			default:
+			}
+			select {
+			case <-s.stream:
+				atomic.AddUint64(&s.dropped, 1)
+			default:
+			}
+		}
+	case OverflowDropNewest:
+		select {
+		case s.stream <- v:
+		default:
+			atomic.AddUint64(&s.dropped, 1)
+		}
+	default:
+		select {
+		case s.stream <- v:
+		default:
+			atomic.AddUint64(&s.dropped, 1)
+			return false
+		}
+	}
+	return true
+}
+
+// fail delivers the error terminating the subscription.
+func (s *subscription) fail(err error) {
+	if err == nil {
+		return
+	}
+	s.sendMu.Lock()
+	defer s.sendMu.Unlock()
+	if s.closed {
+		return
+	}
+	select {
+	case s.err <- err:
+	default:
+		// An error is already pending.
+	}
+}
+
+func (s *subscription) Recv(ctx context.Context) (interface{}, error) {
 	select {
 	case <-ctx.Done():
 		return nil, ctx.Err()
-	case d := <-s.stream:
+	case d, ok := <-s.stream:
+		if !ok {
+			return nil, ErrSubscriptionClosed
+		}
 		return d, nil
-	case err := <-s.err:
+	case err, ok := <-s.err:
+		if !ok {
+			return nil, ErrSubscriptionClosed
+		}
 		return nil, err
 	}
 }
 
-func (s *Subscription) Unsubscribe() {
+// waitConfirmed waits for the server to accept the subscription request.
+func (s *subscription) waitConfirmed(ctx context.Context) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-s.up.confirmed:
+		return s.up.confirmErr
+	case <-s.done:
+		return ErrSubscriptionClosed
+	case err, ok := <-s.err:
+		if !ok {
+			return ErrSubscriptionClosed
+		}
+		return err
+	}
+}
+
+func (s *subscription) Unsubscribe() {
 	s.unsubscribe(nil)
 }
 
-func (s *Subscription) unsubscribe(err error) {
+func (s *subscription) unsubscribe(err error) {
+	s.closeOnce.Do(func() {
+		// Unblocks a pending push.
+		close(s.done)
+	})
 	s.closeFunc(err)
+	s.sendMu.Lock()
+	defer s.sendMu.Unlock()
+	if s.closed {
+		return
+	}
 	s.closed = true
 	close(s.stream)
 	close(s.err)
 }
+
+// Subscription is a subscription delivering notifications of type T.
+//
+// It must be closed with Unsubscribe once no longer needed.
+type Subscription[T any] struct {
+	sub *subscription
+
+	streamOnce sync.Once
+	streamCh   chan T
+}
+
+func newTypedSubscription[T any](sub *subscription) *Subscription[T] {
+	return &Subscription[T]{sub: sub}
+}
+
+// Recv waits for the next notification; it returns ErrSubscriptionClosed
+// once the subscription is unsubscribed, or the error which ended it.
+func (s *Subscription[T]) Recv(ctx context.Context) (T, error) {
+	d, err := s.sub.Recv(ctx)
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	return d.(T), nil
+}
+
+// Err returns the channel delivering the error which ended the subscription.
+func (s *Subscription[T]) Err() <-chan error {
+	return s.sub.err
+}
+
+// Response returns a channel delivering the next notification.
+func (s *Subscription[T]) Response() <-chan T {
+	typedChan := make(chan T, 1)
+	go func(ch chan T) {
+		// TODO: will this subscription yield more than one result?
+		d, ok := <-s.sub.stream
+		if !ok {
+			return
+		}
+		ch <- d.(T)
+	}(typedChan)
+	return typedChan
+}
+
+// Stream returns a channel delivering all the notifications;
+// it is closed on Unsubscribe. The error which ends
+// the subscription is delivered by Err.
+//
+// Stream must not be used along with Recv, Response or All,
+// which consume the same notifications.
+func (s *Subscription[T]) Stream() <-chan T {
+	s.streamOnce.Do(func() {
+		s.streamCh = make(chan T)
+		go func() {
+			defer close(s.streamCh)
+			for {
+				select {
+				case <-s.sub.done:
+					return
+				case d, ok := <-s.sub.stream:
+					if !ok {
+						return
+					}
+					select {
+					case s.streamCh <- d.(T):
+					case <-s.sub.done:
+						return
+					}
+				}
+			}
+		}()
+	})
+	return s.streamCh
+}
+
+// All returns an iterator over the notifications, to be used
+// with range-over-func (Go 1.23+):
+//
+//	for got, err := range sub.All() {
+//	  if err != nil {
+//	    return err
+//	  }
+//	  ...
+//	}
+//
+// The iteration stops after yielding the error which ended
+// the subscription, or silently on Unsubscribe.
+func (s *Subscription[T]) All() func(yield func(T, error) bool) {
+	return func(yield func(T, error) bool) {
+		for {
+			d, err := s.Recv(context.Background())
+			if errors.Is(err, ErrSubscriptionClosed) {
+				return
+			}
+			if !yield(d, err) || err != nil {
+				return
+			}
+		}
+	}
+}
+
+// Unsubscribe closes the subscription; it can be called more than once.
+func (s *Subscription[T]) Unsubscribe() {
+	s.sub.Unsubscribe()
+}
+
+// Dropped returns the number of notifications discarded
+// because the buffer was full.
+func (s *Subscription[T]) Dropped() uint64 {
+	return s.sub.Dropped()
+}
+
+// confirm waits for the server to accept the subscription request;
+// the subscription is closed if it fails.
+func (s *Subscription[T]) confirm(ctx context.Context) error {
+	if err := s.sub.waitConfirmed(ctx); err != nil {
+		s.Unsubscribe()
+		return err
+	}
+	return nil
+}
+
+// confirmed is used by the context-aware subscribe methods: it waits
+// for the server to accept the subscription returned with err.
+func confirmed[S interface{ confirm(context.Context) error }](ctx context.Context, sub S, err error) (S, error) {
+	if err == nil {
+		err = sub.confirm(ctx)
+	}
+	if err != nil {
+		var zero S
+		return zero, err
+	}
+	return sub, nil
+}
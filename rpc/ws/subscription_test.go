@@ -0,0 +1,198 @@
+// Copyright 2021 github.com/gagliardetto
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ws
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+	"github.com/gagliardetto/solana-go/rpc/rpctest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// subscribeAccount subscribes to an account of the test server,
+// and sets its lamports from 1 to count without reading the notifications.
+func subscribeAccount(t *testing.T, ctx context.Context, opts *Options, count uint64, subOpts ...SubscriptionOption) (*rpctest.Server, solana.PublicKey, *AccountSubscription) {
+	srv := rpctest.NewServer()
+	t.Cleanup(srv.Close)
+
+	client, err := ConnectWithOptions(ctx, srv.WSURL, opts)
+	require.NoError(t, err)
+	t.Cleanup(client.Close)
+
+	pubkey := solana.NewWallet().PublicKey()
+	sub, err := client.AccountSubscribe(pubkey, rpc.CommitmentConfirmed, subOpts...)
+	require.NoError(t, err)
+	require.Eventually(t, func() bool { return srv.SubscriptionCount() == 1 }, 5*time.Second, 5*time.Millisecond)
+
+	owner := solana.NewWallet().PublicKey()
+	for lamports := uint64(1); lamports <= count; lamports++ {
+		srv.SetAccount(pubkey, rpctest.Account{Lamports: lamports, Owner: owner})
+	}
+	return srv, pubkey, sub
+}
+
+func recvLamports(t *testing.T, ctx context.Context, sub *AccountSubscription) uint64 {
+	got, err := sub.Recv(ctx)
+	require.NoError(t, err)
+	return got.Value.Lamports
+}
+
+func TestSubscription_OverflowDropNewest(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	_, _, sub := subscribeAccount(t, ctx, nil, 5, WithBufferSize(2), WithOverflowPolicy(OverflowDropNewest))
+	require.Eventually(t, func() bool { return sub.Dropped() == 3 }, 5*time.Second, 5*time.Millisecond)
+
+	assert.Equal(t, uint64(1), recvLamports(t, ctx, sub))
+	assert.Equal(t, uint64(2), recvLamports(t, ctx, sub))
+}
+
+func TestSubscription_OverflowDropOldest(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// The policy is set on the client, the buffer size on the subscription.
+	_, _, sub := subscribeAccount(t, ctx, &Options{OverflowPolicy: OverflowDropOldest}, 5, WithBufferSize(2))
+	require.Eventually(t, func() bool { return sub.Dropped() == 3 }, 5*time.Second, 5*time.Millisecond)
+
+	assert.Equal(t, uint64(4), recvLamports(t, ctx, sub))
+	assert.Equal(t, uint64(5), recvLamports(t, ctx, sub))
+}
+
+func TestSubscription_OverflowBlock(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	srv, pubkey, sub := subscribeAccount(t, ctx, &Options{SubscriptionBufferSize: 1, OverflowPolicy: OverflowBlock}, 5)
+	for lamports := uint64(1); lamports <= 5; lamports++ {
+		assert.Equal(t, lamports, recvLamports(t, ctx, sub))
+	}
+	assert.Zero(t, sub.Dropped())
+
+	// Unsubscribing releases the reading of the connection,
+	// blocked on the full buffer.
+	srv.SetAccount(pubkey, rpctest.Account{Lamports: 6})
+	srv.SetAccount(pubkey, rpctest.Account{Lamports: 7})
+	sub.Unsubscribe()
+	require.Eventually(t, func() bool { return srv.SubscriptionCount() == 0 }, 5*time.Second, 5*time.Millisecond)
+}
+
+func TestSubscription_OverflowClose(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	srv, _, sub := subscribeAccount(t, ctx, nil, 3, WithBufferSize(1))
+	require.Eventually(t, func() bool { return srv.SubscriptionCount() == 0 }, 5*time.Second, 5*time.Millisecond)
+	assert.Equal(t, uint64(1), sub.Dropped())
+
+	for {
+		_, err := sub.Recv(ctx)
+		if err != nil {
+			assert.Contains(t, err.Error(), "reached channel max capacity")
+			break
+		}
+	}
+}
+
+func TestSubscription_Context(t *testing.T) {
+	srv := rpctest.NewServer()
+	defer srv.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	client, err := Connect(ctx, srv.WSURL)
+	require.NoError(t, err)
+	defer client.Close()
+
+	// Confirmed by the server on return.
+	sub, err := client.SlotSubscribeContext(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, srv.SubscriptionCount())
+	sub.Unsubscribe()
+	sub.Unsubscribe()
+
+	// Rejected by the server.
+	_, err = client.LogsSubscribeContext(ctx, LogsSubscribeFilterAll, rpc.CommitmentConfirmed)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "subscription rejected")
+	legacy, err := client.LogsSubscribe(LogsSubscribeFilterAll, rpc.CommitmentConfirmed)
+	require.NoError(t, err)
+	_, err = legacy.Recv(ctx)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "subscription rejected")
+	legacy.Unsubscribe()
+
+	// Cancelled before the answer of the server:
+	// the subscription is removed once the server answers.
+	srv.SetLatency("accountSubscribe", 200*time.Millisecond)
+	shortCtx, shortCancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer shortCancel()
+	_, err = client.AccountSubscribeContext(shortCtx, solana.NewWallet().PublicKey(), rpc.CommitmentConfirmed)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	require.Eventually(t, func() bool { return srv.CallCount("accountUnsubscribe") == 1 }, 5*time.Second, 5*time.Millisecond)
+	require.Eventually(t, func() bool { return srv.SubscriptionCount() == 0 }, 5*time.Second, 5*time.Millisecond)
+}
+
+func TestSubscription_StreamAndAll(t *testing.T) {
+	srv := rpctest.NewServer()
+	defer srv.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	client, err := Connect(ctx, srv.WSURL)
+	require.NoError(t, err)
+	defer client.Close()
+
+	pubkey := solana.NewWallet().PublicKey()
+	owner := solana.NewWallet().PublicKey()
+	sub, err := client.AccountSubscribeContext(ctx, pubkey, rpc.CommitmentConfirmed)
+	require.NoError(t, err)
+
+	for lamports := uint64(1); lamports <= 4; lamports++ {
+		srv.SetAccount(pubkey, rpctest.Account{Lamports: lamports, Owner: owner})
+	}
+
+	var got []uint64
+	sub.All()(func(res *AccountResult, err error) bool {
+		require.NoError(t, err)
+		got = append(got, res.Value.Lamports)
+		return len(got) < 2
+	})
+	assert.Equal(t, []uint64{1, 2}, got)
+
+	stream := sub.Stream()
+	assert.Equal(t, uint64(3), (<-stream).Value.Lamports)
+	assert.Equal(t, uint64(4), (<-stream).Value.Lamports)
+
+	// The stream is closed on Unsubscribe, which can be repeated.
+	sub.Unsubscribe()
+	sub.Unsubscribe()
+	_, ok := <-stream
+	assert.False(t, ok)
+	_, err = sub.Recv(ctx)
+	assert.ErrorIs(t, err, ErrSubscriptionClosed)
+
+	// The iteration ends silently once unsubscribed.
+	sub.All()(func(*AccountResult, error) bool {
+		t.Fatal("unexpected notification")
+		return false
+	})
+}
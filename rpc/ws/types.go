@@ -73,6 +73,22 @@ type Options struct {
 	HttpHeader       http.Header
 	HandshakeTimeout time.Duration
 	ShortID          bool // some RPC do not support int63/uint64 id, so need to enable it to rand a int31/uint32 id
+
+	// If set, the client reconnects when the connection is lost,
+	// and subscribes again to all the active subscriptions.
+	Reconnect *ReconnectOptions
+
+	// Defaults of the subscriptions, overridable per subscription
+	// with SubscriptionOption (see SubscriptionOptions).
+	SubscriptionBufferSize int
+	OverflowPolicy         OverflowPolicy
+
+	// If set, identical subscription requests (same method and parameters)
+	// share one subscription on the server, which is unsubscribed
+	// when the last local subscription using it is closed.
+	// Signature subscriptions are never shared: the server closes
+	// them after their notification.
+	ShareSubscriptions bool
 }
 
 var DefaultHandshakeTimeout = 45 * time.Second
@@ -0,0 +1,85 @@
+// Copyright 2021 github.com/gagliardetto
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ws
+
+import (
+	stdjson "encoding/json"
+	"fmt"
+)
+
+// upstream is a subscription on the server. Its notifications are delivered
+// to one local subscription, or to several if shared (see Options.ShareSubscriptions).
+type upstream struct {
+	req   *request
+	subID uint64
+	// Whether subID is valid on the current connection;
+	// reset by a reconnection until the server answers again.
+	registered bool
+
+	unsubscribeMethod string
+
+	// Set if the upstream can be shared.
+	key string
+
+	// The local subscriptions, guarded by the lock of the client.
+	subs []*subscription
+
+	// Closed when the server answers the subscription request;
+	// confirmErr is set if the server rejected it.
+	confirmed  chan struct{}
+	confirmErr error
+}
+
+func newUpstream(req *request, unsubscribeMethod string) *upstream {
+	return &upstream{
+		req:               req,
+		unsubscribeMethod: unsubscribeMethod,
+		confirmed:         make(chan struct{}),
+	}
+}
+
+// confirm sets the answer of the server to the subscription request.
+// Must be called with the lock of the client held.
+func (up *upstream) confirm(err error) {
+	select {
+	case <-up.confirmed:
+		// Already confirmed, before a reconnection.
+	default:
+		up.confirmErr = err
+		close(up.confirmed)
+	}
+}
+
+// remove removes a local subscription; it returns false if not found.
+// Must be called with the lock of the client held.
+func (up *upstream) remove(sub *subscription) bool {
+	for i, candidate := range up.subs {
+		if candidate == sub {
+			up.subs = append(up.subs[:i], up.subs[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// subscriptionKey identifies the identical subscription requests:
+// same method, same parameters.
+func subscriptionKey(req *request) (string, error) {
+	params, err := stdjson.Marshal(req.Params)
+	if err != nil {
+		return "", fmt.Errorf("unable to encode subscription params: %w", err)
+	}
+	return req.Method + ":" + string(params), nil
+}
@@ -0,0 +1,130 @@
+// Copyright 2021 github.com/gagliardetto
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ws
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+	"github.com/gagliardetto/solana-go/rpc/rpctest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_ShareSubscriptions(t *testing.T) {
+	srv := rpctest.NewServer()
+	defer srv.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	client, err := ConnectWithOptions(ctx, srv.WSURL, &Options{ShareSubscriptions: true})
+	require.NoError(t, err)
+	defer client.Close()
+
+	pubkey := solana.NewWallet().PublicKey()
+	owner := solana.NewWallet().PublicKey()
+	first, err := client.AccountSubscribeContext(ctx, pubkey, rpc.CommitmentConfirmed)
+	require.NoError(t, err)
+	second, err := client.AccountSubscribeContext(ctx, pubkey, rpc.CommitmentConfirmed, WithBufferSize(10))
+	require.NoError(t, err)
+	// Different options: not shared.
+	finalized, err := client.AccountSubscribeContext(ctx, pubkey, rpc.CommitmentFinalized)
+	require.NoError(t, err)
+	assert.Equal(t, 2, srv.SubscriptionCount())
+	assert.Equal(t, 2, srv.CallCount("accountSubscribe"))
+
+	srv.SetAccount(pubkey, rpctest.Account{Lamports: 1, Owner: owner})
+	got1, err := first.Recv(ctx)
+	require.NoError(t, err)
+	got2, err := second.Recv(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1), got1.Value.Lamports)
+	assert.Equal(t, uint64(1), got2.Value.Lamports)
+	// Each subscription decodes its own copy.
+	assert.NotSame(t, got1, got2)
+
+	// The server subscription is kept while used.
+	first.Unsubscribe()
+	first.Unsubscribe()
+	srv.SetAccount(pubkey, rpctest.Account{Lamports: 2, Owner: owner})
+	got2, err = second.Recv(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(2), got2.Value.Lamports)
+	assert.Equal(t, 2, srv.SubscriptionCount())
+
+	second.Unsubscribe()
+	require.Eventually(t, func() bool { return srv.SubscriptionCount() == 1 }, 5*time.Second, 5*time.Millisecond)
+	finalized.Unsubscribe()
+	require.Eventually(t, func() bool { return srv.SubscriptionCount() == 0 }, 5*time.Second, 5*time.Millisecond)
+
+	// Subscribing again creates a new server subscription.
+	again, err := client.AccountSubscribeContext(ctx, pubkey, rpc.CommitmentConfirmed)
+	require.NoError(t, err)
+	defer again.Unsubscribe()
+	assert.Equal(t, 1, srv.SubscriptionCount())
+}
+
+func TestClient_ShareSubscriptionsDisabled(t *testing.T) {
+	srv := rpctest.NewServer()
+	defer srv.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	client, err := Connect(ctx, srv.WSURL)
+	require.NoError(t, err)
+	defer client.Close()
+
+	for i := 0; i < 2; i++ {
+		sub, err := client.SlotSubscribeContext(ctx)
+		require.NoError(t, err)
+		defer sub.Unsubscribe()
+	}
+	assert.Equal(t, 2, srv.SubscriptionCount())
+}
+
+func TestClient_ShareSubscriptionsSkipsSignatures(t *testing.T) {
+	srv := rpctest.NewServer()
+	defer srv.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	client, err := ConnectWithOptions(ctx, srv.WSURL, &Options{ShareSubscriptions: true})
+	require.NoError(t, err)
+	defer client.Close()
+
+	sig := solana.Signature{1, 2, 3}
+	first, err := client.SignatureSubscribe(sig, rpc.CommitmentConfirmed)
+	require.NoError(t, err)
+	require.Eventually(t, func() bool { return srv.SubscriptionCount() == 1 }, 5*time.Second, 5*time.Millisecond)
+
+	// The server closes the subscription after its notification.
+	srv.SetSignatureStatus(sig, &rpc.SignatureStatusesResult{Slot: 10, ConfirmationStatus: rpc.ConfirmationStatusConfirmed})
+	got, err := first.Recv(ctx)
+	require.NoError(t, err)
+	assert.Nil(t, got.Value.Err)
+	require.Eventually(t, func() bool { return srv.SubscriptionCount() == 0 }, 5*time.Second, 5*time.Millisecond)
+
+	// A later identical subscription is a new one on the server.
+	second, err := client.SignatureSubscribe(sig, rpc.CommitmentConfirmed)
+	require.NoError(t, err)
+	defer second.Unsubscribe()
+	got, err = second.Recv(ctx)
+	require.NoError(t, err)
+	assert.Nil(t, got.Value.Err)
+	assert.Equal(t, 2, srv.CallCount("signatureSubscribe"))
+}
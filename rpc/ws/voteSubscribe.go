@@ -37,7 +37,7 @@ type VoteResult struct {
 // This subscription is unstable and only available if the validator
 // was started with the --rpc-pubsub-enable-vote-subscription flag.
 // The format of this subscription may change in the future.
-func (cl *Client) VoteSubscribe() (*VoteSubscription, error) {
+func (cl *Client) VoteSubscribe(subOpts ...SubscriptionOption) (*VoteSubscription, error) {
 	genSub, err := cl.subscribe(
 		nil,
 		nil,
@@ -48,50 +48,26 @@ func (cl *Client) VoteSubscribe() (*VoteSubscription, error) {
 			err := decodeResponseFromMessage(msg, &res)
 			return &res, err
 		},
+		subOpts,
 	)
 	if err != nil {
 		return nil, err
 	}
 	return &VoteSubscription{
-		sub: genSub,
+		newTypedSubscription[*VoteResult](genSub),
 	}, nil
 }
 
-type VoteSubscription struct {
-	sub *Subscription
-}
-
-func (sw *VoteSubscription) Recv(ctx context.Context) (*VoteResult, error) {
-	select {
-	case <-ctx.Done():
-		return nil, ctx.Err()
-	case d, ok := <-sw.sub.stream:
-		if !ok {
-			return nil, ErrSubscriptionClosed
-		}
-		return d.(*VoteResult), nil
-	case err := <-sw.sub.err:
-		return nil, err
-	}
+// VoteSubscribeContext is like VoteSubscribe, but waits for the server
+// to accept the subscription; if ctx is done first, the subscription is cancelled.
+func (cl *Client) VoteSubscribeContext(
+	ctx context.Context,
+	subOpts ...SubscriptionOption,
+) (*VoteSubscription, error) {
+	sub, err := cl.VoteSubscribe(subOpts...)
+	return confirmed(ctx, sub, err)
 }
 
-func (sw *VoteSubscription) Err() <-chan error {
-	return sw.sub.err
-}
-
-func (sw *VoteSubscription) Response() <-chan *VoteResult {
-	typedChan := make(chan *VoteResult, 1)
-	go func(ch chan *VoteResult) {
-		// TODO: will this subscription yield more than one result?
-		d, ok := <-sw.sub.stream
-		if !ok {
-			return
-		}
-		ch <- d.(*VoteResult)
-	}(typedChan)
-	return typedChan
-}
-
-func (sw *VoteSubscription) Unsubscribe() {
-	sw.sub.Unsubscribe()
+type VoteSubscription struct {
+	*Subscription[*VoteResult]
 }
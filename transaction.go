@@ -186,9 +186,16 @@ var debugNewTransaction = false
 type TransactionBuilder struct {
 	instructions    []Instruction
 	recentBlockHash Hash
+	blockhashSource BlockhashSource
 	opts            []TransactionOption
 }
 
+// BlockhashSource provides recent blockhashes to a TransactionBuilder
+// (e.g. *rpc.BlockhashProvider).
+type BlockhashSource interface {
+	RecentBlockhash() (Hash, error)
+}
+
 // NewTransactionBuilder creates a new instruction builder.
 func NewTransactionBuilder() *TransactionBuilder {
 	return &TransactionBuilder{}
@@ -206,6 +213,13 @@ func (builder *TransactionBuilder) SetRecentBlockHash(recentBlockHash Hash) *Tra
 	return builder
 }
 
+// SetBlockhashSource sets the source of the recent blockhash,
+// used by Build when no blockhash was set with SetRecentBlockHash.
+func (builder *TransactionBuilder) SetBlockhashSource(source BlockhashSource) *TransactionBuilder {
+	builder.blockhashSource = source
+	return builder
+}
+
 // WithOpt adds a TransactionOption.
 func (builder *TransactionBuilder) WithOpt(opt TransactionOption) *TransactionBuilder {
 	builder.opts = append(builder.opts, opt)
@@ -221,9 +235,17 @@ func (builder *TransactionBuilder) SetFeePayer(feePayer PublicKey) *TransactionB
 
 // Build builds and returns a *Transaction.
 func (builder *TransactionBuilder) Build() (*Transaction, error) {
+	recentBlockHash := builder.recentBlockHash
+	if recentBlockHash.IsZero() && builder.blockhashSource != nil {
+		var err error
+		recentBlockHash, err = builder.blockhashSource.RecentBlockhash()
+		if err != nil {
+			return nil, fmt.Errorf("unable to get recent blockhash: %w", err)
+		}
+	}
 	return NewTransaction(
 		builder.instructions,
-		builder.recentBlockHash,
+		recentBlockHash,
 		builder.opts...,
 	)
 }
@@ -107,6 +107,40 @@ func TestNewTransaction(t *testing.T) {
 	})
 }
 
+type staticBlockhashSource Hash
+
+func (src staticBlockhashSource) RecentBlockhash() (Hash, error) {
+	return Hash(src), nil
+}
+
+func TestTransactionBuilder_BlockhashSource(t *testing.T) {
+	instruction := &testTransactionInstructions{
+		accounts: []*AccountMeta{
+			{PublicKey: MustPublicKeyFromBase58("A9QnpgfhCkmiBSjgBuWk76Wo3HxzxvDopUq9x6UUMmjn"), IsSigner: true, IsWritable: true},
+		},
+		data:      []byte{0xaa, 0xbb},
+		programID: MustPublicKeyFromBase58("11111111111111111111111111111111"),
+	}
+	fromSource := MustHashFromBase58("A9QnpgfhCkmiBSjgBuWk76Wo3HxzxvDopUq9x6UUMmjn")
+	explicit := MustHashFromBase58("9hFtYBYmBJCVguRYs9pBTWKYAFoKfjYR7zBPpEkVsmD")
+
+	trx, err := NewTransactionBuilder().
+		AddInstruction(instruction).
+		SetBlockhashSource(staticBlockhashSource(fromSource)).
+		Build()
+	require.NoError(t, err)
+	assert.Equal(t, fromSource, trx.Message.RecentBlockhash)
+
+	// An explicit blockhash takes precedence.
+	trx, err = NewTransactionBuilder().
+		AddInstruction(instruction).
+		SetBlockhashSource(staticBlockhashSource(fromSource)).
+		SetRecentBlockHash(explicit).
+		Build()
+	require.NoError(t, err)
+	assert.Equal(t, explicit, trx.Message.RecentBlockhash)
+}
+
 func TestPartialSignTransaction(t *testing.T) {
 	signers := []PrivateKey{
 		NewWallet().PrivateKey,